@@ -52,7 +52,7 @@ type chain struct {
 	lggr           logger.Logger
 }
 
-func newChain(id string, cfg terra.Config, db *sqlx.DB, ks keystore.Terra, logCfg pg.QConfig, eb pg.EventBroadcaster, orm types.ORM, lggr logger.Logger) (*chain, error) {
+func newChain(id string, cfg terra.Config, db *sqlx.DB, ks keystore.Terra, logCfg pg.QConfig, eb pg.EventBroadcaster, orm types.ORM, lggr logger.Logger, txmOpts ...terratxm.TxmOpt) (*chain, error) {
 	lggr = lggr.With("terraChainID", id)
 	var ch = chain{
 		id:   id,
@@ -72,7 +72,7 @@ func newChain(id string, cfg terra.Config, db *sqlx.DB, ks keystore.Terra, logCf
 			}, nil
 		}),
 	}, lggr)
-	ch.txm = terratxm.NewTxm(db, tc, *gpe, ch.id, cfg, ks, lggr, logCfg, eb)
+	ch.txm = terratxm.NewTxm(db, tc, *gpe, ch.id, cfg, ks, lggr, logCfg, eb, txmOpts...)
 	ch.balanceMonitor = monitor.NewBalanceMonitor(ch.id, cfg, lggr, ks, ch.Reader)
 
 	return &ch, nil
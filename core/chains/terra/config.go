@@ -6,14 +6,18 @@ import (
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	"go.uber.org/multierr"
 	"golang.org/x/exp/slices"
 	"gopkg.in/guregu/null.v4"
 
+	"github.com/smartcontractkit/chainlink-relay/pkg/utils"
+
 	"github.com/smartcontractkit/chainlink-terra/pkg/terra"
 	tercfg "github.com/smartcontractkit/chainlink-terra/pkg/terra/config"
 	"github.com/smartcontractkit/chainlink-terra/pkg/terra/db"
+	"github.com/smartcontractkit/chainlink/core/chains/terra/terratxm"
 	"github.com/smartcontractkit/chainlink/core/chains/terra/types"
 	v2 "github.com/smartcontractkit/chainlink/core/config/v2"
 )
@@ -170,13 +174,19 @@ type TerraConfig struct {
 	ChainID *string
 	Enabled *bool
 	tercfg.Chain
-	Nodes TerraNodes
+	TxManager TxManager `toml:",omitempty"`
+	Nodes     TerraNodes
 }
 
 func (c *TerraConfig) IsEnabled() bool {
 	return c.Enabled == nil || *c.Enabled
 }
 
+func (c *TerraConfig) SetDefaults() {
+	c.Chain.SetDefaults()
+	c.TxManager.setDefaults()
+}
+
 func (c *TerraConfig) SetFrom(f *TerraConfig) {
 	if f.ChainID != nil {
 		c.ChainID = f.ChainID
@@ -185,9 +195,168 @@ func (c *TerraConfig) SetFrom(f *TerraConfig) {
 		c.Enabled = f.Enabled
 	}
 	setFromChain(&c.Chain, &f.Chain)
+	c.TxManager.setFrom(&f.TxManager)
 	c.Nodes.SetFrom(&f.Nodes)
 }
 
+// TxManager holds the optional settings of the chain's tx manager, see terratxm.TxmOpt. They're only available to
+// TOML configured chains.
+type TxManager struct {
+	BatchDeadline          *utils.Duration
+	ConfirmationDepth      *int64
+	MaxFee                 *string
+	StartupRampBatches     *int64
+	StartupRampInitialMsgs *int64
+	Health                 TxManagerHealth `toml:",omitempty"`
+}
+
+func (t *TxManager) setDefaults() {
+	if t.BatchDeadline == nil {
+		t.BatchDeadline = utils.MustNewDuration(0)
+	}
+	if t.ConfirmationDepth == nil {
+		t.ConfirmationDepth = new(int64)
+	}
+	if t.StartupRampBatches == nil {
+		t.StartupRampBatches = new(int64)
+	}
+	if t.StartupRampInitialMsgs == nil {
+		t.StartupRampInitialMsgs = new(int64)
+	}
+	t.Health.setDefaults()
+}
+
+func (t *TxManager) setFrom(f *TxManager) {
+	if f.BatchDeadline != nil {
+		t.BatchDeadline = f.BatchDeadline
+	}
+	if f.ConfirmationDepth != nil {
+		t.ConfirmationDepth = f.ConfirmationDepth
+	}
+	if f.MaxFee != nil {
+		t.MaxFee = f.MaxFee
+	}
+	if f.StartupRampBatches != nil {
+		t.StartupRampBatches = f.StartupRampBatches
+	}
+	if f.StartupRampInitialMsgs != nil {
+		t.StartupRampInitialMsgs = f.StartupRampInitialMsgs
+	}
+	t.Health.setFrom(&f.Health)
+}
+
+func (t *TxManager) ValidateConfig() (err error) {
+	if t.ConfirmationDepth != nil && *t.ConfirmationDepth < 0 {
+		err = multierr.Append(err, v2.ErrInvalid{Name: "ConfirmationDepth", Value: *t.ConfirmationDepth, Msg: "must not be negative"})
+	}
+	if t.MaxFee != nil {
+		if _, perr := sdk.ParseCoinNormalized(*t.MaxFee); perr != nil {
+			err = multierr.Append(err, v2.ErrInvalid{Name: "MaxFee", Value: *t.MaxFee, Msg: perr.Error()})
+		}
+	}
+	if t.StartupRampBatches != nil && *t.StartupRampBatches < 0 {
+		err = multierr.Append(err, v2.ErrInvalid{Name: "StartupRampBatches", Value: *t.StartupRampBatches, Msg: "must not be negative"})
+	}
+	if t.StartupRampInitialMsgs != nil && *t.StartupRampInitialMsgs < 0 {
+		err = multierr.Append(err, v2.ErrInvalid{Name: "StartupRampInitialMsgs", Value: *t.StartupRampInitialMsgs, Msg: "must not be negative"})
+	}
+	return
+}
+
+// txmOpts returns the terratxm options for the settings, leaving those not set to the txm's defaults.
+func (t *TxManager) txmOpts() ([]terratxm.TxmOpt, error) {
+	var opts []terratxm.TxmOpt
+	if t.BatchDeadline != nil && t.BatchDeadline.Duration() > 0 {
+		opts = append(opts, terratxm.WithBatchDeadline(t.BatchDeadline.Duration()))
+	}
+	if t.ConfirmationDepth != nil && *t.ConfirmationDepth > 0 {
+		opts = append(opts, terratxm.WithConfirmationDepth(*t.ConfirmationDepth))
+	}
+	if t.MaxFee != nil {
+		maxFee, err := sdk.ParseCoinNormalized(*t.MaxFee)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid MaxFee %q", *t.MaxFee)
+		}
+		opts = append(opts, terratxm.WithMaxFee(maxFee))
+	}
+	if t.StartupRampBatches != nil && *t.StartupRampBatches > 0 {
+		var initialMsgs int64
+		if t.StartupRampInitialMsgs != nil {
+			initialMsgs = *t.StartupRampInitialMsgs
+		}
+		opts = append(opts, terratxm.WithStartupRamp(int(*t.StartupRampBatches), initialMsgs))
+	}
+	return append(opts, terratxm.WithHealthConfig(t.Health.healthConfig())), nil
+}
+
+// TxManagerHealth holds the thresholds of the tx manager's health check, see terratxm.HealthConfig.
+type TxManagerHealth struct {
+	MaxConsecutiveFailures *int64
+	MaxUnstartedBacklog    *int64
+	StaleBatchTimeout      *utils.Duration
+	MaxUnstartedAge        *utils.Duration
+	MaxSequenceDrift       *int64
+}
+
+func (h *TxManagerHealth) setDefaults() {
+	d := terratxm.DefaultHealthConfig()
+	if h.MaxConsecutiveFailures == nil {
+		v := int64(d.MaxConsecutiveFailures)
+		h.MaxConsecutiveFailures = &v
+	}
+	if h.MaxUnstartedBacklog == nil {
+		h.MaxUnstartedBacklog = &d.MaxUnstartedBacklog
+	}
+	if h.StaleBatchTimeout == nil {
+		h.StaleBatchTimeout = utils.MustNewDuration(d.StaleBatchTimeout)
+	}
+	if h.MaxUnstartedAge == nil {
+		h.MaxUnstartedAge = utils.MustNewDuration(d.MaxUnstartedAge)
+	}
+	if h.MaxSequenceDrift == nil {
+		h.MaxSequenceDrift = &d.MaxSequenceDrift
+	}
+}
+
+func (h *TxManagerHealth) setFrom(f *TxManagerHealth) {
+	if f.MaxConsecutiveFailures != nil {
+		h.MaxConsecutiveFailures = f.MaxConsecutiveFailures
+	}
+	if f.MaxUnstartedBacklog != nil {
+		h.MaxUnstartedBacklog = f.MaxUnstartedBacklog
+	}
+	if f.StaleBatchTimeout != nil {
+		h.StaleBatchTimeout = f.StaleBatchTimeout
+	}
+	if f.MaxUnstartedAge != nil {
+		h.MaxUnstartedAge = f.MaxUnstartedAge
+	}
+	if f.MaxSequenceDrift != nil {
+		h.MaxSequenceDrift = f.MaxSequenceDrift
+	}
+}
+
+// healthConfig returns the thresholds, with the defaults of those not set.
+func (h *TxManagerHealth) healthConfig() terratxm.HealthConfig {
+	cfg := terratxm.DefaultHealthConfig()
+	if h.MaxConsecutiveFailures != nil {
+		cfg.MaxConsecutiveFailures = int(*h.MaxConsecutiveFailures)
+	}
+	if h.MaxUnstartedBacklog != nil {
+		cfg.MaxUnstartedBacklog = *h.MaxUnstartedBacklog
+	}
+	if h.StaleBatchTimeout != nil {
+		cfg.StaleBatchTimeout = h.StaleBatchTimeout.Duration()
+	}
+	if h.MaxUnstartedAge != nil {
+		cfg.MaxUnstartedAge = h.MaxUnstartedAge.Duration()
+	}
+	if h.MaxSequenceDrift != nil {
+		cfg.MaxSequenceDrift = *h.MaxSequenceDrift
+	}
+	return cfg
+}
+
 func setFromChain(c, f *tercfg.Chain) {
 	if f.BlockRate != nil {
 		c.BlockRate = f.BlockRate
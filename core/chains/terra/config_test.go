@@ -2,10 +2,16 @@ package terra
 
 import (
 	"testing"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-relay/pkg/utils"
+
+	"github.com/smartcontractkit/chainlink/core/chains/terra/terratxm"
 )
 
 func Test_sdkDecFromDecimal(t *testing.T) {
@@ -23,3 +29,57 @@ func Test_sdkDecFromDecimal(t *testing.T) {
 		})
 	}
 }
+
+func TestTxManager(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		var c TerraConfig
+		c.SetDefaults()
+		require.NoError(t, c.TxManager.ValidateConfig())
+		assert.Equal(t, terratxm.DefaultHealthConfig(), c.TxManager.Health.healthConfig())
+
+		opts, err := c.TxManager.txmOpts()
+		require.NoError(t, err)
+		assert.Len(t, opts, 1, "only the health thresholds")
+	})
+
+	t.Run("set", func(t *testing.T) {
+		tm := TxManager{
+			BatchDeadline:          utils.MustNewDuration(time.Minute),
+			ConfirmationDepth:      ptr[int64](3),
+			MaxFee:                 ptr("1000000uluna"),
+			StartupRampBatches:     ptr[int64](5),
+			StartupRampInitialMsgs: ptr[int64](10),
+			Health: TxManagerHealth{
+				MaxConsecutiveFailures: ptr[int64](2),
+				StaleBatchTimeout:      utils.MustNewDuration(time.Hour),
+			},
+		}
+		require.NoError(t, tm.ValidateConfig())
+		exp := terratxm.DefaultHealthConfig()
+		exp.MaxConsecutiveFailures = 2
+		exp.StaleBatchTimeout = time.Hour
+		assert.Equal(t, exp, tm.Health.healthConfig())
+
+		opts, err := tm.txmOpts()
+		require.NoError(t, err)
+		assert.Len(t, opts, 5)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tm := TxManager{
+			ConfirmationDepth:  ptr[int64](-1),
+			MaxFee:             ptr("lots"),
+			StartupRampBatches: ptr[int64](-2),
+		}
+		err := tm.ValidateConfig()
+		require.Error(t, err)
+		for _, name := range []string{"ConfirmationDepth", "MaxFee", "StartupRampBatches"} {
+			assert.Contains(t, err.Error(), name)
+		}
+
+		_, err = tm.txmOpts()
+		require.ErrorContains(t, err, "invalid MaxFee")
+	})
+}
+
+func ptr[T any](t T) *T { return &t }
@@ -0,0 +1,43 @@
+package terratxm
+
+import (
+	"sort"
+	"time"
+
+	"github.com/smartcontractkit/chainlink-terra/pkg/terra"
+)
+
+// WithBatchDeadline bounds the time spent sending a batch. Each sender's tx is confirmed before the next sender's is
+// sent, so a batch with many senders can take far longer than the block rate, delaying the next poll along with msgs
+// enqueued meanwhile. Once a batch has been sending for longer than d, no other sender is started, and the msgs of the
+// remaining senders are left Started to be sent first by the next batch. The sender being sent when the deadline passes
+// is not interrupted, so a batch can still exceed d by the time it takes to send and confirm a single tx. A batch sent
+// as a single multi-signer tx, see WithMultiSignerBatching, is not bounded. By default, batches are not bounded.
+func WithBatchDeadline(d time.Duration) TxmOpt {
+	return func(txm *Txm) {
+		txm.batchDeadline = d
+	}
+}
+
+// orderSenders returns the senders of msgsByFrom in the order they should be sent: those deferred by the last batch
+// first, so they aren't starved by senders ahead of them, then by their oldest msg.
+func (txm *Txm) orderSenders(msgsByFrom map[string]terra.Msgs) []string {
+	senders := make([]string, 0, len(msgsByFrom))
+	for s := range msgsByFrom {
+		senders = append(senders, s)
+	}
+	sort.Slice(senders, func(i, j int) bool {
+		_, di := txm.deferredSenders[senders[i]]
+		_, dj := txm.deferredSenders[senders[j]]
+		if di != dj {
+			return di
+		}
+		// Msgs are sorted oldest first
+		mi, mj := msgsByFrom[senders[i]][0], msgsByFrom[senders[j]][0]
+		if !mi.CreatedAt.Equal(mj.CreatedAt) {
+			return mi.CreatedAt.Before(mj.CreatedAt)
+		}
+		return mi.ID < mj.ID
+	})
+	return senders
+}
@@ -0,0 +1,76 @@
+package terratxm
+
+import (
+	"github.com/smartcontractkit/chainlink/core/logger"
+)
+
+// broadcastResultsBuffer is the number of broadcast results buffered while the callback of WithOnBroadcast is busy.
+const broadcastResultsBuffer = 100
+
+// BroadcastResult is the outcome of broadcasting a tx, see WithOnBroadcast.
+type BroadcastResult struct {
+	// Senders are the addresses signing the tx, several for a multi-signer tx, see WithMultiSignerBatching.
+	Senders []string
+	// MsgIDs are the ids of the msgs sent in the tx.
+	MsgIDs []int64
+	// TxHash is the hash of the tx, set even if broadcasting it failed.
+	TxHash string
+	// Err is the reason the node didn't accept the tx, nil if it did.
+	Err error
+}
+
+// broadcastHook delivers broadcast results to the callback of WithOnBroadcast.
+type broadcastHook struct {
+	onBroadcast func(BroadcastResult)
+	results     chan BroadcastResult
+	done        chan struct{}
+}
+
+// WithOnBroadcast calls onBroadcast with the result of each attempt to broadcast a tx, whether the node accepted it or
+// not, e.g. to react to msgs being sent without polling the db. A tx accepted by the node may still not land, see
+// pg.ChannelTerraMsgConfirmed to follow confirmations. onBroadcast is called on its own goroutine, in the order of the
+// broadcasts, so a slow callback doesn't hold up sending msgs: up to 100 results are buffered while it's busy, and
+// further ones are dropped with a warning. A panic in onBroadcast is logged and recovered.
+func WithOnBroadcast(onBroadcast func(BroadcastResult)) TxmOpt {
+	return func(txm *Txm) {
+		txm.broadcastHook = &broadcastHook{
+			onBroadcast: onBroadcast,
+			results:     make(chan BroadcastResult, broadcastResultsBuffer),
+			done:        make(chan struct{}),
+		}
+	}
+}
+
+// notifyBroadcast passes result to the callback of WithOnBroadcast, if set, without blocking.
+func (txm *Txm) notifyBroadcast(result BroadcastResult) {
+	if txm.broadcastHook == nil {
+		return
+	}
+	select {
+	case txm.broadcastHook.results <- result:
+	default:
+		txm.lggr.Warnw("broadcast callback is falling behind, dropping result", "hash", result.TxHash, "msgs", result.MsgIDs)
+	}
+}
+
+// run calls the callback with each result until stop is closed.
+func (h *broadcastHook) run(stop <-chan struct{}, lggr logger.Logger) {
+	defer close(h.done)
+	for {
+		select {
+		case <-stop:
+			return
+		case result := <-h.results:
+			h.call(result, lggr)
+		}
+	}
+}
+
+func (h *broadcastHook) call(result BroadcastResult, lggr logger.Logger) {
+	defer func() {
+		if rerr := recover(); rerr != nil {
+			lggr.Errorw("broadcast callback panicked", "err", rerr, "hash", result.TxHash, "msgs", result.MsgIDs)
+		}
+	}()
+	h.onBroadcast(result)
+}
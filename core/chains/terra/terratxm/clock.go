@@ -0,0 +1,47 @@
+package terratxm
+
+import "time"
+
+// Clock is the source of time of the Txm: the current time, and the waits between batches and between confirmation
+// polls. It's the real clock by default, see WithClock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel receiving the current time once d elapsed, like time.After.
+	After(d time.Duration) <-chan time.Time
+	// NewTicker returns a Ticker ticking every d, like time.NewTicker.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker ticks every period until stopped, see Clock.NewTicker.
+type Ticker interface {
+	// C returns the channel receiving the ticks.
+	C() <-chan time.Time
+	// Stop stops the ticker. Like time.Ticker.Stop, it doesn't close the channel.
+	Stop()
+}
+
+// WithClock replaces the real clock of the txm with clock, e.g. with a fake one so that tests can trigger batches and
+// confirmation polls by advancing time rather than waiting. Every time read by the txm comes from clock, including the
+// timeouts of msgs, the health checks, the not before time of scheduled msgs, see EnqueueWithNotBefore, and the
+// cooldowns of WithFailoverClients. The creation and update times of msgs are written from clock too, rather than from
+// the db's, so msg timeouts compare times of the same clock.
+func WithClock(clock Clock) TxmOpt {
+	return func(txm *Txm) {
+		txm.clock = clock
+		txm.orm.now = clock.Now
+	}
+}
+
+// realClock is the Clock of the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (realClock) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
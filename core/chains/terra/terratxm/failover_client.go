@@ -0,0 +1,264 @@
+package terratxm
+
+import (
+	"context"
+	"io"
+	"net"
+	"regexp"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	tmtypes "github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+
+	terraclient "github.com/smartcontractkit/chainlink-terra/pkg/terra/client"
+	"github.com/smartcontractkit/terra.go/key"
+
+	"github.com/smartcontractkit/chainlink/core/logger"
+)
+
+const (
+	// failoverCooldown is how long an endpoint is tried last after a transient error. It doubles with each consecutive
+	// transient error, up to maxFailoverCooldown.
+	failoverCooldown    = 10 * time.Second
+	maxFailoverCooldown = 5 * time.Minute
+)
+
+// WithFailoverClients sends requests through several endpoints instead of the client returned by the tc func passed to
+// NewTxm, which is then unused. Each request goes to the first healthy endpoint, in the given order, and is retried on
+// the next one if it fails with a transient error, e.g. a connection refused or a timeout. Other errors, e.g. a failed
+// simulation, are returned right away, as any endpoint would return them. An endpoint failing with a transient error is
+// unhealthy for a cooldown which grows with consecutive failures, during which it's only tried after the healthy ones.
+//
+// A broadcast failing on a transient error may have reached the endpoint's mempool anyway, in which case broadcasting
+// it through the next endpoint fails on its sequence and the msgs are retried like for any failed broadcast.
+func WithFailoverClients(clients ...terraclient.ReaderWriter) TxmOpt {
+	return func(txm *Txm) {
+		// read the clock when used, so it's the txm's even if set by a later option, see WithClock
+		fc := newFailoverClient(txm.lggr, func() time.Time { return txm.clock.Now() }, clients...)
+		txm.tc = func() (terraclient.ReaderWriter, error) {
+			return fc, nil
+		}
+	}
+}
+
+var _ terraclient.ReaderWriter = (*failoverClient)(nil)
+
+// failoverClient is a terraclient.ReaderWriter which fails over between endpoints, see WithFailoverClients.
+type failoverClient struct {
+	lggr logger.Logger
+	now  func() time.Time
+
+	mu        sync.Mutex
+	endpoints []*failoverEndpoint
+}
+
+type failoverEndpoint struct {
+	client terraclient.ReaderWriter
+	// failures is the number of consecutive transient errors of the endpoint.
+	failures int
+	// unhealthyUntil is the end of the endpoint's cooldown after its last transient error.
+	unhealthyUntil time.Time
+}
+
+func newFailoverClient(lggr logger.Logger, now func() time.Time, clients ...terraclient.ReaderWriter) *failoverClient {
+	fc := &failoverClient{lggr: lggr.Named("FailoverClient"), now: now}
+	for _, c := range clients {
+		fc.endpoints = append(fc.endpoints, &failoverEndpoint{client: c})
+	}
+	return fc
+}
+
+// order returns the indexes of the endpoints in the order they should be tried: the healthy ones in their configured
+// order, followed by the unhealthy ones whose cooldown ends first.
+func (c *failoverClient) order() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	order := make([]int, len(c.endpoints))
+	until := make([]time.Time, len(c.endpoints))
+	for i, e := range c.endpoints {
+		order[i] = i
+		if e.unhealthyUntil.After(now) {
+			until[i] = e.unhealthyUntil
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return until[order[i]].Before(until[order[j]])
+	})
+	return order
+}
+
+func (c *failoverClient) markHealthy(i int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.endpoints[i].failures = 0
+	c.endpoints[i].unhealthyUntil = time.Time{}
+}
+
+func (c *failoverClient) markUnhealthy(i int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.endpoints[i]
+	e.failures++
+	cooldown := failoverCooldown
+	for n := 1; n < e.failures && cooldown < maxFailoverCooldown; n++ {
+		cooldown *= 2
+	}
+	if cooldown > maxFailoverCooldown {
+		cooldown = maxFailoverCooldown
+	}
+	e.unhealthyUntil = c.now().Add(cooldown)
+}
+
+// do calls f with each endpoint in turn until it succeeds or fails with an error which isn't transient.
+func (c *failoverClient) do(method string, f func(terraclient.ReaderWriter) error) error {
+	if len(c.endpoints) == 0 {
+		return errors.Errorf("%s: no endpoints", method)
+	}
+	var errs error
+	for _, i := range c.order() {
+		err := f(c.endpoints[i].client)
+		if err == nil || !isTransientClientError(err) {
+			// the endpoint responded, even if with an error
+			c.markHealthy(i)
+			return err
+		}
+		c.markUnhealthy(i)
+		c.lggr.Warnw("Request failed on a transient error, trying the next endpoint", "method", method, "endpoint", i, "err", err)
+		errs = multierr.Append(errs, errors.Wrapf(err, "endpoint %d", i))
+	}
+	return errors.Wrapf(errs, "%s failed on all %d endpoints", method, len(c.endpoints))
+}
+
+// transientClientErrRegexp matches transient errors which were flattened into strings along the way, e.g. by the
+// tendermint RPC client or by gRPC.
+var transientClientErrRegexp = regexp.MustCompile(`connection refused|connection reset|broken pipe|i/o timeout|no such host|` +
+	`EOF|code = Unavailable|code = DeadlineExceeded|code = ResourceExhausted|\b50[234] `)
+
+// isTransientClientError returns true if err was caused by the endpoint or the network rather than by the request, so
+// it may succeed on another endpoint.
+func isTransientClientError(err error) bool {
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET):
+		return true
+	}
+	return transientClientErrRegexp.MatchString(err.Error())
+}
+
+func (c *failoverClient) Account(address sdk.AccAddress) (accountNumber uint64, sequence uint64, err error) {
+	err = c.do("Account", func(rw terraclient.ReaderWriter) (err error) {
+		accountNumber, sequence, err = rw.Account(address)
+		return
+	})
+	return
+}
+
+func (c *failoverClient) ContractStore(contractAddress sdk.AccAddress, queryMsg []byte) (resp []byte, err error) {
+	err = c.do("ContractStore", func(rw terraclient.ReaderWriter) (err error) {
+		resp, err = rw.ContractStore(contractAddress, queryMsg)
+		return
+	})
+	return
+}
+
+func (c *failoverClient) TxsEvents(events []string, paginationParams *query.PageRequest) (resp *txtypes.GetTxsEventResponse, err error) {
+	err = c.do("TxsEvents", func(rw terraclient.ReaderWriter) (err error) {
+		resp, err = rw.TxsEvents(events, paginationParams)
+		return
+	})
+	return
+}
+
+func (c *failoverClient) Tx(hash string) (resp *txtypes.GetTxResponse, err error) {
+	err = c.do("Tx", func(rw terraclient.ReaderWriter) (err error) {
+		resp, err = rw.Tx(hash)
+		return
+	})
+	return
+}
+
+func (c *failoverClient) LatestBlock() (resp *tmtypes.GetLatestBlockResponse, err error) {
+	err = c.do("LatestBlock", func(rw terraclient.ReaderWriter) (err error) {
+		resp, err = rw.LatestBlock()
+		return
+	})
+	return
+}
+
+func (c *failoverClient) BlockByHeight(height int64) (resp *tmtypes.GetBlockByHeightResponse, err error) {
+	err = c.do("BlockByHeight", func(rw terraclient.ReaderWriter) (err error) {
+		resp, err = rw.BlockByHeight(height)
+		return
+	})
+	return
+}
+
+func (c *failoverClient) Balance(addr sdk.AccAddress, denom string) (balance *sdk.Coin, err error) {
+	err = c.do("Balance", func(rw terraclient.ReaderWriter) (err error) {
+		balance, err = rw.Balance(addr, denom)
+		return
+	})
+	return
+}
+
+func (c *failoverClient) SignAndBroadcast(msgs []sdk.Msg, accountNum uint64, sequence uint64, gasPrice sdk.DecCoin, signer key.PrivKey, mode txtypes.BroadcastMode) (resp *txtypes.BroadcastTxResponse, err error) {
+	err = c.do("SignAndBroadcast", func(rw terraclient.ReaderWriter) (err error) {
+		resp, err = rw.SignAndBroadcast(msgs, accountNum, sequence, gasPrice, signer, mode)
+		return
+	})
+	return
+}
+
+func (c *failoverClient) Broadcast(txBytes []byte, mode txtypes.BroadcastMode) (resp *txtypes.BroadcastTxResponse, err error) {
+	err = c.do("Broadcast", func(rw terraclient.ReaderWriter) (err error) {
+		resp, err = rw.Broadcast(txBytes, mode)
+		return
+	})
+	return
+}
+
+func (c *failoverClient) Simulate(txBytes []byte) (resp *txtypes.SimulateResponse, err error) {
+	err = c.do("Simulate", func(rw terraclient.ReaderWriter) (err error) {
+		resp, err = rw.Simulate(txBytes)
+		return
+	})
+	return
+}
+
+func (c *failoverClient) BatchSimulateUnsigned(msgs terraclient.SimMsgs, sequence uint64) (resp *terraclient.BatchSimResults, err error) {
+	err = c.do("BatchSimulateUnsigned", func(rw terraclient.ReaderWriter) (err error) {
+		resp, err = rw.BatchSimulateUnsigned(msgs, sequence)
+		return
+	})
+	return
+}
+
+func (c *failoverClient) SimulateUnsigned(msgs []sdk.Msg, sequence uint64) (resp *txtypes.SimulateResponse, err error) {
+	err = c.do("SimulateUnsigned", func(rw terraclient.ReaderWriter) (err error) {
+		resp, err = rw.SimulateUnsigned(msgs, sequence)
+		return
+	})
+	return
+}
+
+func (c *failoverClient) CreateAndSign(msgs []sdk.Msg, account uint64, sequence uint64, gasLimit uint64, gasLimitMultiplier float64, gasPrice sdk.DecCoin, signer key.PrivKey, timeoutHeight uint64) (txBytes []byte, err error) {
+	err = c.do("CreateAndSign", func(rw terraclient.ReaderWriter) (err error) {
+		txBytes, err = rw.CreateAndSign(msgs, account, sequence, gasLimit, gasLimitMultiplier, gasPrice, signer, timeoutHeight)
+		return
+	})
+	return
+}
@@ -0,0 +1,55 @@
+package terratxm
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	terraclient "github.com/smartcontractkit/chainlink-terra/pkg/terra/client"
+)
+
+// ErrInsufficientBalance is returned when a tx is not broadcast because its fee payer can't afford the fee, see
+// WithBalanceCheck.
+var ErrInsufficientBalance = errors.New("insufficient balance for tx fee")
+
+// WithBalanceCheck checks the balance of the fee payer of each tx before signing it, and doesn't broadcast txs whose
+// fee exceeds it, rather than having the chain reject them for insufficient funds, which churns the sender's sequence.
+// Their msgs are left Started, so they are retried on each poll until the fee payer is funded or they expire. The fee
+// payer is the fee granter if set, see WithFeeGranter, otherwise the first sender of the tx. This costs a balance query
+// per tx.
+func WithBalanceCheck() TxmOpt {
+	return func(txm *Txm) {
+		txm.balanceCheck = true
+	}
+}
+
+// feePayer returns the account paying the fee of a tx whose first sender is sender, as set by signMultiSignerTx and
+// CreateAndSign.
+func (txm *Txm) feePayer(sender sdk.AccAddress) sdk.AccAddress {
+	if !txm.feeGranter.Empty() {
+		return txm.feeGranter
+	}
+	return sender
+}
+
+// checkBalance returns ErrInsufficientBalance if the balance of payer, in the denom of fee, is below fee, logging and
+// counting the skipped tx. It returns the error of the balance query if it fails, so the tx is retried on the next poll.
+func (txm *Txm) checkBalance(tc terraclient.ReaderWriter, payer sdk.AccAddress, fee sdk.Coin, logKVs ...interface{}) error {
+	if !txm.balanceCheck {
+		return nil
+	}
+	balance, err := tc.Balance(payer, fee.Denom)
+	if err != nil {
+		txm.lggr.Warnw("unable to read fee payer balance", append(logKVs, "err", err, "payer", payer.String())...)
+		return errors.Wrapf(err, "unable to read balance of fee payer %s", payer)
+	}
+	if balance != nil && balance.Amount.GTE(fee.Amount) {
+		return nil
+	}
+	available := sdk.NewCoin(fee.Denom, sdk.ZeroInt())
+	if balance != nil {
+		available = *balance
+	}
+	txm.lggr.Warnw("tx fee exceeds fee payer balance, not broadcasting", append(logKVs, "payer", payer.String(), "fee", fee.String(), "balance", available.String())...)
+	promTerraTxmInsufficientBalance.WithLabelValues(txm.orm.chainID).Inc()
+	return errors.Wrapf(ErrInsufficientBalance, "fee %s exceeds balance %s of fee payer %s", fee, available, payer)
+}
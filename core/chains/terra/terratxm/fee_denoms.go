@@ -0,0 +1,93 @@
+package terratxm
+
+import (
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	terraclient "github.com/smartcontractkit/chainlink-terra/pkg/terra/client"
+)
+
+// WithFeeDenoms sets the denoms fees may be paid in, in order of preference. Before signing each tx, the balance of its
+// fee payer is read in each denom in turn, and the fee is paid in the first denom whose balance covers it, at the price
+// of the txm's gas price estimator in that denom. Denoms the estimator has no price for are skipped. If the fee payer
+// can't afford the fee in any denom, the tx isn't broadcast, as with WithBalanceCheck. The max fee of WithMaxFee still
+// applies to the fee in the chosen denom.
+//
+// With a single denom, fees are paid in it instead of uluna, and the balance is only checked with WithBalanceCheck. The
+// price of the gas pricer is used for its denom, see WithGasPricer.
+func WithFeeDenoms(denoms ...string) TxmOpt {
+	return func(txm *Txm) {
+		txm.feeDenoms = denoms
+	}
+}
+
+// txGasPrice returns the gas price to pay the fee of a tx with the given gas limit at, in the first fee denom payer can
+// afford, see WithFeeDenoms, or at gasPrice if none are set. It returns ErrMaxFeeExceeded or ErrInsufficientBalance if
+// the tx shouldn't be broadcast.
+func (txm *Txm) txGasPrice(tc terraclient.ReaderWriter, payer sdk.AccAddress, gasPrice sdk.DecCoin, gasLimit uint64, logKVs ...interface{}) (sdk.DecCoin, error) {
+	if len(txm.feeDenoms) > 1 {
+		return txm.affordableGasPrice(tc, payer, gasPrice, gasLimit, logKVs...)
+	}
+	if len(txm.feeDenoms) == 1 {
+		var err error
+		if gasPrice, err = txm.gasPriceIn(txm.feeDenoms[0], gasPrice); err != nil {
+			txm.lggr.Errorw("unable to get gas price", append(logKVs, "err", err)...)
+			return sdk.DecCoin{}, err
+		}
+	}
+	fee := txFee(gasLimit, txm.cfg.GasLimitMultiplier(), gasPrice)
+	if err := txm.checkMaxFee(fee, logKVs...); err != nil {
+		return sdk.DecCoin{}, err
+	}
+	if err := txm.checkBalance(tc, payer, fee, logKVs...); err != nil {
+		return sdk.DecCoin{}, err
+	}
+	return gasPrice, nil
+}
+
+// affordableGasPrice returns the gas price in the first fee denom whose fee payer's balance covers the fee, or
+// ErrInsufficientBalance, logging and counting the skipped tx, if there is none.
+func (txm *Txm) affordableGasPrice(tc terraclient.ReaderWriter, payer sdk.AccAddress, gasPrice sdk.DecCoin, gasLimit uint64, logKVs ...interface{}) (sdk.DecCoin, error) {
+	var unaffordable []string
+	for _, denom := range txm.feeDenoms {
+		price, err := txm.gasPriceIn(denom, gasPrice)
+		if err != nil {
+			txm.lggr.Debugw("skipping fee denom", append(logKVs, "err", err, "denom", denom)...)
+			continue
+		}
+		fee := txFee(gasLimit, txm.cfg.GasLimitMultiplier(), price)
+		balance, err := tc.Balance(payer, denom)
+		if err != nil {
+			txm.lggr.Warnw("unable to read fee payer balance", append(logKVs, "err", err, "payer", payer.String(), "denom", denom)...)
+			return sdk.DecCoin{}, errors.Wrapf(err, "unable to read %s balance of fee payer %s", denom, payer)
+		}
+		if balance != nil && balance.Amount.GTE(fee.Amount) {
+			if err = txm.checkMaxFee(fee, logKVs...); err != nil {
+				return sdk.DecCoin{}, err
+			}
+			return price, nil
+		}
+		available := sdk.NewCoin(denom, sdk.ZeroInt())
+		if balance != nil {
+			available = *balance
+		}
+		unaffordable = append(unaffordable, "fee "+fee.String()+" exceeds balance "+available.String())
+	}
+	txm.lggr.Warnw("tx fee exceeds fee payer balance in every fee denom, not broadcasting", append(logKVs, "payer", payer.String(), "denoms", txm.feeDenoms, "fees", unaffordable)...)
+	promTerraTxmInsufficientBalance.WithLabelValues(txm.orm.chainID).Inc()
+	return sdk.DecCoin{}, errors.Wrapf(ErrInsufficientBalance, "no fee denom of %v affordable by fee payer %s: %s", txm.feeDenoms, payer, strings.Join(unaffordable, ", "))
+}
+
+// gasPriceIn returns the gas price in denom: gasPrice if it is in denom, otherwise the price of the gas price estimator.
+func (txm *Txm) gasPriceIn(denom string, gasPrice sdk.DecCoin) (sdk.DecCoin, error) {
+	if gasPrice.Denom == denom {
+		return gasPrice, nil
+	}
+	price, ok := txm.gpe.GasPrices()[denom]
+	if !ok {
+		return sdk.DecCoin{}, errors.Errorf("no %s gas price", denom)
+	}
+	return price, nil
+}
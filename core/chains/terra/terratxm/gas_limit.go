@@ -0,0 +1,51 @@
+package terratxm
+
+import (
+	"github.com/smartcontractkit/chainlink-terra/pkg/terra"
+	terraclient "github.com/smartcontractkit/chainlink-terra/pkg/terra/client"
+)
+
+// limitToGasLimits limits the msgs of each sender to those with a gas limit override if its first msg has one, or to
+// those without one otherwise, returning the overrides of the msgs kept, by id. The gas used by a single msg of a
+// simulated batch isn't known, so overridden msgs are batched separately from the others: the gas limit of a batch of
+// overridden msgs is the sum of their overrides, or the simulated gas if higher, see gasLimitOverride. The msgs left out
+// are left Started, to be sent in later batches.
+func (txm *Txm) limitToGasLimits(msgsByFrom map[string]terra.Msgs) (map[int64]uint64, error) {
+	var ids []int64
+	for _, msgs := range msgsByFrom {
+		ids = append(ids, msgs.GetIDs()...)
+	}
+	gasLimits, err := txm.orm.GetMsgGasLimits(ids)
+	if err != nil {
+		txm.lggr.Errorw("unable to read msg gas limits", "err", err)
+		return nil, err
+	}
+	for s, msgs := range msgsByFrom {
+		_, overridden := gasLimits[msgs[0].ID]
+		var same, deferred terra.Msgs
+		for _, m := range msgs {
+			if _, ok := gasLimits[m.ID]; ok == overridden {
+				same = append(same, m)
+			} else {
+				deferred = append(deferred, m)
+			}
+		}
+		if len(deferred) > 0 {
+			txm.lggr.Debugw("deferring msgs with a different kind of gas limit to a later batch", "from", s, "overridden", overridden, "msgs", deferred.GetIDs())
+			for _, m := range deferred {
+				delete(gasLimits, m.ID)
+			}
+		}
+		msgsByFrom[s] = same
+	}
+	return gasLimits, nil
+}
+
+// gasLimitOverride returns the sum of the gas limit overrides of msgs, or 0 if they have none.
+func gasLimitOverride(msgs terraclient.SimMsgs, gasLimits map[int64]uint64) uint64 {
+	var sum uint64
+	for _, m := range msgs {
+		sum += gasLimits[m.ID]
+	}
+	return sum
+}
@@ -0,0 +1,36 @@
+package terratxm
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	terraclient "github.com/smartcontractkit/chainlink-terra/pkg/terra/client"
+)
+
+// GasPricer returns the gas price that txs are sent at.
+type GasPricer interface {
+	// GasPrice returns the current gas price in uluna.
+	GasPrice() (sdk.DecCoin, error)
+}
+
+// WithGasPricer overrides the gas price source, e.g. to enforce a floor or to follow a price feed. By default, the uluna
+// price of the txm's gas price estimator is used.
+func WithGasPricer(gasPricer GasPricer) TxmOpt {
+	return func(txm *Txm) {
+		txm.gasPricer = gasPricer
+	}
+}
+
+// estimatorGasPricer is the default GasPricer, using the uluna price of a gas price estimator.
+type estimatorGasPricer struct {
+	gpe terraclient.ComposedGasPriceEstimator
+}
+
+func (p estimatorGasPricer) GasPrice() (sdk.DecCoin, error) {
+	prices := p.gpe.GasPrices()
+	gasPrice, ok := prices["uluna"]
+	if !ok {
+		return sdk.DecCoin{}, errors.New("unexpected empty uluna price")
+	}
+	return gasPrice, nil
+}
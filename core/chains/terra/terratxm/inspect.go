@@ -0,0 +1,45 @@
+package terratxm
+
+import (
+	"time"
+
+	"github.com/smartcontractkit/chainlink-terra/pkg/terra/db"
+)
+
+// listMsgStates are the states listed by ListMessages by default: all but the terminal Confirmed and Errored.
+var listMsgStates = []db.State{db.Unstarted, db.Started, db.Broadcasted, Confirming, TimedOut}
+
+// MsgInfo describes a msg for operators, see Txm.ListMessages.
+type MsgInfo struct {
+	ID         int64
+	ContractID string
+	// Sender is the address sending the msg, empty if it can't be decoded.
+	Sender string
+	State  db.State
+	// TxHash is the hash of the tx the msg was last sent in, nil if it isn't sent yet.
+	TxHash *string
+	// RetryCount is the number of failed attempts to send the msg so far.
+	RetryCount int64
+	CreatedAt  time.Time
+	// Age is the time since the msg was enqueued.
+	Age time.Duration
+}
+
+// ListMessages returns the msgs in any of states with an id greater than afterID, in id order, i.e. oldest first, up to
+// limit, e.g. to give operators a live view of the msgs in flight without querying the db. Without states, it returns
+// the msgs which aren't Confirmed or Errored yet. Paging through them by the id of the last msg returned bounds the msgs
+// read at once, however large the backlog.
+func (txm *Txm) ListMessages(afterID int64, limit int64, states ...db.State) ([]MsgInfo, error) {
+	if len(states) == 0 {
+		states = listMsgStates
+	}
+	msgs, err := txm.orm.GetMsgInfosAfter(states, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	now := txm.clock.Now()
+	for i := range msgs {
+		msgs[i].Age = now.Sub(msgs[i].CreatedAt)
+	}
+	return msgs, nil
+}
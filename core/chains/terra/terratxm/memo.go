@@ -0,0 +1,40 @@
+package terratxm
+
+import (
+	"github.com/smartcontractkit/chainlink-terra/pkg/terra"
+)
+
+// maxMemoLength is the default max memo length of cosmos chains, beyond which txs are rejected.
+const maxMemoLength = 256
+
+// limitToMemos limits the msgs of each sender to those with the same memo as its first msg, returning the memo of each
+// sender. A tx has a single memo, so the other msgs are left Started, to be sent in later batches.
+func (txm *Txm) limitToMemos(msgsByFrom map[string]terra.Msgs) (map[string]string, error) {
+	var ids []int64
+	for _, msgs := range msgsByFrom {
+		ids = append(ids, msgs.GetIDs()...)
+	}
+	memos, err := txm.orm.GetMsgMemos(ids)
+	if err != nil {
+		txm.lggr.Errorw("unable to read msg memos", "err", err)
+		return nil, err
+	}
+	senderMemos := make(map[string]string, len(msgsByFrom))
+	for s, msgs := range msgsByFrom {
+		memo := memos[msgs[0].ID]
+		var same, deferred terra.Msgs
+		for _, m := range msgs {
+			if memos[m.ID] == memo {
+				same = append(same, m)
+			} else {
+				deferred = append(deferred, m)
+			}
+		}
+		if len(deferred) > 0 {
+			txm.lggr.Debugw("deferring msgs with a different memo to a later batch", "from", s, "memo", memo, "msgs", deferred.GetIDs())
+		}
+		msgsByFrom[s] = same
+		senderMemos[s] = memo
+	}
+	return senderMemos, nil
+}
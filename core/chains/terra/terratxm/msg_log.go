@@ -0,0 +1,69 @@
+package terratxm
+
+import (
+	"github.com/smartcontractkit/chainlink-terra/pkg/terra/db"
+
+	"github.com/smartcontractkit/chainlink/core/services/pg"
+)
+
+// msgTransitionColumns are the terra_msgs columns returned by the updates transitioning msgs, see transitionMsgs.
+const msgTransitionColumns = `id, contract_id, state, type, raw, tx_hash, retry_count`
+
+// msgTransition is a msg as updated by a transition, with the fields logged for it.
+type msgTransition struct {
+	ID         int64    `db:"id"`
+	ContractID string   `db:"contract_id"`
+	State      db.State `db:"state"`
+	Type       string   `db:"type"`
+	Raw        []byte   `db:"raw"`
+	TxHash     *string  `db:"tx_hash"`
+	RetryCount int64    `db:"retry_count"`
+}
+
+// transitionMsgs runs query, an UPDATE of terra_msgs moving msgs to a new state, logging each msg it updated with
+// logMsgTransition, and returns the msgs updated. query must not have a RETURNING clause, as it's added to return
+// msgTransitionColumns. Msgs are logged once updated, so they may be logged for a transaction which is rolled back later.
+func (o *ORM) transitionMsgs(q pg.Q, query string, args ...interface{}) ([]msgTransition, error) {
+	return o.returningMsgs(q, o.lggr.Infow, "Msg state transition", query, args...)
+}
+
+// updateMsgsInState is like transitionMsgs for an UPDATE leaving the state of msgs as is, e.g. recording a pending
+// broadcast or a failed attempt. Such updates are frequent, so they are only logged at debug level, as "Msg updated".
+func (o *ORM) updateMsgsInState(q pg.Q, query string, args ...interface{}) ([]msgTransition, error) {
+	return o.returningMsgs(q, o.lggr.Debugw, "Msg updated", query, args...)
+}
+
+func (o *ORM) returningMsgs(q pg.Q, logw func(string, ...interface{}), msg, query string, args ...interface{}) ([]msgTransition, error) {
+	var updated []msgTransition
+	if err := q.Select(&updated, query+` RETURNING `+msgTransitionColumns, args...); err != nil {
+		return nil, err
+	}
+	for _, m := range updated {
+		logMsg(logw, msg, m)
+	}
+	return updated, nil
+}
+
+// logMsgTransition logs a msg entering a new state, with the same keys for all transitions, so a msg can be traced end
+// to end by its msgID:
+//   - msgID is the id of the msg.
+//   - sender is the address sending the msg, empty if it can't be decoded.
+//   - contractID is the contract the msg was enqueued for.
+//   - txHash is the hash of the tx the msg is sent in, empty if it isn't sent yet.
+//   - state is the state of the msg once transitioned.
+//   - retryCount is the number of failed attempts to send the msg so far.
+func (o *ORM) logMsgTransition(m msgTransition) {
+	logMsg(o.lggr.Infow, "Msg state transition", m)
+}
+
+func logMsg(logw func(string, ...interface{}), msg string, m msgTransition) {
+	var sender, txHash string
+	if _, s, err := unmarshalMsg(m.Type, m.Raw); err == nil {
+		sender = s
+	}
+	if m.TxHash != nil {
+		txHash = *m.TxHash
+	}
+	logw(msg, "msgID", m.ID, "sender", sender, "contractID", m.ContractID, "txHash", txHash, "state", m.State,
+		"retryCount", m.RetryCount)
+}
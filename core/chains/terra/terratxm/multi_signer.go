@@ -0,0 +1,204 @@
+package terratxm
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	clienttx "github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/pkg/errors"
+	"github.com/terra-money/core/app/params"
+	"go.uber.org/multierr"
+
+	"github.com/smartcontractkit/chainlink-terra/pkg/terra"
+	terraclient "github.com/smartcontractkit/chainlink-terra/pkg/terra/client"
+
+	"github.com/smartcontractkit/chainlink/core/services/keystore/keys/terrakey"
+)
+
+// multiSignerTxConfig encodes and signs multi-signer txs. Unlike the terra app's encoding config, it only registers
+// the codecs it owns, so creating it doesn't race with other users of the global amino codec.
+var multiSignerTxConfig = params.MakeEncodingConfig().TxConfig
+
+// WithMultiSignerBatching enables combining the msgs of all senders in a batch into a single tx signed by each of them,
+// saving the fee overhead and block inclusion of a tx per sender. The default is a tx per sender.
+//
+// A multi-signer tx is only possible under the following constraints:
+//   - Every sender's key must be in the keystore, as each sender signs the tx. Msgs from senders without a key are
+//     errored as usual.
+//   - The whole fee is paid at a single gas price by the first signer, i.e. the sender with the lowest address, whose
+//     account must cover the fee for the msgs of all senders.
+//   - Unsigned multi-signer txs can't be simulated, so the gas limit is the sum of the gas used by each sender's msgs
+//     when simulated separately, which slightly overestimates the gas used.
+//   - Signatures use SIGN_MODE_DIRECT with the senders' secp256k1 keys.
+//   - A tx has a single memo, so the msgs of all senders must have the same memo, see EnqueueWithMemo. Otherwise the
+//     batch is sent in a tx per sender.
+//
+// If the multi-signer tx can't be signed or broadcast, or its fee exceeds the max fee (see WithMaxFee), the batch falls
+// back to a tx per sender.
+func WithMultiSignerBatching() TxmOpt {
+	return func(txm *Txm) {
+		txm.multiSigner = true
+	}
+}
+
+// sendMultiSignerBatch sends the msgs of all senders in a single tx signed by each sender, falling back to a tx per
+// sender if that fails. Msgs that fail simulation are marked Errored, as for a tx per sender.
+func (txm *Txm) sendMultiSignerBatch(ctx context.Context, gasPrice sdk.DecCoin, msgsByFrom map[string]terra.Msgs, keys map[string]terrakey.Key, memos map[string]string, gasLimits map[int64]uint64) error {
+	tc, err := txm.tc()
+	if err != nil {
+		txm.lggr.Criticalw("unable to get client", "err", err)
+		return err
+	}
+	senders := make([]string, 0, len(keys))
+	for s := range keys {
+		senders = append(senders, s)
+	}
+	// Sorted so the fee payer is deterministic
+	sort.Strings(senders)
+
+	var merr error
+	var stxs []*senderTx
+	for _, s := range senders {
+		sender, _ := sdk.AccAddressFromBech32(s) // Already checked validity in groupMsgsBySender
+		stx, err := txm.prepareSenderTx(tc, sender, keys[s], msgsByFrom[s], memos[s], gasLimits)
+		if err != nil {
+			// Retried on next poll, like a tx per sender
+			merr = multierr.Append(merr, err)
+			continue
+		}
+		if stx != nil {
+			stxs = append(stxs, stx)
+		}
+	}
+	if len(stxs) == 1 {
+		return multierr.Append(merr, txm.sendSenderTx(ctx, tc, gasPrice, stxs[0]))
+	}
+	if len(stxs) == 0 {
+		return merr
+	}
+	if !sameMemo(stxs) {
+		txm.lggr.Debugw("senders have msgs with different memos, sending a tx per sender", "from", senders)
+		return multierr.Append(merr, txm.sendSenderTxs(ctx, tc, gasPrice, stxs))
+	}
+
+	err = txm.sendMultiSignerTx(ctx, tc, gasPrice, stxs)
+	if err == nil || ctx.Err() != nil {
+		return multierr.Append(merr, err)
+	}
+	txm.lggr.Warnw("unable to send multi-signer tx, falling back to a tx per sender", "err", err)
+	return multierr.Append(merr, txm.sendSenderTxs(ctx, tc, gasPrice, stxs))
+}
+
+// sendSenderTxs sends a tx per sender, stopping early if ctx is done.
+func (txm *Txm) sendSenderTxs(ctx context.Context, tc terraclient.ReaderWriter, gasPrice sdk.DecCoin, stxs []*senderTx) error {
+	var merr error
+	for _, stx := range stxs {
+		merr = multierr.Append(merr, txm.sendSenderTx(ctx, tc, gasPrice, stx))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return merr
+}
+
+// sameMemo reports whether all stxs are to be sent with the same memo.
+func sameMemo(stxs []*senderTx) bool {
+	for _, stx := range stxs[1:] {
+		if stx.memo != stxs[0].memo {
+			return false
+		}
+	}
+	return true
+}
+
+// sendMultiSignerTx signs, broadcasts and confirms a single tx with the msgs of all stxs.
+func (txm *Txm) sendMultiSignerTx(ctx context.Context, tc terraclient.ReaderWriter, gasPrice sdk.DecCoin, stxs []*senderTx) error {
+	lb, err := tc.LatestBlock()
+	if err != nil {
+		txm.lggr.Warnw("unable to get latest block", "err", err)
+		return err
+	}
+	timeoutHeight := uint64(lb.Block.Header.Height) + uint64(txm.cfg.BlocksUntilTxTimeout())
+
+	var ids []int64
+	var msgs terraclient.SimMsgs
+	var gasLimit uint64
+	senders := make([]string, len(stxs))
+	for i, stx := range stxs {
+		ids = append(ids, stx.msgs.GetSimMsgsIDs()...)
+		msgs = append(msgs, stx.msgs...)
+		gasLimit += stx.gasLimit
+		senders[i] = stx.sender.String()
+	}
+	gasPrice, err = txm.txGasPrice(tc, txm.feePayer(stxs[0].sender), gasPrice, gasLimit, "from", senders, "msgs", ids)
+	if err != nil {
+		return err
+	}
+	signedTx, err := signMultiSignerTx(txm.orm.chainID, stxs, txm.cfg.GasLimitMultiplier(), gasPrice, timeoutHeight, stxs[0].memo, txm.feeGranter)
+	if err != nil {
+		txm.lggr.Errorw("unable to sign multi-signer tx", "err", err)
+		return err
+	}
+
+	err = txm.broadcastAndConfirm(ctx, tc, signedTx, senders, ids,
+		"from", senders, "msgs", msgs, "gasLimit", gasLimit, "gasPrice", gasPrice.String(), "timeoutHeight", timeoutHeight, "feeGranter", txm.feeGranter)
+	txm.recordSendResult(err, stxs...)
+	return err
+}
+
+// signMultiSignerTx builds a tx with the msgs of all stxs, in order, and the given memo, and signs it with the key of
+// each sender. The fee granter pays the fee if set, otherwise the first sender does. It's also used for txs of a single
+// sender with a memo or fee granter.
+func signMultiSignerTx(chainID string, stxs []*senderTx, gasLimitMultiplier float64, gasPrice sdk.DecCoin, timeoutHeight uint64, memo string, feeGranter sdk.AccAddress) ([]byte, error) {
+	var msgs []sdk.Msg
+	var gasLimit uint64
+	for _, stx := range stxs {
+		msgs = append(msgs, stx.msgs.GetMsgs()...)
+		gasLimit += stx.gasLimit
+	}
+	txBuilder := multiSignerTxConfig.NewTxBuilder()
+	if err := txBuilder.SetMsgs(msgs...); err != nil {
+		return nil, err
+	}
+	// Same gas limit and fee as CreateAndSign
+	txBuilder.SetGasLimit(uint64(math.Ceil(float64(gasLimit) * gasLimitMultiplier)))
+	txBuilder.SetFeeAmount(sdk.NewCoins(txFee(gasLimit, gasLimitMultiplier, gasPrice)))
+	txBuilder.SetTimeoutHeight(timeoutHeight)
+	txBuilder.SetMemo(memo)
+	if !feeGranter.Empty() {
+		txBuilder.SetFeeGranter(feeGranter)
+	}
+
+	// In SIGN_MODE_DIRECT every signer signs the signer infos of all signers, so they must all be set before signing.
+	// Signatures are in the order of the msg signers, which matches stxs as each has a single sender.
+	sigs := make([]signing.SignatureV2, len(stxs))
+	for i, stx := range stxs {
+		sigs[i] = signing.SignatureV2{
+			PubKey:   stx.key.PublicKey(),
+			Data:     &signing.SingleSignatureData{SignMode: signing.SignMode_SIGN_MODE_DIRECT},
+			Sequence: stx.sequence,
+		}
+	}
+	if err := txBuilder.SetSignatures(sigs...); err != nil {
+		return nil, err
+	}
+	for i, stx := range stxs {
+		sig, err := clienttx.SignWithPrivKey(signing.SignMode_SIGN_MODE_DIRECT, authsigning.SignerData{
+			ChainID:       chainID,
+			AccountNumber: stx.accountNumber,
+			Sequence:      stx.sequence,
+		}, txBuilder, NewKeyWrapper(stx.key), multiSignerTxConfig, stx.sequence)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to sign for %s", stx.sender)
+		}
+		sigs[i] = sig
+	}
+	if err := txBuilder.SetSignatures(sigs...); err != nil {
+		return nil, err
+	}
+	return multiSignerTxConfig.TxEncoder()(txBuilder.GetTx())
+}
@@ -2,6 +2,8 @@ package terratxm
 
 import (
 	"database/sql"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -14,10 +16,49 @@ import (
 	"github.com/smartcontractkit/chainlink/core/services/pg"
 )
 
-// ORM manages the data model for terra tx management.
+// ErrIdempotencyKeyReused is returned when an idempotency key is reused for a different msg.
+var ErrIdempotencyKeyReused = errors.New("idempotency key already used for a different msg")
+
+// TimedOut is the state of msgs whose tx could not be confirmed within the timeout period. It complements the states
+// defined by the chainlink-terra db package. TimedOut msgs keep their tx hash so they can be confirmed if the tx
+// lands late, or requeued once it's verified to be absent from the chain.
+const TimedOut db.State = "timed_out"
+
+// Confirming is the state of msgs whose tx is on chain, but not yet deep enough to be final, see
+// WithConfirmationDepth. Like TimedOut, it complements the states defined by the chainlink-terra db package.
+const Confirming db.State = "confirming"
+
+// msgColumns are the terra_msgs columns scanned into a terra.Msg.
+const msgColumns = `id, terra_chain_id, contract_id, state, type, raw, tx_hash, created_at, updated_at`
+
+// DefaultMsgPriority is the priority of msgs enqueued without one. Msgs with a higher priority are sent first.
+const DefaultMsgPriority int32 = 0
+
+// insertMsgQuery inserts an Unstarted msg, see insertMsg. An empty memo and a zero gas limit are stored as NULL, as is
+// a NULL not before time.
+const insertMsgQuery = `INSERT INTO terra_msgs (contract_id, type, raw, state, terra_chain_id, priority, memo, gas_limit, not_before, created_at, updated_at) 
+	VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8::bigint, 0), $9, $10, $10) RETURNING id`
+
+// selectedMsgColumns are the msgColumns of msgs selected to be sent. The creation time of a msg scheduled with a not
+// before time is when it became due, so that it's ordered, and expires after the TxMsgTimeout, from then on.
+const selectedMsgColumns = `id, terra_chain_id, contract_id, state, type, raw, tx_hash, GREATEST(created_at, not_before) AS created_at, updated_at`
+
+// msgsOrder is the order msgs are selected in: highest priority first, then oldest first.
+const msgsOrder = ` ORDER BY priority DESC, created_at ASC, id ASC`
+
+// ORM manages the data model for terra tx management. It only reads and updates the msgs of its chain, so msgs of
+// other chains with the given ids are left out.
 type ORM struct {
 	chainID string
+	db      *sqlx.DB
 	q       pg.Q
+	lggr    logger.Logger
+	// now is the clock msgs are timestamped with, and msgs scheduled with a not before time are checked against, see
+	// InsertMsgWithNotBefore.
+	now func() time.Time
+
+	insertMsgMu   sync.Mutex
+	insertMsgStmt *sqlx.Stmt // prepared on first use, see preparedInsertMsg
 }
 
 // NewORM creates an ORM scoped to chainID.
@@ -26,78 +67,602 @@ func NewORM(chainID string, db *sqlx.DB, lggr logger.Logger, cfg pg.QConfig) *OR
 	q := pg.NewQ(db, namedLogger, cfg)
 	return &ORM{
 		chainID: chainID,
+		db:      db,
 		q:       q,
+		lggr:    namedLogger,
+		now:     time.Now,
+	}
+}
+
+// Close releases the prepared statements of the ORM. They are prepared again if the ORM is used afterwards.
+func (o *ORM) Close() error {
+	o.insertMsgMu.Lock()
+	defer o.insertMsgMu.Unlock()
+	if o.insertMsgStmt == nil {
+		return nil
 	}
+	err := o.insertMsgStmt.Close()
+	o.insertMsgStmt = nil
+	return err
 }
 
-// InsertMsg inserts a terra msg, assumed to be a serialized terra ExecuteContractMsg.
+// preparedInsertMsg returns the insert statement used by InsertMsg, preparing it once so that frequent enqueues
+// don't pay for parsing and planning the query every time. The statement itself is safe for concurrent use.
+func (o *ORM) preparedInsertMsg() (*sqlx.Stmt, error) {
+	o.insertMsgMu.Lock()
+	defer o.insertMsgMu.Unlock()
+	if o.insertMsgStmt == nil {
+		ctx, cancel := o.q.Context()
+		defer cancel()
+		stmt, err := o.db.PreparexContext(ctx, insertMsgQuery)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to prepare insert msg statement")
+		}
+		o.insertMsgStmt = stmt
+	}
+	return o.insertMsgStmt, nil
+}
+
+// InsertMsg inserts a terra msg with the DefaultMsgPriority, see InsertMsgWithPriority.
 func (o *ORM) InsertMsg(contractID, typeURL string, msg []byte, qopts ...pg.QOpt) (int64, error) {
-	var tm terra.Msg
+	return o.InsertMsgWithPriority(contractID, typeURL, msg, DefaultMsgPriority, qopts...)
+}
+
+// InsertMsgWithPriority inserts a terra msg, assumed to be a serialized terra ExecuteContractMsg, to be selected ahead
+// of any msgs with a lower priority.
+func (o *ORM) InsertMsgWithPriority(contractID, typeURL string, msg []byte, priority int32, qopts ...pg.QOpt) (int64, error) {
+	return o.insertMsg(contractID, typeURL, msg, priority, "", 0, nil, qopts...)
+}
+
+// InsertMsgWithMemo inserts a terra msg with the DefaultMsgPriority, to be sent in a tx with the given memo.
+func (o *ORM) InsertMsgWithMemo(contractID, typeURL string, msg []byte, memo string, qopts ...pg.QOpt) (int64, error) {
+	return o.insertMsg(contractID, typeURL, msg, DefaultMsgPriority, memo, 0, nil, qopts...)
+}
+
+// InsertMsgWithGasLimit inserts a terra msg with the DefaultMsgPriority, to be sent with at least the given gas limit.
+func (o *ORM) InsertMsgWithGasLimit(contractID, typeURL string, msg []byte, gasLimit int64, qopts ...pg.QOpt) (int64, error) {
+	return o.insertMsg(contractID, typeURL, msg, DefaultMsgPriority, "", gasLimit, nil, qopts...)
+}
+
+// InsertMsgWithNotBefore inserts a terra msg with the DefaultMsgPriority, which isn't selected to be sent before
+// notBefore.
+func (o *ORM) InsertMsgWithNotBefore(contractID, typeURL string, msg []byte, notBefore time.Time, qopts ...pg.QOpt) (int64, error) {
+	return o.insertMsg(contractID, typeURL, msg, DefaultMsgPriority, "", 0, &notBefore, qopts...)
+}
+
+// insertMsg inserts a terra msg. It uses a prepared statement when run against the ORM's db directly or within a
+// transaction on it.
+func (o *ORM) insertMsg(contractID, typeURL string, msg []byte, priority int32, memo string, gasLimit int64, notBefore *time.Time, qopts ...pg.QOpt) (int64, error) {
+	q := o.q.WithOpts(qopts...)
+	args := []interface{}{contractID, typeURL, msg, db.Unstarted, o.chainID, priority, memo, gasLimit, notBefore, o.now()}
+	var id int64
+	var stmt *sqlx.Stmt
+	var err error
+	switch queryer := q.Queryer.(type) {
+	case *sqlx.DB:
+		if queryer == o.db {
+			stmt, err = o.preparedInsertMsg()
+		}
+	case *sqlx.Tx:
+		stmt, err = o.preparedInsertMsg()
+	}
+	if err != nil {
+		return 0, err
+	}
+	if stmt == nil {
+		if err = q.Get(&id, insertMsgQuery, args...); err != nil {
+			return 0, err
+		}
+		o.logMsgTransition(msgTransition{ID: id, ContractID: contractID, State: db.Unstarted, Type: typeURL, Raw: msg})
+		return id, nil
+	}
+
+	ctx, cancel := q.Context()
+	defer cancel()
+	if tx, ok := q.Queryer.(*sqlx.Tx); ok {
+		// the tx specific statement is closed along with the tx
+		stmt = tx.StmtxContext(ctx, stmt)
+	}
+	if err = stmt.GetContext(ctx, &id, args...); err != nil {
+		return 0, err
+	}
+	o.logMsgTransition(msgTransition{ID: id, ContractID: contractID, State: db.Unstarted, Type: typeURL, Raw: msg})
+	return id, nil
+}
+
+// MsgInsert is a terra msg to insert with InsertMsgs.
+type MsgInsert struct {
+	ContractID string
+	TypeURL    string
+	Raw        []byte
+	Priority   int32
+	Memo       string     // none if empty
+	GasLimit   int64      // none if 0
+	NotBefore  *time.Time // none if nil
+}
+
+// InsertMsgs inserts Unstarted terra msgs in a single statement, so a single insert notification is sent for all of
+// them, and returns their ids in the order of msgs.
+func (o *ORM) InsertMsgs(msgs []MsgInsert, qopts ...pg.QOpt) ([]int64, error) {
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+	contractIDs := make([]string, len(msgs))
+	typeURLs := make([]string, len(msgs))
+	raws := make([][]byte, len(msgs))
+	priorities := make([]int32, len(msgs))
+	memos := make([]string, len(msgs))
+	gasLimits := make([]int64, len(msgs))
+	notBefores := make([]*time.Time, len(msgs))
+	for i, m := range msgs {
+		contractIDs[i], typeURLs[i], raws[i], priorities[i], memos[i], gasLimits[i], notBefores[i] = m.ContractID, m.TypeURL, m.Raw, m.Priority, m.Memo, m.GasLimit, m.NotBefore
+	}
+	q := o.q.WithOpts(qopts...)
+	// Ids are drawn in the order of msgs before inserting, so each can be returned along with the position of its msg
+	var inserted []struct {
+		ID  int64 `db:"id"`
+		Ord int64 `db:"ord"`
+	}
+	err := q.Select(&inserted, `WITH m AS (
+		SELECT nextval('terra_msgs_id_seq') AS id, u.*
+		FROM unnest($3::text[], $4::text[], $5::bytea[], $6::int[], $7::text[], $8::bigint[], $9::timestamptz[])
+			WITH ORDINALITY AS u(contract_id, type, raw, priority, memo, gas_limit, not_before, ord)
+		ORDER BY u.ord
+	), inserted AS (
+		INSERT INTO terra_msgs (id, contract_id, type, raw, state, terra_chain_id, priority, memo, gas_limit, not_before, created_at, updated_at)
+		SELECT id, contract_id, type, raw, $1, $2, priority, NULLIF(memo, ''), NULLIF(gas_limit, 0), not_before, $10, $10 FROM m
+		RETURNING id
+	)
+	SELECT inserted.id, m.ord FROM inserted JOIN m USING (id)`, db.Unstarted, o.chainID, contractIDs, typeURLs, raws, priorities, memos, gasLimits, notBefores, o.now())
+	if err != nil {
+		return nil, err
+	}
+	if len(inserted) != len(msgs) {
+		return nil, errors.Errorf("expected %d records inserted, got %d", len(msgs), len(inserted))
+	}
+	ids := make([]int64, len(msgs))
+	for _, r := range inserted {
+		if r.Ord < 1 || r.Ord > int64(len(msgs)) || ids[r.Ord-1] != 0 {
+			return nil, errors.Errorf("unexpected ordinality %d of inserted record %d", r.Ord, r.ID)
+		}
+		ids[r.Ord-1] = r.ID
+	}
+	for i, m := range msgs {
+		o.logMsgTransition(msgTransition{ID: ids[i], ContractID: m.ContractID, State: db.Unstarted, Type: m.TypeURL, Raw: m.Raw})
+	}
+	return ids, nil
+}
+
+// InsertMsgWithKey inserts a terra msg with an idempotency key, which must be unique per chain.
+func (o *ORM) InsertMsgWithKey(contractID, typeURL string, msg []byte, idempotencyKey string, qopts ...pg.QOpt) (int64, error) {
+	var id int64
 	q := o.q.WithOpts(qopts...)
-	err := q.Get(&tm, `INSERT INTO terra_msgs (contract_id, type, raw, state, terra_chain_id, created_at, updated_at) 
-	VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) RETURNING *`, contractID, typeURL, msg, db.Unstarted, o.chainID)
+	err := q.Get(&id, `INSERT INTO terra_msgs (contract_id, type, raw, state, terra_chain_id, idempotency_key, created_at, updated_at) 
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $7) RETURNING id`, contractID, typeURL, msg, db.Unstarted, o.chainID, idempotencyKey, o.now())
 	if err != nil {
 		return 0, err
 	}
-	return tm.ID, nil
+	o.logMsgTransition(msgTransition{ID: id, ContractID: contractID, State: db.Unstarted, Type: typeURL, Raw: msg})
+	return id, nil
+}
+
+// GetMsgByIdempotencyKey returns the msg inserted with the given idempotency key, or sql.ErrNoRows if none exists.
+func (o *ORM) GetMsgByIdempotencyKey(idempotencyKey string, qopts ...pg.QOpt) (terra.Msg, error) {
+	var tm terra.Msg
+	q := o.q.WithOpts(qopts...)
+	err := q.Get(&tm, `SELECT `+msgColumns+` FROM terra_msgs WHERE terra_chain_id = $1 AND idempotency_key = $2`, o.chainID, idempotencyKey)
+	return tm, err
 }
 
 // UpdateMsgsContract updates messages for the given contract.
 func (o *ORM) UpdateMsgsContract(contractID string, from, to db.State, qopts ...pg.QOpt) error {
 	q := o.q.WithOpts(qopts...)
-	_, err := q.Exec(`UPDATE terra_msgs SET state = $1, updated_at = NOW() 
-	WHERE terra_chain_id = $2 AND contract_id = $3 AND state = $4`, to, o.chainID, contractID, from)
+	_, err := o.transitionMsgs(q, `UPDATE terra_msgs SET state = $1, updated_at = $5 
+	WHERE terra_chain_id = $2 AND contract_id = $3 AND state = $4`, to, o.chainID, contractID, from, o.now())
+	return err
+}
+
+// GetMsgsState returns the messages with a given state up to limit, highest priority first, then oldest first.
+func (o *ORM) GetMsgsState(state db.State, limit int64, qopts ...pg.QOpt) (terra.Msgs, error) {
+	return o.getMsgsState(state, limit, "", "", qopts...)
+}
+
+// GetMsgsStateForUpdate is like GetMsgsState, but locks the msgs until the end of the transaction, so they can't be
+// cancelled with CancelMsg while their state is updated.
+func (o *ORM) GetMsgsStateForUpdate(state db.State, limit int64, qopts ...pg.QOpt) (terra.Msgs, error) {
+	return o.getMsgsState(state, limit, "", " FOR UPDATE", qopts...)
+}
+
+// GetStartedMsgs returns the Started msgs up to limit, in the order of GetMsgsState, excluding those with a pending
+// broadcast, which may already have been sent. See SetPendingBroadcast.
+func (o *ORM) GetStartedMsgs(limit int64, qopts ...pg.QOpt) (terra.Msgs, error) {
+	return o.getMsgsState(db.Started, limit, " AND tx_hash IS NULL", "", qopts...)
+}
+
+// GetPendingBroadcastMsgs returns the Started msgs with a pending broadcast up to limit, in the order of GetMsgsState,
+// along with the hash of the tx they were being broadcast in. See SetPendingBroadcast.
+func (o *ORM) GetPendingBroadcastMsgs(limit int64, qopts ...pg.QOpt) (terra.Msgs, error) {
+	return o.getMsgsState(db.Started, limit, " AND tx_hash IS NOT NULL", "", qopts...)
+}
+
+// getMsgsState selects msgs like GetMsgsState. Unstarted msgs whose not before time is still in the future are left out,
+// see InsertMsgWithNotBefore.
+func (o *ORM) getMsgsState(state db.State, limit int64, filter, lock string, qopts ...pg.QOpt) (terra.Msgs, error) {
+	if limit < 1 {
+		return terra.Msgs{}, errors.New("limit must be greater than 0")
+	}
+	q := o.q.WithOpts(qopts...)
+	args := []interface{}{state, o.chainID, limit}
+	if state == db.Unstarted {
+		filter += ` AND (not_before IS NULL OR not_before <= $4)`
+		args = append(args, o.now())
+	}
+	var msgs terra.Msgs
+	if err := q.Select(&msgs, `SELECT `+selectedMsgColumns+` FROM terra_msgs WHERE state = $1 AND terra_chain_id = $2`+filter+msgsOrder+` LIMIT $3`+lock, args...); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// SetPendingBroadcast records the hash of the tx the Started msgs with the given ids are about to be broadcast in.
+// Until they are updated to Broadcasted, or the pending broadcast is cleared, they are not sent again.
+func (o *ORM) SetPendingBroadcast(ids []int64, txHash string, qopts ...pg.QOpt) error {
+	q := o.q.WithOpts(qopts...)
+	updated, err := o.updateMsgsInState(q, `UPDATE terra_msgs SET tx_hash = $1, updated_at = $4 WHERE id = ANY($2) AND state = $3 AND tx_hash IS NULL AND terra_chain_id = $5`,
+		txHash, ids, db.Started, o.now(), o.chainID)
 	if err != nil {
 		return err
 	}
+	if len(updated) != len(ids) {
+		return errors.Errorf("expected %d records updated, got %d", len(ids), len(updated))
+	}
 	return nil
 }
 
-// GetMsgsState returns the oldest messages with a given state up to limit.
-func (o *ORM) GetMsgsState(state db.State, limit int64, qopts ...pg.QOpt) (terra.Msgs, error) {
+// ClearPendingBroadcast clears the pending broadcast in the tx with the given hash of the msgs with the given ids, once
+// the broadcast is known to have failed, so they can be sent again.
+func (o *ORM) ClearPendingBroadcast(ids []int64, txHash string, qopts ...pg.QOpt) error {
+	q := o.q.WithOpts(qopts...)
+	_, err := o.updateMsgsInState(q, `UPDATE terra_msgs SET tx_hash = NULL, updated_at = $4 WHERE id = ANY($1) AND state = $2 AND tx_hash = $3 AND terra_chain_id = $5`,
+		ids, db.Started, txHash, o.now(), o.chainID)
+	return err
+}
+
+// CancelMsg marks the msg with the given id as Errored, recording reason, if it is still Unstarted.
+// It returns false if the msg doesn't exist or was already started.
+func (o *ORM) CancelMsg(id int64, reason string, qopts ...pg.QOpt) (bool, error) {
+	q := o.q.WithOpts(qopts...)
+	updated, err := o.transitionMsgs(q, `UPDATE terra_msgs SET state = $1, error = $2, updated_at = $6 WHERE id = $3 AND terra_chain_id = $4 AND state = $5`,
+		db.Errored, reason, id, o.chainID, db.Unstarted, o.now())
+	if err != nil {
+		return false, err
+	}
+	return len(updated) == 1, nil
+}
+
+// CountMsgsState returns the number of messages with a given state.
+func (o *ORM) CountMsgsState(state db.State, qopts ...pg.QOpt) (int64, error) {
+	q := o.q.WithOpts(qopts...)
+	var count int64
+	if err := q.Get(&count, `SELECT count(*) FROM terra_msgs WHERE state = $1 AND terra_chain_id = $2`, state, o.chainID); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetUnstartedStats returns the number of Unstarted messages and the creation time of the oldest one.
+// oldest is the zero time if there are none. Msgs scheduled with a not before time are only counted once due, as of
+// when they became due.
+func (o *ORM) GetUnstartedStats(qopts ...pg.QOpt) (count int64, oldest time.Time, err error) {
+	q := o.q.WithOpts(qopts...)
+	var stats struct {
+		Count  int64        `db:"count"`
+		Oldest sql.NullTime `db:"oldest"`
+	}
+	err = q.Get(&stats, `SELECT count(*) AS count, min(GREATEST(created_at, not_before)) AS oldest FROM terra_msgs
+	WHERE state = $1 AND terra_chain_id = $2 AND (not_before IS NULL OR not_before <= $3)`, db.Unstarted, o.chainID, o.now())
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return stats.Count, stats.Oldest.Time, nil
+}
+
+// StuckMsg is a msg still pending longer than the stuck msg SLA, see WithStuckMsgCheck.
+type StuckMsg struct {
+	ID         int64     `db:"id"`
+	ContractID string    `db:"contract_id"`
+	State      db.State  `db:"state"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// CountMsgsCreatedBefore returns the number of msgs in each of states created before t. States without such msgs are
+// left out. The creation time of a msg scheduled with a not before time is when it became due, as for
+// selectedMsgColumns.
+func (o *ORM) CountMsgsCreatedBefore(states []db.State, t time.Time, qopts ...pg.QOpt) (map[db.State]int64, error) {
+	q := o.q.WithOpts(qopts...)
+	var rows []struct {
+		State db.State `db:"state"`
+		Count int64    `db:"count"`
+	}
+	err := q.Select(&rows, `SELECT state, count(*) AS count FROM terra_msgs WHERE terra_chain_id = $1 AND state = ANY($2) AND GREATEST(created_at, not_before) < $3 GROUP BY state`,
+		o.chainID, stateStrings(states), t)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[db.State]int64, len(rows))
+	for _, row := range rows {
+		counts[row.State] = row.Count
+	}
+	return counts, nil
+}
+
+// GetMsgsCreatedBefore returns up to limit msgs in any of states created before t, oldest first. As for
+// CountMsgsCreatedBefore, the creation time of a msg scheduled with a not before time is when it became due.
+func (o *ORM) GetMsgsCreatedBefore(states []db.State, t time.Time, limit int64, qopts ...pg.QOpt) ([]StuckMsg, error) {
+	q := o.q.WithOpts(qopts...)
+	var msgs []StuckMsg
+	err := q.Select(&msgs, `SELECT id, contract_id, state, GREATEST(created_at, not_before) AS created_at FROM terra_msgs
+	WHERE terra_chain_id = $1 AND state = ANY($2) AND GREATEST(created_at, not_before) < $3 ORDER BY GREATEST(created_at, not_before) ASC, id ASC LIMIT $4`,
+		o.chainID, stateStrings(states), t, limit)
+	return msgs, err
+}
+
+// GetMsgInfosAfter returns the msgs in any of states with an id greater than afterID, in id order, up to limit, decoding
+// their senders. Their Age is left for the caller to set, see Txm.ListMessages.
+func (o *ORM) GetMsgInfosAfter(states []db.State, afterID int64, limit int64, qopts ...pg.QOpt) ([]MsgInfo, error) {
 	if limit < 1 {
-		return terra.Msgs{}, errors.New("limit must be greater than 0")
+		return nil, errors.New("limit must be greater than 0")
 	}
 	q := o.q.WithOpts(qopts...)
-	var msgs terra.Msgs
-	if err := q.Select(&msgs, `SELECT * FROM terra_msgs WHERE state = $1 AND terra_chain_id = $2 ORDER BY id ASC LIMIT $3`, state, o.chainID, limit); err != nil {
+	var rows []struct {
+		ID         int64     `db:"id"`
+		ContractID string    `db:"contract_id"`
+		State      db.State  `db:"state"`
+		Type       string    `db:"type"`
+		Raw        []byte    `db:"raw"`
+		TxHash     *string   `db:"tx_hash"`
+		RetryCount int64     `db:"retry_count"`
+		CreatedAt  time.Time `db:"created_at"`
+	}
+	err := q.Select(&rows, `SELECT id, contract_id, state, type, raw, tx_hash, retry_count, created_at FROM terra_msgs WHERE terra_chain_id = $1 AND state = ANY($2) AND id > $3 ORDER BY id ASC LIMIT $4`,
+		o.chainID, stateStrings(states), afterID, limit)
+	if err != nil {
 		return nil, err
 	}
+	msgs := make([]MsgInfo, len(rows))
+	for i, row := range rows {
+		var sender string
+		if _, s, err := unmarshalMsg(row.Type, row.Raw); err == nil {
+			sender = s
+		}
+		msgs[i] = MsgInfo{
+			ID:         row.ID,
+			ContractID: row.ContractID,
+			Sender:     sender,
+			State:      row.State,
+			TxHash:     row.TxHash,
+			RetryCount: row.RetryCount,
+			CreatedAt:  row.CreatedAt,
+		}
+	}
 	return msgs, nil
 }
 
-// GetMsgs returns any messages matching ids.
+func stateStrings(states []db.State) []string {
+	strs := make([]string, len(states))
+	for i, state := range states {
+		strs[i] = string(state)
+	}
+	return strs
+}
+
+// GetMsg returns the msg of this chain with the given id, including the hash of the tx it was broadcast in, if any.
+// It returns sql.ErrNoRows if there is no such msg.
+func (o *ORM) GetMsg(id int64, qopts ...pg.QOpt) (terra.Msg, error) {
+	var tm terra.Msg
+	q := o.q.WithOpts(qopts...)
+	err := q.Get(&tm, `SELECT `+msgColumns+` FROM terra_msgs WHERE id = $1 AND terra_chain_id = $2`, id, o.chainID)
+	return tm, err
+}
+
+// GetMsgMemos returns the memos of the msgs of this chain with the given ids, by id. Msgs without a memo are left
+// out.
+func (o *ORM) GetMsgMemos(ids []int64, qopts ...pg.QOpt) (map[int64]string, error) {
+	q := o.q.WithOpts(qopts...)
+	var rows []struct {
+		ID   int64  `db:"id"`
+		Memo string `db:"memo"`
+	}
+	if err := q.Select(&rows, `SELECT id, memo FROM terra_msgs WHERE id = ANY($1) AND terra_chain_id = $2 AND memo IS NOT NULL`, ids, o.chainID); err != nil {
+		return nil, err
+	}
+	memos := make(map[int64]string, len(rows))
+	for _, row := range rows {
+		memos[row.ID] = row.Memo
+	}
+	return memos, nil
+}
+
+// GetMsgGasLimits returns the gas limit overrides of the msgs with the given ids, by id. Msgs without one are left out.
+func (o *ORM) GetMsgGasLimits(ids []int64, qopts ...pg.QOpt) (map[int64]uint64, error) {
+	q := o.q.WithOpts(qopts...)
+	var rows []struct {
+		ID       int64 `db:"id"`
+		GasLimit int64 `db:"gas_limit"`
+	}
+	if err := q.Select(&rows, `SELECT id, gas_limit FROM terra_msgs WHERE id = ANY($1) AND terra_chain_id = $2 AND gas_limit IS NOT NULL`, ids, o.chainID); err != nil {
+		return nil, err
+	}
+	gasLimits := make(map[int64]uint64, len(rows))
+	for _, row := range rows {
+		gasLimits[row.ID] = uint64(row.GasLimit)
+	}
+	return gasLimits, nil
+}
+
+// GetMsgs returns any messages of this chain matching ids.
 func (o *ORM) GetMsgs(ids ...int64) (terra.Msgs, error) {
 	var msgs terra.Msgs
-	if err := o.q.Select(&msgs, `SELECT * FROM terra_msgs WHERE id = ANY($1)`, ids); err != nil {
+	if err := o.q.Select(&msgs, `SELECT `+msgColumns+` FROM terra_msgs WHERE id = ANY($1) AND terra_chain_id = $2`, ids, o.chainID); err != nil {
 		return nil, err
 	}
 	return msgs, nil
 }
 
-// UpdateMsgs updates msgs with the given ids.
+// UpdateMsgs updates the msgs with the given ids in any of the from states to state to, and returns the ids of those
+// updated: the others were already transitioned, e.g. concurrently, or don't exist.
+// txHash is required when updating to Broadcasted, and optionally recorded when updating to Confirmed,
+// so confirmed msgs can be traced to the tx that settled them.
 // Note state transitions are validated at the db level.
-func (o *ORM) UpdateMsgs(ids []int64, state db.State, txHash *string, qopts ...pg.QOpt) error {
-	if state == db.Broadcasted && txHash == nil {
-		return errors.New("txHash is required when updating to broadcasted")
+func (o *ORM) UpdateMsgs(ids []int64, from []db.State, to db.State, txHash *string, qopts ...pg.QOpt) ([]int64, error) {
+	if to == db.Broadcasted && txHash == nil {
+		return nil, errors.New("txHash is required when updating to broadcasted")
 	}
 	q := o.q.WithOpts(qopts...)
-	var res sql.Result
+	var updated []msgTransition
 	var err error
-	if state == db.Broadcasted {
-		res, err = q.Exec(`UPDATE terra_msgs SET state = $1, updated_at = NOW(), tx_hash = $2 WHERE id = ANY($3)`, state, *txHash, ids)
+	if txHash != nil && (to == db.Broadcasted || to == db.Confirmed) {
+		updated, err = o.transitionMsgs(q, `UPDATE terra_msgs SET state = $1, updated_at = $5, tx_hash = $2 WHERE id = ANY($3) AND state = ANY($4) AND terra_chain_id = $6`, to, *txHash, ids, stateStrings(from), o.now(), o.chainID)
 	} else {
-		res, err = q.Exec(`UPDATE terra_msgs SET state = $1, updated_at = NOW() WHERE id = ANY($2)`, state, ids)
+		updated, err = o.transitionMsgs(q, `UPDATE terra_msgs SET state = $1, updated_at = $4 WHERE id = ANY($2) AND state = ANY($3) AND terra_chain_id = $5`, to, ids, stateStrings(from), o.now(), o.chainID)
 	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	count, err := res.RowsAffected()
+	return transitionIDs(updated), nil
+}
+
+// UpdateMsgsFromState updates the msgs with the given ids from state from to state to, like UpdateMsgs, so msgs read in
+// a state are only transitioned once even if several senders race to update them.
+// It returns the number of msgs updated: the other msgs were already transitioned, or don't exist.
+func (o *ORM) UpdateMsgsFromState(ids []int64, from, to db.State, txHash *string, qopts ...pg.QOpt) (int64, error) {
+	updated, err := o.UpdateMsgs(ids, []db.State{from}, to, txHash, qopts...)
+	return int64(len(updated)), err
+}
+
+func transitionIDs(transitions []msgTransition) []int64 {
+	ids := make([]int64, len(transitions))
+	for i, m := range transitions {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+// RequeueMsgs moves msgs with the given ids in state from back to Unstarted, clearing their tx hash so they are sent
+// again. It errors if any of them isn't in state from, e.g. as it was transitioned concurrently.
+// Note state transitions are validated at the db level.
+func (o *ORM) RequeueMsgs(ids []int64, from db.State, qopts ...pg.QOpt) error {
+	q := o.q.WithOpts(qopts...)
+	updated, err := o.transitionMsgs(q, `UPDATE terra_msgs SET state = $1, tx_hash = NULL, updated_at = $4 WHERE id = ANY($2) AND state = $3 AND terra_chain_id = $5`, db.Unstarted, ids, from, o.now(), o.chainID)
 	if err != nil {
 		return err
 	}
-	if int(count) != len(ids) {
-		return errors.Errorf("expected %d records updated, got %d", len(ids), count)
+	if len(updated) != len(ids) {
+		return errors.Errorf("expected %d records updated, got %d", len(ids), len(updated))
 	}
 	return nil
 }
+
+// RequeueErroredMsgs moves the Errored msgs with the given ids back to Unstarted, clearing their error, tx hash and
+// retries, so they are sent again like newly enqueued msgs. Their creation time is reset, and their not before time
+// cleared, so they don't expire right away once older than the TxMsgTimeout. Ids of msgs which aren't Errored, or belong
+// to another chain, are ignored, as are msgs superseded by a newer msg of their contract which is Unstarted, or also
+// being requeued. As when a msg is enqueued, any other Unstarted msgs of the contracts of the requeued msgs are
+// cancelled, so a contract has at most one Unstarted msg. It returns the ids of the msgs requeued, and of those
+// cancelled. It should be run in a transaction, so msgs are only cancelled along with the requeue.
+func (o *ORM) RequeueErroredMsgs(ids []int64, qopts ...pg.QOpt) (requeued, cancelled []int64, err error) {
+	q := o.q.WithOpts(qopts...)
+	now := o.now()
+	updated, err := o.transitionMsgs(q, `UPDATE terra_msgs SET state = $1, error = NULL, tx_hash = NULL, retry_count = 0, last_error = NULL, not_before = NULL, created_at = $5, updated_at = $5
+	WHERE id IN (
+		SELECT m.id FROM terra_msgs m WHERE m.id = ANY($2) AND m.terra_chain_id = $3 AND m.state = $4 AND NOT EXISTS (
+			SELECT 1 FROM terra_msgs n WHERE n.terra_chain_id = m.terra_chain_id AND n.contract_id = m.contract_id AND n.id > m.id
+			AND (n.state = $1 OR (n.state = $4 AND n.id = ANY($2)))
+		)
+	)`, db.Unstarted, ids, o.chainID, db.Errored, now)
+	if err != nil || len(updated) == 0 {
+		return nil, nil, err
+	}
+	requeued = make([]int64, len(updated))
+	contractIDs := make([]string, len(updated))
+	for i, m := range updated {
+		requeued[i], contractIDs[i] = m.ID, m.ContractID
+	}
+	superseded, err := o.transitionMsgs(q, `UPDATE terra_msgs SET state = $1, updated_at = $6
+	WHERE terra_chain_id = $2 AND contract_id = ANY($3) AND state = $4 AND NOT (id = ANY($5))`, db.Errored, o.chainID, contractIDs, db.Unstarted, requeued, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, m := range superseded {
+		cancelled = append(cancelled, m.ID)
+	}
+	return requeued, cancelled, nil
+}
+
+// UpdateMsgsErrored marks the msgs with the given ids in any of the from states as Errored, recording the reason, and
+// returns the ids of those updated: the others were already transitioned, e.g. concurrently, or don't exist.
+// Note state transitions are validated at the db level.
+func (o *ORM) UpdateMsgsErrored(ids []int64, from []db.State, reason string, qopts ...pg.QOpt) ([]int64, error) {
+	q := o.q.WithOpts(qopts...)
+	updated, err := o.transitionMsgs(q, `UPDATE terra_msgs SET state = $1, error = $2, updated_at = $5 WHERE id = ANY($3) AND state = ANY($4) AND terra_chain_id = $6`,
+		db.Errored, reason, ids, stateStrings(from), o.now(), o.chainID)
+	if err != nil {
+		return nil, err
+	}
+	return transitionIDs(updated), nil
+}
+
+// MsgRetries are the failed attempts to send a msg, see RecordMsgsFailure.
+type MsgRetries struct {
+	// RetryCount is the number of times sending the msg failed, i.e. it was retried, or is to be.
+	RetryCount int64 `db:"retry_count"`
+	// LastError is the error of the last failed attempt, if any.
+	LastError sql.NullString `db:"last_error"`
+}
+
+// RecordMsgsFailure records a failed attempt to send the msgs with the given ids, incrementing their retry count and
+// recording cause as their last error.
+func (o *ORM) RecordMsgsFailure(ids []int64, cause string, qopts ...pg.QOpt) error {
+	q := o.q.WithOpts(qopts...)
+	_, err := o.updateMsgsInState(q, `UPDATE terra_msgs SET retry_count = retry_count + 1, last_error = $1, updated_at = $3 WHERE id = ANY($2) AND terra_chain_id = $4`,
+		cause, ids, o.now(), o.chainID)
+	return err
+}
+
+// GetMsgRetries returns the failed attempts to send the msg of this chain with the given id.
+// It returns sql.ErrNoRows if there is no such msg.
+func (o *ORM) GetMsgRetries(id int64, qopts ...pg.QOpt) (MsgRetries, error) {
+	var r MsgRetries
+	q := o.q.WithOpts(qopts...)
+	err := q.Get(&r, `SELECT retry_count, last_error FROM terra_msgs WHERE id = $1 AND terra_chain_id = $2`, id, o.chainID)
+	return r, err
+}
+
+// ErrorMsgsOverRetries marks the msgs with the given ids in state from which failed more than maxRetries times as
+// Errored, recording the reason, and returns their ids.
+// Note state transitions are validated at the db level.
+func (o *ORM) ErrorMsgsOverRetries(ids []int64, from db.State, maxRetries int64, reason string, qopts ...pg.QOpt) ([]int64, error) {
+	q := o.q.WithOpts(qopts...)
+	updated, err := o.transitionMsgs(q, `UPDATE terra_msgs SET state = $1, error = $2, updated_at = $6 WHERE id = ANY($3) AND retry_count > $4 AND state = $5 AND terra_chain_id = $7`,
+		db.Errored, reason, ids, maxRetries, from, o.now(), o.chainID)
+	if err != nil {
+		return nil, err
+	}
+	return transitionIDs(updated), nil
+}
+
+// GetMsgsErroredAfter returns the Errored msgs which failed with the given reason and have an id greater than afterID,
+// in id order, up to limit. Paging through them by the id of the last msg returned bounds the msgs held in memory at
+// once, however many there are.
+func (o *ORM) GetMsgsErroredAfter(reason string, afterID int64, limit int64, qopts ...pg.QOpt) (terra.Msgs, error) {
+	if limit < 1 {
+		return terra.Msgs{}, errors.New("limit must be greater than 0")
+	}
+	q := o.q.WithOpts(qopts...)
+	var msgs terra.Msgs
+	if err := q.Select(&msgs, `SELECT `+msgColumns+` FROM terra_msgs WHERE state = $1 AND error = $2 AND terra_chain_id = $3 AND id > $4 ORDER BY id ASC LIMIT $5`,
+		db.Errored, reason, o.chainID, afterID, limit); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
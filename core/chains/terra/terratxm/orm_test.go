@@ -1,8 +1,10 @@
 package terratxm_test
 
 import (
+	"database/sql"
 	"fmt"
 	"math/rand"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,6 +13,7 @@ import (
 	"github.com/smartcontractkit/chainlink/core/chains/terra"
 	"github.com/smartcontractkit/chainlink/core/internal/testutils/pgtest"
 	"github.com/smartcontractkit/chainlink/core/logger"
+	"github.com/smartcontractkit/chainlink/core/services/pg"
 
 	. "github.com/smartcontractkit/chainlink-terra/pkg/terra/db"
 
@@ -62,9 +65,9 @@ func TestORM(t *testing.T) {
 
 	// Update
 	txHash := "123"
-	err = o.UpdateMsgs([]int64{mid}, Started, &txHash)
+	_, err = o.UpdateMsgs([]int64{mid}, []State{Unstarted}, Started, &txHash)
 	require.NoError(t, err)
-	err = o.UpdateMsgs([]int64{mid}, Broadcasted, &txHash)
+	_, err = o.UpdateMsgs([]int64{mid}, []State{Started}, Broadcasted, &txHash)
 	require.NoError(t, err)
 	broadcasted, err := o.GetMsgsState(Broadcasted, 5)
 	require.NoError(t, err)
@@ -74,9 +77,241 @@ func TestORM(t *testing.T) {
 	assert.Equal(t, *broadcasted[0].TxHash, txHash)
 	assert.Equal(t, chainID, broadcasted[0].ChainID)
 
-	err = o.UpdateMsgs([]int64{mid}, Confirmed, nil)
+	_, err = o.UpdateMsgs([]int64{mid}, []State{Broadcasted}, Confirmed, &txHash)
 	require.NoError(t, err)
 	confirmed, err := o.GetMsgsState(Confirmed, 5)
 	require.NoError(t, err)
 	require.Equal(t, 1, len(confirmed))
+
+	// The hash of the settling tx is kept on the msg
+	msg, err := o.GetMsg(mid)
+	require.NoError(t, err)
+	assert.Equal(t, Confirmed, msg.State)
+	require.NotNil(t, msg.TxHash)
+	assert.Equal(t, txHash, *msg.TxHash)
+	_, err = o.GetMsg(-1)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	// Msgs of other chains aren't found
+	otherChainID := fmt.Sprintf("Chainlinktest-%d", rand.Int31n(999999))
+	_, err = terra.NewORM(db, lggr, logCfg).CreateChain(otherChainID, nil)
+	require.NoError(t, err)
+	other := NewORM(otherChainID, db, lggr, logCfg)
+	_, err = other.GetMsg(mid)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = o.GetMsgRetries(mid)
+	require.NoError(t, err)
+	_, err = other.GetMsgRetries(mid)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Idempotency key
+	_, err = o.GetMsgByIdempotencyKey("key")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	mid3, err := o.InsertMsgWithKey("0x123", "", []byte("keyed"), "key")
+	require.NoError(t, err)
+	keyed, err := o.GetMsgByIdempotencyKey("key")
+	require.NoError(t, err)
+	assert.Equal(t, mid3, keyed.ID)
+	assert.Equal(t, "keyed", string(keyed.Raw))
+	_, err = o.InsertMsgWithKey("0x123", "", []byte("keyed"), "key")
+	require.Error(t, err)
+
+	// Prepared inserts within a tx, and after the statement was closed
+	var mid4 int64
+	err = pg.NewQ(db, lggr, logCfg).Transaction(func(tx pg.Queryer) error {
+		mid4, err = o.InsertMsg("0x123", "", []byte("in tx"), pg.WithQueryer(tx))
+		return err
+	})
+	require.NoError(t, err)
+	msg, err = o.GetMsg(mid4)
+	require.NoError(t, err)
+	assert.Equal(t, "in tx", string(msg.Raw))
+	require.NoError(t, o.Close())
+	require.NoError(t, o.Close())
+	mid5, err := o.InsertMsg("0x123", "", []byte("reprepared"))
+	require.NoError(t, err)
+	assert.Greater(t, mid5, mid4)
+	require.NoError(t, o.Close())
+}
+
+func TestORM_OtherChain(t *testing.T) {
+	db := pgtest.NewSqlxDB(t)
+	lggr := logger.TestLogger(t)
+	logCfg := pgtest.NewQConfig(true)
+	chainID := fmt.Sprintf("Chainlinktest-%d", rand.Int31n(999999))
+	_, err := terra.NewORM(db, lggr, logCfg).CreateChain(chainID, nil)
+	require.NoError(t, err)
+	o := NewORM(chainID, db, lggr, logCfg)
+	otherChainID := fmt.Sprintf("Chainlinktest-%d", rand.Int31n(999999))
+	_, err = terra.NewORM(db, lggr, logCfg).CreateChain(otherChainID, nil)
+	require.NoError(t, err)
+	other := NewORM(otherChainID, db, lggr, logCfg)
+
+	memoID, err := o.InsertMsgWithMemo("0x123", "", []byte("memo"), "a memo")
+	require.NoError(t, err)
+	gasLimitID, err := o.InsertMsgWithGasLimit("0xabc", "", []byte("gas limit"), 100_000)
+	require.NoError(t, err)
+	ids := []int64{memoID, gasLimitID}
+
+	// Msgs of other chains are neither read...
+	msgs, err := other.GetMsgs(ids...)
+	require.NoError(t, err)
+	assert.Empty(t, msgs)
+	memos, err := other.GetMsgMemos(ids)
+	require.NoError(t, err)
+	assert.Empty(t, memos)
+	gasLimits, err := other.GetMsgGasLimits(ids)
+	require.NoError(t, err)
+	assert.Empty(t, gasLimits)
+
+	// ...nor updated
+	updated, err := other.UpdateMsgs(ids, []State{Unstarted}, Started, nil)
+	require.NoError(t, err)
+	assert.Empty(t, updated)
+	require.NoError(t, other.RecordMsgsFailure(ids, "failed"))
+	updated, err = other.ErrorMsgsOverRetries(ids, Unstarted, 0, "too many retries")
+	require.NoError(t, err)
+	assert.Empty(t, updated)
+	updated, err = other.UpdateMsgsErrored(ids, []State{Unstarted}, "errored")
+	require.NoError(t, err)
+	assert.Empty(t, updated)
+
+	msgs, err = o.GetMsgs(ids...)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	for _, msg := range msgs {
+		assert.Equal(t, Unstarted, msg.State)
+		retries, err := o.GetMsgRetries(msg.ID)
+		require.NoError(t, err)
+		assert.Zero(t, retries.RetryCount)
+	}
+	memos, err = o.GetMsgMemos(ids)
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]string{memoID: "a memo"}, memos)
+	gasLimits, err = o.GetMsgGasLimits(ids)
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]uint64{gasLimitID: 100_000}, gasLimits)
+}
+
+func TestORM_UpdateMsgsFromState(t *testing.T) {
+	db := pgtest.NewSqlxDB(t)
+	lggr := logger.TestLogger(t)
+	logCfg := pgtest.NewQConfig(true)
+	chainID := fmt.Sprintf("Chainlinktest-%d", rand.Int31n(999999))
+	_, err := terra.NewORM(db, lggr, logCfg).CreateChain(chainID, nil)
+	require.NoError(t, err)
+	o := NewORM(chainID, db, lggr, logCfg)
+
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		id, err := o.InsertMsg("0x123", "", []byte("hello"))
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	// Race two senders starting the same msgs
+	const senders = 2
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	updated := make([]int64, senders)
+	errs := make([]error, senders)
+	for i := 0; i < senders; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			updated[i], errs[i] = o.UpdateMsgsFromState(ids, Unstarted, Started, nil)
+		}()
+	}
+	close(start)
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	assert.ElementsMatch(t, []int64{int64(len(ids)), 0}, updated, "exactly one sender should start the msgs")
+	started, err := o.GetMsgsState(Started, 10)
+	require.NoError(t, err)
+	assert.Len(t, started, len(ids))
+
+	// Only msgs still in the expected state are updated
+	errored, err := o.UpdateMsgs(ids[:2], []State{Unstarted}, Errored, nil)
+	require.NoError(t, err)
+	assert.Empty(t, errored)
+	errored, err = o.UpdateMsgsErrored(ids[:2], []State{Unstarted, Started}, "boom")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, ids[:2], errored)
+	errored, err = o.UpdateMsgsErrored(ids[:2], []State{Unstarted, Started}, "boom")
+	require.NoError(t, err)
+	assert.Empty(t, errored, "already errored")
+	txHash := "123"
+	n, err := o.UpdateMsgsFromState(ids, Started, Broadcasted, &txHash)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	broadcasted, err := o.GetMsgsState(Broadcasted, 10)
+	require.NoError(t, err)
+	require.Len(t, broadcasted, 3)
+	for _, msg := range broadcasted {
+		require.NotNil(t, msg.TxHash)
+		assert.Equal(t, txHash, *msg.TxHash)
+	}
+
+	// Requeueing only applies to msgs in the expected state
+	require.Error(t, o.RequeueMsgs(ids[2:], TimedOut))
+	n, err = o.UpdateMsgsFromState(ids[2:], Broadcasted, TimedOut, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	require.NoError(t, o.RequeueMsgs(ids[2:], TimedOut))
+	unstarted, err := o.GetMsgsState(Unstarted, 10)
+	require.NoError(t, err)
+	assert.Len(t, unstarted, 3)
+
+	_, err = o.UpdateMsgsFromState(ids, Started, Broadcasted, nil)
+	assert.EqualError(t, err, "txHash is required when updating to broadcasted")
+}
+
+func BenchmarkORM_InsertMsg(b *testing.B) {
+	db := pgtest.NewSqlxDB(b)
+	lggr := logger.TestLogger(b)
+	logCfg := pgtest.NewQConfig(false)
+	chainID := fmt.Sprintf("Chainlinktest-%d", rand.Int31n(999999))
+	_, err := terra.NewORM(db, lggr, logCfg).CreateChain(chainID, nil)
+	require.NoError(b, err)
+	o := NewORM(chainID, db, lggr, logCfg)
+	b.Cleanup(func() { assert.NoError(b, o.Close()) })
+	raw := []byte("hello")
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i := 0; i < 10_000; i++ {
+			_, err := o.InsertMsg("0x123", "", raw)
+			require.NoError(b, err)
+		}
+	}
+}
+
+// BenchmarkORM_GetMsgsState compares selecting a batch of msgs with loading a whole backlog of 100k Unstarted msgs, to
+// check the memory used per batch is bounded by the batch size rather than the backlog.
+func BenchmarkORM_GetMsgsState(b *testing.B) {
+	db := pgtest.NewSqlxDB(b)
+	lggr := logger.TestLogger(b)
+	logCfg := pgtest.NewQConfig(false)
+	chainID := fmt.Sprintf("Chainlinktest-%d", rand.Int31n(999999))
+	_, err := terra.NewORM(db, lggr, logCfg).CreateChain(chainID, nil)
+	require.NoError(b, err)
+	o := NewORM(chainID, db, lggr, logCfg)
+	const backlog = 100_000
+	_, err = db.Exec(`INSERT INTO terra_msgs (contract_id, type, raw, state, terra_chain_id, created_at, updated_at)
+	SELECT '0x123', '', '\x68656c6c6f', $1, $2, NOW(), NOW() FROM generate_series(1, $3)`, Unstarted, chainID, backlog)
+	require.NoError(b, err)
+
+	for _, limit := range []int64{100, backlog} {
+		b.Run(fmt.Sprintf("limit %d", limit), func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				msgs, err := o.GetMsgsState(Unstarted, limit)
+				require.NoError(b, err)
+				require.Len(b, msgs, int(limit))
+			}
+		})
+	}
 }
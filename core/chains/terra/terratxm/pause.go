@@ -0,0 +1,32 @@
+package terratxm
+
+// Pause stops sending msgs, e.g. during maintenance, without closing the txm. Msgs can still be enqueued meanwhile,
+// and are left Unstarted until Resume. Txs already being sent are still confirmed. While paused, Healthy doesn't
+// report batches as stale, but still reports a backlog over the health thresholds.
+func (txm *Txm) Pause() {
+	if txm.paused.CAS(false, true) {
+		txm.lggr.Infow("Paused sending msgs")
+	}
+}
+
+// Resume resumes sending msgs after Pause, starting with a batch of the msgs enqueued while paused.
+func (txm *Txm) Resume() {
+	if !txm.paused.CAS(true, false) {
+		return
+	}
+	txm.healthMu.Lock()
+	// No batch was expected while paused
+	txm.health.lastSuccess = txm.clock.Now()
+	txm.healthMu.Unlock()
+	txm.lggr.Infow("Resumed sending msgs")
+	select {
+	case txm.resumed <- struct{}{}:
+	default:
+		// A batch is already due
+	}
+}
+
+// Paused returns true if sending msgs is paused, see Pause.
+func (txm *Txm) Paused() bool {
+	return txm.paused.Load()
+}
@@ -0,0 +1,34 @@
+package terratxm
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// txs not broadcast because their fee exceeded the max fee
+	promTerraTxmMaxFeeExceeded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "terra_txm_tx_max_fee_exceeded",
+		Help: "Number of txs that were not broadcast because their fee exceeded the configured max fee",
+	}, []string{"chainID"})
+	// txs not broadcast because their fee exceeded the balance of their fee payer
+	promTerraTxmInsufficientBalance = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "terra_txm_tx_insufficient_balance",
+		Help: "Number of txs that were not broadcast because their fee exceeded the balance of their fee payer",
+	}, []string{"chainID"})
+	// sequence on chain minus the expected sequence, per sender
+	promTerraTxmSequenceDrift = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "terra_txm_sender_sequence_drift",
+		Help: "Sequence of a sender on chain minus the sequence expected after the last tx sent from it",
+	}, []string{"chainID", "sender"})
+	// batches which left senders to the next batch because they exceeded the batch deadline
+	promTerraTxmBatchDeadlineExceeded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "terra_txm_batch_deadline_exceeded",
+		Help: "Number of batches which deferred senders to the next batch because they exceeded the configured batch deadline",
+	}, []string{"chainID"})
+	// msgs pending longer than the stuck msg SLA, per state
+	promTerraTxmStuckMsgs = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "terra_txm_stuck_msgs",
+		Help: "Number of msgs in a non-terminal state which were enqueued longer ago than the configured SLA",
+	}, []string{"chainID", "state"})
+)
@@ -0,0 +1,86 @@
+package terratxm
+
+import (
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	terraclient "github.com/smartcontractkit/chainlink-terra/pkg/terra/client"
+)
+
+// WithSequenceCache caches the account number and sequence of each sender, saving an Account RPC per sender on each
+// batch. The txm is assumed to be the only user of its keys: after a successful send the cached sequence is advanced
+// locally. The cache starts out empty, so each sender is read from chain on the first batch after starting, and a
+// sender is read from chain again whenever sending from it fails, e.g. because the tx was rejected for a sequence
+// mismatch.
+func WithSequenceCache() TxmOpt {
+	return func(txm *Txm) {
+		txm.seqs = &sequenceCache{accounts: make(map[string]accountSequence)}
+	}
+}
+
+type accountSequence struct {
+	accountNumber uint64
+	sequence      uint64
+}
+
+// sequenceCache holds the next sequence of each sender. A nil cache is disabled and caches nothing.
+type sequenceCache struct {
+	mu       sync.Mutex
+	accounts map[string]accountSequence
+}
+
+func (c *sequenceCache) get(sender sdk.AccAddress) (accountSequence, bool) {
+	if c == nil {
+		return accountSequence{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	as, ok := c.accounts[sender.String()]
+	return as, ok
+}
+
+func (c *sequenceCache) set(sender sdk.AccAddress, as accountSequence) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accounts[sender.String()] = as
+}
+
+// invalidate drops the cached sequence of sender, so it's read from chain again on next use.
+func (c *sequenceCache) invalidate(sender sdk.AccAddress) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.accounts, sender.String())
+}
+
+// account returns the account number and next sequence of sender, from the sequence cache if enabled and populated.
+func (txm *Txm) account(tc terraclient.Reader, sender sdk.AccAddress) (accountNumber, sequence uint64, err error) {
+	if as, ok := txm.seqs.get(sender); ok {
+		return as.accountNumber, as.sequence, nil
+	}
+	accountNumber, sequence, err = tc.Account(sender)
+	if err != nil {
+		return 0, 0, err
+	}
+	txm.seqs.set(sender, accountSequence{accountNumber: accountNumber, sequence: sequence})
+	return accountNumber, sequence, nil
+}
+
+// recordSendResult advances the cached sequence of each stx sent in a tx, or drops it if sending failed, as the
+// sequence on chain is then unknown. The expected sequence is recorded either way, see SequenceDrift.
+func (txm *Txm) recordSendResult(err error, stxs ...*senderTx) {
+	for _, stx := range stxs {
+		txm.recordLocalSequence(stx.sender, stx.sequence, err == nil)
+		if err != nil {
+			txm.seqs.invalidate(stx.sender)
+			continue
+		}
+		txm.seqs.set(stx.sender, accountSequence{accountNumber: stx.accountNumber, sequence: stx.sequence + 1})
+	}
+}
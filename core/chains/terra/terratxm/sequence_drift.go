@@ -0,0 +1,132 @@
+package terratxm
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// sequenceDriftCheckPeriod is how often the run loop checks the sequence drift of recent senders, see SequenceDrift.
+	sequenceDriftCheckPeriod = time.Minute
+	// sequenceDriftWindow is how long a sender is checked for sequence drift after it was last sent from.
+	sequenceDriftWindow = time.Hour
+)
+
+// localSequence is the sequence a sender is expected to have on chain, as of the last tx sent from it.
+type localSequence struct {
+	sequence uint64
+	sentAt   time.Time
+}
+
+// recordLocalSequence records the sequence sender is expected to have on chain after sending a tx with the given
+// sequence: the next one if the tx was sent, otherwise the same one.
+func (txm *Txm) recordLocalSequence(sender sdk.AccAddress, sequence uint64, sent bool) {
+	if sent {
+		sequence++
+	}
+	txm.healthMu.Lock()
+	defer txm.healthMu.Unlock()
+	if txm.health.localSequences == nil {
+		txm.health.localSequences = make(map[string]localSequence)
+	}
+	txm.health.localSequences[sender.String()] = localSequence{sequence: sequence, sentAt: txm.clock.Now()}
+}
+
+// SequenceDrift returns the drift of each sender recently sent from, i.e. its sequence on chain minus the sequence
+// expected after the last tx sent from it. A positive drift means txs were sent from the key by someone else, or
+// landed after being given up on, and a negative one that sent txs never landed. Either usually leaves msgs stuck on
+// sequence mismatches. Senders with queued msgs which weren't sent from yet have no expected sequence to compare.
+//
+// The drift of each sender is also recorded for Healthy, see HealthConfig.MaxSequenceDrift, and exported as a metric.
+// If reading some senders from chain fails, the drift of the others is still returned along with the errors.
+func (txm *Txm) SequenceDrift() (map[string]int64, error) {
+	tc, err := txm.tc()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get client")
+	}
+	txm.healthMu.Lock()
+	locals := make(map[string]localSequence, len(txm.health.localSequences))
+	for s, local := range txm.health.localSequences {
+		if txm.clock.Now().Sub(local.sentAt) > sequenceDriftWindow {
+			delete(txm.health.localSequences, s)
+			delete(txm.health.sequenceDrift, s)
+			promTerraTxmSequenceDrift.DeleteLabelValues(txm.orm.chainID, s)
+			continue
+		}
+		locals[s] = local
+	}
+	txm.healthMu.Unlock()
+
+	var merr error
+	drift := make(map[string]int64, len(locals))
+	for s, local := range locals {
+		sender, err := sdk.AccAddressFromBech32(s)
+		if err != nil {
+			merr = multierr.Append(merr, errors.Wrapf(err, "invalid sender %s", s))
+			continue
+		}
+		_, onChain, err := tc.Account(sender)
+		if err != nil {
+			merr = multierr.Append(merr, errors.Wrapf(err, "unable to read account of %s", s))
+			continue
+		}
+		drift[s] = int64(onChain) - int64(local.sequence)
+	}
+
+	txm.healthMu.Lock()
+	defer txm.healthMu.Unlock()
+	if txm.health.sequenceDrift == nil {
+		txm.health.sequenceDrift = make(map[string]int64)
+	}
+	for s, d := range drift {
+		// A sender sent from since reading its account would be checked against a stale expected sequence
+		if txm.health.localSequences[s] != locals[s] {
+			delete(drift, s)
+			continue
+		}
+		txm.health.sequenceDrift[s] = d
+		promTerraTxmSequenceDrift.WithLabelValues(txm.orm.chainID, s).Set(float64(d))
+	}
+	return drift, merr
+}
+
+// checkSequenceDrift logs the senders whose sequence drifted, see SequenceDrift.
+func (txm *Txm) checkSequenceDrift() {
+	drift, err := txm.SequenceDrift()
+	if err != nil {
+		txm.lggr.Warnw("unable to check sequence drift of some senders", "err", err)
+	}
+	for s, d := range drift {
+		if d != 0 {
+			txm.lggr.Warnw("sender sequence drifted from expected sequence", "from", s, "drift", d)
+		}
+	}
+}
+
+// maxSequenceDrift returns the sender with the largest absolute sequence drift, preferring the lowest address on ties.
+// Must be called with healthMu held.
+func (txm *Txm) maxSequenceDrift() (sender string, drift int64) {
+	senders := make([]string, 0, len(txm.health.sequenceDrift))
+	for s := range txm.health.sequenceDrift {
+		senders = append(senders, s)
+	}
+	sort.Strings(senders)
+	for _, s := range senders {
+		if d := txm.health.sequenceDrift[s]; abs(d) > abs(drift) {
+			sender, drift = s, d
+		}
+	}
+	return sender, drift
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
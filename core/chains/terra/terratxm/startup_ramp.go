@@ -0,0 +1,39 @@
+package terratxm
+
+// startupRamp holds the settings of WithStartupRamp.
+type startupRamp struct {
+	batches     int
+	initialMsgs int64
+}
+
+// WithStartupRamp limits the msgs taken by the first batches after the txm starts, so a backlog accumulated during
+// downtime is drained gradually rather than all at once, spiking resources and RPC load. The first batch takes up to
+// initialMsgs msgs, and the limit grows linearly with each of the following batches, up to MaxMsgsPerBatch from batch
+// number batches+1 on. Like with MaxMsgsPerBatch, leftover Started msgs count towards the limit. By default, every
+// batch takes up to MaxMsgsPerBatch msgs.
+func WithStartupRamp(batches int, initialMsgs int64) TxmOpt {
+	return func(txm *Txm) {
+		txm.startupRamp = &startupRamp{batches: batches, initialMsgs: initialMsgs}
+	}
+}
+
+// batchMsgLimit returns the max number of msgs of the next batch, counting it towards the startup ramp, see
+// WithStartupRamp. Only used by the run loop.
+func (txm *Txm) batchMsgLimit() int64 {
+	limit := txm.cfg.MaxMsgsPerBatch()
+	if txm.startupRamp == nil || txm.rampedBatches >= txm.startupRamp.batches {
+		return limit
+	}
+	batch := int64(txm.rampedBatches)
+	txm.rampedBatches++
+	initial := txm.startupRamp.initialMsgs
+	if initial < 1 {
+		initial = 1
+	}
+	if initial >= limit {
+		return limit
+	}
+	limit = initial + (limit-initial)*batch/int64(txm.startupRamp.batches)
+	txm.lggr.Debugw("ramping up batches after start", "batch", batch+1, "of", txm.startupRamp.batches, "maxMsgs", limit)
+	return limit
+}
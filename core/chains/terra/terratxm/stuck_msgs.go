@@ -0,0 +1,66 @@
+package terratxm
+
+import (
+	"time"
+
+	"github.com/smartcontractkit/chainlink-terra/pkg/terra/db"
+)
+
+// maxStuckMsgsReported is the number of the oldest stuck msgs passed to the stuck msg callback and logged by each check.
+const maxStuckMsgsReported = 100
+
+// stuckMsgStates are the non-terminal states checked for stuck msgs. TimedOut msgs are pending until requeued, or
+// found on chain after all, see reconcileTimedOutMsgs.
+var stuckMsgStates = []db.State{db.Unstarted, db.Started, db.Broadcasted, TimedOut, Confirming}
+
+// stuckMsgCheck holds the settings of WithStuckMsgCheck.
+type stuckMsgCheck struct {
+	sla      time.Duration
+	interval time.Duration
+	onStuck  func([]StuckMsg)
+}
+
+// WithStuckMsgCheck checks every interval for msgs enqueued longer than sla ago which are still in a non-terminal
+// state, i.e. Unstarted, Started, Broadcasted, TimedOut or Confirming, e.g. to alert when msgs miss their delivery
+// SLA. A msg scheduled with a not before time is only checked from when it became due. The
+// number of stuck msgs in each state is exported as the terra_txm_stuck_msgs gauge, and the oldest ones are logged and,
+// if onStuck isn't nil, passed to it, e.g. to page someone. onStuck is only called if there are stuck msgs, and runs
+// on the run loop, so it should return quickly. The check only observes msgs, leaving them as they are.
+func WithStuckMsgCheck(sla, interval time.Duration, onStuck func([]StuckMsg)) TxmOpt {
+	return func(txm *Txm) {
+		txm.stuckMsgs = &stuckMsgCheck{sla: sla, interval: interval, onStuck: onStuck}
+	}
+}
+
+// checkStuckMsgs counts and reports the msgs stuck past the SLA, see WithStuckMsgCheck.
+func (txm *Txm) checkStuckMsgs() {
+	cutoff := txm.clock.Now().Add(-txm.stuckMsgs.sla)
+	counts, err := txm.orm.CountMsgsCreatedBefore(stuckMsgStates, cutoff)
+	if err != nil {
+		txm.lggr.Errorw("unable to count stuck msgs", "err", err)
+		return
+	}
+	var total int64
+	for _, state := range stuckMsgStates {
+		promTerraTxmStuckMsgs.WithLabelValues(txm.orm.chainID, string(state)).Set(float64(counts[state]))
+		total += counts[state]
+	}
+	if total == 0 {
+		return
+	}
+
+	stuck, err := txm.orm.GetMsgsCreatedBefore(stuckMsgStates, cutoff, maxStuckMsgsReported)
+	if err != nil {
+		txm.lggr.Errorw("unable to read stuck msgs", "err", err, "count", total)
+		return
+	}
+	if len(stuck) == 0 {
+		// Moved on since they were counted
+		return
+	}
+	txm.lggr.Warnw("msgs pending past SLA", "count", total, "sla", txm.stuckMsgs.sla, "oldestID", stuck[0].ID,
+		"oldestState", stuck[0].State, "oldestCreatedAt", stuck[0].CreatedAt)
+	if txm.stuckMsgs.onStuck != nil {
+		txm.stuckMsgs.onStuck(stuck)
+	}
+}
@@ -1,14 +1,22 @@
 package terratxm
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
 	"encoding/hex"
+	"encoding/json"
+	"math"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/jpillora/backoff"
 	"github.com/pkg/errors"
 	"github.com/smartcontractkit/sqlx"
+	"go.uber.org/atomic"
+	"go.uber.org/multierr"
 	"golang.org/x/exp/slices"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -34,6 +42,35 @@ var (
 	_ terra.TxManager     = (*Txm)(nil)
 )
 
+// reasonNoKey is the error recorded for msgs whose sender has no key in the keystore.
+const reasonNoKey = "no key for sender"
+
+// reasonMaxRetries is the error recorded for msgs which failed to be sent more than the max retries, see
+// WithMaxMsgRetries. The error of their last attempt is kept as their last error, see ORM.GetMsgRetries.
+const reasonMaxRetries = "max retries exceeded"
+
+// reasonCancelled is the error recorded for msgs cancelled with Txm.Cancel.
+const reasonCancelled = "cancelled"
+
+// ErrMaxFeeExceeded is returned when a tx is not broadcast because its fee exceeds the max fee, see WithMaxFee.
+var ErrMaxFeeExceeded = errors.New("tx fee exceeds max fee")
+
+const (
+	// DefaultMaxConsecutiveFailures is the number of consecutive failed batches after which the txm is unhealthy.
+	DefaultMaxConsecutiveFailures = 5
+	// DefaultMaxUnstartedBacklog is the number of Unstarted msgs above which the txm is unhealthy.
+	DefaultMaxUnstartedBacklog = 1000
+	// DefaultStaleBatchTimeout is how long msgs may be pending without a successful batch before the txm is unhealthy.
+	DefaultStaleBatchTimeout = 10 * time.Minute
+	// DefaultMaxUnstartedAge is how long the oldest Unstarted msg may wait before the txm is unhealthy.
+	DefaultMaxUnstartedAge = 30 * time.Minute
+	// DefaultMaxSequenceDrift is the largest drift of a sender's sequence from the expected one before the txm is
+	// unhealthy. A tx given up on which lands later drifts by one, so a small drift is tolerated.
+	DefaultMaxSequenceDrift = 3
+	// DefaultMaxBatchBackoff is the longest delay between batches after consecutive batch failures.
+	DefaultMaxBatchBackoff = 2 * time.Minute
+)
+
 // Txm manages transactions for the terra blockchain.
 type Txm struct {
 	starter    utils.StartStopOnce
@@ -45,23 +82,192 @@ type Txm struct {
 	ks         keystore.Terra
 	stop, done chan struct{}
 	cfg        terra.Config
-	gpe        terraclient.ComposedGasPriceEstimator
+
+	healthCfg HealthConfig
+	healthMu  sync.RWMutex
+	health    batchHealth
+
+	// gasPricer is the source of gas prices, see WithGasPricer.
+	gasPricer GasPricer
+	// gpe is the gas price estimator, used for the prices of fee denoms other than the gas pricer's.
+	gpe terraclient.ComposedGasPriceEstimator
+	// feeDenoms are the denoms fees may be paid in, in order of preference, if set. See WithFeeDenoms.
+	feeDenoms []string
+	// multiSigner enables combining msgs from multiple senders into a single tx, see WithMultiSignerBatching.
+	multiSigner bool
+	// seqs caches the sequence of each sender, if enabled. See WithSequenceCache.
+	seqs *sequenceCache
+	// maxFee is the highest fee paid for a single tx, if set. See WithMaxFee.
+	maxFee *sdk.Coin
+	// balanceCheck skips txs whose fee exceeds the balance of their fee payer, see WithBalanceCheck.
+	balanceCheck bool
+	// feeGranter pays the fees of all txs instead of their first signer, if set. See WithFeeGranter.
+	feeGranter sdk.AccAddress
+	// maxMsgRetries is the number of failed attempts after which msgs are Errored, if set. See WithMaxMsgRetries.
+	maxMsgRetries int64
+	// confirmationDepth is the number of blocks a tx must be below the latest block before its msgs are Confirmed, if
+	// set. See WithConfirmationDepth.
+	confirmationDepth int64
+	// maxBatchBackoff caps the delay between batches after consecutive failures, see WithMaxBatchBackoff.
+	maxBatchBackoff time.Duration
+	// batchBackoff is only used by the run loop.
+	batchBackoff backoff.Backoff
+	// batchDeadline bounds the time spent sending a batch, if set. See WithBatchDeadline.
+	batchDeadline time.Duration
+	// deferredSenders are the senders left out of the last batch by its deadline. Only used by the run loop.
+	deferredSenders map[string]struct{}
+	// paused stops the run loop from sending batches, see Pause.
+	paused atomic.Bool
+	// resumed triggers a batch when sending is resumed, see Resume.
+	resumed chan struct{}
+	// stuckMsgs reports msgs pending past an SLA, if set. See WithStuckMsgCheck.
+	stuckMsgs *stuckMsgCheck
+	// broadcastHook passes broadcast results to a callback, if set. See WithOnBroadcast.
+	broadcastHook *broadcastHook
+	// startupRamp limits the msgs of the first batches, if set. See WithStartupRamp.
+	startupRamp *startupRamp
+	// rampedBatches is the number of batches limited by the startup ramp so far. Only used by the run loop.
+	rampedBatches int
+	// clock is the source of time, the real clock unless set with WithClock.
+	clock Clock
+}
+
+// HealthConfig holds the thresholds used by Txm.Healthy.
+type HealthConfig struct {
+	// MaxConsecutiveFailures is the number of consecutive failed batches tolerated.
+	MaxConsecutiveFailures int
+	// MaxUnstartedBacklog is the number of Unstarted msgs tolerated.
+	MaxUnstartedBacklog int64
+	// StaleBatchTimeout is how long msgs may be pending without a successful batch.
+	StaleBatchTimeout time.Duration
+	// MaxUnstartedAge is how long the oldest Unstarted msg may wait to be started.
+	MaxUnstartedAge time.Duration
+	// MaxSequenceDrift is the largest sequence drift tolerated for a sender, see Txm.SequenceDrift.
+	MaxSequenceDrift int64
+}
+
+// DefaultHealthConfig returns the default health thresholds.
+func DefaultHealthConfig() HealthConfig {
+	return HealthConfig{
+		MaxConsecutiveFailures: DefaultMaxConsecutiveFailures,
+		MaxUnstartedBacklog:    DefaultMaxUnstartedBacklog,
+		StaleBatchTimeout:      DefaultStaleBatchTimeout,
+		MaxUnstartedAge:        DefaultMaxUnstartedAge,
+		MaxSequenceDrift:       DefaultMaxSequenceDrift,
+	}
+}
+
+// batchHealth tracks the outcome of recent batches.
+type batchHealth struct {
+	consecutiveFailures int
+	lastErr             error
+	// lastSuccess is the time of the last successful batch, or of Start if none has succeeded yet.
+	lastSuccess time.Time
+	unstarted   int64
+	// oldestUnstarted is the creation time of the oldest Unstarted msg, or the zero time if there are none.
+	oldestUnstarted time.Time
+	// localSequences is the expected sequence of each sender recently sent from, see SequenceDrift.
+	localSequences map[string]localSequence
+	// sequenceDrift is the last sequence drift of each sender recently sent from.
+	sequenceDrift map[string]int64
+}
+
+// WithMaxFee sets the highest fee that is paid for a single tx, as a safety valve against gas price spikes or a
+// misbehaving gas price estimator. Txs with a higher fee, or a fee in a different denom, are not broadcast. Their msgs
+// are left Started, so they are retried on each poll until the fee drops below maxFee or they expire.
+func WithMaxFee(maxFee sdk.Coin) TxmOpt {
+	return func(txm *Txm) {
+		txm.maxFee = &maxFee
+	}
+}
+
+// WithFeeGranter sets a fee granter which pays the fees of all txs, so operational keys only sign msgs and don't need
+// to be funded. The granter must have granted a fee allowance covering the fees to each sender, with the x/feegrant
+// module, e.g. with `terrad tx feegrant grant <granter> <sender>`. Txs are rejected by the node without an active
+// allowance. Granted fees use a little more gas than the simulated msgs, which is covered by the gas limit multiplier.
+func WithFeeGranter(granter sdk.AccAddress) TxmOpt {
+	return func(txm *Txm) {
+		txm.feeGranter = granter
+	}
+}
+
+// WithMaxMsgRetries marks msgs Errored once sending them failed more than maxRetries times, so they're no longer
+// selected. An attempt fails if the node rejects the tx, or if the tx times out without landing. By default, msgs are
+// retried until they expire, see terra.Config.TxMsgTimeout.
+func WithMaxMsgRetries(maxRetries int64) TxmOpt {
+	return func(txm *Txm) {
+		txm.maxMsgRetries = maxRetries
+	}
+}
+
+// WithConfirmationDepth requires the tx of msgs to be at least depth blocks below the latest block before they're
+// Confirmed, for chains with occasional reorgs. Meanwhile, msgs whose tx is on chain are Confirming. This doesn't delay
+// sending the next batch, as the sequence of the senders already advanced. By default, msgs are Confirmed as soon as
+// their tx is on chain, as tendermint blocks are final once committed.
+func WithConfirmationDepth(depth int64) TxmOpt {
+	return func(txm *Txm) {
+		txm.confirmationDepth = depth
+	}
+}
+
+// WithMaxBatchBackoff overrides DefaultMaxBatchBackoff, the longest delay between batches while the chain is erroring.
+func WithMaxBatchBackoff(d time.Duration) TxmOpt {
+	return func(txm *Txm) {
+		txm.maxBatchBackoff = d
+	}
+}
+
+// MsgsConfirmedEvent is the payload published on pg.ChannelTerraMsgConfirmed when msgs are confirmed on chain.
+type MsgsConfirmedEvent struct {
+	ChainID string  `json:"chainID"`
+	TxHash  string  `json:"txHash"`
+	MsgIDs  []int64 `json:"msgIDs"`
+}
+
+// TxmOpt configures optional Txm behavior.
+type TxmOpt func(*Txm)
+
+// WithHealthConfig overrides the default health thresholds.
+func WithHealthConfig(cfg HealthConfig) TxmOpt {
+	return func(txm *Txm) {
+		txm.healthCfg = cfg
+	}
 }
 
 // NewTxm creates a txm. Uses simulation so should only be used to send txes to trusted contracts i.e. OCR.
-func NewTxm(db *sqlx.DB, tc func() (terraclient.ReaderWriter, error), gpe terraclient.ComposedGasPriceEstimator, chainID string, cfg terra.Config, ks keystore.Terra, lggr logger.Logger, logCfg pg.QConfig, eb pg.EventBroadcaster) *Txm {
+func NewTxm(db *sqlx.DB, tc func() (terraclient.ReaderWriter, error), gpe terraclient.ComposedGasPriceEstimator, chainID string, cfg terra.Config, ks keystore.Terra, lggr logger.Logger, logCfg pg.QConfig, eb pg.EventBroadcaster, opts ...TxmOpt) *Txm {
 	lggr = lggr.Named("Txm")
-	return &Txm{
-		starter: utils.StartStopOnce{},
-		eb:      eb,
-		orm:     NewORM(chainID, db, lggr, logCfg),
-		ks:      ks,
-		tc:      tc,
-		lggr:    lggr,
-		stop:    make(chan struct{}),
-		done:    make(chan struct{}),
-		cfg:     cfg,
-		gpe:     gpe,
+	txm := &Txm{
+		starter:   utils.StartStopOnce{},
+		eb:        eb,
+		orm:       NewORM(chainID, db, lggr, logCfg),
+		ks:        ks,
+		tc:        tc,
+		lggr:      lggr,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+		resumed:   make(chan struct{}, 1),
+		cfg:       cfg,
+		gasPricer: estimatorGasPricer{gpe: gpe},
+		gpe:       gpe,
+		healthCfg: DefaultHealthConfig(),
+		clock:     realClock{},
+
+		maxBatchBackoff: DefaultMaxBatchBackoff,
+	}
+	for _, opt := range opts {
+		opt(txm)
+	}
+	txm.health.lastSuccess = txm.clock.Now()
+	txm.batchBackoff = txm.newBatchBackoff()
+	return txm
+}
+
+func (txm *Txm) newBatchBackoff() backoff.Backoff {
+	return backoff.Backoff{
+		Min:    txm.cfg.BlockRate(),
+		Max:    txm.maxBatchBackoff,
+		Jitter: true,
 	}
 }
 
@@ -73,6 +279,12 @@ func (txm *Txm) Start(context.Context) error {
 			return err
 		}
 		txm.sub = sub
+		txm.healthMu.Lock()
+		txm.health.lastSuccess = txm.clock.Now()
+		txm.healthMu.Unlock()
+		if txm.broadcastHook != nil {
+			go txm.broadcastHook.run(txm.stop, txm.lggr)
+		}
 		go txm.run()
 		return nil
 	})
@@ -119,20 +331,65 @@ func (txm *Txm) run() {
 	defer cancel()
 	txm.confirmAnyUnconfirmed(ctx)
 	// Jitter in case we have multiple terra chains each with their own client.
-	tick := time.After(utils.WithJitter(txm.cfg.BlockRate()))
+	tick := txm.clock.After(utils.WithJitter(txm.cfg.BlockRate()))
+	// While backing off from failed batches, inserts don't trigger a batch so a dead node isn't hammered.
+	var backoffUntil time.Time
+	driftTicker := txm.clock.NewTicker(sequenceDriftCheckPeriod)
+	defer driftTicker.Stop()
+	var stuckCheck <-chan time.Time
+	if txm.stuckMsgs != nil {
+		stuckTicker := txm.clock.NewTicker(txm.stuckMsgs.interval)
+		defer stuckTicker.Stop()
+		stuckCheck = stuckTicker.C()
+	}
+	sendMsgBatch := func() {
+		delay, backingOff := txm.nextBatchDelay(txm.sendMsgBatch(ctx))
+		tick = txm.clock.After(delay)
+		backoffUntil = time.Time{}
+		if backingOff {
+			backoffUntil = txm.clock.Now().Add(delay)
+		}
+	}
 	for {
 		select {
 		case <-txm.sub.Events():
-			txm.sendMsgBatch(ctx)
+			if txm.paused.Load() || txm.clock.Now().Before(backoffUntil) {
+				// Picked up by the batch on resume, or at the end of the backoff
+				continue
+			}
+			sendMsgBatch()
 		case <-tick:
-			txm.sendMsgBatch(ctx)
-			tick = time.After(utils.WithJitter(txm.cfg.BlockRate()))
+			if txm.paused.Load() {
+				// No more ticks until resumed
+				tick = nil
+				continue
+			}
+			sendMsgBatch()
+		case <-txm.resumed:
+			sendMsgBatch()
+		case <-driftTicker.C():
+			// In the run loop, so no tx is in flight while comparing sequences
+			txm.checkSequenceDrift()
+		case <-stuckCheck:
+			txm.checkStuckMsgs()
 		case <-txm.stop:
 			return
 		}
 	}
 }
 
+// nextBatchDelay returns the delay until the next batch given the result of the last one. Consecutive failures back
+// off exponentially with jitter, from BlockRate up to the max batch backoff, and a success resets the backoff.
+func (txm *Txm) nextBatchDelay(err error) (delay time.Duration, backingOff bool) {
+	if err == nil {
+		txm.batchBackoff.Reset()
+		return utils.WithJitter(txm.cfg.BlockRate()), false
+	}
+	delay = txm.batchBackoff.Duration()
+	txm.lggr.Warnw("batch failed, backing off", "err", err, "attempt", txm.batchBackoff.Attempt(), "delay", delay)
+	return delay, true
+}
+
 var (
 	typeMsgSend            = sdk.MsgTypeURL(&types.MsgSend{})
 	typeMsgExecuteContract = sdk.MsgTypeURL(&wasmtypes.MsgExecuteContract{})
@@ -181,18 +438,39 @@ func (e *msgValidator) sortValid() {
 	})
 }
 
-func (txm *Txm) sendMsgBatch(ctx context.Context) {
-	msgs := msgValidator{cutoff: time.Now().Add(-txm.cfg.TxMsgTimeout())}
+// sendMsgBatch sends a batch of msgs and records the result for Healthy, returning an error if the batch failed.
+func (txm *Txm) sendMsgBatch(ctx context.Context) error {
+	err := multierr.Combine(txm.recoverPendingBroadcasts(ctx), txm.reconcileTimedOutMsgs(), txm.reconcileConfirmingMsgs(), txm.processMsgBatch(ctx))
+	if err != nil && ctx.Err() != nil {
+		// Shutting down, not a batch failure.
+		return nil
+	}
+	unstarted, oldest, cerr := txm.orm.GetUnstartedStats()
+	if cerr != nil {
+		txm.lggr.Errorw("unable to count unstarted msgs", "err", cerr)
+		err = multierr.Append(err, cerr)
+	}
+	txm.recordBatchResult(err, unstarted, oldest, cerr == nil)
+	return err
+}
+
+// processMsgBatch sends a batch of msgs, returning an error if the batch failed for any sender.
+func (txm *Txm) processMsgBatch(ctx context.Context) error {
+	batchStart := txm.clock.Now()
+	msgs := msgValidator{cutoff: txm.clock.Now().Add(-txm.cfg.TxMsgTimeout())}
+	maxMsgs := txm.batchMsgLimit()
 	err := txm.orm.q.Transaction(func(tx pg.Queryer) error {
-		// There may be leftover Started messages after a crash or failed send attempt.
-		started, err := txm.orm.GetMsgsState(db.Started, txm.cfg.MaxMsgsPerBatch(), pg.WithQueryer(tx))
+		// There may be leftover Started messages after a crash or failed send attempt. Those with a pending broadcast
+		// are left to recoverPendingBroadcasts, as they may already have been sent.
+		started, err := txm.orm.GetStartedMsgs(maxMsgs, pg.WithQueryer(tx))
 		if err != nil {
 			txm.lggr.Errorw("unable to read unstarted msgs", "err", err)
 			return err
 		}
-		if limit := txm.cfg.MaxMsgsPerBatch() - int64(len(started)); limit > 0 {
-			// Use the remaining batch budget for Unstarted
-			unstarted, err := txm.orm.GetMsgsState(db.Unstarted, limit, pg.WithQueryer(tx)) //nolint
+		if limit := maxMsgs - int64(len(started)); limit > 0 {
+			// Use the remaining batch budget for Unstarted, highest priority first, locked so they can't be cancelled
+			// while being started.
+			unstarted, err := txm.orm.GetMsgsStateForUpdate(db.Unstarted, limit, pg.WithQueryer(tx)) //nolint
 			if err != nil {
 				txm.lggr.Errorw("unable to read unstarted msgs", "err", err)
 				return err
@@ -200,88 +478,259 @@ func (txm *Txm) sendMsgBatch(ctx context.Context) {
 			for _, msg := range unstarted {
 				msgs.add(msg)
 			}
-			// Update valid, Unstarted messages to Started
-			err = txm.orm.UpdateMsgs(msgs.valid.GetIDs(), db.Started, nil, pg.WithQueryer(tx))
+			// Update valid, Unstarted messages to Started, unless another sender got to them first
+			ids := msgs.valid.GetIDs()
+			updated, err := txm.orm.UpdateMsgsFromState(ids, db.Unstarted, db.Started, nil, pg.WithQueryer(tx))
 			if err != nil {
 				// Assume transient db error retry
 				txm.lggr.Errorw("unable to mark unstarted txes as started", "err", err)
 				return err
 			}
+			if updated != int64(len(ids)) {
+				txm.lggr.Errorw("unable to mark unstarted txes as started, some were transitioned concurrently", "expected", len(ids), "updated", updated)
+				return errors.Errorf("expected %d msgs started, got %d", len(ids), updated)
+			}
 		}
 		for _, msg := range started {
 			msgs.add(msg)
 		}
-		// Update expired messages (Unstarted or Started) to Errored
-		err = txm.orm.UpdateMsgs(msgs.expired.GetIDs(), db.Errored, nil, pg.WithQueryer(tx))
+		// Update expired messages (Unstarted or Started) to Errored, unless they were transitioned concurrently
+		expired := msgs.expired.GetIDs()
+		updated, err := txm.orm.UpdateMsgs(expired, []db.State{db.Unstarted, db.Started}, db.Errored, nil, pg.WithQueryer(tx))
 		if err != nil {
 			// Assume transient db error retry
 			txm.lggr.Errorw("unable to mark expired txes as errored", "err", err)
 			return err
 		}
+		if len(updated) != len(expired) {
+			txm.lggr.Warnw("some expired msgs were transitioned concurrently, leaving them as they are", "msgs", expired, "updated", updated)
+		}
 		return nil
 	})
 	if err != nil {
-		return
+		return err
 	}
 	if len(msgs.valid) == 0 {
-		return
+		return nil
 	}
 	msgs.sortValid()
 	txm.lggr.Debugw("building a batch", "not expired", msgs.valid, "marked expired", msgs.expired)
+	msgsByFrom := txm.groupMsgsBySender(msgs.valid)
+	memos, err := txm.limitToMemos(msgsByFrom)
+	if err != nil {
+		return err
+	}
+	gasLimits, err := txm.limitToGasLimits(msgsByFrom)
+	if err != nil {
+		return err
+	}
+
+	txm.lggr.Debugw("msgsByFrom", "msgsByFrom", msgsByFrom)
+	gasPrice, err := txm.GasPrice()
+	if err != nil {
+		// Should be impossible
+		txm.lggr.Criticalw("Failed to get gas price", "err", err)
+		return err
+	}
+	var merr error
+	keys := make(map[string]terrakey.Key)
+	for s, msgs := range msgsByFrom {
+		key, err := txm.ks.Get(s)
+		if errors.Is(err, keystore.ErrLocked) {
+			// The keystore is unlocked some time after the node starts, so leave the msgs Started to be sent once it
+			// is. The batch fails, so polling backs off meanwhile.
+			txm.lggr.Warnw("keystore is locked, retrying msgs once unlocked", "from", s, "msgs", msgs.GetIDs())
+			merr = multierr.Append(merr, errors.Wrapf(err, "unable to get key for %s", s))
+			continue
+		}
+		if err != nil {
+			// We check the transmitter key exists when the job is added. So it would have to be deleted
+			// after it was added for this to happen. Mark the msgs as errored so they aren't retried every poll,
+			// they can be re-enqueued with ReenqueueMissingKeyMsgs should the key be re-added.
+			txm.lggr.Warnw("unable to find key for from address, marking msgs as errored", "err", err, "from", s, "msgs", msgs.GetIDs())
+			if _, err2 := txm.orm.UpdateMsgsErrored(msgs.GetIDs(), []db.State{db.Started}, reasonNoKey); err2 != nil {
+				txm.lggr.Errorw("unable to mark msgs from unknown sender as errored", "err", err2, "from", s)
+				merr = multierr.Append(merr, err2)
+			}
+			continue
+		}
+		keys[s] = key
+	}
+	txm.deferredSenders = nil
+	if txm.multiSigner && len(keys) > 1 {
+		return multierr.Append(merr, txm.sendMultiSignerBatch(ctx, gasPrice, msgsByFrom, keys, memos, gasLimits))
+	}
+	for _, s := range txm.orderSenders(msgsByFrom) {
+		key, ok := keys[s]
+		if !ok {
+			continue
+		}
+		if txm.batchDeadline > 0 && txm.clock.Now().Sub(batchStart) > txm.batchDeadline {
+			// Left Started, to be sent first by the next batch
+			if txm.deferredSenders == nil {
+				txm.deferredSenders = make(map[string]struct{})
+			}
+			txm.deferredSenders[s] = struct{}{}
+			continue
+		}
+		sender, _ := sdk.AccAddressFromBech32(s) // Already checked validity above
+		merr = multierr.Append(merr, txm.sendMsgBatchFromAddress(ctx, gasPrice, sender, key, msgsByFrom[s], memos[s], gasLimits))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	if len(txm.deferredSenders) > 0 {
+		txm.lggr.Warnw("batch deadline exceeded, deferring remaining senders to the next batch", "deadline", txm.batchDeadline,
+			"elapsed", txm.clock.Now().Sub(batchStart), "deferred", len(txm.deferredSenders), "senders", len(keys))
+		promTerraTxmBatchDeadlineExceeded.WithLabelValues(txm.orm.chainID).Inc()
+	}
+	return merr
+}
+
+// groupMsgsBySender decodes msgs and groups them by sender, skipping any which can't be decoded.
+func (txm *Txm) groupMsgsBySender(msgs terra.Msgs) map[string]terra.Msgs {
 	var msgsByFrom = make(map[string]terra.Msgs)
-	for _, m := range msgs.valid {
-		msg, sender, err2 := unmarshalMsg(m.Type, m.Raw)
-		if err2 != nil {
+	for _, m := range msgs {
+		msg, sender, err := unmarshalMsg(m.Type, m.Raw)
+		if err != nil {
 			// Should be impossible given the check in Enqueue
-			txm.lggr.Criticalw("Failed to unmarshal msg, skipping", "err", err2, "msg", m)
+			txm.lggr.Criticalw("Failed to unmarshal msg, skipping", "err", err, "msg", m)
 			continue
 		}
 		m.DecodedMsg = msg
-		_, err2 = sdk.AccAddressFromBech32(sender)
-		if err2 != nil {
+		_, err = sdk.AccAddressFromBech32(sender)
+		if err != nil {
 			// Should never happen, we parse sender on Enqueue
-			txm.lggr.Criticalw("Unable to parse sender", "err", err2, "sender", sender)
+			txm.lggr.Criticalw("Unable to parse sender", "err", err, "sender", sender)
 			continue
 		}
 		msgsByFrom[sender] = append(msgsByFrom[sender], m)
 	}
+	return msgsByFrom
+}
+
+// EstimateBatchFee returns the projected fee per sender for the next batch, without changing any msg state or sending
+// anything. The batch is selected and grouped like sendMsgBatch: leftover Started msgs, then Unstarted msgs up to
+// MaxMsgsPerBatch, excluding expired msgs. Msgs which fail simulation are excluded, as they would not be sent.
+// If estimating fails for a sender, the fees for the remaining senders are returned along with the error.
+func (txm *Txm) EstimateBatchFee() (map[string]sdk.Coins, error) {
+	msgs := msgValidator{cutoff: txm.clock.Now().Add(-txm.cfg.TxMsgTimeout())}
+	started, err := txm.orm.GetStartedMsgs(txm.cfg.MaxMsgsPerBatch())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read started msgs")
+	}
+	if limit := txm.cfg.MaxMsgsPerBatch() - int64(len(started)); limit > 0 {
+		unstarted, err2 := txm.orm.GetMsgsState(db.Unstarted, limit)
+		if err2 != nil {
+			return nil, errors.Wrap(err2, "unable to read unstarted msgs")
+		}
+		for _, msg := range unstarted {
+			msgs.add(msg)
+		}
+	}
+	for _, msg := range started {
+		msgs.add(msg)
+	}
+	fees := make(map[string]sdk.Coins)
+	if len(msgs.valid) == 0 {
+		return fees, nil
+	}
+	msgs.sortValid()
 
-	txm.lggr.Debugw("msgsByFrom", "msgsByFrom", msgsByFrom)
 	gasPrice, err := txm.GasPrice()
 	if err != nil {
-		// Should be impossible
-		txm.lggr.Criticalw("Failed to get gas price", "err", err)
-		return
+		return nil, errors.Wrap(err, "unable to get gas price")
 	}
-	for s, msgs := range msgsByFrom {
-		sender, _ := sdk.AccAddressFromBech32(s) // Already checked validity above
-		key, err := txm.ks.Get(sender.String())
+	tc, err := txm.tc()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get client")
+	}
+	var merr error
+	for s, msgs := range txm.groupMsgsBySender(msgs.valid) {
+		fee, err := txm.estimateFeeFromAddress(tc, gasPrice, s, msgs)
 		if err != nil {
-			txm.lggr.Errorw("unable to find key for from address", "err", err, "from", sender.String())
-			// We check the transmitter key exists when the job is added. So it would have to be deleted
-			// after it was added for this to happen. Retry on next poll should the key be re-added.
+			merr = multierr.Append(merr, errors.Wrapf(err, "unable to estimate fee for %s", s))
 			continue
 		}
-		txm.sendMsgBatchFromAddress(ctx, gasPrice, sender, key, msgs)
-		if ctx.Err() != nil {
-			return
-		}
+		fees[s] = fee
+	}
+	return fees, merr
+}
+
+// estimateFeeFromAddress simulates msgs from sender and returns the fee a batch of the successful msgs would pay.
+func (txm *Txm) estimateFeeFromAddress(tc terraclient.ReaderWriter, gasPrice sdk.DecCoin, s string, msgs terra.Msgs) (sdk.Coins, error) {
+	sender, _ := sdk.AccAddressFromBech32(s) // Already checked validity in groupMsgsBySender
+	_, sn, err := txm.account(tc, sender)
+	if err != nil {
+		return nil, err
+	}
+	simResults, err := tc.BatchSimulateUnsigned(msgs.GetSimMsgs(), sn)
+	if err != nil {
+		return nil, err
+	}
+	if len(simResults.Succeeded) == 0 {
+		return sdk.NewCoins(), nil
 	}
+	sim, err := tc.SimulateUnsigned(simResults.Succeeded.GetMsgs(), sn)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.NewCoins(txFee(sim.GasInfo.GasUsed, txm.cfg.GasLimitMultiplier(), gasPrice)), nil
+}
+
+// txFee returns the fee of a tx with the given simulated gas usage, as set by CreateAndSign.
+func txFee(gasUsed uint64, gasLimitMultiplier float64, gasPrice sdk.DecCoin) sdk.Coin {
+	gasLimit := uint64(math.Ceil(float64(gasUsed) * gasLimitMultiplier))
+	return sdk.NewCoin(gasPrice.Denom, gasPrice.Amount.MulInt64(int64(gasLimit)).Ceil().RoundInt())
+}
 
+// checkMaxFee returns ErrMaxFeeExceeded if fee exceeds the max fee, logging and counting the skipped tx.
+func (txm *Txm) checkMaxFee(fee sdk.Coin, logKVs ...interface{}) error {
+	if txm.maxFee == nil {
+		return nil
+	}
+	if fee.Denom == txm.maxFee.Denom && fee.Amount.LTE(txm.maxFee.Amount) {
+		return nil
+	}
+	txm.lggr.Warnw("tx fee exceeds max fee, not broadcasting", append(logKVs, "fee", fee.String(), "maxFee", txm.maxFee.String())...)
+	promTerraTxmMaxFeeExceeded.WithLabelValues(txm.orm.chainID).Inc()
+	return errors.Wrapf(ErrMaxFeeExceeded, "fee %s exceeds max fee %s", fee, txm.maxFee)
 }
 
-func (txm *Txm) sendMsgBatchFromAddress(ctx context.Context, gasPrice sdk.DecCoin, sender sdk.AccAddress, key terrakey.Key, msgs terra.Msgs) {
+func (txm *Txm) sendMsgBatchFromAddress(ctx context.Context, gasPrice sdk.DecCoin, sender sdk.AccAddress, key terrakey.Key, msgs terra.Msgs, memo string, gasLimits map[int64]uint64) error {
 	tc, err := txm.tc()
 	if err != nil {
 		txm.lggr.Criticalw("unable to get client", "err", err)
-		return
+		return err
 	}
-	an, sn, err := tc.Account(sender)
+	stx, err := txm.prepareSenderTx(tc, sender, key, msgs, memo, gasLimits)
+	if err != nil || stx == nil {
+		return err
+	}
+	return txm.sendSenderTx(ctx, tc, gasPrice, stx)
+}
+
+// senderTx is a batch of msgs from a single sender which succeeded in simulation, ready to be signed.
+type senderTx struct {
+	sender        sdk.AccAddress
+	key           terrakey.Key
+	accountNumber uint64
+	sequence      uint64
+	msgs          terraclient.SimMsgs
+	gasLimit      uint64
+	memo          string
+}
+
+// prepareSenderTx simulates msgs from sender, marking those that fail as Errored, and returns the batch of the
+// successful msgs along with its gas limit, to be sent with memo. The gas limit is the simulated gas, or the sum of the
+// gas limit overrides of the msgs in gasLimits if higher. It returns nil if all msgs failed.
+func (txm *Txm) prepareSenderTx(tc terraclient.ReaderWriter, sender sdk.AccAddress, key terrakey.Key, msgs terra.Msgs, memo string, gasLimits map[int64]uint64) (*senderTx, error) {
+	an, sn, err := txm.account(tc, sender)
 	if err != nil {
 		txm.lggr.Warnw("unable to read account", "err", err, "from", sender.String())
 		// If we can't read the account, assume transient api issues and leave msgs unstarted
 		// to retry on next poll.
-		return
+		return nil, err
 	}
 
 	txm.lggr.Debugw("simulating batch", "from", sender, "msgs", msgs, "seqnum", sn)
@@ -292,85 +741,200 @@ func (txm *Txm) sendMsgBatchFromAddress(ctx context.Context, gasPrice sdk.DecCoi
 		// Note one rare scenario in which this can happen: the terra node misbehaves
 		// in that it confirms a txhash is present but still gives an old seq num.
 		// This is benign as the next retry will succeeds.
-		return
+		// A cached sequence may be stale as well, so read it from chain on retry.
+		txm.seqs.invalidate(sender)
+		return nil, err
 	}
 	txm.lggr.Debugw("simulation results", "from", sender, "succeeded", simResults.Succeeded, "failed", simResults.Failed)
-	err = txm.orm.UpdateMsgs(simResults.Failed.GetSimMsgsIDs(), db.Errored, nil)
+	err = txm.updateMsgsFromState(simResults.Failed.GetSimMsgsIDs(), db.Started, db.Errored, nil)
 	if err != nil {
 		txm.lggr.Errorw("unable to mark failed sim txes as errored", "err", err, "from", sender.String())
 		// If we can't mark them as failed retry on next poll. Presumably same ones will fail.
-		return
+		return nil, err
 	}
 
 	// Continue if there are no successful txes
 	if len(simResults.Succeeded) == 0 {
 		txm.lggr.Warnw("all sim msgs errored, not sending tx", "from", sender.String())
-		return
+		return nil, nil
 	}
 	// Get the gas limit for the successful batch
 	s, err := tc.SimulateUnsigned(simResults.Succeeded.GetMsgs(), sn)
 	if err != nil {
 		// In the OCR context this should only happen upon stale report
 		txm.lggr.Warnw("unexpected failure after successful simulation", "err", err)
-		return
+		txm.seqs.invalidate(sender)
+		return nil, err
 	}
 	gasLimit := s.GasInfo.GasUsed
+	if override := gasLimitOverride(simResults.Succeeded, gasLimits); override > gasLimit {
+		txm.lggr.Debugw("using gas limit override above simulated gas", "from", sender, "simulated", gasLimit, "override", override)
+		gasLimit = override
+	}
+	return &senderTx{
+		sender:        sender,
+		key:           key,
+		accountNumber: an,
+		sequence:      sn,
+		msgs:          simResults.Succeeded,
+		gasLimit:      gasLimit,
+		memo:          memo,
+	}, nil
+}
 
+// sendSenderTx signs, broadcasts and confirms a tx with the msgs of stx.
+func (txm *Txm) sendSenderTx(ctx context.Context, tc terraclient.ReaderWriter, gasPrice sdk.DecCoin, stx *senderTx) error {
 	lb, err := tc.LatestBlock()
 	if err != nil {
-		txm.lggr.Warnw("unable to get latest block", "err", err, "from", sender.String())
+		txm.lggr.Warnw("unable to get latest block", "err", err, "from", stx.sender.String())
 		// Assume transient api issue and retry.
-		return
+		return err
 	}
 	timeoutHeight := uint64(lb.Block.Header.Height) + uint64(txm.cfg.BlocksUntilTxTimeout())
-	signedTx, err := tc.CreateAndSign(simResults.Succeeded.GetMsgs(), an, sn, gasLimit, txm.cfg.GasLimitMultiplier(),
-		gasPrice, NewKeyWrapper(key), timeoutHeight)
+	gasPrice, err = txm.txGasPrice(tc, txm.feePayer(stx.sender), gasPrice, stx.gasLimit, "from", stx.sender.String(), "msgs", stx.msgs.GetSimMsgsIDs())
 	if err != nil {
-		txm.lggr.Errorw("unable to sign tx", "err", err, "from", sender.String())
-		return
+		return err
+	}
+	var signedTx []byte
+	if stx.memo == "" && txm.feeGranter.Empty() {
+		signedTx, err = tc.CreateAndSign(stx.msgs.GetMsgs(), stx.accountNumber, stx.sequence, stx.gasLimit, txm.cfg.GasLimitMultiplier(),
+			gasPrice, NewKeyWrapper(stx.key), timeoutHeight)
+	} else {
+		// CreateAndSign can't set a memo or fee granter
+		signedTx, err = signMultiSignerTx(txm.orm.chainID, []*senderTx{stx}, txm.cfg.GasLimitMultiplier(), gasPrice, timeoutHeight, stx.memo, txm.feeGranter)
+	}
+	if err != nil {
+		txm.lggr.Errorw("unable to sign tx", "err", err, "from", stx.sender.String())
+		return err
 	}
+	err = txm.broadcastAndConfirm(ctx, tc, signedTx, []string{stx.sender.String()}, stx.msgs.GetSimMsgsIDs(),
+		"from", stx.sender, "msgs", stx.msgs, "gasLimit", stx.gasLimit, "gasPrice", gasPrice.String(), "timeoutHeight", timeoutHeight, "memo", stx.memo, "feeGranter", txm.feeGranter)
+	txm.recordSendResult(err, stx)
+	return err
+}
 
-	// We need to ensure that we either broadcast successfully and mark the tx as
-	// broadcasted OR we do not broadcast successfully and we do not mark it as broadcasted.
-	// We do this by first marking it broadcasted then rolling back if the broadcast api call fails.
-	// There is still a small chance of network failure or node/db crash after broadcasting but before committing the tx,
-	// in which case the msgs would be picked up again and re-broadcast, ensuring at-least once delivery.
-	var resp *txtypes.BroadcastTxResponse
-	err = txm.orm.q.Transaction(func(tx pg.Queryer) error {
-		txHash := strings.ToUpper(hex.EncodeToString(tmhash.Sum(signedTx)))
-		err = txm.orm.UpdateMsgs(simResults.Succeeded.GetSimMsgsIDs(), db.Broadcasted, &txHash, pg.WithQueryer(tx))
-		if err != nil {
-			return err
-		}
+// broadcastAndConfirm broadcasts signedTx, signed by senders, marking the msgs with the given ids as Broadcasted, and
+// waits for the tx to be confirmed. The result of the broadcast is passed to the callback of WithOnBroadcast, if set.
+// logKVs are added to the broadcast logs.
+//
+// Crash recovery: the hash of the tx is committed as a pending broadcast of the msgs before broadcasting, and the msgs
+// are only marked Broadcasted once the node accepted the tx. If the node rejects it, the pending broadcast is cleared
+// and the msgs are sent again on the next poll. If the txm crashes, or fails to mark the msgs Broadcasted, after
+// broadcasting, the msgs are left with a pending broadcast, which recoverPendingBroadcasts resolves by looking for the
+// tx on chain instead of sending the msgs again.
+func (txm *Txm) broadcastAndConfirm(ctx context.Context, tc terraclient.ReaderWriter, signedTx []byte, senders []string, ids []int64, logKVs ...interface{}) error {
+	txHash := strings.ToUpper(hex.EncodeToString(tmhash.Sum(signedTx)))
+	if err := txm.orm.SetPendingBroadcast(ids, txHash); err != nil {
+		txm.lggr.Errorw("unable to record pending broadcast", append(logKVs, "err", err, "hash", txHash)...)
+		// Nothing was sent, retry on next poll
+		return err
+	}
 
-		txm.lggr.Infow("broadcasting tx", "from", sender, "msgs", simResults.Succeeded, "gasLimit", gasLimit, "gasPrice", gasPrice.String(), "timeoutHeight", timeoutHeight, "hash", txHash)
-		resp, err = tc.Broadcast(signedTx, txtypes.BroadcastMode_BROADCAST_MODE_SYNC)
-		if err != nil {
-			// Rollback marking as broadcasted
-			// Note can happen if the node's mempool is full, where we expect errCode 20.
-			return err
+	txm.lggr.Infow("broadcasting tx", append(logKVs, "hash", txHash)...)
+	resp, err := tc.Broadcast(signedTx, txtypes.BroadcastMode_BROADCAST_MODE_SYNC)
+	if err == nil && resp.TxResponse == nil {
+		err = errors.New("unexpected nil tx response")
+	}
+	txm.notifyBroadcast(BroadcastResult{Senders: senders, MsgIDs: ids, TxHash: txHash, Err: err})
+	if err != nil {
+		// Note can happen if the node's mempool is full, where we expect errCode 20.
+		txm.lggr.Errorw("error broadcasting tx", append(logKVs, "err", err)...)
+		if cerr := txm.orm.ClearPendingBroadcast(ids, txHash); cerr != nil {
+			// Left pending, so recoverPendingBroadcasts requeues the msgs once the tx is known not to have landed
+			txm.lggr.Errorw("unable to clear pending broadcast", "err", cerr, "hash", txHash, "msgs", ids)
+			return multierr.Append(err, cerr)
 		}
-		if resp.TxResponse == nil {
-			// Rollback marking as broadcasted
-			return errors.New("unexpected nil tx response")
+		// Was unable to broadcast, retry on next poll
+		if rerr := txm.recordMsgsFailure(ids, err); rerr != nil {
+			return multierr.Append(err, rerr)
 		}
-		if resp.TxResponse.TxHash != txHash {
-			// Should never happen
-			txm.lggr.Criticalw("txhash mismatch", "got", resp.TxResponse.TxHash, "want", txHash)
+		if _, eerr := txm.errorMsgsOverRetries(ids, db.Started); eerr != nil {
+			txm.lggr.Errorw("unable to mark msgs over max retries as errored", "err", eerr, "msgs", ids)
+			return multierr.Append(err, eerr)
 		}
-		return nil
-	})
-	if err != nil {
-		txm.lggr.Errorw("error broadcasting tx", "err", err, "from", sender.String())
-		// Was unable to broadcast, retry on next poll
-		return
+		return err
+	}
+	if resp.TxResponse.TxHash != txHash {
+		// Should never happen
+		txm.lggr.Criticalw("txhash mismatch", "got", resp.TxResponse.TxHash, "want", txHash)
+	}
+	if err = txm.updateMsgsFromState(ids, db.Started, db.Broadcasted, &txHash); err != nil {
+		// Left pending, so recoverPendingBroadcasts confirms the msgs on a later poll
+		txm.lggr.Errorw("unable to mark broadcasted txes as broadcasted", "err", err, "hash", txHash, "msgs", ids)
+		return err
 	}
 
 	maxPolls, pollPeriod := txm.confirmPollConfig()
-	if err := txm.confirmTx(ctx, tc, resp.TxResponse.TxHash, simResults.Succeeded.GetSimMsgsIDs(), maxPolls, pollPeriod); err != nil {
+	if err := txm.confirmTx(ctx, tc, resp.TxResponse.TxHash, ids, maxPolls, pollPeriod); err != nil {
 		txm.lggr.Errorw("error confirming tx", "err", err, "hash", resp.TxResponse.TxHash)
-		return
+		return err
+	}
+	return nil
+}
+
+// recoverPendingBroadcasts resolves msgs left with a pending broadcast by a crash, or a failed update, after broadcasting
+// their tx, so they're never sent twice. If the tx is found on chain, the msgs are marked Confirmed. Otherwise the node
+// may still have the tx in its mempool, so the msgs are marked Broadcasted and confirmed as usual: should the tx not
+// land before timing out, reconcileTimedOutMsgs requeues them once it's verified to be absent from the chain.
+// If the lookup fails, the msgs are left pending to retry on next poll.
+func (txm *Txm) recoverPendingBroadcasts(ctx context.Context) error {
+	pending, err := txm.orm.GetPendingBroadcastMsgs(txm.cfg.MaxMsgsPerBatch())
+	if err != nil {
+		txm.lggr.Errorw("unable to read msgs with a pending broadcast", "err", err)
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	tc, err := txm.tc()
+	if err != nil {
+		txm.lggr.Criticalw("unable to get client for handling pending broadcasts", "count", len(pending), "err", err)
+		return err
+	}
+	msgsByTxHash := make(map[string]terra.Msgs)
+	for _, msg := range pending {
+		msgsByTxHash[*msg.TxHash] = append(msgsByTxHash[*msg.TxHash], msg)
+	}
+	var errs error
+	for txHash, msgs := range msgsByTxHash {
+		txHash, ids := txHash, msgs.GetIDs()
+		found, err := txOnChain(tc, txHash)
+		if err != nil {
+			txm.lggr.Warnw("unable to look for tx of pending broadcast", "err", err, "hash", txHash)
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		err = txm.orm.q.Transaction(func(tx pg.Queryer) error {
+			if err := txm.updateMsgsFromState(ids, db.Started, db.Broadcasted, &txHash, pg.WithQueryer(tx)); err != nil {
+				return err
+			}
+			if found {
+				return txm.updateMsgsFromState(ids, db.Broadcasted, txm.onChainState(), &txHash, pg.WithQueryer(tx))
+			}
+			return nil
+		})
+		if err != nil {
+			txm.lggr.Errorw("unable to update msgs with a pending broadcast", "err", err, "hash", txHash)
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		if found {
+			txm.lggr.Infow("tx of pending broadcast found on chain", "hash", txHash, "msgs", ids)
+			if txm.onChainState() == db.Confirmed {
+				txm.notifyConfirmed(txHash, ids)
+			}
+			continue
+		}
+		txm.lggr.Infow("tx of pending broadcast not found on chain, confirming", "hash", txHash, "msgs", ids)
+		maxPolls, pollPeriod := txm.confirmPollConfig()
+		if err := txm.confirmTx(ctx, tc, txHash, ids, maxPolls, pollPeriod); err != nil {
+			errs = multierr.Append(errs, err)
+			if ctx.Err() != nil {
+				return errs
+			}
+		}
 	}
+	return errs
 }
 
 func (txm *Txm) confirmPollConfig() (maxPolls int, pollPeriod time.Duration) {
@@ -388,7 +952,8 @@ func (txm *Txm) confirmPollConfig() (maxPolls int, pollPeriod time.Duration) {
 
 func (txm *Txm) confirmTx(ctx context.Context, tc terraclient.Reader, txHash string, broadcasted []int64, maxPolls int, pollPeriod time.Duration) error {
 	// We either mark these broadcasted txes as confirmed or errored.
-	// Confirmed: we see the txhash onchain. There are no reorgs in cosmos chains.
+	// Confirmed: we see the txhash onchain. There are no reorgs in cosmos chains, unless configured otherwise with
+	// WithConfirmationDepth, in which case they're Confirming until the tx is deep enough.
 	// Errored: we do not see the txhash onchain after waiting for N blocks worth
 	// of time (plus a small buffer to account for block time variance) where N
 	// is TimeoutHeight - HeightAtBroadcast. In other words, if we wait for that long
@@ -398,7 +963,7 @@ func (txm *Txm) confirmTx(ctx context.Context, tc terraclient.Reader, txHash str
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(utils.WithJitter(pollPeriod)):
+		case <-txm.clock.After(utils.WithJitter(pollPeriod)):
 		}
 		// Confirm that this tx is onchain, ensuring the sequence number has incremented
 		// so we can build a new batch
@@ -417,27 +982,372 @@ func (txm *Txm) confirmTx(ctx context.Context, tc terraclient.Reader, txHash str
 			continue
 		}
 
-		txm.lggr.Infow("successfully sent batch", "hash", txHash, "msgs", broadcasted)
-		// If confirmed mark these as completed.
-		err = txm.orm.UpdateMsgs(broadcasted, db.Confirmed, nil)
+		txm.lggr.Infow("successfully sent batch", "hash", txHash, "msgs", broadcasted, "height", tx.TxResponse.Height)
+		// If confirmed mark these as completed, or as confirming until the tx is deep enough.
+		state := txm.onChainState()
+		err = txm.updateMsgsFromState(broadcasted, db.Broadcasted, state, &txHash)
 		if err != nil {
 			return err
 		}
+		if state == db.Confirmed {
+			txm.notifyConfirmed(txHash, broadcasted)
+		}
 		return nil
 	}
-	txm.lggr.Errorw("unable to confirm tx after timeout period, marking errored", "hash", txHash)
-	// If we are unable to confirm the tx after the timeout period
-	// mark these msgs as errored
-	err := txm.orm.UpdateMsgs(broadcasted, db.Errored, nil)
+	txm.lggr.Errorw("unable to confirm tx after timeout period, marking timed out", "hash", txHash)
+	// If we are unable to confirm the tx after the timeout period mark these msgs as timed out.
+	// They are only requeued once the tx is verified to be absent from the chain, see reconcileTimedOutMsgs.
+	err := txm.updateMsgsFromState(broadcasted, db.Broadcasted, TimedOut, nil)
+	if err != nil {
+		txm.lggr.Errorw("unable to mark timed out txes as timed out", "err", err, "txes", broadcasted, "num", len(broadcasted))
+		return err
+	}
+	return txm.recordMsgsFailure(broadcasted, errors.Errorf("tx %s not confirmed before timing out", txHash))
+}
+
+// reconcileTimedOutMsgs looks for the txs of TimedOut msgs on chain. Msgs whose tx landed after all are marked
+// Confirmed, and msgs whose tx is absent are requeued as Unstarted, or Errored if they failed more than the max retries,
+// see WithMaxMsgRetries. If the lookup fails, the msgs are left TimedOut to retry on next poll, so a tx which might
+// still land is never sent twice.
+func (txm *Txm) reconcileTimedOutMsgs() error {
+	timedOut, err := txm.orm.GetMsgsState(TimedOut, txm.cfg.MaxMsgsPerBatch())
+	if err != nil {
+		txm.lggr.Errorw("unable to read timed out msgs", "err", err)
+		return err
+	}
+	if len(timedOut) == 0 {
+		return nil
+	}
+	tc, err := txm.tc()
+	if err != nil {
+		txm.lggr.Criticalw("unable to get client for handling timed out txes", "count", len(timedOut), "err", err)
+		return err
+	}
+	msgsByTxHash := make(map[string]terra.Msgs)
+	for _, msg := range timedOut {
+		msgsByTxHash[*msg.TxHash] = append(msgsByTxHash[*msg.TxHash], msg)
+	}
+	var errs error
+	for txHash, msgs := range msgsByTxHash {
+		found, err := txOnChain(tc, txHash)
+		if err != nil {
+			txm.lggr.Warnw("unable to look for timed out tx", "err", err, "hash", txHash)
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		if found {
+			txm.lggr.Infow("timed out tx landed after all", "hash", txHash, "msgs", msgs.GetIDs())
+			state := txm.onChainState()
+			if err := txm.updateMsgsFromState(msgs.GetIDs(), TimedOut, state, &txHash); err != nil {
+				txm.lggr.Errorw("unable to mark timed out txes as confirmed", "err", err, "hash", txHash)
+				errs = multierr.Append(errs, err)
+				continue
+			}
+			if state == db.Confirmed {
+				txm.notifyConfirmed(txHash, msgs.GetIDs())
+			}
+			continue
+		}
+		txm.lggr.Infow("timed out tx not found on chain, requeueing msgs", "hash", txHash, "msgs", msgs.GetIDs())
+		err = txm.orm.q.Transaction(func(tx pg.Queryer) error {
+			requeue, err := txm.errorMsgsOverRetries(msgs.GetIDs(), TimedOut, pg.WithQueryer(tx))
+			if err != nil || len(requeue) == 0 {
+				return err
+			}
+			return txm.orm.RequeueMsgs(requeue, TimedOut, pg.WithQueryer(tx))
+		})
+		if err != nil {
+			txm.lggr.Errorw("unable to requeue timed out txes", "err", err, "hash", txHash)
+			errs = multierr.Append(errs, err)
+		}
+	}
+	return errs
+}
+
+// recordMsgsFailure records a failed attempt to send the msgs with the given ids, see ORM.RecordMsgsFailure. Failing to
+// record it is returned, as the msgs would otherwise be retried past the max retries.
+func (txm *Txm) recordMsgsFailure(ids []int64, cause error) error {
+	if err := txm.orm.RecordMsgsFailure(ids, cause.Error()); err != nil {
+		txm.lggr.Errorw("unable to record failed attempt", "err", err, "msgs", ids, "cause", cause)
+		return errors.Wrap(err, "unable to record failed attempt")
+	}
+	return nil
+}
+
+// updateMsgsFromState moves the msgs with the given ids from state from to state to, see ORM.UpdateMsgsFromState, so msgs
+// concurrently moved out of from are left as they are instead of being overwritten. Those are only logged, as the
+// others still need to be transitioned.
+func (txm *Txm) updateMsgsFromState(ids []int64, from, to db.State, txHash *string, qopts ...pg.QOpt) error {
+	updated, err := txm.orm.UpdateMsgsFromState(ids, from, to, txHash, qopts...)
 	if err != nil {
-		txm.lggr.Errorw("unable to mark timed out txes as errored", "err", err, "txes", broadcasted, "num", len(broadcasted))
 		return err
 	}
+	if updated != int64(len(ids)) {
+		txm.lggr.Warnw("some msgs were transitioned concurrently, leaving them as they are", "msgs", ids, "from", from, "to", to, "expected", len(ids), "updated", updated)
+	}
 	return nil
 }
 
+// errorMsgsOverRetries marks the msgs with the given ids in state from which failed more than the max retries as
+// Errored, see WithMaxMsgRetries, and returns the ids of the others.
+func (txm *Txm) errorMsgsOverRetries(ids []int64, from db.State, qopts ...pg.QOpt) ([]int64, error) {
+	if txm.maxMsgRetries <= 0 {
+		return ids, nil
+	}
+	errored, err := txm.orm.ErrorMsgsOverRetries(ids, from, txm.maxMsgRetries, reasonMaxRetries, qopts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(errored) == 0 {
+		return ids, nil
+	}
+	txm.lggr.Errorw("msgs failed more than the max retries, marking errored", "msgs", errored, "maxRetries", txm.maxMsgRetries)
+	var remaining []int64
+	for _, id := range ids {
+		if !slices.Contains(errored, id) {
+			remaining = append(remaining, id)
+		}
+	}
+	return remaining, nil
+}
+
+// onChainState returns the state of msgs whose tx was found on chain: Confirmed, unless a confirmation depth is set,
+// in which case they're Confirming until reconcileConfirmingMsgs finds their tx deep enough.
+func (txm *Txm) onChainState() db.State {
+	if txm.confirmationDepth > 0 {
+		return Confirming
+	}
+	return db.Confirmed
+}
+
+// reconcileConfirmingMsgs marks Confirming msgs Confirmed once their tx is at least the confirmation depth below the
+// latest block. Msgs whose tx is no longer found, e.g. after a reorg, are marked TimedOut, so reconcileTimedOutMsgs
+// requeues them once the tx is verified to be absent from the chain. If a lookup fails, the msgs are left Confirming
+// to retry on next poll.
+func (txm *Txm) reconcileConfirmingMsgs() error {
+	confirming, err := txm.orm.GetMsgsState(Confirming, txm.cfg.MaxMsgsPerBatch())
+	if err != nil {
+		txm.lggr.Errorw("unable to read confirming msgs", "err", err)
+		return err
+	}
+	if len(confirming) == 0 {
+		return nil
+	}
+	tc, err := txm.tc()
+	if err != nil {
+		txm.lggr.Criticalw("unable to get client for handling confirming txes", "count", len(confirming), "err", err)
+		return err
+	}
+	lb, err := tc.LatestBlock()
+	if err != nil {
+		txm.lggr.Warnw("unable to get latest block", "err", err)
+		return err
+	}
+	head := lb.Block.Header.Height
+	msgsByTxHash := make(map[string]terra.Msgs)
+	for _, msg := range confirming {
+		msgsByTxHash[*msg.TxHash] = append(msgsByTxHash[*msg.TxHash], msg)
+	}
+	var errs error
+	for txHash, msgs := range msgsByTxHash {
+		ids := msgs.GetIDs()
+		tx, err := tc.Tx(txHash)
+		if err != nil && strings.Contains(err.Error(), "not found") {
+			txm.lggr.Warnw("confirming tx no longer found on chain, marking timed out", "hash", txHash, "msgs", ids)
+			if err := txm.updateMsgsFromState(ids, Confirming, TimedOut, nil); err != nil {
+				txm.lggr.Errorw("unable to mark confirming txes as timed out", "err", err, "hash", txHash)
+				errs = multierr.Append(errs, err)
+			}
+			continue
+		}
+		if err == nil && (tx.TxResponse == nil || tx.TxResponse.TxHash != txHash) {
+			err = errors.New("unexpected tx response")
+		}
+		if err != nil {
+			txm.lggr.Warnw("unable to look for confirming tx", "err", err, "hash", txHash)
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		if depth := head - tx.TxResponse.Height; depth < txm.confirmationDepth {
+			txm.lggr.Debugw("waiting for confirming tx to be deep enough", "hash", txHash, "height", tx.TxResponse.Height, "depth", depth)
+			continue
+		}
+		txm.lggr.Infow("confirming tx is deep enough, marking confirmed", "hash", txHash, "height", tx.TxResponse.Height, "msgs", ids)
+		if err := txm.updateMsgsFromState(ids, Confirming, db.Confirmed, &txHash); err != nil {
+			txm.lggr.Errorw("unable to mark confirming txes as confirmed", "err", err, "hash", txHash)
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		txm.notifyConfirmed(txHash, ids)
+	}
+	return errs
+}
+
+// notifyConfirmed publishes a MsgsConfirmedEvent for the msgs with the given ids, so other services can react to
+// them settling. Failures are only logged, as the msgs are confirmed regardless.
+func (txm *Txm) notifyConfirmed(txHash string, ids []int64) {
+	if txm.eb == nil {
+		return
+	}
+	payload, err := json.Marshal(MsgsConfirmedEvent{ChainID: txm.orm.chainID, TxHash: txHash, MsgIDs: ids})
+	if err != nil {
+		txm.lggr.Errorw("unable to marshal confirmed msgs event", "err", err, "hash", txHash, "msgs", ids)
+		return
+	}
+	if err = txm.eb.Notify(pg.ChannelTerraMsgConfirmed, string(payload)); err != nil {
+		txm.lggr.Errorw("unable to publish confirmed msgs event", "err", err, "hash", txHash, "msgs", ids)
+	}
+}
+
+// txOnChain returns whether the tx with the given hash was included on chain. The query may return no tx, e.g. while
+// it's still pending, or several, e.g. duplicates due to a node quirk, so the tx is found if any of them has its hash.
+func txOnChain(tc terraclient.Reader, txHash string) (bool, error) {
+	resp, err := tc.TxsEvents([]string{"tx.hash=" + txHash}, nil)
+	if err != nil {
+		return false, err
+	}
+	if resp == nil {
+		return false, errors.New("unexpected nil txs events response")
+	}
+	for _, txResp := range resp.TxResponses {
+		if txResp != nil && strings.EqualFold(txResp.TxHash, txHash) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // Enqueue enqueue a msg destined for the terra chain.
 func (txm *Txm) Enqueue(contractID string, msg sdk.Msg) (int64, error) {
+	return txm.EnqueueWithPriority(contractID, msg, DefaultMsgPriority)
+}
+
+// EnqueueWithPriority enqueues a msg like Enqueue, to be batched ahead of any Unstarted msgs with a lower priority,
+// e.g. for time critical operations which shouldn't wait behind a backlog. Each batch takes the highest priority msgs
+// first, after any leftover Started msgs, so a msg is never held back by lower priority Unstarted ones, however many
+// there are. Msgs with the same priority are selected oldest first.
+func (txm *Txm) EnqueueWithPriority(contractID string, msg sdk.Msg, priority int32) (int64, error) {
+	return txm.enqueue(contractID, msg, priority, "", 0, nil)
+}
+
+// EnqueueWithMemo enqueues a msg like Enqueue, to be sent in a tx with the given memo, e.g. to tag the tx with a
+// correlation ID. A tx has a single memo, so msgs are only batched with msgs with the same memo: a sender's batch is
+// limited to the msgs with the memo of its first msg, the others are sent in later batches. Likewise, senders with
+// different memos don't share a multi-signer tx, see WithMultiSignerBatching.
+func (txm *Txm) EnqueueWithMemo(contractID string, msg sdk.Msg, memo string) (int64, error) {
+	if len(memo) > maxMemoLength {
+		return 0, errors.Errorf("memo is %d characters long, must be at most %d", len(memo), maxMemoLength)
+	}
+	return txm.enqueue(contractID, msg, DefaultMsgPriority, memo, 0, nil)
+}
+
+// EnqueueWithGasLimit enqueues a msg like Enqueue, to be sent with at least the given gas limit, e.g. for contract calls
+// whose gas needs vary too much for simulation to be reliable. Like the simulated gas it's a floor over, the gas limit
+// is scaled by the GasLimitMultiplier. Since the gas used by each msg of a batch isn't known, msgs with a gas limit are
+// only batched with other msgs with a gas limit, in a tx whose gas limit is the sum of theirs, or the simulated gas if
+// higher. Msgs without one are sent in separate batches.
+func (txm *Txm) EnqueueWithGasLimit(contractID string, msg sdk.Msg, gasLimit uint64) (int64, error) {
+	if gasLimit == 0 || gasLimit > math.MaxInt64 {
+		return 0, errors.Errorf("gas limit must be between 1 and %d, got %d", int64(math.MaxInt64), gasLimit)
+	}
+	return txm.enqueue(contractID, msg, DefaultMsgPriority, "", int64(gasLimit), nil)
+}
+
+// EnqueueWithNotBefore enqueues a msg like Enqueue, to be sent no earlier than notBefore, e.g. for an action which is
+// timelocked on chain. Until then the msg is left out of batches, and of the backlog reported by BacklogStats and
+// Healthy. Once due, it's picked up by the next batch like any Unstarted msg, and the TxMsgTimeout counts from
+// notBefore rather than from when it was enqueued. Like any Unstarted msg, it's cancelled if a newer msg is enqueued
+// for the same contract, even before it's due.
+func (txm *Txm) EnqueueWithNotBefore(contractID string, msg sdk.Msg, notBefore time.Time) (int64, error) {
+	if notBefore.IsZero() {
+		return 0, errors.New("not before time must be set")
+	}
+	return txm.enqueue(contractID, msg, DefaultMsgPriority, "", 0, &notBefore)
+}
+
+// EnqueueReq is a msg to enqueue with EnqueueBatch, along with the options of the other Enqueue methods.
+type EnqueueReq struct {
+	ContractID string
+	Msg        sdk.Msg
+	// Priority is the priority of the msg, see EnqueueWithPriority. The zero value is the DefaultMsgPriority.
+	Priority int32
+	// Memo is the memo of the tx of the msg if set, see EnqueueWithMemo.
+	Memo string
+	// GasLimit is the gas limit of the msg if set, see EnqueueWithGasLimit.
+	GasLimit uint64
+	// NotBefore is the earliest time the msg is sent at if set, see EnqueueWithNotBefore.
+	NotBefore time.Time
+}
+
+// EnqueueBatch enqueues several msgs at once like Enqueue, e.g. when bootstrapping, and returns their ids in the order
+// of reqs. The msgs are inserted in a single statement within a single transaction, so the txm is notified once for
+// all of them, rather than once per msg. Like Enqueue, it keeps a single Unstarted msg per contract: it cancels the
+// Unstarted msgs of their contracts enqueued before, and msgs of the batch are cancelled by later msgs of the batch for
+// the same contract, as if they had been enqueued one by one. Nothing is enqueued if any msg is invalid.
+func (txm *Txm) EnqueueBatch(reqs []EnqueueReq) ([]int64, error) {
+	inserts := make([]MsgInsert, len(reqs))
+	// contractIDs maps the contracts of the msgs to the index of their last msg
+	contractIDs := make(map[string]int)
+	for i, req := range reqs {
+		if len(req.Memo) > maxMemoLength {
+			return nil, errors.Errorf("msg %d: memo is %d characters long, must be at most %d", i, len(req.Memo), maxMemoLength)
+		}
+		if req.GasLimit > math.MaxInt64 {
+			return nil, errors.Errorf("msg %d: gas limit must be at most %d, got %d", i, int64(math.MaxInt64), req.GasLimit)
+		}
+		typeURL, raw, err := txm.marshalMsg(req.Msg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "msg %d", i)
+		}
+		inserts[i] = MsgInsert{
+			ContractID: req.ContractID,
+			TypeURL:    typeURL,
+			Raw:        raw,
+			Priority:   req.Priority,
+			Memo:       req.Memo,
+			GasLimit:   int64(req.GasLimit),
+		}
+		if !req.NotBefore.IsZero() {
+			notBefore := req.NotBefore
+			inserts[i].NotBefore = &notBefore
+		}
+		contractIDs[req.ContractID] = i
+	}
+
+	var ids []int64
+	err := txm.orm.q.Transaction(func(tx pg.Queryer) (err error) {
+		for contractID := range contractIDs {
+			// cancel any unstarted msgs (normally just one)
+			if err = txm.orm.UpdateMsgsContract(contractID, db.Unstarted, db.Errored, pg.WithQueryer(tx)); err != nil {
+				return err
+			}
+		}
+		ids, err = txm.orm.InsertMsgs(inserts, pg.WithQueryer(tx))
+		if err != nil {
+			return err
+		}
+		var superseded []int64
+		for i, req := range reqs {
+			if contractIDs[req.ContractID] != i {
+				superseded = append(superseded, ids[i])
+			}
+		}
+		if len(superseded) == 0 {
+			return nil
+		}
+		cancelled, err := txm.orm.UpdateMsgs(superseded, []db.State{db.Unstarted}, db.Errored, nil, pg.WithQueryer(tx))
+		if err != nil {
+			return err
+		}
+		if len(cancelled) != len(superseded) {
+			return errors.Errorf("expected %d superseded msgs cancelled, got %d", len(superseded), len(cancelled))
+		}
+		return nil
+	})
+	return ids, err
+}
+
+func (txm *Txm) enqueue(contractID string, msg sdk.Msg, priority int32, memo string, gasLimit int64, notBefore *time.Time) (int64, error) {
 	typeURL, raw, err := txm.marshalMsg(msg)
 	if err != nil {
 		return 0, err
@@ -455,12 +1365,129 @@ func (txm *Txm) Enqueue(contractID string, msg sdk.Msg) (int64, error) {
 		if err != nil {
 			return err
 		}
-		id, err = txm.orm.InsertMsg(contractID, typeURL, raw, pg.WithQueryer(tx))
+		id, err = txm.orm.insertMsg(contractID, typeURL, raw, priority, memo, gasLimit, notBefore, pg.WithQueryer(tx))
 		return err
 	})
 	return id, err
 }
 
+// Cancel cancels the msg with the given id if it hasn't been started yet, marking it as Errored.
+// It returns false if the msg doesn't exist or is already being sent, in which case it can't be cancelled.
+// Batches lock the Unstarted msgs they start, so a msg is never both cancelled and sent.
+func (txm *Txm) Cancel(id int64) (bool, error) {
+	return txm.orm.CancelMsg(id, reasonCancelled)
+}
+
+// Requeue moves the Errored msgs with the given ids back to Unstarted, e.g. to retry them once their key is imported or
+// their sender's balance is topped up. Their retries are reset, so they get another WithMaxMsgRetries attempts. Ids of
+// msgs which aren't Errored are ignored, and the number of msgs actually requeued is returned. Batches never select
+// Errored msgs, and the state is checked by the update itself, so requeuing is safe while batches are being sent.
+// A requeued msg is sent like a newly enqueued one, so its TxMsgTimeout counts from when it was requeued. Like Enqueue,
+// requeuing keeps a single Unstarted msg per contract: msgs superseded by a newer Unstarted msg of their contract, or
+// by a newer msg requeued along with them, are left Errored, and older Unstarted msgs of their contract are cancelled.
+// Like any Unstarted msg, a requeued msg is cancelled if a newer msg is enqueued for the same contract.
+func (txm *Txm) Requeue(ids []int64) (int, error) {
+	var requeued []int64
+	err := txm.orm.q.Transaction(func(tx pg.Queryer) (err error) {
+		requeued, _, err = txm.orm.RequeueErroredMsgs(ids, pg.WithQueryer(tx))
+		return err
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to requeue msgs")
+	}
+	txm.lggr.Infow("Requeued errored msgs", "requeued", requeued, "ids", ids)
+	return len(requeued), nil
+}
+
+// EnqueueUnique enqueues a msg like Enqueue, but deduplicates on idempotencyKey: if a msg was already
+// enqueued with the same key, the existing msg ID is returned and nothing is inserted.
+// Reusing a key for a msg with a different contract or payload returns ErrIdempotencyKeyReused.
+// Concurrent calls with the same new key may fail with a unique constraint violation, in which
+// case retrying returns the ID of the msg that won.
+func (txm *Txm) EnqueueUnique(contractID string, msg sdk.Msg, idempotencyKey string) (int64, error) {
+	if idempotencyKey == "" {
+		return 0, errors.New("idempotency key must not be empty")
+	}
+	typeURL, raw, err := txm.marshalMsg(msg)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = txm.orm.q.Transaction(func(tx pg.Queryer) error {
+		existing, err := txm.orm.GetMsgByIdempotencyKey(idempotencyKey, pg.WithQueryer(tx))
+		if err == nil {
+			if existing.ContractID != contractID || existing.Type != typeURL || !bytes.Equal(existing.Raw, raw) {
+				return errors.Wrapf(ErrIdempotencyKeyReused, "key %q used by msg %d", idempotencyKey, existing.ID)
+			}
+			id = existing.ID
+			return nil
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		// cancel any unstarted msgs (normally just one)
+		err = txm.orm.UpdateMsgsContract(contractID, db.Unstarted, db.Errored, pg.WithQueryer(tx))
+		if err != nil {
+			return err
+		}
+		id, err = txm.orm.InsertMsgWithKey(contractID, typeURL, raw, idempotencyKey, pg.WithQueryer(tx))
+		return err
+	})
+	return id, err
+}
+
+// ReenqueueMissingKeyMsgs moves the msgs from sender which errored because its key was missing from the keystore back
+// to Unstarted, returning their IDs. It should be called once the key has been (re-)imported. Like Requeue, the msgs
+// are sent like newly enqueued ones, however long ago they errored, and only the newest msg of each contract is.
+func (txm *Txm) ReenqueueMissingKeyMsgs(sender string) ([]int64, error) {
+	if _, err := txm.ks.Get(sender); err != nil {
+		return nil, errors.Wrapf(err, "no key for sender %s", sender)
+	}
+	var ids []int64
+	err := txm.orm.q.Transaction(func(tx pg.Queryer) error {
+		// The errored msgs are paged through, as a missing key may have errored a whole backlog
+		var afterID int64
+		for {
+			errored, err := txm.orm.GetMsgsErroredAfter(reasonNoKey, afterID, txm.cfg.MaxMsgsPerBatch(), pg.WithQueryer(tx))
+			if err != nil {
+				return err
+			}
+			if len(errored) == 0 {
+				return nil
+			}
+			afterID = errored[len(errored)-1].ID
+			var reenqueue []int64
+			for _, m := range errored {
+				_, msgSender, err := unmarshalMsg(m.Type, m.Raw)
+				if err != nil || msgSender != sender {
+					continue
+				}
+				reenqueue = append(reenqueue, m.ID)
+			}
+			if len(reenqueue) == 0 {
+				continue
+			}
+			// Clears the reason along with the state, so they aren't re-enqueued again. Msgs of a later page supersede
+			// those of the same contract re-enqueued before, which are cancelled.
+			requeued, cancelled, err := txm.orm.RequeueErroredMsgs(reenqueue, pg.WithQueryer(tx))
+			if err != nil {
+				return err
+			}
+			kept := ids[:0]
+			for _, id := range ids {
+				if !slices.Contains(cancelled, id) {
+					kept = append(kept, id)
+				}
+			}
+			ids = append(kept, requeued...)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
 func (txm *Txm) marshalMsg(msg sdk.Msg) (string, []byte, error) {
 	switch ms := msg.(type) {
 	case *wasmtypes.MsgExecuteContract:
@@ -494,30 +1521,96 @@ func (txm *Txm) GetMsgs(ids ...int64) (terra.Msgs, error) {
 	return txm.orm.GetMsgs(ids...)
 }
 
-// GasPrice returns the gas price from the estimator in uluna.
+// GetMsgRetries returns the failed attempts to send the msg with the given id, see WithMaxMsgRetries.
+func (txm *Txm) GetMsgRetries(id int64) (MsgRetries, error) {
+	return txm.orm.GetMsgRetries(id)
+}
+
+// GasPrice returns the gas price in uluna from the txm's GasPricer.
 func (txm *Txm) GasPrice() (sdk.DecCoin, error) {
-	prices := txm.gpe.GasPrices()
-	gasPrice, ok := prices["uluna"]
-	if !ok {
-		return sdk.DecCoin{}, errors.New("unexpected empty uluna price")
-	}
-	return gasPrice, nil
+	return txm.gasPricer.GasPrice()
 }
 
 // Close close service
 func (txm *Txm) Close() error {
-	txm.sub.Close()
-	close(txm.stop)
-	<-txm.done
-	return nil
+	err := txm.starter.StopOnce("terratxm", func() error {
+		txm.sub.Close()
+		close(txm.stop)
+		<-txm.done
+		if txm.broadcastHook != nil {
+			<-txm.broadcastHook.done
+		}
+		return nil
+	})
+	// Msgs can be enqueued whether or not the txm was started, so the ORM is released even if it wasn't, or failed to.
+	return multierr.Combine(err, txm.orm.Close())
+}
+
+// BacklogStats returns the number of Unstarted msgs and the creation time of the oldest one.
+// oldest is the zero time if there is no backlog.
+func (txm *Txm) BacklogStats() (count int, oldest time.Time, err error) {
+	n, oldest, err := txm.orm.GetUnstartedStats()
+	if err != nil {
+		return 0, time.Time{}, errors.Wrap(err, "unable to read unstarted msgs")
+	}
+	return int(n), oldest, nil
+}
+
+// recordBatchResult updates the health state after a batch attempt.
+// unstarted and oldest are only recorded if countOK, i.e. they were successfully read.
+func (txm *Txm) recordBatchResult(err error, unstarted int64, oldest time.Time, countOK bool) {
+	txm.healthMu.Lock()
+	defer txm.healthMu.Unlock()
+	if countOK {
+		txm.health.unstarted = unstarted
+		txm.health.oldestUnstarted = oldest
+	}
+	if err != nil {
+		txm.health.consecutiveFailures++
+		txm.health.lastErr = err
+		return
+	}
+	txm.health.consecutiveFailures = 0
+	txm.health.lastErr = nil
+	txm.health.lastSuccess = txm.clock.Now()
 }
 
-// Healthy service is healthy
+// Healthy returns an error if the txm is not started, if the last MaxConsecutiveFailures batches failed,
+// if the Unstarted backlog exceeds MaxUnstartedBacklog, if the oldest Unstarted msg is older than MaxUnstartedAge,
+// if msgs are pending and no batch has succeeded within StaleBatchTimeout, or if the sequence of a sender drifted by
+// more than MaxSequenceDrift.
 func (txm *Txm) Healthy() error {
+	if err := txm.starter.Healthy(); err != nil {
+		return err
+	}
+	txm.healthMu.RLock()
+	defer txm.healthMu.RUnlock()
+	cfg := txm.healthCfg
+	if cfg.MaxConsecutiveFailures > 0 && txm.health.consecutiveFailures >= cfg.MaxConsecutiveFailures {
+		return errors.Wrapf(txm.health.lastErr, "%d consecutive batches failed", txm.health.consecutiveFailures)
+	}
+	if cfg.MaxUnstartedBacklog > 0 && txm.health.unstarted > cfg.MaxUnstartedBacklog {
+		return errors.Errorf("unstarted backlog of %d msgs exceeds limit of %d", txm.health.unstarted, cfg.MaxUnstartedBacklog)
+	}
+	if cfg.MaxUnstartedAge > 0 && !txm.health.oldestUnstarted.IsZero() {
+		if age := txm.clock.Now().Sub(txm.health.oldestUnstarted); age > cfg.MaxUnstartedAge {
+			return errors.Errorf("oldest unstarted msg is %s old, exceeding limit of %s", age, cfg.MaxUnstartedAge)
+		}
+	}
+	if cfg.StaleBatchTimeout > 0 && txm.health.unstarted > 0 && !txm.paused.Load() {
+		if since := txm.clock.Now().Sub(txm.health.lastSuccess); since > cfg.StaleBatchTimeout {
+			return errors.Errorf("no successful batch for %s with %d msgs pending", since, txm.health.unstarted)
+		}
+	}
+	if cfg.MaxSequenceDrift > 0 {
+		if sender, drift := txm.maxSequenceDrift(); abs(drift) > cfg.MaxSequenceDrift {
+			return errors.Errorf("sequence of %s drifted by %d from the expected sequence, exceeding limit of %d", sender, drift, cfg.MaxSequenceDrift)
+		}
+	}
 	return nil
 }
 
-// Ready service is ready
+// Ready returns an error until Start has successfully subscribed to msg inserts.
 func (txm *Txm) Ready() error {
-	return nil
+	return txm.starter.Ready()
 }
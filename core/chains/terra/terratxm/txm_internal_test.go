@@ -1,19 +1,33 @@
 package terratxm
 
 import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"math/rand"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	tmservicetypes "github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	"github.com/cosmos/cosmos-sdk/std"
 	cosmostypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
 	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/smartcontractkit/sqlx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/tmhash"
 	tmtypes "github.com/tendermint/tendermint/proto/tendermint/types"
+	"github.com/terra-money/core/app/params"
 	wasmtypes "github.com/terra-money/core/x/wasm/types"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/guregu/null.v4"
@@ -23,7 +37,12 @@ import (
 	"github.com/smartcontractkit/chainlink/core/internal/testutils"
 	"github.com/smartcontractkit/chainlink/core/internal/testutils/pgtest"
 	"github.com/smartcontractkit/chainlink/core/internal/testutils/terratest"
+	"github.com/smartcontractkit/chainlink/core/logger"
 	"github.com/smartcontractkit/chainlink/core/services/keystore"
+	"github.com/smartcontractkit/chainlink/core/services/keystore/keys/terrakey"
+	ksmocks "github.com/smartcontractkit/chainlink/core/services/keystore/mocks"
+	"github.com/smartcontractkit/chainlink/core/services/pg"
+	pgmocks "github.com/smartcontractkit/chainlink/core/services/pg/mocks"
 	"github.com/smartcontractkit/chainlink/core/utils"
 
 	"github.com/smartcontractkit/chainlink-terra/pkg/terra"
@@ -43,6 +62,123 @@ func newReaderWriterMock(t *testing.T) *tcmocks.ReaderWriter {
 	return tc
 }
 
+// newTestTxm returns a txm on a chain of its own, so msgs left over from other tests aren't included,
+// along with the mock of the client it sends through.
+func newTestTxm(t *testing.T, db *sqlx.DB, ks keystore.Terra, lggr logger.Logger, cfg terra.Config, eb pg.EventBroadcaster, opts ...TxmOpt) (*Txm, *tcmocks.ReaderWriter) {
+	chainID := fmt.Sprintf("Chainlinktest-%d", rand.Int31n(999999))
+	terratest.MustInsertChain(t, db, &Chain{ID: chainID})
+	gpe := terraclient.NewMustGasPriceEstimator([]terraclient.GasPricesEstimator{
+		terraclient.NewFixedGasPriceEstimator(map[string]cosmostypes.DecCoin{
+			"uluna": cosmostypes.NewDecCoinFromDec("uluna", cosmostypes.MustNewDecFromStr("0.01")),
+		}),
+	}, lggr)
+	tc := newReaderWriterMock(t)
+	tcFn := func() (terraclient.ReaderWriter, error) { return tc, nil }
+	return NewTxm(db, tcFn, *gpe, chainID, cfg, ks, lggr, pgtest.NewQConfig(true), eb, opts...), tc
+}
+
+// fakeClock is a Clock whose time only moves when advanced, firing the waits and ticks due by then.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// fakeTimer is a pending After, or a ticker if period is set.
+type fakeTimer struct {
+	at     time.Time
+	period time.Duration
+	c      chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Now()}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	return c.add(d, 0).c
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Ticker {
+	return &fakeTicker{clock: c, timer: c.add(d, d)}
+}
+
+func (c *fakeClock) add(d, period time.Duration) *fakeTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := &fakeTimer{at: c.now.Add(d), period: period, c: make(chan time.Time, 1)}
+	c.timers = append(c.timers, timer)
+	c.fire()
+	return timer
+}
+
+// Advance moves the time forward by d, firing the waits and ticks due by then. Like time.Ticker, a ticker drops the
+// ticks its reader isn't ready for.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.fire()
+}
+
+func (c *fakeClock) fire() {
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.at.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		select {
+		case timer.c <- c.now:
+		default:
+		}
+		if timer.period > 0 {
+			for !timer.at.After(c.now) {
+				timer.at = timer.at.Add(timer.period)
+			}
+			pending = append(pending, timer)
+		}
+	}
+	c.timers = pending
+}
+
+// waiting returns the number of pending Afters, i.e. not counting tickers.
+func (c *fakeClock) waiting() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var n int
+	for _, timer := range c.timers {
+		if timer.period == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+type fakeTicker struct {
+	clock *fakeClock
+	timer *fakeTimer
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.timer.c }
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, timer := range t.clock.timers {
+		if timer == t.timer {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return
+		}
+	}
+}
+
 func TestTxm(t *testing.T) {
 	db := pgtest.NewSqlxDB(t)
 	lggr := testutils.LoggerAssertMaxLevel(t, zapcore.ErrorLevel)
@@ -76,7 +212,12 @@ func TestTxm(t *testing.T) {
 	t.Run("single msg", func(t *testing.T) {
 		tc := newReaderWriterMock(t)
 		tcFn := func() (terraclient.ReaderWriter, error) { return tc, nil }
-		txm := NewTxm(db, tcFn, *gpe, chainID, cfg, ks.Terra(), lggr, logCfg, nil)
+		eb := pgmocks.NewEventBroadcaster(t)
+		var payload string
+		eb.On("Notify", pg.ChannelTerraMsgConfirmed, mock.Anything).Run(func(args mock.Arguments) {
+			payload = args.String(1)
+		}).Return(nil).Once()
+		txm := NewTxm(db, tcFn, *gpe, chainID, cfg, ks.Terra(), lggr, logCfg, eb)
 
 		// Enqueue a single msg, then send it in a batch
 		id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
@@ -107,6 +248,13 @@ func TestTxm(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 1, len(completed))
 		assert.Equal(t, completed[0].State, Confirmed)
+		require.NotNil(t, completed[0].TxHash)
+		assert.Equal(t, txResp.TxHash, *completed[0].TxHash)
+
+		// The confirmation is published
+		var event MsgsConfirmedEvent
+		require.NoError(t, json.Unmarshal([]byte(payload), &event))
+		assert.Equal(t, MsgsConfirmedEvent{ChainID: chainID, TxHash: txResp.TxHash, MsgIDs: []int64{id1}}, event)
 	})
 
 	t.Run("two msgs different accounts", func(t *testing.T) {
@@ -164,6 +312,89 @@ func TestTxm(t *testing.T) {
 		assert.Equal(t, Confirmed, completed[1].State)
 	})
 
+	t.Run("multi signer", func(t *testing.T) {
+		tc := newReaderWriterMock(t)
+		tcFn := func() (terraclient.ReaderWriter, error) { return tc, nil }
+		txm := NewTxm(db, tcFn, *gpe, chainID, cfg, ks.Terra(), lggr, pgtest.NewQConfig(true), nil, WithMultiSignerBatching())
+
+		id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		id2, err := txm.Enqueue(contract2.String(), generateExecuteMsg(t, []byte(`2`), sender2, contract2))
+		require.NoError(t, err)
+
+		tc.On("Account", mock.Anything).Return(uint64(0), uint64(0), nil).Twice()
+		tc.On("BatchSimulateUnsigned", mock.Anything, mock.Anything).Return(func(msgs terraclient.SimMsgs, _ uint64) *terraclient.BatchSimResults {
+			return &terraclient.BatchSimResults{Succeeded: msgs}
+		}, nil).Twice()
+		tc.On("SimulateUnsigned", mock.Anything, mock.Anything).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+			GasUsed: 1_000_000,
+		}}, nil).Twice()
+		tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+			Header: tmtypes.Header{Height: 1},
+		}}, nil).Once()
+		// A single tx is broadcast for both senders, signed without CreateAndSign
+		var txHash string
+		tc.On("Broadcast", mock.Anything, mock.Anything).Return(func(txBytes []byte, _ txtypes.BroadcastMode) *txtypes.BroadcastTxResponse {
+			txHash = strings.ToUpper(hex.EncodeToString(tmhash.Sum(txBytes)))
+			return &txtypes.BroadcastTxResponse{TxResponse: &cosmostypes.TxResponse{TxHash: txHash}}
+		}, nil).Once()
+		tc.On("Tx", mock.Anything).Return(func(hash string) *txtypes.GetTxResponse {
+			return &txtypes.GetTxResponse{Tx: &txtypes.Tx{}, TxResponse: &cosmostypes.TxResponse{TxHash: hash}}
+		}, nil).Once()
+		txm.sendMsgBatch(testutils.Context(t))
+
+		completed, err := txm.orm.GetMsgs(id1, id2)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(completed))
+		for _, msg := range completed {
+			assert.Equal(t, Confirmed, msg.State)
+			require.NotNil(t, msg.TxHash)
+			assert.Equal(t, txHash, *msg.TxHash)
+		}
+	})
+
+	t.Run("multi signer falls back to a tx per sender", func(t *testing.T) {
+		tc := newReaderWriterMock(t)
+		tcFn := func() (terraclient.ReaderWriter, error) { return tc, nil }
+		txm := NewTxm(db, tcFn, *gpe, chainID, cfg, ks.Terra(), lggr, pgtest.NewQConfig(true), nil, WithMultiSignerBatching())
+
+		id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		id2, err := txm.Enqueue(contract2.String(), generateExecuteMsg(t, []byte(`2`), sender2, contract2))
+		require.NoError(t, err)
+
+		tc.On("Account", mock.Anything).Return(uint64(0), uint64(0), nil).Twice()
+		tc.On("BatchSimulateUnsigned", mock.Anything, mock.Anything).Return(func(msgs terraclient.SimMsgs, _ uint64) *terraclient.BatchSimResults {
+			return &terraclient.BatchSimResults{Succeeded: msgs}
+		}, nil).Twice()
+		tc.On("SimulateUnsigned", mock.Anything, mock.Anything).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+			GasUsed: 1_000_000,
+		}}, nil).Twice()
+		tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+			Header: tmtypes.Header{Height: 1},
+		}}, nil).Times(3)
+		// The multi-signer tx is rejected
+		tc.On("Broadcast", mock.Anything, mock.Anything).Return(nil, errors.New("signature verification failed")).Once()
+		tc.On("CreateAndSign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]byte{0x01}, nil).Once()
+		tc.On("CreateAndSign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]byte{0x02}, nil).Once()
+		tc.On("Broadcast", mock.Anything, mock.Anything).Return(func(txBytes []byte, _ txtypes.BroadcastMode) *txtypes.BroadcastTxResponse {
+			return &txtypes.BroadcastTxResponse{TxResponse: &cosmostypes.TxResponse{TxHash: strings.ToUpper(hex.EncodeToString(tmhash.Sum(txBytes)))}}
+		}, nil).Twice()
+		tc.On("Tx", mock.Anything).Return(func(hash string) *txtypes.GetTxResponse {
+			return &txtypes.GetTxResponse{Tx: &txtypes.Tx{}, TxResponse: &cosmostypes.TxResponse{TxHash: hash}}
+		}, nil).Twice()
+		txm.sendMsgBatch(testutils.Context(t))
+
+		completed, err := txm.orm.GetMsgs(id1, id2)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(completed))
+		require.NotNil(t, completed[0].TxHash)
+		require.NotNil(t, completed[1].TxHash)
+		assert.Equal(t, Confirmed, completed[0].State)
+		assert.Equal(t, Confirmed, completed[1].State)
+		assert.NotEqual(t, *completed[0].TxHash, *completed[1].TxHash)
+	})
+
 	t.Run("two msgs different contracts", func(t *testing.T) {
 		tc := newReaderWriterMock(t)
 		tcFn := func() (terraclient.ReaderWriter, error) { return tc, nil }
@@ -235,14 +466,303 @@ func TestTxm(t *testing.T) {
 		i, err := txm.orm.InsertMsg("blah", "", []byte{0x01})
 		require.NoError(t, err)
 		txh := "0x123"
-		require.NoError(t, txm.orm.UpdateMsgs([]int64{i}, Started, &txh))
-		require.NoError(t, txm.orm.UpdateMsgs([]int64{i}, Broadcasted, &txh))
+		mustUpdateMsgs(t, txm.orm, []int64{i}, Unstarted, Started, &txh)
+		mustUpdateMsgs(t, txm.orm, []int64{i}, Started, Broadcasted, &txh)
 		err = txm.confirmTx(testutils.Context(t), tc, txh, []int64{i}, 2, 1*time.Millisecond)
 		require.NoError(t, err)
 		m, err := txm.orm.GetMsgs(i)
 		require.NoError(t, err)
 		require.Equal(t, 1, len(m))
-		assert.Equal(t, Errored, m[0].State)
+		assert.Equal(t, TimedOut, m[0].State)
+
+		// The tx landed after the timeout period
+		tc.On("TxsEvents", []string{"tx.hash=" + txh}, mock.Anything).Return(&txtypes.GetTxsEventResponse{
+			TxResponses: []*cosmostypes.TxResponse{{TxHash: txh}},
+		}, nil).Once()
+		require.NoError(t, txm.reconcileTimedOutMsgs())
+		m, err = txm.orm.GetMsgs(i)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(m))
+		assert.Equal(t, Confirmed, m[0].State)
+	})
+
+	t.Run("confirmation depth", func(t *testing.T) {
+		cfg := terra.NewConfig(ChainCfg{}, lggr)
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil, WithConfirmationDepth(3))
+		broadcast := func(txh string) int64 {
+			i, err := txm.orm.InsertMsg("blah", "", []byte{0x01})
+			require.NoError(t, err)
+			mustUpdateMsgs(t, txm.orm, []int64{i}, Unstarted, Started, &txh)
+			mustUpdateMsgs(t, txm.orm, []int64{i}, Started, Broadcasted, &txh)
+			return i
+		}
+		assertState := func(id int64, state State) {
+			m, err := txm.orm.GetMsgs(id)
+			require.NoError(t, err)
+			require.Len(t, m, 1)
+			assert.Equal(t, state, m[0].State)
+		}
+		// head sets the latest block of the fake chain, on which the tx with hash txh is in block 10
+		head := func(height int64, txh string) {
+			tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+				Header: tmtypes.Header{Height: height},
+			}}, nil).Once()
+			tc.On("Tx", txh).Return(&txtypes.GetTxResponse{
+				Tx:         &txtypes.Tx{},
+				TxResponse: &cosmostypes.TxResponse{TxHash: txh, Height: 10},
+			}, nil).Once()
+		}
+
+		txh := "0x456"
+		i := broadcast(txh)
+		tc.On("Tx", txh).Return(&txtypes.GetTxResponse{
+			Tx:         &txtypes.Tx{},
+			TxResponse: &cosmostypes.TxResponse{TxHash: txh, Height: 10},
+		}, nil).Once()
+		require.NoError(t, txm.confirmTx(testutils.Context(t), tc, txh, []int64{i}, 2, 1*time.Millisecond))
+		assertState(i, Confirming)
+
+		for _, height := range []int64{10, 11, 12} {
+			head(height, txh)
+			require.NoError(t, txm.reconcileConfirmingMsgs())
+			assertState(i, Confirming)
+		}
+		head(13, txh)
+		require.NoError(t, txm.reconcileConfirmingMsgs())
+		assertState(i, Confirmed)
+
+		// A tx reorged out before it's deep enough is timed out, to be requeued once verified absent
+		txh = "0x789"
+		i = broadcast(txh)
+		tc.On("Tx", txh).Return(&txtypes.GetTxResponse{
+			Tx:         &txtypes.Tx{},
+			TxResponse: &cosmostypes.TxResponse{TxHash: txh, Height: 10},
+		}, nil).Once()
+		require.NoError(t, txm.confirmTx(testutils.Context(t), tc, txh, []int64{i}, 2, 1*time.Millisecond))
+		assertState(i, Confirming)
+		tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+			Header: tmtypes.Header{Height: 11},
+		}}, nil).Once()
+		tc.On("Tx", txh).Return(nil, errors.New("tx not found")).Once()
+		require.NoError(t, txm.reconcileConfirmingMsgs())
+		assertState(i, TimedOut)
+	})
+
+	t.Run("retries", func(t *testing.T) {
+		cfg := terra.NewConfig(ChainCfg{}, lggr)
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil, WithMaxMsgRetries(1))
+		assertMsg := func(id int64, state State, retryCount int64, lastError string) {
+			m, err := txm.orm.GetMsg(id)
+			require.NoError(t, err)
+			assert.Equal(t, state, m.State)
+			r, err := txm.GetMsgRetries(id)
+			require.NoError(t, err)
+			assert.Equal(t, retryCount, r.RetryCount)
+			assert.Equal(t, lastError, r.LastError.String)
+		}
+
+		// Rejected by the node
+		i, err := txm.orm.InsertMsg("blah", "", []byte{0x01})
+		require.NoError(t, err)
+		assertMsg(i, Unstarted, 0, "")
+		mustUpdateMsgs(t, txm.orm, []int64{i}, Unstarted, Started, nil)
+		tc.On("Broadcast", mock.Anything, mock.Anything).Return(nil, errors.New("mempool is full")).Twice()
+		require.Error(t, txm.broadcastAndConfirm(testutils.Context(t), tc, []byte{0x01}, nil, []int64{i}))
+		assertMsg(i, Started, 1, "mempool is full")
+		require.Error(t, txm.broadcastAndConfirm(testutils.Context(t), tc, []byte{0x02}, nil, []int64{i}))
+		assertMsg(i, Errored, 2, "mempool is full")
+		m, err := txm.orm.GetMsgsErroredAfter(reasonMaxRetries, 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, []int64{i}, m.GetIDs())
+
+		// Timed out without landing
+		j, err := txm.orm.InsertMsg("blah", "", []byte{0x01})
+		require.NoError(t, err)
+		for attempt, txh := range []string{"0xabc", "0xdef"} {
+			mustUpdateMsgs(t, txm.orm, []int64{j}, Unstarted, Started, &txh)
+			mustUpdateMsgs(t, txm.orm, []int64{j}, Started, Broadcasted, &txh)
+			tc.On("Tx", txh).Return(nil, errors.New("not found")).Twice()
+			require.NoError(t, txm.confirmTx(testutils.Context(t), tc, txh, []int64{j}, 2, 1*time.Millisecond))
+			assertMsg(j, TimedOut, int64(attempt+1), fmt.Sprintf("tx %s not confirmed before timing out", txh))
+			tc.On("TxsEvents", []string{"tx.hash=" + txh}, mock.Anything).Return(&txtypes.GetTxsEventResponse{}, nil).Once()
+			require.NoError(t, txm.reconcileTimedOutMsgs())
+		}
+		assertMsg(j, Errored, 2, "tx 0xdef not confirmed before timing out")
+	})
+
+	t.Run("requeue", func(t *testing.T) {
+		cfg := terra.NewConfig(ChainCfg{}, lggr)
+
+		txm, _ := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil)
+
+		var errored []int64
+		for i := 0; i < 2; i++ {
+			id, err := txm.orm.InsertMsg(fmt.Sprintf("requeue-%d", i), "", []byte{0x01})
+			require.NoError(t, err)
+			mustUpdateMsgs(t, txm.orm, []int64{id}, Unstarted, Started, nil)
+			require.NoError(t, txm.orm.RecordMsgsFailure([]int64{id}, "insufficient funds"))
+			mustUpdateMsgsErrored(t, txm.orm, []int64{id}, Started, reasonMaxRetries)
+			errored = append(errored, id)
+		}
+		confirmed, err := txm.orm.InsertMsg("blah", "", []byte{0x02})
+		require.NoError(t, err)
+		txHash := "0x123"
+		mustUpdateMsgs(t, txm.orm, []int64{confirmed}, Unstarted, Started, &txHash)
+		mustUpdateMsgs(t, txm.orm, []int64{confirmed}, Started, Broadcasted, &txHash)
+		mustUpdateMsgs(t, txm.orm, []int64{confirmed}, Broadcasted, Confirmed, &txHash)
+
+		n, err := txm.Requeue(append(errored, confirmed, confirmed+1000))
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		for _, id := range errored {
+			m, err := txm.orm.GetMsg(id)
+			require.NoError(t, err)
+			assert.Equal(t, Unstarted, m.State)
+			r, err := txm.GetMsgRetries(id)
+			require.NoError(t, err)
+			assert.Zero(t, r.RetryCount)
+			assert.False(t, r.LastError.Valid)
+		}
+		m, err := txm.orm.GetMsg(confirmed)
+		require.NoError(t, err)
+		assert.Equal(t, Confirmed, m.State)
+
+		// already requeued
+		n, err = txm.Requeue(errored)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+
+		// A contract keeps a single Unstarted msg: superseded msgs are left errored, older Unstarted ones cancelled
+		var superseded []int64
+		for i := 0; i < 3; i++ {
+			id, err := txm.orm.InsertMsg("superseded", "", []byte{0x03})
+			require.NoError(t, err)
+			_, err = txm.Cancel(id)
+			require.NoError(t, err)
+			superseded = append(superseded, id)
+		}
+		newer, err := txm.orm.InsertMsg("superseded", "", []byte{0x04})
+		require.NoError(t, err)
+		n, err = txm.Requeue(superseded)
+		require.NoError(t, err)
+		assert.Zero(t, n, "superseded by a newer unstarted msg")
+		_, err = txm.Cancel(newer)
+		require.NoError(t, err)
+		n, err = txm.Requeue(superseded[:2])
+		require.NoError(t, err)
+		assert.Equal(t, 1, n, "only the newest of the requeued msgs")
+		n, err = txm.Requeue(superseded[2:])
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		for id, state := range map[int64]State{superseded[0]: Errored, superseded[1]: Errored, superseded[2]: Unstarted, newer: Errored} {
+			m, err := txm.orm.GetMsg(id)
+			require.NoError(t, err)
+			assert.Equal(t, state, m.State, "msg %d", id)
+		}
+	})
+
+	t.Run("requeue expired", func(t *testing.T) {
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil)
+
+		// Enqueued and errored long enough ago for the msg to be past the TxMsgTimeout
+		txm.orm.now = func() time.Time { return time.Now().Add(-2 * cfg.TxMsgTimeout()) }
+		id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		mustUpdateMsgsErrored(t, txm.orm, []int64{id1}, Unstarted, "insufficient funds")
+		txm.orm.now = time.Now
+
+		n, err := txm.Requeue([]int64{id1})
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+
+		// Sent like a newly enqueued msg, rather than expired
+		tc.On("Account", mock.Anything).Return(uint64(0), uint64(0), nil)
+		tc.On("BatchSimulateUnsigned", mock.Anything, mock.Anything).Return(&terraclient.BatchSimResults{
+			Succeeded: terraclient.SimMsgs{{ID: id1, Msg: &wasmtypes.MsgExecuteContract{
+				Sender:     sender1.String(),
+				ExecuteMsg: []byte(`1`),
+			}}},
+		}, nil).Once()
+		tc.On("SimulateUnsigned", mock.Anything, mock.Anything).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+			GasUsed: 1_000_000,
+		}}, nil).Once()
+		tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+			Header: tmtypes.Header{Height: 1},
+		}}, nil).Once()
+		tc.On("CreateAndSign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]byte{0x01}, nil).Once()
+		txResp := &cosmostypes.TxResponse{TxHash: "4BF5122F344554C53BDE2EBB8CD2B7E3D1600AD631C385A5D7CCE23C7785459A"}
+		tc.On("Broadcast", mock.Anything, mock.Anything).Return(&txtypes.BroadcastTxResponse{TxResponse: txResp}, nil).Once()
+		tc.On("Tx", mock.Anything).Return(&txtypes.GetTxResponse{Tx: &txtypes.Tx{}, TxResponse: txResp}, nil).Once()
+		require.NoError(t, txm.sendMsgBatch(testutils.Context(t)))
+
+		m, err := txm.orm.GetMsg(id1)
+		require.NoError(t, err)
+		assert.Equal(t, Confirmed, m.State)
+		require.NotNil(t, m.TxHash)
+		assert.Equal(t, txResp.TxHash, *m.TxHash)
+	})
+
+	t.Run("dropped tx", func(t *testing.T) {
+		blockRate, err := relayutils.NewDuration(2 * time.Millisecond)
+		require.NoError(t, err)
+		pollPeriod, err := relayutils.NewDuration(1 * time.Millisecond)
+		require.NoError(t, err)
+		cfgFastTimeout := terra.NewConfig(ChainCfg{
+			BlockRate:            &blockRate,
+			BlocksUntilTxTimeout: null.IntFrom(1),
+			ConfirmPollPeriod:    &pollPeriod,
+		}, lggr)
+
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, cfgFastTimeout, nil)
+		maxPolls, _ := txm.confirmPollConfig()
+		require.Equal(t, 2, maxPolls)
+
+		id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		tc.On("Account", mock.Anything).Return(uint64(0), uint64(0), nil).Once()
+		tc.On("BatchSimulateUnsigned", mock.Anything, mock.Anything).Return(&terraclient.BatchSimResults{
+			Succeeded: terraclient.SimMsgs{{ID: id1, Msg: &wasmtypes.MsgExecuteContract{
+				Sender:     sender1.String(),
+				ExecuteMsg: []byte(`1`),
+			}}},
+		}, nil).Once()
+		tc.On("SimulateUnsigned", mock.Anything, mock.Anything).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+			GasUsed: 1_000_000,
+		}}, nil).Once()
+		tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+			Header: tmtypes.Header{Height: 1},
+		}}, nil).Once()
+		tc.On("CreateAndSign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]byte{0x01}, nil).Once()
+		txHash := "4BF5122F344554C53BDE2EBB8CD2B7E3D1600AD631C385A5D7CCE23C7785459A"
+		txResp := &cosmostypes.TxResponse{TxHash: txHash}
+		tc.On("Broadcast", mock.Anything, mock.Anything).Return(&txtypes.BroadcastTxResponse{TxResponse: txResp}, nil).Once()
+		// The tx is dropped from the mempool, so it's never found
+		tc.On("Tx", txHash).Return(nil, errors.New("not found")).Times(maxPolls)
+		txm.sendMsgBatch(testutils.Context(t))
+
+		m, err := txm.orm.GetMsgs(id1)
+		require.NoError(t, err)
+		require.Len(t, m, 1)
+		assert.Equal(t, TimedOut, m[0].State)
+		require.NotNil(t, m[0].TxHash)
+		assert.Equal(t, txHash, *m[0].TxHash)
+
+		// Unable to verify the tx is absent, so the msg is neither requeued nor broadcast again
+		tc.On("TxsEvents", []string{"tx.hash=" + txHash}, mock.Anything).Return(nil, errors.New("unavailable")).Once()
+		txm.sendMsgBatch(testutils.Context(t))
+		m, err = txm.orm.GetMsgs(id1)
+		require.NoError(t, err)
+		assert.Equal(t, TimedOut, m[0].State)
+		tc.AssertNumberOfCalls(t, "Broadcast", 1)
+
+		// Once the tx is verified to be absent the msg is requeued to be sent again
+		tc.On("TxsEvents", []string{"tx.hash=" + txHash}, mock.Anything).Return(&txtypes.GetTxsEventResponse{}, nil).Once()
+		require.NoError(t, txm.reconcileTimedOutMsgs())
+		m, err = txm.orm.GetMsgs(id1)
+		require.NoError(t, err)
+		assert.Equal(t, Unstarted, m[0].State)
+		assert.Nil(t, m[0].TxHash)
+		tc.AssertNumberOfCalls(t, "Broadcast", 1)
 	})
 
 	t.Run("confirm any unconfirmed", func(t *testing.T) {
@@ -270,17 +790,17 @@ func TestTxm(t *testing.T) {
 		require.NoError(t, err)
 		id3, err := txm.orm.InsertMsg("blah", "", []byte{0x03})
 		require.NoError(t, err)
-		err = txm.orm.UpdateMsgs([]int64{id1}, Started, &txHash1)
+		_, err = txm.orm.UpdateMsgs([]int64{id1}, []State{Unstarted}, Started, &txHash1)
 		require.NoError(t, err)
-		err = txm.orm.UpdateMsgs([]int64{id2}, Started, &txHash2)
+		_, err = txm.orm.UpdateMsgs([]int64{id2}, []State{Unstarted}, Started, &txHash2)
 		require.NoError(t, err)
-		err = txm.orm.UpdateMsgs([]int64{id3}, Started, &txHash3)
+		_, err = txm.orm.UpdateMsgs([]int64{id3}, []State{Unstarted}, Started, &txHash3)
 		require.NoError(t, err)
-		err = txm.orm.UpdateMsgs([]int64{id1}, Broadcasted, &txHash1)
+		_, err = txm.orm.UpdateMsgs([]int64{id1}, []State{Started}, Broadcasted, &txHash1)
 		require.NoError(t, err)
-		err = txm.orm.UpdateMsgs([]int64{id2}, Broadcasted, &txHash2)
+		_, err = txm.orm.UpdateMsgs([]int64{id2}, []State{Started}, Broadcasted, &txHash2)
 		require.NoError(t, err)
-		err = txm.orm.UpdateMsgs([]int64{id3}, Broadcasted, &txHash3)
+		_, err = txm.orm.UpdateMsgs([]int64{id3}, []State{Started}, Broadcasted, &txHash3)
 		require.NoError(t, err)
 
 		// Confirm them as in a restart while confirming scenario
@@ -350,7 +870,7 @@ func TestTxm(t *testing.T) {
 		// Leftover started is processed
 		msg1 := generateExecuteMsg(t, []byte{0x03}, sender1, contract)
 		id1 := mustInsertMsg(t, txm, contract.String(), msg1)
-		require.NoError(t, txm.orm.UpdateMsgs([]int64{id1}, Started, nil))
+		mustUpdateMsgs(t, txm.orm, []int64{id1}, Unstarted, Started, nil)
 		msgs := terraclient.SimMsgs{{ID: id1, Msg: &wasmtypes.MsgExecuteContract{
 			Sender:     sender1.String(),
 			ExecuteMsg: []byte{0x03},
@@ -368,7 +888,7 @@ func TestTxm(t *testing.T) {
 		msg2 := generateExecuteMsg(t, []byte{0x04}, sender1, contract)
 		msg3 := generateExecuteMsg(t, []byte{0x05}, sender1, contract)
 		id2 := mustInsertMsg(t, txm, contract.String(), msg2)
-		require.NoError(t, txm.orm.UpdateMsgs([]int64{id2}, Started, nil))
+		mustUpdateMsgs(t, txm.orm, []int64{id2}, Unstarted, Started, nil)
 		time.Sleep(time.Millisecond) // ensure != CreatedAt
 		id3 := mustInsertMsg(t, txm, contract.String(), msg3)
 		msgs = terraclient.SimMsgs{{ID: id2, Msg: &wasmtypes.MsgExecuteContract{
@@ -390,12 +910,2095 @@ func TestTxm(t *testing.T) {
 		assert.Equal(t, Confirmed, ms[0].State)
 		assert.Equal(t, Confirmed, ms[1].State)
 	})
-}
 
-func mustInsertMsg(t *testing.T, txm *Txm, contractID string, msg cosmostypes.Msg) int64 {
-	typeURL, raw, err := txm.marshalMsg(msg)
-	require.NoError(t, err)
-	id, err := txm.orm.InsertMsg(contractID, typeURL, raw)
-	require.NoError(t, err)
-	return id
+	t.Run("missing key", func(t *testing.T) {
+		k3, err := ks.Terra().Create()
+		require.NoError(t, err)
+		sender3, err := cosmostypes.AccAddressFromBech32(k3.PublicKeyStr())
+		require.NoError(t, err)
+		_, err = ks.Terra().Delete(k3.ID())
+		require.NoError(t, err)
+
+		txm, _ := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil)
+
+		id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender3, contract))
+		require.NoError(t, err)
+
+		// Msgs without a key are errored instead of retried every poll
+		txm.sendMsgBatch(testutils.Context(t))
+		ms, err := txm.orm.GetMsgs(id1)
+		require.NoError(t, err)
+		require.Len(t, ms, 1)
+		assert.Equal(t, Errored, ms[0].State)
+		unstarted, err := txm.orm.GetMsgsState(Unstarted, 10)
+		require.NoError(t, err)
+		require.Empty(t, unstarted)
+
+		// Can't re-enqueue until the key is back
+		_, err = txm.ReenqueueMissingKeyMsgs(sender3.String())
+		require.Error(t, err)
+
+		require.NoError(t, ks.Terra().Add(k3))
+		ids, err := txm.ReenqueueMissingKeyMsgs(sender3.String())
+		require.NoError(t, err)
+		require.Equal(t, []int64{id1}, ids)
+		ms, err = txm.orm.GetMsgs(id1)
+		require.NoError(t, err)
+		require.Len(t, ms, 1)
+		assert.Equal(t, Unstarted, ms[0].State)
+		assert.Equal(t, contract.String(), ms[0].ContractID)
+
+		// Only re-enqueued once
+		ids, err = txm.ReenqueueMissingKeyMsgs(sender3.String())
+		require.NoError(t, err)
+		require.Empty(t, ids)
+	})
+
+	t.Run("missing key imported after the timeout", func(t *testing.T) {
+		k4, err := ks.Terra().Create()
+		require.NoError(t, err)
+		sender4, err := cosmostypes.AccAddressFromBech32(k4.PublicKeyStr())
+		require.NoError(t, err)
+		_, err = ks.Terra().Delete(k4.ID())
+		require.NoError(t, err)
+
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil)
+
+		// Errored for the missing key long enough ago for the msgs to be past the TxMsgTimeout
+		txm.orm.now = func() time.Time { return time.Now().Add(-2 * cfg.TxMsgTimeout()) }
+		var errored []int64
+		for i := 0; i < 2; i++ {
+			id, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte{byte(i)}, sender4, contract))
+			require.NoError(t, err)
+			mustUpdateMsgsErrored(t, txm.orm, []int64{id}, Unstarted, reasonNoKey)
+			errored = append(errored, id)
+		}
+		txm.orm.now = time.Now
+
+		// Only the newest msg of the contract is re-enqueued, and it's sent rather than expired
+		require.NoError(t, ks.Terra().Add(k4))
+		ids, err := txm.ReenqueueMissingKeyMsgs(sender4.String())
+		require.NoError(t, err)
+		require.Equal(t, errored[1:], ids)
+
+		tc.On("Account", mock.Anything).Return(uint64(0), uint64(0), nil)
+		tc.On("BatchSimulateUnsigned", mock.Anything, mock.Anything).Return(&terraclient.BatchSimResults{
+			Succeeded: terraclient.SimMsgs{{ID: errored[1], Msg: &wasmtypes.MsgExecuteContract{
+				Sender:     sender4.String(),
+				ExecuteMsg: []byte{1},
+			}}},
+		}, nil).Once()
+		tc.On("SimulateUnsigned", mock.Anything, mock.Anything).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+			GasUsed: 1_000_000,
+		}}, nil).Once()
+		tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+			Header: tmtypes.Header{Height: 1},
+		}}, nil).Once()
+		tc.On("CreateAndSign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]byte{0x01}, nil).Once()
+		txResp := &cosmostypes.TxResponse{TxHash: "4BF5122F344554C53BDE2EBB8CD2B7E3D1600AD631C385A5D7CCE23C7785459C"}
+		tc.On("Broadcast", mock.Anything, mock.Anything).Return(&txtypes.BroadcastTxResponse{TxResponse: txResp}, nil).Once()
+		tc.On("Tx", mock.Anything).Return(&txtypes.GetTxResponse{Tx: &txtypes.Tx{}, TxResponse: txResp}, nil).Once()
+		require.NoError(t, txm.sendMsgBatch(testutils.Context(t)))
+
+		ms, err := txm.orm.GetMsgs(errored...)
+		require.NoError(t, err)
+		require.Len(t, ms, 2)
+		assert.Equal(t, Errored, ms[0].State)
+		assert.Equal(t, Confirmed, ms[1].State)
+	})
+
+	t.Run("keystore errors", func(t *testing.T) {
+		for _, tt := range []struct {
+			name     string
+			err      error
+			expState State
+		}{
+			// Retried once the keystore is unlocked
+			{"locked", keystore.ErrLocked, Started},
+			{"not found", keystore.KeyNotFoundError{ID: sender1.String(), KeyType: "Terra"}, Errored},
+		} {
+			tt := tt
+			t.Run(tt.name, func(t *testing.T) {
+				terraKs := ksmocks.NewTerra(t)
+				terraKs.On("Get", sender1.String()).Return(terrakey.Key{}, tt.err).Once()
+
+				txm, _ := newTestTxm(t, db, terraKs, lggr, cfg, nil)
+
+				id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+				require.NoError(t, err)
+				err = txm.sendMsgBatch(testutils.Context(t))
+				if tt.expState == Started {
+					require.ErrorIs(t, err, keystore.ErrLocked)
+					_, backingOff := txm.nextBatchDelay(err)
+					assert.True(t, backingOff)
+				} else {
+					require.NoError(t, err)
+				}
+
+				ms, err := txm.orm.GetMsgs(id1)
+				require.NoError(t, err)
+				require.Len(t, ms, 1)
+				assert.Equal(t, tt.expState, ms[0].State)
+			})
+		}
+	})
+
+	t.Run("enqueue unique", func(t *testing.T) {
+		tcFn := func() (terraclient.ReaderWriter, error) { return newReaderWriterMock(t), nil }
+		txm := NewTxm(db, tcFn, *gpe, chainID, cfg, ks.Terra(), lggr, pgtest.NewQConfig(true), nil)
+
+		msg1 := generateExecuteMsg(t, []byte(`1`), sender1, contract)
+		id1, err := txm.EnqueueUnique(contract.String(), msg1, "key-1")
+		require.NoError(t, err)
+
+		// Retrying with the same key and payload returns the existing msg without cancelling it.
+		id2, err := txm.EnqueueUnique(contract.String(), msg1, "key-1")
+		require.NoError(t, err)
+		assert.Equal(t, id1, id2)
+		ms, err := txm.orm.GetMsgs(id1)
+		require.NoError(t, err)
+		require.Len(t, ms, 1)
+		assert.Equal(t, Unstarted, ms[0].State)
+
+		// Reusing the key for a different payload is rejected.
+		msg2 := generateExecuteMsg(t, []byte(`2`), sender1, contract)
+		_, err = txm.EnqueueUnique(contract.String(), msg2, "key-1")
+		require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+		id3, err := txm.EnqueueUnique(contract.String(), msg2, "key-2")
+		require.NoError(t, err)
+		assert.NotEqual(t, id1, id3)
+
+		_, err = txm.EnqueueUnique(contract.String(), msg2, "")
+		require.Error(t, err)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		txm, _ := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil)
+
+		id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		cancelled, err := txm.Cancel(id1)
+		require.NoError(t, err)
+		assert.True(t, cancelled)
+		ms, err := txm.orm.GetMsgsErroredAfter(reasonCancelled, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, ms, 1)
+		assert.Equal(t, id1, ms[0].ID)
+
+		// Already cancelled
+		cancelled, err = txm.Cancel(id1)
+		require.NoError(t, err)
+		assert.False(t, cancelled)
+
+		// Already started
+		id2, err := txm.Enqueue(contract2.String(), generateExecuteMsg(t, []byte(`2`), sender1, contract2))
+		require.NoError(t, err)
+		mustUpdateMsgs(t, txm.orm, []int64{id2}, Unstarted, Started, nil)
+		cancelled, err = txm.Cancel(id2)
+		require.NoError(t, err)
+		assert.False(t, cancelled)
+
+		// Unknown
+		cancelled, err = txm.Cancel(-1)
+		require.NoError(t, err)
+		assert.False(t, cancelled)
+	})
+
+	t.Run("cancel races batch", func(t *testing.T) {
+		raceCfg := terra.NewConfig(ChainCfg{MaxMsgsPerBatch: null.IntFrom(20)}, lggr)
+
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, raceCfg, nil)
+		// Started msgs stay Started, so they can be told apart from cancelled ones
+		tc.On("Account", mock.Anything).Return(uint64(0), uint64(0), errors.New("rpc unavailable")).Maybe()
+
+		var ids []int64
+		for i := 0; i < 20; i++ {
+			// Distinct contracts, as Enqueue replaces the Unstarted msg of a contract
+			id := mustInsertMsg(t, txm, fmt.Sprintf("contract-%d", i), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+			ids = append(ids, id)
+		}
+
+		cancelled := make([]bool, len(ids))
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, id := range ids {
+				var err error
+				cancelled[i], err = txm.Cancel(id)
+				assert.NoError(t, err)
+			}
+		}()
+		err := txm.processMsgBatch(testutils.Context(t))
+		wg.Wait()
+		if err != nil {
+			// The batch itself must not conflict with the cancels
+			require.ErrorContains(t, err, "rpc unavailable")
+		}
+
+		ms, err := txm.orm.GetMsgs(ids...)
+		require.NoError(t, err)
+		require.Len(t, ms, len(ids))
+		states := make(map[int64]State)
+		for _, m := range ms {
+			states[m.ID] = m.State
+		}
+		for i, id := range ids {
+			if cancelled[i] {
+				assert.Equal(t, Errored, states[id], "msg %d", id)
+			} else {
+				assert.Equal(t, Started, states[id], "msg %d", id)
+			}
+		}
+	})
+
+	t.Run("estimate batch fee", func(t *testing.T) {
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil)
+
+		fees, err := txm.EstimateBatchFee()
+		require.NoError(t, err)
+		assert.Empty(t, fees)
+
+		id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		tc.On("Account", mock.Anything).Return(uint64(0), uint64(0), nil).Once()
+		tc.On("BatchSimulateUnsigned", mock.Anything, mock.Anything).Return(&terraclient.BatchSimResults{
+			Failed: nil,
+			Succeeded: terraclient.SimMsgs{{ID: id1, Msg: &wasmtypes.MsgExecuteContract{
+				Sender:     sender1.String(),
+				ExecuteMsg: []byte(`1`),
+			}}},
+		}, nil).Once()
+		tc.On("SimulateUnsigned", mock.Anything, mock.Anything).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+			GasUsed: 1_000_000,
+		}}, nil).Once()
+
+		fees, err = txm.EstimateBatchFee()
+		require.NoError(t, err)
+		require.Len(t, fees, 1)
+		gasLimit := int64(1_000_000 * cfg.GasLimitMultiplier())
+		expected := cosmostypes.NewCoins(cosmostypes.NewCoin("uluna", cosmostypes.MustNewDecFromStr("0.01").MulInt64(gasLimit).Ceil().RoundInt()))
+		assert.Equal(t, expected, fees[sender1.String()])
+
+		// Estimating doesn't change any state
+		ms, err := txm.orm.GetMsgs(id1)
+		require.NoError(t, err)
+		require.Len(t, ms, 1)
+		assert.Equal(t, Unstarted, ms[0].State)
+	})
+
+	t.Run("restart after broadcast before update", func(t *testing.T) {
+		pollPeriod, err := relayutils.NewDuration(1 * time.Millisecond)
+		require.NoError(t, err)
+		cfgFastPoll := terra.NewConfig(ChainCfg{ConfirmPollPeriod: &pollPeriod}, lggr)
+		// No CreateAndSign or Broadcast expected
+		restarted, tc := newTestTxm(t, db, ks.Terra(), lggr, cfgFastPoll, nil)
+		crashed := NewTxm(db, nil, *gpe, restarted.orm.chainID, cfgFastPoll, ks.Terra(), lggr, pgtest.NewQConfig(true), nil)
+
+		// The state left by a crash right after broadcasting: msgs Started, with the hash of their tx pending
+		// Each msg is started before the next is enqueued, which would cancel it while Unstarted
+		landed, err := crashed.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		mustUpdateMsgs(t, crashed.orm, []int64{landed}, Unstarted, Started, nil)
+		inMempool, err := crashed.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`2`), sender2, contract))
+		require.NoError(t, err)
+		mustUpdateMsgs(t, crashed.orm, []int64{inMempool}, Unstarted, Started, nil)
+		landedHash, inMempoolHash := "LANDED", "INMEMPOOL"
+		require.NoError(t, crashed.orm.SetPendingBroadcast([]int64{landed}, landedHash))
+		require.NoError(t, crashed.orm.SetPendingBroadcast([]int64{inMempool}, inMempoolHash))
+
+		tc.On("TxsEvents", []string{"tx.hash=" + landedHash}, mock.Anything).Return(&txtypes.GetTxsEventResponse{
+			TxResponses: []*cosmostypes.TxResponse{{TxHash: landedHash}},
+		}, nil).Once()
+		// Not on chain yet, so confirmed as usual rather than sent again
+		tc.On("TxsEvents", []string{"tx.hash=" + inMempoolHash}, mock.Anything).Return(&txtypes.GetTxsEventResponse{}, nil).Once()
+		tc.On("Tx", inMempoolHash).Return(&txtypes.GetTxResponse{
+			Tx:         &txtypes.Tx{},
+			TxResponse: &cosmostypes.TxResponse{TxHash: inMempoolHash},
+		}, nil).Once()
+		require.NoError(t, restarted.sendMsgBatch(testutils.Context(t)))
+
+		for id, txHash := range map[int64]string{landed: landedHash, inMempool: inMempoolHash} {
+			m, err := restarted.orm.GetMsg(id)
+			require.NoError(t, err)
+			assert.Equal(t, Confirmed, m.State, id)
+			require.NotNil(t, m.TxHash, id)
+			assert.Equal(t, txHash, *m.TxHash, id)
+		}
+		tc.AssertNotCalled(t, "Broadcast", mock.Anything, mock.Anything)
+
+		// Nothing left to recover or send
+		require.NoError(t, restarted.sendMsgBatch(testutils.Context(t)))
+	})
+
+	t.Run("rejected broadcast clears pending broadcast", func(t *testing.T) {
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil)
+
+		id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		mustUpdateMsgs(t, txm.orm, []int64{id1}, Unstarted, Started, nil)
+		tc.On("Broadcast", mock.Anything, mock.Anything).Return(nil, errors.New("mempool is full")).Once()
+		require.Error(t, txm.broadcastAndConfirm(testutils.Context(t), tc, []byte{0x01}, nil, []int64{id1}))
+
+		// Sent again on next poll
+		started, err := txm.orm.GetStartedMsgs(10)
+		require.NoError(t, err)
+		require.Len(t, started, 1)
+		assert.Equal(t, id1, started[0].ID)
+		assert.Nil(t, started[0].TxHash)
+		pending, err := txm.orm.GetPendingBroadcastMsgs(10)
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+	})
+
+	t.Run("broadcast hook", func(t *testing.T) {
+		pollPeriod, err := relayutils.NewDuration(1 * time.Millisecond)
+		require.NoError(t, err)
+		hookCfg := terra.NewConfig(ChainCfg{ConfirmPollPeriod: &pollPeriod}, lggr)
+
+		results := make(chan BroadcastResult, 10)
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, hookCfg, nil,
+			WithOnBroadcast(func(result BroadcastResult) {
+				results <- result
+				if result.Err != nil {
+					panic("callback failed")
+				}
+			}))
+		stop := make(chan struct{})
+		go txm.broadcastHook.run(stop, lggr)
+		t.Cleanup(func() {
+			close(stop)
+			<-txm.broadcastHook.done
+		})
+		receive := func() BroadcastResult {
+			select {
+			case result := <-results:
+				return result
+			case <-time.After(5 * time.Second):
+				t.Fatal("broadcast callback not called")
+				return BroadcastResult{}
+			}
+		}
+		senders := []string{sender1.String()}
+
+		// Rejected by the node
+		id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		mustUpdateMsgs(t, txm.orm, []int64{id1}, Unstarted, Started, nil)
+		tc.On("Broadcast", []byte{0x01}, mock.Anything).Return(nil, errors.New("mempool is full")).Once()
+		require.Error(t, txm.broadcastAndConfirm(testutils.Context(t), tc, []byte{0x01}, senders, []int64{id1}))
+		result := receive()
+		assert.Equal(t, senders, result.Senders)
+		assert.Equal(t, []int64{id1}, result.MsgIDs)
+		assert.Equal(t, strings.ToUpper(hex.EncodeToString(tmhash.Sum([]byte{0x01}))), result.TxHash)
+		assert.EqualError(t, result.Err, "mempool is full")
+
+		// Accepted, despite the callback panicking on the last result
+		txHash := strings.ToUpper(hex.EncodeToString(tmhash.Sum([]byte{0x02})))
+		txResp := &cosmostypes.TxResponse{TxHash: txHash}
+		tc.On("Broadcast", []byte{0x02}, mock.Anything).Return(&txtypes.BroadcastTxResponse{TxResponse: txResp}, nil).Once()
+		tc.On("Tx", txHash).Return(&txtypes.GetTxResponse{Tx: &txtypes.Tx{}, TxResponse: txResp}, nil).Once()
+		require.NoError(t, txm.broadcastAndConfirm(testutils.Context(t), tc, []byte{0x02}, senders, []int64{id1}))
+		result = receive()
+		assert.Equal(t, BroadcastResult{Senders: senders, MsgIDs: []int64{id1}, TxHash: txHash}, result)
+	})
+
+	t.Run("sequence cache", func(t *testing.T) {
+		pollPeriod, err := relayutils.NewDuration(1 * time.Millisecond)
+		require.NoError(t, err)
+		cfgFastPoll := terra.NewConfig(ChainCfg{ConfirmPollPeriod: &pollPeriod}, lggr)
+
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, cfgFastPoll, nil, WithSequenceCache())
+
+		// expectSend expects msg id to be sent from account 3 at sequence, failing to broadcast with broadcastErr if set
+		expectSend := func(id int64, sequence uint64, broadcastErr error) {
+			signedTx := []byte(fmt.Sprintf("tx-%d", sequence))
+			txHash := strings.ToUpper(hex.EncodeToString(tmhash.Sum(signedTx)))
+			tc.On("BatchSimulateUnsigned", mock.Anything, sequence).Return(&terraclient.BatchSimResults{
+				Succeeded: terraclient.SimMsgs{{ID: id, Msg: &wasmtypes.MsgExecuteContract{
+					Sender:     sender1.String(),
+					ExecuteMsg: []byte(`1`),
+				}}},
+			}, nil).Once()
+			tc.On("SimulateUnsigned", mock.Anything, sequence).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+				GasUsed: 1_000_000,
+			}}, nil).Once()
+			tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+				Header: tmtypes.Header{Height: 1},
+			}}, nil).Once()
+			tc.On("CreateAndSign", mock.Anything, uint64(3), sequence, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(signedTx, nil).Once()
+			if broadcastErr != nil {
+				tc.On("Broadcast", signedTx, mock.Anything).Return(nil, broadcastErr).Once()
+				return
+			}
+			txResp := &cosmostypes.TxResponse{TxHash: txHash}
+			tc.On("Broadcast", signedTx, mock.Anything).Return(&txtypes.BroadcastTxResponse{TxResponse: txResp}, nil).Once()
+			tc.On("Tx", txHash).Return(&txtypes.GetTxResponse{Tx: &txtypes.Tx{}, TxResponse: txResp}, nil).Once()
+		}
+		assertState := func(id int64, state State) {
+			ms, err := txm.orm.GetMsgs(id)
+			require.NoError(t, err)
+			require.Len(t, ms, 1)
+			assert.Equal(t, state, ms[0].State)
+		}
+
+		// The sequence is read from chain on the first batch, then advanced locally
+		id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		tc.On("Account", sender1).Return(uint64(3), uint64(5), nil).Once()
+		expectSend(id1, 5, nil)
+		require.NoError(t, txm.sendMsgBatch(testutils.Context(t)))
+		assertState(id1, Confirmed)
+
+		id2, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		expectSend(id2, 6, nil)
+		require.NoError(t, txm.sendMsgBatch(testutils.Context(t)))
+		assertState(id2, Confirmed)
+
+		// Another tx from the same key was sent meanwhile, so the cached sequence is rejected
+		id3, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		expectSend(id3, 7, errors.New("account sequence mismatch, expected 8, got 7: incorrect account sequence"))
+		require.Error(t, txm.sendMsgBatch(testutils.Context(t)))
+		assertState(id3, Started)
+
+		// The sequence is read from chain again on retry
+		tc.On("Account", sender1).Return(uint64(3), uint64(8), nil).Once()
+		expectSend(id3, 8, nil)
+		require.NoError(t, txm.sendMsgBatch(testutils.Context(t)))
+		assertState(id3, Confirmed)
+	})
+
+	t.Run("priority", func(t *testing.T) {
+		prioCfg := terra.NewConfig(ChainCfg{MaxMsgsPerBatch: null.IntFrom(1)}, lggr)
+
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, prioCfg, nil)
+
+		// A backlog of low priority msgs, for different contracts so they don't replace each other
+		var low []int64
+		for i := 0; i < 3; i++ {
+			id, err := txm.Enqueue(fmt.Sprintf("low-%d", i), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+			require.NoError(t, err)
+			low = append(low, id)
+		}
+		high, err := txm.EnqueueWithPriority("high", generateExecuteMsg(t, []byte(`2`), sender1, contract), 10)
+		require.NoError(t, err)
+
+		// The high priority msg is the only one to fit in the batch
+		tc.On("Account", mock.Anything).Return(uint64(0), uint64(0), nil)
+		tc.On("BatchSimulateUnsigned", mock.MatchedBy(func(msgs terraclient.SimMsgs) bool {
+			return len(msgs) == 1 && msgs[0].ID == high
+		}), mock.Anything).Return(&terraclient.BatchSimResults{
+			Succeeded: terraclient.SimMsgs{{ID: high, Msg: &wasmtypes.MsgExecuteContract{
+				Sender:     sender1.String(),
+				ExecuteMsg: []byte(`2`),
+			}}},
+		}, nil).Once()
+		tc.On("SimulateUnsigned", mock.Anything, mock.Anything).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+			GasUsed: 1_000_000,
+		}}, nil).Once()
+		tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+			Header: tmtypes.Header{Height: 1},
+		}}, nil).Once()
+		signedTx := []byte("high")
+		tc.On("CreateAndSign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(signedTx, nil).Once()
+		txResp := &cosmostypes.TxResponse{TxHash: strings.ToUpper(hex.EncodeToString(tmhash.Sum(signedTx)))}
+		tc.On("Broadcast", signedTx, mock.Anything).Return(&txtypes.BroadcastTxResponse{TxResponse: txResp}, nil).Once()
+		tc.On("Tx", txResp.TxHash).Return(&txtypes.GetTxResponse{Tx: &txtypes.Tx{}, TxResponse: txResp}, nil).Once()
+		require.NoError(t, txm.sendMsgBatch(testutils.Context(t)))
+
+		ms, err := txm.orm.GetMsgs(append(low, high)...)
+		require.NoError(t, err)
+		require.Len(t, ms, 4)
+		for _, m := range ms {
+			if m.ID == high {
+				assert.Equal(t, Confirmed, m.State)
+			} else {
+				assert.Equal(t, Unstarted, m.State, "msg %d", m.ID)
+			}
+		}
+	})
+
+	t.Run("memo", func(t *testing.T) {
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil)
+
+		_, err := txm.EnqueueWithMemo("a", generateExecuteMsg(t, []byte(`1`), sender1, contract), strings.Repeat("x", 257))
+		require.Error(t, err)
+		id1, err := txm.EnqueueWithMemo("a", generateExecuteMsg(t, []byte(`1`), sender1, contract), "order-1")
+		require.NoError(t, err)
+		id2, err := txm.EnqueueWithMemo("b", generateExecuteMsg(t, []byte(`2`), sender1, contract), "order-2")
+		require.NoError(t, err)
+
+		encodingConfig := params.MakeEncodingConfig()
+		std.RegisterInterfaces(encodingConfig.InterfaceRegistry)
+		wasmtypes.RegisterInterfaces(encodingConfig.InterfaceRegistry)
+		// expectSend expects msg id to be sent alone, returning the memo of the broadcast tx
+		expectSend := func(id int64) *string {
+			var memo string
+			tc.On("Account", sender1).Return(uint64(3), uint64(id), nil).Once()
+			tc.On("BatchSimulateUnsigned", mock.MatchedBy(func(msgs terraclient.SimMsgs) bool {
+				return len(msgs) == 1 && msgs[0].ID == id
+			}), mock.Anything).Return(&terraclient.BatchSimResults{
+				Succeeded: terraclient.SimMsgs{{ID: id, Msg: generateExecuteMsg(t, []byte(`1`), sender1, contract)}},
+			}, nil).Once()
+			tc.On("SimulateUnsigned", mock.Anything, mock.Anything).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+				GasUsed: 1_000_000,
+			}}, nil).Once()
+			tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+				Header: tmtypes.Header{Height: 1},
+			}}, nil).Once()
+			var txResp *cosmostypes.TxResponse
+			tc.On("Broadcast", mock.Anything, mock.Anything).Return(func(signedTx []byte, _ txtypes.BroadcastMode) *txtypes.BroadcastTxResponse {
+				decoded, err := encodingConfig.TxConfig.TxDecoder()(signedTx)
+				require.NoError(t, err)
+				tx, ok := decoded.(authsigning.Tx)
+				require.True(t, ok)
+				memo = tx.GetMemo()
+				txResp = &cosmostypes.TxResponse{TxHash: strings.ToUpper(hex.EncodeToString(tmhash.Sum(signedTx)))}
+				return &txtypes.BroadcastTxResponse{TxResponse: txResp}
+			}, nil).Once()
+			tc.On("Tx", mock.Anything).Return(func(string) *txtypes.GetTxResponse {
+				return &txtypes.GetTxResponse{Tx: &txtypes.Tx{}, TxResponse: txResp}
+			}, nil).Once()
+			return &memo
+		}
+		assertState := func(id int64, state State) {
+			ms, err := txm.orm.GetMsgs(id)
+			require.NoError(t, err)
+			require.Len(t, ms, 1)
+			assert.Equal(t, state, ms[0].State)
+		}
+
+		// Msgs with different memos aren't batched together
+		memo := expectSend(id1)
+		require.NoError(t, txm.sendMsgBatch(testutils.Context(t)))
+		assert.Equal(t, "order-1", *memo)
+		assertState(id1, Confirmed)
+		assertState(id2, Started)
+
+		memo = expectSend(id2)
+		require.NoError(t, txm.sendMsgBatch(testutils.Context(t)))
+		assert.Equal(t, "order-2", *memo)
+		assertState(id2, Confirmed)
+	})
+
+	t.Run("gas limit", func(t *testing.T) {
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil)
+
+		_, err := txm.EnqueueWithGasLimit("a", generateExecuteMsg(t, []byte(`1`), sender1, contract), 0)
+		require.Error(t, err)
+		id1, err := txm.EnqueueWithGasLimit("a", generateExecuteMsg(t, []byte(`1`), sender1, contract), 3_000_000)
+		require.NoError(t, err)
+		id2, err := txm.Enqueue("b", generateExecuteMsg(t, []byte(`2`), sender1, contract))
+		require.NoError(t, err)
+
+		// expectSend expects msg id to be sent alone after simulating 1M gas, returning the gas limit it's signed with
+		expectSend := func(id int64) *uint64 {
+			var gasLimit uint64
+			tc.On("Account", sender1).Return(uint64(3), uint64(id), nil).Once()
+			tc.On("BatchSimulateUnsigned", mock.MatchedBy(func(msgs terraclient.SimMsgs) bool {
+				return len(msgs) == 1 && msgs[0].ID == id
+			}), mock.Anything).Return(&terraclient.BatchSimResults{
+				Succeeded: terraclient.SimMsgs{{ID: id, Msg: generateExecuteMsg(t, []byte(`1`), sender1, contract)}},
+			}, nil).Once()
+			tc.On("SimulateUnsigned", mock.Anything, mock.Anything).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+				GasUsed: 1_000_000,
+			}}, nil).Once()
+			tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+				Header: tmtypes.Header{Height: 1},
+			}}, nil).Once()
+			signedTx := []byte(fmt.Sprintf("tx-%d", id))
+			tc.On("CreateAndSign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) { gasLimit = args.Get(3).(uint64) }).Return(signedTx, nil).Once()
+			txResp := &cosmostypes.TxResponse{TxHash: strings.ToUpper(hex.EncodeToString(tmhash.Sum(signedTx)))}
+			tc.On("Broadcast", signedTx, mock.Anything).Return(&txtypes.BroadcastTxResponse{TxResponse: txResp}, nil).Once()
+			tc.On("Tx", txResp.TxHash).Return(&txtypes.GetTxResponse{Tx: &txtypes.Tx{}, TxResponse: txResp}, nil).Once()
+			return &gasLimit
+		}
+		assertState := func(id int64, state State) {
+			ms, err := txm.orm.GetMsgs(id)
+			require.NoError(t, err)
+			require.Len(t, ms, 1)
+			assert.Equal(t, state, ms[0].State)
+		}
+
+		// The override is used over the simulated gas, and msgs without one aren't batched with it
+		gasLimit := expectSend(id1)
+		require.NoError(t, txm.sendMsgBatch(testutils.Context(t)))
+		assert.Equal(t, uint64(3_000_000), *gasLimit)
+		assertState(id1, Confirmed)
+		assertState(id2, Started)
+
+		gasLimit = expectSend(id2)
+		require.NoError(t, txm.sendMsgBatch(testutils.Context(t)))
+		assert.Equal(t, uint64(1_000_000), *gasLimit)
+		assertState(id2, Confirmed)
+	})
+
+	t.Run("not before", func(t *testing.T) {
+		txm, _ := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil)
+		now := time.Now()
+		txm.orm.now = func() time.Time { return now }
+
+		// The sender has no key, so batches process its msgs by marking them errored.
+		noKeySender := cosmostypes.AccAddress("not before no key!!!")
+		_, err := txm.EnqueueWithNotBefore("a", generateExecuteMsg(t, []byte(`1`), noKeySender, contract), time.Time{})
+		require.Error(t, err)
+		notBefore1 := now.Add(time.Hour).Truncate(time.Microsecond)
+		id1, err := txm.EnqueueWithNotBefore("a", generateExecuteMsg(t, []byte(`1`), noKeySender, contract), notBefore1)
+		require.NoError(t, err)
+		ids, err := txm.EnqueueBatch([]EnqueueReq{
+			{ContractID: "b", Msg: generateExecuteMsg(t, []byte(`2`), noKeySender, contract), NotBefore: now.Add(2 * time.Hour)},
+		})
+		require.NoError(t, err)
+		id2 := ids[0]
+		assertState := func(id int64, state State) {
+			ms, err := txm.orm.GetMsgs(id)
+			require.NoError(t, err)
+			require.Len(t, ms, 1)
+			assert.Equal(t, state, ms[0].State)
+		}
+
+		// Neither is due yet
+		unstarted, err := txm.orm.GetMsgsState(Unstarted, 10)
+		require.NoError(t, err)
+		assert.Empty(t, unstarted)
+		count, _, err := txm.BacklogStats()
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		require.NoError(t, txm.processMsgBatch(testutils.Context(t)))
+		assertState(id1, Unstarted)
+		assertState(id2, Unstarted)
+
+		// Once due, a msg is selected as of its not before time, so it doesn't expire before it's sent
+		now = now.Add(90 * time.Minute)
+		unstarted, err = txm.orm.GetMsgsState(Unstarted, 10)
+		require.NoError(t, err)
+		require.Len(t, unstarted, 1)
+		assert.Equal(t, id1, unstarted[0].ID)
+		assert.True(t, notBefore1.Equal(unstarted[0].CreatedAt), "created at %s", unstarted[0].CreatedAt)
+		count, oldest, err := txm.BacklogStats()
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.True(t, notBefore1.Equal(oldest), "oldest %s", oldest)
+		require.NoError(t, txm.processMsgBatch(testutils.Context(t)))
+		assertState(id1, Errored)
+		assertState(id2, Unstarted)
+
+		now = now.Add(time.Hour)
+		require.NoError(t, txm.processMsgBatch(testutils.Context(t)))
+		assertState(id2, Errored)
+	})
+
+	t.Run("batch deadline", func(t *testing.T) {
+		const numSenders = 5
+		deadlineCfg := terra.NewConfig(ChainCfg{MaxMsgsPerBatch: null.IntFrom(numSenders)}, lggr)
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, deadlineCfg, nil, WithBatchDeadline(50*time.Millisecond))
+
+		for i := 0; i < numSenders; i++ {
+			k, err := ks.Terra().Create()
+			require.NoError(t, err)
+			sender, err := cosmostypes.AccAddressFromBech32(k.PublicKeyStr())
+			require.NoError(t, err)
+			_, err = txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender, contract))
+			require.NoError(t, err)
+		}
+
+		// Each sender takes longer than the deadline, failing to read its account so its msgs are retried
+		var sent []string
+		tc.On("Account", mock.Anything).Run(func(args mock.Arguments) {
+			sent = append(sent, args.Get(0).(cosmostypes.AccAddress).String())
+		}).After(100*time.Millisecond).Return(uint64(0), uint64(0), errors.New("slow node"))
+
+		for i := 1; i <= numSenders; i++ {
+			require.Error(t, txm.processMsgBatch(testutils.Context(t)))
+			// Only the first sender is started, the others are deferred
+			require.Len(t, sent, i)
+			assert.Len(t, txm.deferredSenders, numSenders-1)
+		}
+		// Deferred senders go first, so each sender was started once
+		started := make(map[string]struct{})
+		for _, s := range sent {
+			started[s] = struct{}{}
+		}
+		assert.Len(t, started, numSenders)
+	})
+
+	t.Run("pause and resume", func(t *testing.T) {
+		blockRate, err := relayutils.NewDuration(10 * time.Millisecond)
+		require.NoError(t, err)
+		pauseCfg := terra.NewConfig(ChainCfg{BlockRate: &blockRate, MaxMsgsPerBatch: null.IntFrom(10)}, lggr)
+		events := make(chan pg.Event)
+		sub := pgmocks.NewSubscription(t)
+		sub.On("Events").Return((<-chan pg.Event)(events))
+		sub.On("Close").Return()
+		eb := pgmocks.NewEventBroadcaster(t)
+		eb.On("Subscribe", pg.ChannelInsertOnTerraMsg, "").Return(sub, nil)
+		eb.On("Notify", pg.ChannelTerraMsgConfirmed, mock.Anything).Return(nil).Maybe()
+		// No client calls are expected until resumed
+
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, pauseCfg, eb)
+
+		txm.Pause()
+		assert.True(t, txm.Paused())
+		require.NoError(t, txm.Start(testutils.Context(t)))
+		t.Cleanup(func() { assert.NoError(t, txm.Close()) })
+
+		// Enqueueing still works, and the insert notifications are drained
+		var ids []int64
+		for i := 0; i < 3; i++ {
+			id, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+			require.NoError(t, err)
+			ids = append(ids, id)
+			events <- pg.Event{}
+		}
+		// Several block rates, with nothing sent
+		time.Sleep(100 * time.Millisecond)
+		ms, err := txm.orm.GetMsgs(ids...)
+		require.NoError(t, err)
+		require.Len(t, ms, len(ids))
+		for _, m := range ms {
+			assert.Equal(t, Unstarted, m.State)
+		}
+
+		var simMsgs terraclient.SimMsgs
+		for _, id := range ids {
+			simMsgs = append(simMsgs, terraclient.SimMsg{ID: id, Msg: generateExecuteMsg(t, []byte(`1`), sender1, contract)})
+		}
+		tc.On("Account", sender1).Return(uint64(0), uint64(0), nil).Once()
+		tc.On("BatchSimulateUnsigned", mock.MatchedBy(func(msgs terraclient.SimMsgs) bool {
+			return len(msgs) == len(ids)
+		}), mock.Anything).Return(&terraclient.BatchSimResults{Succeeded: simMsgs}, nil).Once()
+		tc.On("SimulateUnsigned", mock.Anything, mock.Anything).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+			GasUsed: 1_000_000,
+		}}, nil).Once()
+		tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+			Header: tmtypes.Header{Height: 1},
+		}}, nil).Once()
+		signedTx := []byte("paused-tx")
+		tc.On("CreateAndSign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(signedTx, nil).Once()
+		txResp := &cosmostypes.TxResponse{TxHash: strings.ToUpper(hex.EncodeToString(tmhash.Sum(signedTx)))}
+		tc.On("Broadcast", signedTx, mock.Anything).Return(&txtypes.BroadcastTxResponse{TxResponse: txResp}, nil).Once()
+		tc.On("Tx", txResp.TxHash).Return(&txtypes.GetTxResponse{Tx: &txtypes.Tx{}, TxResponse: txResp}, nil).Once()
+
+		txm.Resume()
+		assert.False(t, txm.Paused())
+		require.Eventually(t, func() bool {
+			ms, err := txm.orm.GetMsgs(ids...)
+			require.NoError(t, err)
+			for _, m := range ms {
+				if m.State != Confirmed {
+					return false
+				}
+			}
+			return true
+		}, testutils.WaitTimeout(t), 10*time.Millisecond)
+	})
+
+	t.Run("msg transition logs", func(t *testing.T) {
+		observedLggr, observed := logger.TestLoggerObserved(t, zapcore.DebugLevel)
+
+		txm, tc := newTestTxm(t, db, ks.Terra(), observedLggr, cfg, nil)
+
+		id, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		tc.On("Account", sender1).Return(uint64(0), uint64(0), nil).Once()
+		tc.On("BatchSimulateUnsigned", mock.Anything, mock.Anything).Return(&terraclient.BatchSimResults{
+			Succeeded: terraclient.SimMsgs{{ID: id, Msg: generateExecuteMsg(t, []byte(`1`), sender1, contract)}},
+		}, nil).Once()
+		tc.On("SimulateUnsigned", mock.Anything, mock.Anything).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+			GasUsed: 1_000_000,
+		}}, nil).Once()
+		tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+			Header: tmtypes.Header{Height: 1},
+		}}, nil).Once()
+		signedTx := []byte("logged-tx")
+		tc.On("CreateAndSign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(signedTx, nil).Once()
+		txHash := strings.ToUpper(hex.EncodeToString(tmhash.Sum(signedTx)))
+		txResp := &cosmostypes.TxResponse{TxHash: txHash}
+		tc.On("Broadcast", signedTx, mock.Anything).Return(&txtypes.BroadcastTxResponse{TxResponse: txResp}, nil).Once()
+		tc.On("Tx", txHash).Return(&txtypes.GetTxResponse{Tx: &txtypes.Tx{}, TxResponse: txResp}, nil).Once()
+		require.NoError(t, txm.sendMsgBatch(testutils.Context(t)))
+
+		var states []string
+		for _, entry := range observed.FilterMessage("Msg state transition").All() {
+			fields := entry.ContextMap()
+			if fields["msgID"] != id {
+				continue
+			}
+			for _, key := range []string{"msgID", "sender", "contractID", "txHash", "state", "retryCount"} {
+				assert.Contains(t, fields, key)
+			}
+			assert.Equal(t, sender1.String(), fields["sender"])
+			assert.Equal(t, contract.String(), fields["contractID"])
+			assert.Equal(t, int64(0), fields["retryCount"])
+			state := fmt.Sprint(fields["state"])
+			if state == string(Broadcasted) || state == string(Confirmed) {
+				assert.Equal(t, txHash, fields["txHash"])
+			}
+			states = append(states, state)
+		}
+		assert.Equal(t, []string{string(Unstarted), string(Started), string(Broadcasted), string(Confirmed)}, states)
+
+		// Recording the pending broadcast leaves the state as is, so it's only logged at debug level
+		var updates int
+		for _, entry := range observed.FilterMessage("Msg updated").All() {
+			if fields := entry.ContextMap(); fields["msgID"] == id {
+				assert.Equal(t, zapcore.DebugLevel, entry.Level)
+				assert.Equal(t, string(Started), fmt.Sprint(fields["state"]))
+				assert.Equal(t, txHash, fields["txHash"])
+				updates++
+			}
+		}
+		assert.Equal(t, 1, updates)
+	})
+
+	t.Run("enqueue batch", func(t *testing.T) {
+		txm, _ := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil)
+
+		// An earlier unstarted msg of the contract is cancelled, like with Enqueue
+		cancelled, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`0`), sender1, contract))
+		require.NoError(t, err)
+
+		// One msg per contract, so none supersedes another
+		var reqs []EnqueueReq
+		for i := 0; i < 50; i++ {
+			reqs = append(reqs, EnqueueReq{
+				ContractID: fmt.Sprintf("%s-%d", contract, i),
+				Msg:        generateExecuteMsg(t, []byte(fmt.Sprint(i)), sender1, contract),
+				Priority:   int32(i % 3),
+			})
+		}
+		reqs[0].ContractID = contract.String()
+		reqs[0].Memo = "first"
+		reqs[1].GasLimit = 300_000
+		ids, err := txm.EnqueueBatch(reqs)
+		require.NoError(t, err)
+		require.Len(t, ids, 50)
+
+		for i := 1; i < len(ids); i++ {
+			assert.Greater(t, ids[i], ids[i-1])
+		}
+		msgs, err := txm.orm.GetMsgs(ids...)
+		require.NoError(t, err)
+		require.Len(t, msgs, 50)
+		sort.Slice(msgs, func(i, j int) bool { return msgs[i].ID < msgs[j].ID })
+		for i, m := range msgs {
+			assert.Equal(t, ids[i], m.ID)
+			assert.Equal(t, Unstarted, m.State)
+			assert.Equal(t, reqs[i].ContractID, m.ContractID)
+			decoded, _, err := unmarshalMsg(m.Type, m.Raw)
+			require.NoError(t, err)
+			assert.Equal(t, fmt.Sprint(i), string(decoded.(*wasmtypes.MsgExecuteContract).ExecuteMsg))
+		}
+		var priorities []int32
+		require.NoError(t, db.Select(&priorities, `SELECT priority FROM terra_msgs WHERE id = ANY($1) ORDER BY id`, ids))
+		for i, priority := range priorities {
+			assert.Equal(t, int32(i%3), priority)
+		}
+		memos, err := txm.orm.GetMsgMemos(ids)
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]string{ids[0]: "first"}, memos)
+		gasLimits, err := txm.orm.GetMsgGasLimits(ids)
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]uint64{ids[1]: 300_000}, gasLimits)
+
+		cancelledMsgs, err := txm.orm.GetMsgs(cancelled)
+		require.NoError(t, err)
+		require.Len(t, cancelledMsgs, 1)
+		assert.Equal(t, Errored, cancelledMsgs[0].State)
+
+		t.Run("invalid msg", func(t *testing.T) {
+			_, err := txm.EnqueueBatch([]EnqueueReq{
+				{ContractID: contract2.String(), Msg: generateExecuteMsg(t, []byte(`1`), sender1, contract2)},
+				{ContractID: contract2.String(), Msg: generateExecuteMsg(t, []byte(`2`), sender1, contract2), Memo: strings.Repeat("a", maxMemoLength+1)},
+			})
+			require.ErrorContains(t, err, "msg 1: memo is")
+			unstarted, err := txm.orm.GetMsgsState(Unstarted, 100)
+			require.NoError(t, err)
+			assert.Len(t, unstarted, 50)
+		})
+
+		t.Run("same contract", func(t *testing.T) {
+			// Msgs of the batch are superseded by its later msgs for the same contract, keeping one Unstarted msg each
+			ids, err := txm.EnqueueBatch([]EnqueueReq{
+				{ContractID: contract2.String(), Msg: generateExecuteMsg(t, []byte(`1`), sender1, contract2)},
+				{ContractID: "other", Msg: generateExecuteMsg(t, []byte(`2`), sender1, contract2)},
+				{ContractID: contract2.String(), Msg: generateExecuteMsg(t, []byte(`3`), sender1, contract2)},
+				{ContractID: contract2.String(), Msg: generateExecuteMsg(t, []byte(`4`), sender1, contract2)},
+			})
+			require.NoError(t, err)
+			require.Len(t, ids, 4)
+			msgs, err := txm.orm.GetMsgs(ids...)
+			require.NoError(t, err)
+			states := make(map[int64]State)
+			for _, m := range msgs {
+				states[m.ID] = m.State
+			}
+			assert.Equal(t, map[int64]State{ids[0]: Errored, ids[1]: Unstarted, ids[2]: Errored, ids[3]: Unstarted}, states)
+
+			// A later Enqueue supersedes the batch's msg, as usual
+			id, err := txm.Enqueue(contract2.String(), generateExecuteMsg(t, []byte(`5`), sender1, contract2))
+			require.NoError(t, err)
+			unstarted, err := txm.orm.GetMsgsState(Unstarted, 100)
+			require.NoError(t, err)
+			var contract2Msgs []int64
+			for _, m := range unstarted {
+				if m.ContractID == contract2.String() {
+					contract2Msgs = append(contract2Msgs, m.ID)
+				}
+			}
+			assert.Equal(t, []int64{id}, contract2Msgs)
+		})
+	})
+
+	t.Run("stuck msgs", func(t *testing.T) {
+		var reported [][]StuckMsg
+		txm, _ := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil,
+			WithStuckMsgCheck(time.Hour, time.Minute, func(stuck []StuckMsg) { reported = append(reported, stuck) }))
+		gauge := func(state State) float64 {
+			return testutil.ToFloat64(promTerraTxmStuckMsgs.WithLabelValues(txm.orm.chainID, string(state)))
+		}
+
+		// Nothing is reported while no msg is past the SLA
+		recent, err := txm.orm.InsertMsg(contract.String(), "", []byte{0})
+		require.NoError(t, err)
+		txm.checkStuckMsgs()
+		assert.Empty(t, reported)
+		assert.Equal(t, float64(0), gauge(Unstarted))
+
+		now := time.Now()
+		stuck, err := txm.orm.InsertMsg(contract.String(), "", []byte{1})
+		require.NoError(t, err)
+		errored, err := txm.orm.InsertMsg(contract.String(), "", []byte{2})
+		require.NoError(t, err)
+		mustUpdateMsgs(t, txm.orm, []int64{errored}, Unstarted, Errored, nil)
+		for _, id := range []int64{stuck, errored} {
+			_, err = db.Exec(`UPDATE terra_msgs SET created_at = $1 WHERE id = $2`, now.Add(-2*time.Hour), id)
+			require.NoError(t, err)
+		}
+
+		txm.checkStuckMsgs()
+		require.Len(t, reported, 1)
+		require.Len(t, reported[0], 1)
+		assert.Equal(t, stuck, reported[0][0].ID)
+		assert.Equal(t, Unstarted, reported[0][0].State)
+		assert.Equal(t, contract.String(), reported[0][0].ContractID)
+		assert.WithinDuration(t, now.Add(-2*time.Hour), reported[0][0].CreatedAt, time.Second)
+		assert.Equal(t, float64(1), gauge(Unstarted))
+		assert.Equal(t, float64(0), gauge(Errored), "terminal states aren't checked")
+
+		// The check only observes
+		msgs, err := txm.orm.GetMsgs(stuck, recent)
+		require.NoError(t, err)
+		for _, m := range msgs {
+			assert.Equal(t, Unstarted, m.State)
+		}
+
+		// Cleared once the msg moves on
+		mustUpdateMsgs(t, txm.orm, []int64{stuck}, Unstarted, Errored, nil)
+		txm.checkStuckMsgs()
+		assert.Len(t, reported, 1)
+		assert.Equal(t, float64(0), gauge(Unstarted))
+
+		// Timed out msgs are still pending, while scheduled msgs are only pending once due
+		timedOut, err := txm.orm.InsertMsg(contract.String(), "", []byte{3})
+		require.NoError(t, err)
+		txHash := "0x123"
+		mustUpdateMsgs(t, txm.orm, []int64{timedOut}, Unstarted, Started, nil)
+		mustUpdateMsgs(t, txm.orm, []int64{timedOut}, Started, Broadcasted, &txHash)
+		mustUpdateMsgs(t, txm.orm, []int64{timedOut}, Broadcasted, TimedOut, nil)
+		scheduled, err := txm.orm.InsertMsgWithNotBefore(contract.String(), "", []byte{4}, now.Add(-30*time.Minute))
+		require.NoError(t, err)
+		for _, id := range []int64{timedOut, scheduled} {
+			_, err = db.Exec(`UPDATE terra_msgs SET created_at = $1 WHERE id = $2`, now.Add(-2*time.Hour), id)
+			require.NoError(t, err)
+		}
+		txm.checkStuckMsgs()
+		require.Len(t, reported, 2)
+		require.Len(t, reported[1], 1)
+		assert.Equal(t, timedOut, reported[1][0].ID)
+		assert.Equal(t, TimedOut, reported[1][0].State)
+		assert.Equal(t, float64(1), gauge(TimedOut))
+		assert.Equal(t, float64(0), gauge(Unstarted), "scheduled msgs aren't stuck before they're due")
+
+		_, err = db.Exec(`UPDATE terra_msgs SET not_before = $1 WHERE id = $2`, now.Add(-90*time.Minute), scheduled)
+		require.NoError(t, err)
+		txm.checkStuckMsgs()
+		require.Len(t, reported, 3)
+		require.Len(t, reported[2], 2)
+		assert.Equal(t, timedOut, reported[2][0].ID)
+		assert.Equal(t, scheduled, reported[2][1].ID)
+		assert.WithinDuration(t, now.Add(-90*time.Minute), reported[2][1].CreatedAt, time.Second, "pending since due")
+		assert.Equal(t, float64(1), gauge(Unstarted))
+	})
+
+	t.Run("list messages", func(t *testing.T) {
+		txm, _ := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil)
+
+		var reqs []EnqueueReq
+		for i := 0; i < 4; i++ {
+			reqs = append(reqs, EnqueueReq{ContractID: fmt.Sprintf("%s-%d", contract, i), Msg: generateExecuteMsg(t, []byte(fmt.Sprint(i)), sender1, contract)})
+		}
+		ids, err := txm.EnqueueBatch(reqs)
+		require.NoError(t, err)
+		unstarted, started, broadcasted, errored := ids[0], ids[1], ids[2], ids[3]
+		require.NoError(t, txm.orm.RecordMsgsFailure([]int64{unstarted}, "boom"))
+		mustUpdateMsgs(t, txm.orm, []int64{started, broadcasted}, Unstarted, Started, nil)
+		txHash := "0x123"
+		mustUpdateMsgs(t, txm.orm, []int64{broadcasted}, Started, Broadcasted, &txHash)
+		mustUpdateMsgs(t, txm.orm, []int64{errored}, Unstarted, Errored, nil)
+		listedIDs := func(msgs []MsgInfo) (ids []int64) {
+			for _, m := range msgs {
+				ids = append(ids, m.ID)
+			}
+			return
+		}
+
+		// Defaults to the msgs not completed yet
+		msgs, err := txm.ListMessages(0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{unstarted, started, broadcasted}, listedIDs(msgs))
+
+		msgs, err = txm.ListMessages(0, 10, Started)
+		require.NoError(t, err)
+		require.Equal(t, []int64{started}, listedIDs(msgs))
+		assert.Equal(t, Started, msgs[0].State)
+		assert.Nil(t, msgs[0].TxHash)
+
+		msgs, err = txm.ListMessages(0, 10, Unstarted, Broadcasted)
+		require.NoError(t, err)
+		require.Equal(t, []int64{unstarted, broadcasted}, listedIDs(msgs))
+		for _, m := range msgs {
+			assert.Equal(t, contract.String(), m.ContractID)
+			assert.Equal(t, sender1.String(), m.Sender)
+			assert.Positive(t, m.Age)
+		}
+		assert.Equal(t, Unstarted, msgs[0].State)
+		assert.Equal(t, int64(1), msgs[0].RetryCount)
+		assert.Equal(t, Broadcasted, msgs[1].State)
+		require.NotNil(t, msgs[1].TxHash)
+		assert.Equal(t, txHash, *msgs[1].TxHash)
+		assert.Equal(t, int64(0), msgs[1].RetryCount)
+
+		msgs, err = txm.ListMessages(0, 10, Confirmed)
+		require.NoError(t, err)
+		assert.Empty(t, msgs)
+
+		// Pages by the id of the last msg listed
+		msgs, err = txm.ListMessages(0, 2)
+		require.NoError(t, err)
+		require.Equal(t, []int64{unstarted, started}, listedIDs(msgs))
+		msgs, err = txm.ListMessages(msgs[1].ID, 2)
+		require.NoError(t, err)
+		require.Equal(t, []int64{broadcasted}, listedIDs(msgs))
+		msgs, err = txm.ListMessages(broadcasted, 2)
+		require.NoError(t, err)
+		assert.Empty(t, msgs)
+
+		_, err = txm.ListMessages(0, 0)
+		require.EqualError(t, err, "limit must be greater than 0")
+	})
+
+	t.Run("startup ramp", func(t *testing.T) {
+		rampCfg := terra.NewConfig(ChainCfg{MaxMsgsPerBatch: null.IntFrom(1000)}, lggr)
+		txm, _ := newTestTxm(t, db, ks.Terra(), lggr, rampCfg, nil, WithStartupRamp(4, 100))
+
+		// A backlog of 10k msgs accumulated during downtime. Their sender has no key, so each batch processes its msgs
+		// by marking them errored.
+		noKeySender := cosmostypes.AccAddress("ramp sender no key!!")
+		typeURL, raw, err := txm.marshalMsg(generateExecuteMsg(t, []byte(`0`), noKeySender, contract))
+		require.NoError(t, err)
+		const backlog = 10_000
+		_, err = db.Exec(`INSERT INTO terra_msgs (contract_id, type, raw, state, terra_chain_id, created_at, updated_at)
+	SELECT $1, $2, $3, $4, $5, NOW(), NOW() FROM generate_series(1, $6)`, contract.String(), typeURL, raw, Unstarted, txm.orm.chainID, backlog)
+		require.NoError(t, err)
+
+		var processed []int64
+		var total int64
+		for tick := 0; tick < 6; tick++ {
+			require.NoError(t, txm.processMsgBatch(testutils.Context(t)))
+			errored, err := txm.orm.CountMsgsState(Errored)
+			require.NoError(t, err)
+			processed = append(processed, errored-total)
+			total = errored
+		}
+		assert.Equal(t, []int64{100, 325, 550, 775, 1000, 1000}, processed)
+		unstarted, err := txm.orm.CountMsgsState(Unstarted)
+		require.NoError(t, err)
+		assert.Equal(t, backlog-total, unstarted)
+	})
+
+	t.Run("fake clock batches", func(t *testing.T) {
+		// No insert notifications, so batches are only triggered by the clock
+		sub := pgmocks.NewSubscription(t)
+		sub.On("Events").Return((<-chan pg.Event)(make(chan pg.Event)))
+		sub.On("Close").Return()
+		eb := pgmocks.NewEventBroadcaster(t)
+		eb.On("Subscribe", pg.ChannelInsertOnTerraMsg, "").Return(sub, nil)
+
+		clock := newFakeClock()
+		txm, _ := newTestTxm(t, db, ks.Terra(), lggr, cfg, eb, WithClock(clock))
+		require.NoError(t, txm.Start(testutils.Context(t)))
+		t.Cleanup(func() { assert.NoError(t, txm.Close()) })
+		state := func(id int64) State {
+			ms, err := txm.orm.GetMsgs(id)
+			require.NoError(t, err)
+			require.Len(t, ms, 1)
+			return ms[0].State
+		}
+
+		// The sender has no key, so each batch processes its msgs by marking them errored.
+		noKeySender := cosmostypes.AccAddress("fake clock no key!!!")
+		for batch := 0; batch < 3; batch++ {
+			id, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), noKeySender, contract))
+			require.NoError(t, err)
+			require.Eventually(t, func() bool { return clock.waiting() == 1 }, testutils.WaitTimeout(t), 10*time.Millisecond,
+				"the run loop should be waiting for the next batch")
+			assert.Equal(t, Unstarted, state(id), "no batch before the block rate elapsed")
+			clock.Advance(2 * cfg.BlockRate())
+			require.Eventually(t, func() bool { return state(id) == Errored }, testutils.WaitTimeout(t), 10*time.Millisecond,
+				"batch %d should be sent once the block rate elapsed", batch)
+		}
+	})
+
+	t.Run("fake clock confirmation polling", func(t *testing.T) {
+		clock := newFakeClock()
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil, WithClock(clock))
+
+		id, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		txHash := "4BF5122F344554C53BDE2EBB8CD2B7E3D1600AD631C385A5D7CCE23C7785459B"
+		mustUpdateMsgs(t, txm.orm, []int64{id}, Unstarted, Started, nil)
+		mustUpdateMsgs(t, txm.orm, []int64{id}, Started, Broadcasted, &txHash)
+		tc.On("Tx", txHash).Return(nil, errors.New("not found")).Once()
+		tc.On("Tx", txHash).Return(&txtypes.GetTxResponse{Tx: &txtypes.Tx{}, TxResponse: &cosmostypes.TxResponse{TxHash: txHash}}, nil).Once()
+
+		done := make(chan error, 1)
+		go func() { done <- txm.confirmTx(testutils.Context(t), tc, txHash, []int64{id}, 3, time.Minute) }()
+		for poll := 0; poll < 2; poll++ {
+			require.Eventually(t, func() bool { return clock.waiting() == 1 }, testutils.WaitTimeout(t), 10*time.Millisecond,
+				"should be waiting for poll %d", poll)
+			tc.AssertNumberOfCalls(t, "Tx", poll)
+			clock.Advance(2 * time.Minute)
+		}
+		require.NoError(t, <-done)
+		ms, err := txm.orm.GetMsgs(id)
+		require.NoError(t, err)
+		require.Len(t, ms, 1)
+		assert.Equal(t, Confirmed, ms[0].State)
+	})
+
+	t.Run("custom gas pricer", func(t *testing.T) {
+		// A floor above the estimator's 0.01uluna
+		floor := cosmostypes.NewDecCoinFromDec("uluna", cosmostypes.MustNewDecFromStr("0.05"))
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil, WithGasPricer(stubGasPricer{price: floor}))
+
+		gasPrice, err := txm.GasPrice()
+		require.NoError(t, err)
+		assert.Equal(t, floor, gasPrice)
+
+		id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		tc.On("Account", mock.Anything).Return(uint64(0), uint64(0), nil).Once()
+		tc.On("BatchSimulateUnsigned", mock.Anything, mock.Anything).Return(&terraclient.BatchSimResults{
+			Failed: nil,
+			Succeeded: terraclient.SimMsgs{{ID: id1, Msg: &wasmtypes.MsgExecuteContract{
+				Sender:     sender1.String(),
+				ExecuteMsg: []byte(`1`),
+			}}},
+		}, nil).Once()
+		tc.On("SimulateUnsigned", mock.Anything, mock.Anything).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+			GasUsed: 1_000_000,
+		}}, nil).Once()
+
+		fees, err := txm.EstimateBatchFee()
+		require.NoError(t, err)
+		require.Len(t, fees, 1)
+		gasLimit := int64(1_000_000 * cfg.GasLimitMultiplier())
+		expected := cosmostypes.NewCoins(cosmostypes.NewCoin("uluna", floor.Amount.MulInt64(gasLimit).Ceil().RoundInt()))
+		assert.Equal(t, expected, fees[sender1.String()])
+
+		t.Run("errors", func(t *testing.T) {
+			txm := NewTxm(db, txm.tc, *gpe, txm.orm.chainID, cfg, ks.Terra(), lggr, pgtest.NewQConfig(true), nil,
+				WithGasPricer(stubGasPricer{err: errors.New("no price")}))
+			_, err := txm.EstimateBatchFee()
+			require.ErrorContains(t, err, "no price")
+		})
+	})
+
+	t.Run("max fee exceeded", func(t *testing.T) {
+		// The fee of 1_000_000 gas at 0.01uluna is at least 10_000uluna.
+		// No CreateAndSign or Broadcast expected, and the msg left Started isn't retried by other tests.
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil, WithMaxFee(cosmostypes.NewInt64Coin("uluna", 1_000)))
+
+		id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		tc.On("Account", mock.Anything).Return(uint64(0), uint64(0), nil)
+		tc.On("BatchSimulateUnsigned", mock.Anything, mock.Anything).Return(&terraclient.BatchSimResults{
+			Failed: nil,
+			Succeeded: terraclient.SimMsgs{{ID: id1, Msg: &wasmtypes.MsgExecuteContract{
+				Sender:     sender1.String(),
+				ExecuteMsg: []byte(`1`),
+			}}},
+		}, nil)
+		tc.On("SimulateUnsigned", mock.Anything, mock.Anything).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+			GasUsed: 1_000_000,
+		}}, nil)
+		tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+			Header: tmtypes.Header{Height: 1},
+		}}, nil)
+
+		err = txm.processMsgBatch(testutils.Context(t))
+		require.ErrorIs(t, err, ErrMaxFeeExceeded)
+
+		// Not broadcast, so left to be retried
+		ms, err := txm.orm.GetMsgs(id1)
+		require.NoError(t, err)
+		require.Len(t, ms, 1)
+		assert.Equal(t, Started, ms[0].State)
+		assert.Nil(t, ms[0].TxHash)
+	})
+
+	t.Run("insufficient balance", func(t *testing.T) {
+		// No CreateAndSign or Broadcast expected, and the msg left Started isn't retried by other tests
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, cfg, nil, WithBalanceCheck())
+
+		id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+		tc.On("Account", mock.Anything).Return(uint64(0), uint64(0), nil)
+		tc.On("BatchSimulateUnsigned", mock.Anything, mock.Anything).Return(&terraclient.BatchSimResults{
+			Failed: nil,
+			Succeeded: terraclient.SimMsgs{{ID: id1, Msg: &wasmtypes.MsgExecuteContract{
+				Sender:     sender1.String(),
+				ExecuteMsg: []byte(`1`),
+			}}},
+		}, nil)
+		tc.On("SimulateUnsigned", mock.Anything, mock.Anything).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+			GasUsed: 1_000_000,
+		}}, nil)
+		tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+			Header: tmtypes.Header{Height: 1},
+		}}, nil)
+		// The fee of 1_000_000 gas at 0.01uluna is at least 10_000uluna
+		balance := cosmostypes.NewInt64Coin("uluna", 9_999)
+		tc.On("Balance", sender1, "uluna").Return(&balance, nil).Once()
+
+		err = txm.processMsgBatch(testutils.Context(t))
+		require.ErrorIs(t, err, ErrInsufficientBalance)
+		require.ErrorContains(t, err, "exceeds balance 9999uluna of fee payer "+sender1.String())
+
+		// Not broadcast, so left to be retried
+		ms, err := txm.orm.GetMsgs(id1)
+		require.NoError(t, err)
+		require.Len(t, ms, 1)
+		assert.Equal(t, Started, ms[0].State)
+		assert.Nil(t, ms[0].TxHash)
+	})
+}
+
+func TestFailoverClient(t *testing.T) {
+	t.Parallel()
+
+	lggr := logger.TestLogger(t)
+	sender := cosmostypes.AccAddress("sender")
+	refused := errors.New("dial tcp 127.0.0.1:26657: connect: connection refused")
+
+	t.Run("fails over on transient errors", func(t *testing.T) {
+		first, second := newReaderWriterMock(t), newReaderWriterMock(t)
+		first.On("Account", sender).Return(uint64(0), uint64(0), refused).Once()
+		second.On("Account", sender).Return(uint64(1), uint64(2), nil).Once()
+		fc := newFailoverClient(lggr, time.Now, first, second)
+
+		an, sn, err := fc.Account(sender)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), an)
+		assert.Equal(t, uint64(2), sn)
+
+		// the first endpoint is cooling down, so the healthy one is preferred
+		second.On("TxsEvents", []string{"tx.height=1"}, (*query.PageRequest)(nil)).Return(&txtypes.GetTxsEventResponse{}, nil).Once()
+		_, err = fc.TxsEvents([]string{"tx.height=1"}, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("returns other errors right away", func(t *testing.T) {
+		first, second := newReaderWriterMock(t), newReaderWriterMock(t)
+		first.On("Broadcast", []byte("tx"), txtypes.BroadcastMode_BROADCAST_MODE_SYNC).Return(nil, errors.New("out of gas")).Once()
+		fc := newFailoverClient(lggr, time.Now, first, second)
+
+		_, err := fc.Broadcast([]byte("tx"), txtypes.BroadcastMode_BROADCAST_MODE_SYNC)
+		require.EqualError(t, err, "out of gas")
+	})
+
+	t.Run("all endpoints fail", func(t *testing.T) {
+		first, second := newReaderWriterMock(t), newReaderWriterMock(t)
+		first.On("LatestBlock").Return(nil, refused).Once()
+		second.On("LatestBlock").Return(nil, io.EOF).Once()
+		fc := newFailoverClient(lggr, time.Now, first, second)
+
+		_, err := fc.LatestBlock()
+		require.ErrorContains(t, err, "LatestBlock failed on all 2 endpoints")
+		require.ErrorContains(t, err, "connection refused")
+		require.ErrorContains(t, err, "EOF")
+	})
+
+	t.Run("cooldown follows the clock", func(t *testing.T) {
+		first, second := newReaderWriterMock(t), newReaderWriterMock(t)
+		clock := newFakeClock()
+		fc := newFailoverClient(lggr, clock.Now, first, second)
+		first.On("LatestBlock").Return(nil, refused).Once()
+		second.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{}, nil).Twice()
+		_, err := fc.LatestBlock()
+		require.NoError(t, err)
+
+		// still cooling down, so the second endpoint is tried first
+		_, err = fc.LatestBlock()
+		require.NoError(t, err)
+
+		clock.Advance(failoverCooldown)
+		first.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{}, nil).Once()
+		_, err = fc.LatestBlock()
+		require.NoError(t, err)
+	})
+
+	t.Run("txm", func(t *testing.T) {
+		first, second := newReaderWriterMock(t), newReaderWriterMock(t)
+		txm := &Txm{lggr: lggr, clock: newFakeClock()}
+		WithFailoverClients(first, second)(txm)
+		tc, err := txm.tc()
+		require.NoError(t, err)
+
+		first.On("Balance", sender, "uluna").Return(nil, context.DeadlineExceeded).Once()
+		second.On("Balance", sender, "uluna").Return(&cosmostypes.Coin{Denom: "uluna", Amount: cosmostypes.NewInt(1)}, nil).Once()
+		balance, err := tc.Balance(sender, "uluna")
+		require.NoError(t, err)
+		assert.Equal(t, cosmostypes.NewInt(1), balance.Amount)
+	})
+}
+
+func TestTxm_checkMaxFee(t *testing.T) {
+	t.Parallel()
+
+	gasPrice := cosmostypes.NewDecCoinFromDec("uluna", cosmostypes.MustNewDecFromStr("0.01"))
+	fee := txFee(1_000_000, 1.5, gasPrice)
+	assert.Equal(t, cosmostypes.NewInt64Coin("uluna", 15_000), fee)
+
+	for _, tt := range []struct {
+		name   string
+		maxFee *cosmostypes.Coin
+		err    bool
+	}{
+		{"no max fee", nil, false},
+		{"below", &cosmostypes.Coin{Denom: "uluna", Amount: cosmostypes.NewInt(20_000)}, false},
+		{"equal", &cosmostypes.Coin{Denom: "uluna", Amount: cosmostypes.NewInt(15_000)}, false},
+		{"above", &cosmostypes.Coin{Denom: "uluna", Amount: cosmostypes.NewInt(14_999)}, true},
+		{"different denom", &cosmostypes.Coin{Denom: "uusd", Amount: cosmostypes.NewInt(1_000_000)}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			txm := &Txm{lggr: logger.TestLogger(t), orm: &ORM{chainID: "test"}, maxFee: tt.maxFee}
+			err := txm.checkMaxFee(fee)
+			if tt.err {
+				require.ErrorIs(t, err, ErrMaxFeeExceeded)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+// stubGasPricer is a GasPricer returning a fixed price, or err if set.
+type stubGasPricer struct {
+	price cosmostypes.DecCoin
+	err   error
+}
+
+func (p stubGasPricer) GasPrice() (cosmostypes.DecCoin, error) {
+	return p.price, p.err
+}
+
+// mustUpdateMsgs transitions all the msgs with the given ids from state from to state to.
+func mustUpdateMsgs(t *testing.T, o *ORM, ids []int64, from, to State, txHash *string) {
+	t.Helper()
+	updated, err := o.UpdateMsgs(ids, []State{from}, to, txHash)
+	require.NoError(t, err)
+	require.ElementsMatch(t, ids, updated)
+}
+
+// mustUpdateMsgsErrored marks all the msgs with the given ids in state from as Errored.
+func mustUpdateMsgsErrored(t *testing.T, o *ORM, ids []int64, from State, reason string) {
+	t.Helper()
+	updated, err := o.UpdateMsgsErrored(ids, []State{from}, reason)
+	require.NoError(t, err)
+	require.ElementsMatch(t, ids, updated)
+}
+
+func mustInsertMsg(t *testing.T, txm *Txm, contractID string, msg cosmostypes.Msg) int64 {
+	typeURL, raw, err := txm.marshalMsg(msg)
+	require.NoError(t, err)
+	id, err := txm.orm.InsertMsg(contractID, typeURL, raw)
+	require.NoError(t, err)
+	return id
+}
+
+func TestTxm_checkBalance(t *testing.T) {
+	t.Parallel()
+
+	payer := cosmostypes.AccAddress("payer")
+	fee := cosmostypes.NewInt64Coin("uluna", 15_000)
+	for _, tt := range []struct {
+		name    string
+		balance *cosmostypes.Coin
+		err     bool
+	}{
+		{"above", &cosmostypes.Coin{Denom: "uluna", Amount: cosmostypes.NewInt(20_000)}, false},
+		{"equal", &cosmostypes.Coin{Denom: "uluna", Amount: cosmostypes.NewInt(15_000)}, false},
+		{"below", &cosmostypes.Coin{Denom: "uluna", Amount: cosmostypes.NewInt(14_999)}, true},
+		{"no balance", nil, true},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tc := newReaderWriterMock(t)
+			tc.On("Balance", payer, "uluna").Return(tt.balance, nil).Once()
+			txm := &Txm{lggr: logger.TestLogger(t), orm: &ORM{chainID: "test"}, balanceCheck: true}
+			err := txm.checkBalance(tc, payer, fee)
+			if tt.err {
+				require.ErrorIs(t, err, ErrInsufficientBalance)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		txm := &Txm{lggr: logger.TestLogger(t), orm: &ORM{chainID: "test"}}
+		require.NoError(t, txm.checkBalance(newReaderWriterMock(t), payer, fee))
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		t.Parallel()
+		tc := newReaderWriterMock(t)
+		tc.On("Balance", payer, "uluna").Return(nil, errors.New("unavailable")).Once()
+		txm := &Txm{lggr: logger.TestLogger(t), orm: &ORM{chainID: "test"}, balanceCheck: true}
+		err := txm.checkBalance(tc, payer, fee)
+		require.ErrorContains(t, err, "unavailable")
+		require.NotErrorIs(t, err, ErrInsufficientBalance)
+	})
+
+	t.Run("fee granter pays", func(t *testing.T) {
+		granter := cosmostypes.AccAddress("granter")
+		assert.Equal(t, payer, (&Txm{}).feePayer(payer))
+		assert.Equal(t, granter, (&Txm{feeGranter: granter}).feePayer(payer))
+	})
+}
+
+func TestTxm_feeDenoms(t *testing.T) {
+	t.Parallel()
+
+	payer := cosmostypes.AccAddress("payer")
+	lunaPrice := cosmostypes.NewDecCoinFromDec("uluna", cosmostypes.MustNewDecFromStr("0.01"))
+	usdPrice := cosmostypes.NewDecCoinFromDec("uusd", cosmostypes.MustNewDecFromStr("0.15"))
+	newTxm := func(t *testing.T, opts ...TxmOpt) *Txm {
+		lggr := logger.TestLogger(t)
+		txm := &Txm{
+			lggr: lggr,
+			orm:  &ORM{chainID: "test"},
+			cfg:  terra.NewConfig(ChainCfg{}, lggr),
+			gpe: *terraclient.NewMustGasPriceEstimator([]terraclient.GasPricesEstimator{
+				terraclient.NewFixedGasPriceEstimator(map[string]cosmostypes.DecCoin{"uluna": lunaPrice, "uusd": usdPrice}),
+			}, lggr),
+		}
+		for _, opt := range opts {
+			opt(txm)
+		}
+		return txm
+	}
+	const gasLimit = 1_000_000
+	gasLimitMultiplier := terra.NewConfig(ChainCfg{}, logger.TestLogger(t)).GasLimitMultiplier()
+	lunaFee := txFee(gasLimit, gasLimitMultiplier, lunaPrice)
+	usdFee := txFee(gasLimit, gasLimitMultiplier, usdPrice)
+	coin := func(c cosmostypes.Coin, delta int64) *cosmostypes.Coin {
+		c.Amount = c.Amount.AddRaw(delta)
+		return &c
+	}
+
+	t.Run("first denom unaffordable", func(t *testing.T) {
+		t.Parallel()
+		tc := newReaderWriterMock(t)
+		tc.On("Balance", payer, "uluna").Return(coin(lunaFee, -1), nil).Once()
+		tc.On("Balance", payer, "uusd").Return(&usdFee, nil).Once()
+		txm := newTxm(t, WithFeeDenoms("uluna", "uusd"))
+		gasPrice, err := txm.txGasPrice(tc, payer, lunaPrice, gasLimit)
+		require.NoError(t, err)
+		assert.Equal(t, usdPrice, gasPrice)
+	})
+
+	t.Run("first denom affordable", func(t *testing.T) {
+		t.Parallel()
+		tc := newReaderWriterMock(t)
+		tc.On("Balance", payer, "uluna").Return(&lunaFee, nil).Once()
+		txm := newTxm(t, WithFeeDenoms("uluna", "uusd"))
+		gasPrice, err := txm.txGasPrice(tc, payer, lunaPrice, gasLimit)
+		require.NoError(t, err)
+		assert.Equal(t, lunaPrice, gasPrice)
+	})
+
+	t.Run("no denom affordable", func(t *testing.T) {
+		t.Parallel()
+		tc := newReaderWriterMock(t)
+		tc.On("Balance", payer, "uluna").Return(nil, nil).Once()
+		tc.On("Balance", payer, "uusd").Return(coin(usdFee, -1), nil).Once()
+		txm := newTxm(t, WithFeeDenoms("uluna", "uusd"))
+		_, err := txm.txGasPrice(tc, payer, lunaPrice, gasLimit)
+		require.ErrorIs(t, err, ErrInsufficientBalance)
+	})
+
+	t.Run("denom without price skipped", func(t *testing.T) {
+		t.Parallel()
+		tc := newReaderWriterMock(t)
+		tc.On("Balance", payer, "uusd").Return(&usdFee, nil).Once()
+		txm := newTxm(t, WithFeeDenoms("ukrw", "uusd"))
+		gasPrice, err := txm.txGasPrice(tc, payer, lunaPrice, gasLimit)
+		require.NoError(t, err)
+		assert.Equal(t, usdPrice, gasPrice)
+	})
+
+	t.Run("max fee of chosen denom", func(t *testing.T) {
+		t.Parallel()
+		tc := newReaderWriterMock(t)
+		tc.On("Balance", payer, "uluna").Return(nil, nil).Once()
+		tc.On("Balance", payer, "uusd").Return(&usdFee, nil).Once()
+		txm := newTxm(t, WithFeeDenoms("uluna", "uusd"), WithMaxFee(lunaFee))
+		_, err := txm.txGasPrice(tc, payer, lunaPrice, gasLimit)
+		require.ErrorIs(t, err, ErrMaxFeeExceeded)
+	})
+
+	t.Run("single denom", func(t *testing.T) {
+		t.Parallel()
+		// The balance isn't checked without WithBalanceCheck
+		txm := newTxm(t, WithFeeDenoms("uusd"))
+		gasPrice, err := txm.txGasPrice(newReaderWriterMock(t), payer, lunaPrice, gasLimit)
+		require.NoError(t, err)
+		assert.Equal(t, usdPrice, gasPrice)
+
+		tc := newReaderWriterMock(t)
+		tc.On("Balance", payer, "uusd").Return(coin(usdFee, -1), nil).Once()
+		txm = newTxm(t, WithFeeDenoms("uusd"), WithBalanceCheck())
+		_, err = txm.txGasPrice(tc, payer, lunaPrice, gasLimit)
+		require.ErrorIs(t, err, ErrInsufficientBalance)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+		txm := newTxm(t)
+		gasPrice, err := txm.txGasPrice(newReaderWriterMock(t), payer, lunaPrice, gasLimit)
+		require.NoError(t, err)
+		assert.Equal(t, lunaPrice, gasPrice)
+	})
+}
+
+func TestTxOnChain(t *testing.T) {
+	t.Parallel()
+
+	const txHash = "4BF5122F344554C53BDE2EBB8CD2B7E3D1600AD631C385A5D7CCE23C7785459A"
+	for _, tt := range []struct {
+		name      string
+		responses []*cosmostypes.TxResponse
+		found     bool
+	}{
+		{"none", nil, false},
+		{"one", []*cosmostypes.TxResponse{{TxHash: txHash}}, true},
+		{"lowercase", []*cosmostypes.TxResponse{{TxHash: strings.ToLower(txHash)}}, true},
+		{"duplicates", []*cosmostypes.TxResponse{{TxHash: txHash}, {TxHash: txHash}}, true},
+		{"several", []*cosmostypes.TxResponse{nil, {TxHash: "other"}, {TxHash: txHash}}, true},
+		{"only others", []*cosmostypes.TxResponse{{TxHash: "other"}, {TxHash: "another"}}, false},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tc := newReaderWriterMock(t)
+			tc.On("TxsEvents", []string{"tx.hash=" + txHash}, mock.Anything).Return(&txtypes.GetTxsEventResponse{
+				TxResponses: tt.responses,
+			}, nil).Once()
+			found, err := txOnChain(tc, txHash)
+			require.NoError(t, err)
+			assert.Equal(t, tt.found, found)
+		})
+	}
+
+	t.Run("query error", func(t *testing.T) {
+		t.Parallel()
+		tc := newReaderWriterMock(t)
+		tc.On("TxsEvents", []string{"tx.hash=" + txHash}, mock.Anything).Return(nil, errors.New("unavailable")).Once()
+		_, err := txOnChain(tc, txHash)
+		require.ErrorContains(t, err, "unavailable")
+	})
+}
+
+func TestTxm_Health(t *testing.T) {
+	db := pgtest.NewSqlxDB(t)
+	lggr := logger.TestLogger(t)
+	ks := keystore.New(db, utils.FastScryptParams, lggr, pgtest.NewQConfig(true))
+	require.NoError(t, ks.Unlock("blah"))
+	k1, err := ks.Terra().Create()
+	require.NoError(t, err)
+	sender1, err := cosmostypes.AccAddressFromBech32(k1.PublicKeyStr())
+	require.NoError(t, err)
+	contract, err := cosmostypes.AccAddressFromBech32("terra1pp76d50yv2ldaahsdxdv8mmzqfjr2ax97gmue8")
+	require.NoError(t, err)
+	chainID := fmt.Sprintf("Chainlinktest-%d", rand.Int31n(999999))
+	terratest.MustInsertChain(t, db, &Chain{ID: chainID})
+	cfg := terra.NewConfig(ChainCfg{}, lggr)
+	gpe := terraclient.NewMustGasPriceEstimator([]terraclient.GasPricesEstimator{
+		terraclient.NewFixedGasPriceEstimator(map[string]cosmostypes.DecCoin{
+			"uluna": cosmostypes.NewDecCoinFromDec("uluna", cosmostypes.MustNewDecFromStr("0.01")),
+		}),
+	}, lggr)
+	newTxm := func(t *testing.T, tc *tcmocks.ReaderWriter, healthCfg HealthConfig) *Txm {
+		tcFn := func() (terraclient.ReaderWriter, error) { return tc, nil }
+		txm := NewTxm(db, tcFn, *gpe, chainID, cfg, ks.Terra(), lggr, pgtest.NewQConfig(true), pg.NewNullEventBroadcaster(), WithHealthConfig(healthCfg))
+		return txm
+	}
+	// markStarted marks the txm as started without running the processing loop.
+	markStarted := func(t *testing.T, txm *Txm) {
+		require.NoError(t, txm.starter.StartOnce("terratxm", func() error { return nil }))
+	}
+
+	t.Run("ready after start", func(t *testing.T) {
+		txm := newTxm(t, newReaderWriterMock(t), DefaultHealthConfig())
+		assert.Error(t, txm.Ready())
+		assert.Error(t, txm.Healthy())
+		require.NoError(t, txm.Start(testutils.Context(t)))
+		assert.NoError(t, txm.Ready())
+		assert.NoError(t, txm.Healthy())
+		require.NoError(t, txm.Close())
+		assert.Error(t, txm.Ready())
+	})
+
+	t.Run("not ready if subscribe fails", func(t *testing.T) {
+		eb := pgmocks.NewEventBroadcaster(t)
+		eb.On("Subscribe", pg.ChannelInsertOnTerraMsg, "").Return(nil, errors.New("subscribe failed"))
+		tcFn := func() (terraclient.ReaderWriter, error) { return newReaderWriterMock(t), nil }
+		txm := NewTxm(db, tcFn, *gpe, chainID, cfg, ks.Terra(), lggr, pgtest.NewQConfig(true), eb)
+		require.Error(t, txm.Start(testutils.Context(t)))
+		assert.Error(t, txm.Ready())
+
+		// The ORM is released even though the txm failed to start
+		mustInsertMsg(t, txm, contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NotNil(t, txm.orm.insertMsgStmt)
+		assert.Error(t, txm.Close(), "never started")
+		assert.Nil(t, txm.orm.insertMsgStmt)
+	})
+
+	t.Run("consecutive failures", func(t *testing.T) {
+		tc := newReaderWriterMock(t)
+		tc.On("Account", mock.Anything).Return(uint64(0), uint64(0), errors.New("rpc unavailable")).Twice()
+		txm := newTxm(t, tc, HealthConfig{MaxConsecutiveFailures: 2})
+		markStarted(t, txm)
+		mustInsertMsg(t, txm, contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+
+		txm.sendMsgBatch(testutils.Context(t))
+		assert.NoError(t, txm.Healthy())
+		txm.sendMsgBatch(testutils.Context(t))
+		err := txm.Healthy()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2 consecutive batches failed")
+		assert.Contains(t, err.Error(), "rpc unavailable")
+
+		// A successful batch resets the failure count.
+		txm.recordBatchResult(nil, 0, time.Time{}, true)
+		assert.NoError(t, txm.Healthy())
+	})
+
+	t.Run("unstarted backlog", func(t *testing.T) {
+		txm := newTxm(t, newReaderWriterMock(t), HealthConfig{MaxUnstartedBacklog: 2})
+		markStarted(t, txm)
+		for i := 0; i < 3; i++ {
+			_, err := txm.orm.InsertMsg(contract.String(), "", []byte{byte(i)})
+			require.NoError(t, err)
+		}
+		unstarted, err := txm.orm.CountMsgsState(Unstarted)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), unstarted)
+		txm.recordBatchResult(nil, unstarted, time.Time{}, true)
+		err = txm.Healthy()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unstarted backlog of 3 msgs exceeds limit of 2")
+	})
+
+	t.Run("oldest unstarted age", func(t *testing.T) {
+		txm, _ := newTestTxm(t, db, ks.Terra(), lggr, cfg, pg.NewNullEventBroadcaster(), WithHealthConfig(HealthConfig{MaxUnstartedAge: time.Hour}))
+		markStarted(t, txm)
+
+		count, oldest, err := txm.BacklogStats()
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		assert.True(t, oldest.IsZero())
+
+		now := time.Now()
+		for i, age := range []time.Duration{time.Minute, 2 * time.Hour, 30 * time.Minute} {
+			id, err := txm.orm.InsertMsg(contract.String(), "", []byte{byte(i)})
+			require.NoError(t, err)
+			_, err = db.Exec(`UPDATE terra_msgs SET created_at = $1 WHERE id = $2`, now.Add(-age), id)
+			require.NoError(t, err)
+		}
+
+		count, oldest, err = txm.BacklogStats()
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.WithinDuration(t, now.Add(-2*time.Hour), oldest, time.Second)
+
+		txm.recordBatchResult(nil, int64(count), oldest, true)
+		err = txm.Healthy()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "oldest unstarted msg is")
+		assert.Contains(t, err.Error(), "exceeding limit of 1h0m0s")
+	})
+
+	t.Run("stale batches", func(t *testing.T) {
+		txm := newTxm(t, newReaderWriterMock(t), HealthConfig{StaleBatchTimeout: time.Millisecond})
+		markStarted(t, txm)
+		txm.recordBatchResult(nil, 0, time.Time{}, true)
+		time.Sleep(5 * time.Millisecond)
+		// Nothing pending, so no batch is expected.
+		assert.NoError(t, txm.Healthy())
+
+		txm.recordBatchResult(errors.New("failed"), 1, time.Now(), true)
+		err := txm.Healthy()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no successful batch for")
+	})
+
+	t.Run("backs off while the chain is erroring", func(t *testing.T) {
+		blockRate, err := relayutils.NewDuration(10 * time.Millisecond)
+		require.NoError(t, err)
+		cfgBackoff := terra.NewConfig(ChainCfg{BlockRate: &blockRate}, lggr)
+
+		txm, tc := newTestTxm(t, db, ks.Terra(), lggr, cfgBackoff, nil, WithMaxBatchBackoff(40*time.Millisecond))
+		id1, err := txm.Enqueue(contract.String(), generateExecuteMsg(t, []byte(`1`), sender1, contract))
+		require.NoError(t, err)
+
+		// The node fails 3 times, then recovers
+		const failures = 3
+		tc.On("Account", mock.Anything).Return(uint64(0), uint64(0), errors.New("rpc unavailable")).Times(failures)
+		tc.On("Account", mock.Anything).Return(uint64(0), uint64(0), nil).Once()
+		tc.On("BatchSimulateUnsigned", mock.Anything, mock.Anything).Return(&terraclient.BatchSimResults{
+			Succeeded: terraclient.SimMsgs{{ID: id1, Msg: &wasmtypes.MsgExecuteContract{
+				Sender:     sender1.String(),
+				ExecuteMsg: []byte(`1`),
+			}}},
+		}, nil).Once()
+		tc.On("SimulateUnsigned", mock.Anything, mock.Anything).Return(&txtypes.SimulateResponse{GasInfo: &cosmostypes.GasInfo{
+			GasUsed: 1_000_000,
+		}}, nil).Once()
+		tc.On("LatestBlock").Return(&tmservicetypes.GetLatestBlockResponse{Block: &tmtypes.Block{
+			Header: tmtypes.Header{Height: 1},
+		}}, nil).Once()
+		tc.On("CreateAndSign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]byte{0x01}, nil).Once()
+		txResp := &cosmostypes.TxResponse{TxHash: "4BF5122F344554C53BDE2EBB8CD2B7E3D1600AD631C385A5D7CCE23C7785459A"}
+		tc.On("Broadcast", mock.Anything, mock.Anything).Return(&txtypes.BroadcastTxResponse{TxResponse: txResp}, nil).Once()
+		tc.On("Tx", mock.Anything).Return(&txtypes.GetTxResponse{Tx: &txtypes.Tx{}, TxResponse: txResp}, nil).Once()
+
+		for i := 0; i < failures; i++ {
+			delay, backingOff := txm.nextBatchDelay(txm.sendMsgBatch(testutils.Context(t)))
+			assert.True(t, backingOff)
+			assert.GreaterOrEqual(t, delay, blockRate.Duration())
+			assert.LessOrEqual(t, delay, 40*time.Millisecond)
+		}
+		assert.Equal(t, float64(failures), txm.batchBackoff.Attempt())
+
+		_, backingOff := txm.nextBatchDelay(txm.sendMsgBatch(testutils.Context(t)))
+		assert.False(t, backingOff)
+		assert.Zero(t, txm.batchBackoff.Attempt())
+		ms, err := txm.orm.GetMsgs(id1)
+		require.NoError(t, err)
+		require.Len(t, ms, 1)
+		assert.Equal(t, Confirmed, ms[0].State)
+	})
+}
+
+func TestTxm_nextBatchDelay(t *testing.T) {
+	t.Parallel()
+
+	lggr := logger.TestLogger(t)
+	blockRate, err := relayutils.NewDuration(time.Second)
+	require.NoError(t, err)
+	txm := &Txm{lggr: lggr, cfg: terra.NewConfig(ChainCfg{BlockRate: &blockRate}, lggr), maxBatchBackoff: 10 * time.Second}
+	txm.batchBackoff = txm.newBatchBackoff()
+
+	for i := 0; i < 10; i++ {
+		delay, backingOff := txm.nextBatchDelay(errors.New("rpc unavailable"))
+		require.True(t, backingOff)
+		// Jittered up to the exponential backoff, capped at the max
+		assert.GreaterOrEqual(t, delay, time.Second)
+		assert.LessOrEqual(t, delay, 10*time.Second)
+	}
+
+	// Succeeding resets to the block rate
+	delay, backingOff := txm.nextBatchDelay(nil)
+	assert.False(t, backingOff)
+	assert.Less(t, delay, 2*time.Second)
+	assert.Zero(t, txm.batchBackoff.Attempt())
+	delay, _ = txm.nextBatchDelay(errors.New("rpc unavailable"))
+	assert.LessOrEqual(t, delay, 2*time.Second)
+}
+
+func TestTxm_batchMsgLimit(t *testing.T) {
+	t.Parallel()
+
+	lggr := logger.TestLogger(t)
+	cfg := terra.NewConfig(ChainCfg{MaxMsgsPerBatch: null.IntFrom(100)}, lggr)
+	limits := func(txm *Txm, n int) []int64 {
+		var limits []int64
+		for i := 0; i < n; i++ {
+			limits = append(limits, txm.batchMsgLimit())
+		}
+		return limits
+	}
+
+	assert.Equal(t, []int64{100, 100}, limits(&Txm{lggr: lggr, cfg: cfg}, 2), "no ramp by default")
+	assert.Equal(t, []int64{10, 40, 70, 100, 100}, limits(&Txm{lggr: lggr, cfg: cfg, startupRamp: &startupRamp{batches: 3, initialMsgs: 10}}, 5))
+	assert.Equal(t, []int64{1, 100}, limits(&Txm{lggr: lggr, cfg: cfg, startupRamp: &startupRamp{batches: 1}}, 2))
+	assert.Equal(t, []int64{100, 100}, limits(&Txm{lggr: lggr, cfg: cfg, startupRamp: &startupRamp{batches: 2, initialMsgs: 500}}, 2),
+		"never above MaxMsgsPerBatch")
+}
+
+func TestTxm_notifyBroadcast(t *testing.T) {
+	t.Parallel()
+
+	txm := &Txm{lggr: logger.TestLogger(t)}
+	// Nothing to notify by default
+	txm.notifyBroadcast(BroadcastResult{TxHash: "0"})
+
+	WithOnBroadcast(func(BroadcastResult) {})(txm)
+	// A busy callback never blocks the txm, results past the buffer are dropped
+	for i := 0; i < broadcastResultsBuffer+10; i++ {
+		txm.notifyBroadcast(BroadcastResult{TxHash: fmt.Sprint(i)})
+	}
+	require.Len(t, txm.broadcastHook.results, broadcastResultsBuffer)
+	assert.Equal(t, "0", (<-txm.broadcastHook.results).TxHash, "results are delivered in order")
+}
+
+func TestTxm_orderSenders(t *testing.T) {
+	now := time.Now()
+	msgsByFrom := map[string]terra.Msgs{
+		"a": {{Msg: Msg{ID: 3, CreatedAt: now}}},
+		"b": {{Msg: Msg{ID: 1, CreatedAt: now.Add(-time.Minute)}}, {Msg: Msg{ID: 4, CreatedAt: now}}},
+		"c": {{Msg: Msg{ID: 2, CreatedAt: now}}},
+		"d": {{Msg: Msg{ID: 5, CreatedAt: now.Add(time.Minute)}}},
+	}
+	txm := &Txm{}
+	assert.Equal(t, []string{"b", "c", "a", "d"}, txm.orderSenders(msgsByFrom))
+
+	txm.deferredSenders = map[string]struct{}{"a": {}, "d": {}}
+	assert.Equal(t, []string{"a", "d", "b", "c"}, txm.orderSenders(msgsByFrom))
+}
+
+func TestTxm_account(t *testing.T) {
+	t.Parallel()
+
+	sender := cosmostypes.AccAddress([]byte("sender______________"))
+	other := cosmostypes.AccAddress([]byte("other_______________"))
+
+	t.Run("disabled", func(t *testing.T) {
+		tc := newReaderWriterMock(t)
+		tc.On("Account", sender).Return(uint64(3), uint64(5), nil).Twice()
+		txm := &Txm{clock: realClock{}}
+		for i := 0; i < 2; i++ {
+			an, sn, err := txm.account(tc, sender)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(3), an)
+			assert.Equal(t, uint64(5), sn)
+			txm.recordSendResult(nil, &senderTx{sender: sender, accountNumber: an, sequence: sn})
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		tc := newReaderWriterMock(t)
+		txm := &Txm{clock: realClock{}}
+		WithSequenceCache()(txm)
+
+		tc.On("Account", sender).Return(uint64(0), uint64(0), errors.New("rpc unavailable")).Once()
+		_, _, err := txm.account(tc, sender)
+		require.Error(t, err)
+
+		tc.On("Account", sender).Return(uint64(3), uint64(5), nil).Once()
+		tc.On("Account", other).Return(uint64(4), uint64(1), nil).Once()
+		for _, expected := range []uint64{5, 6, 7} {
+			an, sn, err := txm.account(tc, sender)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(3), an)
+			assert.Equal(t, expected, sn)
+			_, otherSn, err := txm.account(tc, other)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(1), otherSn)
+			txm.recordSendResult(nil, &senderTx{sender: sender, accountNumber: an, sequence: sn})
+		}
+
+		// Failing to send resyncs from chain
+		txm.recordSendResult(errors.New("account sequence mismatch"), &senderTx{sender: sender, accountNumber: 3, sequence: 8})
+		tc.On("Account", sender).Return(uint64(3), uint64(10), nil).Once()
+		_, sn, err := txm.account(tc, sender)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(10), sn)
+		_, otherSn, err := txm.account(tc, other)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), otherSn)
+	})
+}
+
+func TestTxm_SequenceDrift(t *testing.T) {
+	t.Parallel()
+
+	sender1 := cosmostypes.AccAddress([]byte("sender1_____________"))
+	sender2 := cosmostypes.AccAddress([]byte("sender2_____________"))
+	tc := newReaderWriterMock(t)
+	cfg := DefaultHealthConfig()
+	cfg.MaxSequenceDrift = 2
+	clock := newFakeClock()
+	txm := &Txm{
+		orm:       &ORM{chainID: "Chainlinktest-42"},
+		lggr:      logger.TestLogger(t),
+		tc:        func() (terraclient.ReaderWriter, error) { return tc, nil },
+		healthCfg: cfg,
+		health:    batchHealth{lastSuccess: clock.Now()},
+		clock:     clock,
+	}
+	require.NoError(t, txm.starter.StartOnce("terratxm", func() error { return nil }))
+
+	drift, err := txm.SequenceDrift()
+	require.NoError(t, err)
+	assert.Empty(t, drift)
+
+	// sender1 sent a tx with sequence 5, sender2 failed to send one with sequence 9
+	txm.recordSendResult(nil, &senderTx{sender: sender1, accountNumber: 1, sequence: 5})
+	txm.recordSendResult(errors.New("account sequence mismatch"), &senderTx{sender: sender2, accountNumber: 2, sequence: 9})
+
+	tc.On("Account", sender1).Return(uint64(1), uint64(6), nil).Once()
+	tc.On("Account", sender2).Return(uint64(2), uint64(9), nil).Once()
+	drift, err = txm.SequenceDrift()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{sender1.String(): 0, sender2.String(): 0}, drift)
+	assert.NoError(t, txm.Healthy())
+
+	// Txs were sent from sender2 by someone else
+	tc.On("Account", sender1).Return(uint64(1), uint64(6), nil).Once()
+	tc.On("Account", sender2).Return(uint64(2), uint64(12), nil).Once()
+	drift, err = txm.SequenceDrift()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{sender1.String(): 0, sender2.String(): 3}, drift)
+	assert.EqualError(t, txm.Healthy(), fmt.Sprintf("sequence of %s drifted by 3 from the expected sequence, exceeding limit of 2", sender2))
+
+	// The drift of senders which can't be read is kept until they can
+	tc.On("Account", sender1).Return(uint64(1), uint64(4), nil).Once()
+	tc.On("Account", sender2).Return(uint64(0), uint64(0), errors.New("rpc unavailable")).Once()
+	drift, err = txm.SequenceDrift()
+	require.Error(t, err)
+	assert.Equal(t, map[string]int64{sender1.String(): -2}, drift)
+	assert.Error(t, txm.Healthy())
+
+	// Resynced after sending from sender2 again
+	txm.recordSendResult(nil, &senderTx{sender: sender2, accountNumber: 2, sequence: 12})
+	tc.On("Account", sender1).Return(uint64(1), uint64(6), nil).Once()
+	tc.On("Account", sender2).Return(uint64(2), uint64(13), nil).Once()
+	_, err = txm.SequenceDrift()
+	require.NoError(t, err)
+	assert.NoError(t, txm.Healthy())
+
+	// Senders not sent from recently are no longer checked
+	clock.Advance(2 * sequenceDriftWindow)
+	txm.recordBatchResult(nil, 0, time.Time{}, true)
+	drift, err = txm.SequenceDrift()
+	require.NoError(t, err)
+	assert.Empty(t, drift)
+	assert.NoError(t, txm.Healthy())
+}
+
+func TestSignMultiSignerTx(t *testing.T) {
+	k1, k2 := terrakey.New(), terrakey.New()
+	sender1, sender2 := cosmostypes.AccAddress(k1.PublicKey().Address()), cosmostypes.AccAddress(k2.PublicKey().Address())
+	contract, err := cosmostypes.AccAddressFromBech32("terra1pp76d50yv2ldaahsdxdv8mmzqfjr2ax97gmue8")
+	require.NoError(t, err)
+	stxs := []*senderTx{
+		{sender: sender1, key: k1, accountNumber: 1, sequence: 3, gasLimit: 100_000, msgs: terraclient.SimMsgs{
+			{ID: 1, Msg: generateExecuteMsg(t, []byte(`1`), sender1, contract)},
+			{ID: 2, Msg: generateExecuteMsg(t, []byte(`2`), sender1, contract)},
+		}},
+		{sender: sender2, key: k2, accountNumber: 2, sequence: 7, gasLimit: 50_000, msgs: terraclient.SimMsgs{
+			{ID: 3, Msg: generateExecuteMsg(t, []byte(`3`), sender2, contract)},
+		}},
+	}
+	gasPrice := cosmostypes.NewDecCoinFromDec("uluna", cosmostypes.MustNewDecFromStr("0.01"))
+	txBytes, err := signMultiSignerTx("Chainlinktest-42", stxs, 1.5, gasPrice, 123, "order-42", nil)
+	require.NoError(t, err)
+
+	encodingConfig := params.MakeEncodingConfig()
+	std.RegisterInterfaces(encodingConfig.InterfaceRegistry)
+	wasmtypes.RegisterInterfaces(encodingConfig.InterfaceRegistry)
+	decoded, err := encodingConfig.TxConfig.TxDecoder()(txBytes)
+	require.NoError(t, err)
+	tx, ok := decoded.(authsigning.Tx)
+	require.True(t, ok)
+
+	require.Len(t, tx.GetMsgs(), 3)
+	assert.Equal(t, []cosmostypes.AccAddress{sender1, sender2}, tx.GetSigners())
+	assert.Equal(t, sender1, tx.FeePayer())
+	assert.Equal(t, uint64(225_000), tx.GetGas())
+	assert.Equal(t, cosmostypes.NewCoins(cosmostypes.NewInt64Coin("uluna", 2250)), tx.GetFee())
+	assert.Equal(t, uint64(123), tx.GetTimeoutHeight())
+	assert.Equal(t, "order-42", tx.GetMemo())
+	assert.True(t, tx.FeeGranter().Empty())
+
+	sigs, err := tx.GetSignaturesV2()
+	require.NoError(t, err)
+	require.Len(t, sigs, 2)
+	for i, stx := range stxs {
+		assert.True(t, stx.key.PublicKey().Equals(sigs[i].PubKey))
+		assert.Equal(t, stx.sequence, sigs[i].Sequence)
+		require.NoError(t, authsigning.VerifySignature(sigs[i].PubKey, authsigning.SignerData{
+			ChainID:       "Chainlinktest-42",
+			AccountNumber: stx.accountNumber,
+			Sequence:      stx.sequence,
+		}, sigs[i].Data, encodingConfig.TxConfig.SignModeHandler(), tx))
+	}
+}
+
+func TestSignMultiSignerTx_FeeGranter(t *testing.T) {
+	k := terrakey.New()
+	sender := cosmostypes.AccAddress(k.PublicKey().Address())
+	granter := cosmostypes.AccAddress(terrakey.New().PublicKey().Address())
+	contract, err := cosmostypes.AccAddressFromBech32("terra1pp76d50yv2ldaahsdxdv8mmzqfjr2ax97gmue8")
+	require.NoError(t, err)
+	stxs := []*senderTx{
+		{sender: sender, key: k, accountNumber: 1, sequence: 3, gasLimit: 100_000, msgs: terraclient.SimMsgs{
+			{ID: 1, Msg: generateExecuteMsg(t, []byte(`1`), sender, contract)},
+		}},
+	}
+	gasPrice := cosmostypes.NewDecCoinFromDec("uluna", cosmostypes.MustNewDecFromStr("0.01"))
+	txBytes, err := signMultiSignerTx("Chainlinktest-42", stxs, 1.5, gasPrice, 123, "", granter)
+	require.NoError(t, err)
+
+	encodingConfig := params.MakeEncodingConfig()
+	std.RegisterInterfaces(encodingConfig.InterfaceRegistry)
+	wasmtypes.RegisterInterfaces(encodingConfig.InterfaceRegistry)
+	decoded, err := encodingConfig.TxConfig.TxDecoder()(txBytes)
+	require.NoError(t, err)
+	tx, ok := decoded.(authsigning.Tx)
+	require.True(t, ok)
+
+	// The sender still signs and authorizes the msgs, the granter pays the fee
+	assert.Equal(t, []cosmostypes.AccAddress{sender}, tx.GetSigners())
+	assert.Equal(t, granter, tx.FeeGranter())
+	assert.Equal(t, cosmostypes.NewCoins(cosmostypes.NewInt64Coin("uluna", 1500)), tx.GetFee())
+	sigs, err := tx.GetSignaturesV2()
+	require.NoError(t, err)
+	require.Len(t, sigs, 1)
+	require.NoError(t, authsigning.VerifySignature(sigs[0].PubKey, authsigning.SignerData{
+		ChainID:       "Chainlinktest-42",
+		AccountNumber: 1,
+		Sequence:      3,
+	}, sigs[0].Data, encodingConfig.TxConfig.SignModeHandler(), tx))
 }
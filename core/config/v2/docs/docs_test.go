@@ -97,6 +97,12 @@ func TestDoc(t *testing.T) {
 		fallbackDefaults.SetDefaults()
 
 		assertTOML(t, fallbackDefaults.Chain, defaults.Terra[0].Chain)
+
+		// MaxFee has no default, fees aren't capped
+		docTxManager := defaults.Terra[0].TxManager
+		require.Zero(t, *docTxManager.MaxFee)
+		docTxManager.MaxFee = nil
+		assertTOML(t, fallbackDefaults.TxManager, docTxManager)
 	})
 }
 
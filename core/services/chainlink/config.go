@@ -84,7 +84,7 @@ func (c *Config) setDefaults() {
 		if c.Terra[i] == nil {
 			c.Terra[i] = new(terra.TerraConfig)
 		}
-		c.Terra[i].Chain.SetDefaults()
+		c.Terra[i].SetDefaults()
 	}
 }
 
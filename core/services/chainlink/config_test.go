@@ -597,6 +597,20 @@ func TestConfig_Marshal(t *testing.T) {
 				OCR2CacheTTL:          relayutils.MustNewDuration(time.Hour),
 				TxMsgTimeout:          relayutils.MustNewDuration(time.Second),
 			},
+			TxManager: terra.TxManager{
+				BatchDeadline:          relayutils.MustNewDuration(30 * time.Second),
+				ConfirmationDepth:      ptr[int64](2),
+				MaxFee:                 ptr("5000000uluna"),
+				StartupRampBatches:     ptr[int64](10),
+				StartupRampInitialMsgs: ptr[int64](5),
+				Health: terra.TxManagerHealth{
+					MaxConsecutiveFailures: ptr[int64](7),
+					MaxUnstartedBacklog:    ptr[int64](500),
+					StaleBatchTimeout:      relayutils.MustNewDuration(5 * time.Minute),
+					MaxUnstartedAge:        relayutils.MustNewDuration(15 * time.Minute),
+					MaxSequenceDrift:       ptr[int64](4),
+				},
+			},
 			Nodes: []*tercfg.Node{
 				{Name: ptr("primary"), TendermintURL: relayutils.MustParseURL("http://tender.mint")},
 				{Name: ptr("foo"), TendermintURL: relayutils.MustParseURL("http://foo.url")},
@@ -964,6 +978,20 @@ OCR2CachePollPeriod = '1m0s'
 OCR2CacheTTL = '1h0m0s'
 TxMsgTimeout = '1s'
 
+[Terra.TxManager]
+BatchDeadline = '30s'
+ConfirmationDepth = 2
+MaxFee = '5000000uluna'
+StartupRampBatches = 10
+StartupRampInitialMsgs = 5
+
+[Terra.TxManager.Health]
+MaxConsecutiveFailures = 7
+MaxUnstartedBacklog = 500
+StaleBatchTimeout = '5m0s'
+MaxUnstartedAge = '15m0s'
+MaxSequenceDrift = 4
+
 [[Terra.Nodes]]
 Name = 'primary'
 TendermintURL = 'http://tender.mint'
@@ -4,4 +4,6 @@ package pg
 const (
 	ChannelInsertOnEthTx    = "insert_on_eth_txes"
 	ChannelInsertOnTerraMsg = "insert_on_terra_msg"
+	// ChannelTerraMsgConfirmed is notified by the terra txm when a batch of msgs is confirmed on chain.
+	ChannelTerraMsgConfirmed = "terra_msg_confirmed"
 )
@@ -54,9 +54,13 @@ type (
 		Inputs() []TaskDependency
 		OutputIndex() int32
 		TaskTimeout() (time.Duration, bool)
+		RequestTimeout() (time.Duration, bool)
+		Disabled() bool
+		OutputName() (string, bool)
 		TaskRetries() uint32
 		TaskMinBackoff() time.Duration
 		TaskMaxBackoff() time.Duration
+		RetryPolicy() (RetryPolicy, bool)
 	}
 
 	Config interface {
@@ -97,6 +101,21 @@ const (
 	InputTaskKey = "input"
 )
 
+// ReservedTaskNames can't be used as task names, regardless of case, as the engine relies on them: a task's result is
+// stored in the run's vars under its name, so it would shadow the vars jobs pass in. Integrations passing in other top
+// level vars can add them at init.
+var ReservedTaskNames = []string{InputTaskKey, "jobSpec", "jobRun", "jb"}
+
+// checkTaskName returns an error listing the ReservedTaskNames if dotID is one of them.
+func checkTaskName(dotID string) error {
+	for _, reserved := range ReservedTaskNames {
+		if strings.EqualFold(dotID, reserved) {
+			return errors.Errorf("task name %q is reserved, task names can't be any of (case-insensitive): %s", dotID, strings.Join(ReservedTaskNames, ", "))
+		}
+	}
+	return nil
+}
+
 // RunInfo contains additional information about the finished TaskRun
 type RunInfo struct {
 	IsRetryable bool
@@ -384,6 +403,55 @@ var (
 	nullUint32Type = reflect.TypeOf(cnull.Uint32{})
 )
 
+// taskConstructors holds the task types recognized by UnmarshalTaskFromMap.
+var taskConstructors = map[TaskType]func(base BaseTask) Task{
+	TaskTypePanic:            func(base BaseTask) Task { return &PanicTask{BaseTask: base} },
+	TaskTypeHTTP:             func(base BaseTask) Task { return &HTTPTask{BaseTask: base} },
+	TaskTypeBridge:           func(base BaseTask) Task { return &BridgeTask{BaseTask: base} },
+	TaskTypeMean:             func(base BaseTask) Task { return &MeanTask{BaseTask: base} },
+	TaskTypeMedian:           func(base BaseTask) Task { return &MedianTask{BaseTask: base} },
+	TaskTypeMode:             func(base BaseTask) Task { return &ModeTask{BaseTask: base} },
+	TaskTypeSum:              func(base BaseTask) Task { return &SumTask{BaseTask: base} },
+	TaskTypeAny:              func(base BaseTask) Task { return &AnyTask{BaseTask: base} },
+	TaskTypeJSONParse:        func(base BaseTask) Task { return &JSONParseTask{BaseTask: base} },
+	TaskTypeMemo:             func(base BaseTask) Task { return &MemoTask{BaseTask: base} },
+	TaskTypeMultiply:         func(base BaseTask) Task { return &MultiplyTask{BaseTask: base} },
+	TaskTypeDivide:           func(base BaseTask) Task { return &DivideTask{BaseTask: base} },
+	TaskTypeVRF:              func(base BaseTask) Task { return &VRFTask{BaseTask: base} },
+	TaskTypeVRFV2:            func(base BaseTask) Task { return &VRFTaskV2{BaseTask: base} },
+	TaskTypeEstimateGasLimit: func(base BaseTask) Task { return &EstimateGasLimitTask{BaseTask: base} },
+	TaskTypeETHCall:          func(base BaseTask) Task { return &ETHCallTask{BaseTask: base} },
+	TaskTypeETHGetBlock:      func(base BaseTask) Task { return &ETHGetBlockTask{BaseTask: base} },
+	TaskTypeETHTx:            func(base BaseTask) Task { return &ETHTxTask{BaseTask: base} },
+	TaskTypeETHABIEncode:     func(base BaseTask) Task { return &ETHABIEncodeTask{BaseTask: base} },
+	TaskTypeETHABIEncode2:    func(base BaseTask) Task { return &ETHABIEncodeTask2{BaseTask: base} },
+	TaskTypeETHABIDecode:     func(base BaseTask) Task { return &ETHABIDecodeTask{BaseTask: base} },
+	TaskTypeETHABIDecodeLog:  func(base BaseTask) Task { return &ETHABIDecodeLogTask{BaseTask: base} },
+	TaskTypeCBORParse:        func(base BaseTask) Task { return &CBORParseTask{BaseTask: base} },
+	TaskTypeFail:             func(base BaseTask) Task { return &FailTask{BaseTask: base} },
+	TaskTypeMerge:            func(base BaseTask) Task { return &MergeTask{BaseTask: base} },
+	TaskTypeLength:           func(base BaseTask) Task { return &LengthTask{BaseTask: base} },
+	TaskTypeLessThan:         func(base BaseTask) Task { return &LessThanTask{BaseTask: base} },
+	TaskTypeLookup:           func(base BaseTask) Task { return &LookupTask{BaseTask: base} },
+	TaskTypeLowercase:        func(base BaseTask) Task { return &LowercaseTask{BaseTask: base} },
+	TaskTypeUppercase:        func(base BaseTask) Task { return &UppercaseTask{BaseTask: base} },
+	TaskTypeConditional:      func(base BaseTask) Task { return &ConditionalTask{BaseTask: base} },
+	TaskTypeHexDecode:        func(base BaseTask) Task { return &HexDecodeTask{BaseTask: base} },
+	TaskTypeHexEncode:        func(base BaseTask) Task { return &HexEncodeTask{BaseTask: base} },
+	TaskTypeBase64Decode:     func(base BaseTask) Task { return &Base64DecodeTask{BaseTask: base} },
+	TaskTypeBase64Encode:     func(base BaseTask) Task { return &Base64EncodeTask{BaseTask: base} },
+}
+
+// RegisteredTaskTypes returns the task types recognized by UnmarshalTaskFromMap, sorted by name.
+func RegisteredTaskTypes() []TaskType {
+	types := make([]TaskType, 0, len(taskConstructors))
+	for taskType := range taskConstructors {
+		types = append(types, taskType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
 func UnmarshalTaskFromMap(taskType TaskType, taskMap interface{}, ID int, dotID string) (_ Task, err error) {
 	defer utils.WrapIfError(&err, "UnmarshalTaskFromMap")
 
@@ -395,81 +463,11 @@ func UnmarshalTaskFromMap(taskType TaskType, taskMap interface{}, ID int, dotID
 
 	taskType = TaskType(strings.ToLower(string(taskType)))
 
-	var task Task
-	switch taskType {
-	case TaskTypePanic:
-		task = &PanicTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeHTTP:
-		task = &HTTPTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeBridge:
-		task = &BridgeTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeMean:
-		task = &MeanTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeMedian:
-		task = &MedianTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeMode:
-		task = &ModeTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeSum:
-		task = &SumTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeAny:
-		task = &AnyTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeJSONParse:
-		task = &JSONParseTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeMemo:
-		task = &MemoTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeMultiply:
-		task = &MultiplyTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeDivide:
-		task = &DivideTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeVRF:
-		task = &VRFTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeVRFV2:
-		task = &VRFTaskV2{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeEstimateGasLimit:
-		task = &EstimateGasLimitTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeETHCall:
-		task = &ETHCallTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeETHGetBlock:
-		task = &ETHGetBlockTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeETHTx:
-		task = &ETHTxTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeETHABIEncode:
-		task = &ETHABIEncodeTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeETHABIEncode2:
-		task = &ETHABIEncodeTask2{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeETHABIDecode:
-		task = &ETHABIDecodeTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeETHABIDecodeLog:
-		task = &ETHABIDecodeLogTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeCBORParse:
-		task = &CBORParseTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeFail:
-		task = &FailTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeMerge:
-		task = &MergeTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeLength:
-		task = &LengthTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeLessThan:
-		task = &LessThanTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeLookup:
-		task = &LookupTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeLowercase:
-		task = &LowercaseTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeUppercase:
-		task = &UppercaseTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeConditional:
-		task = &ConditionalTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeHexDecode:
-		task = &HexDecodeTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeHexEncode:
-		task = &HexEncodeTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeBase64Decode:
-		task = &Base64DecodeTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	case TaskTypeBase64Encode:
-		task = &Base64EncodeTask{BaseTask: BaseTask{id: ID, dotID: dotID}}
-	default:
+	newTask, ok := taskConstructors[taskType]
+	if !ok {
 		return nil, errors.Errorf(`unknown task type: "%v"`, taskType)
 	}
+	task := newTask(BaseTask{id: ID, dotID: dotID})
 
 	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
 		Result:           task,
@@ -495,6 +493,15 @@ func UnmarshalTaskFromMap(taskType TaskType, taskMap interface{}, ID int, dotID
 
 	err = decoder.Decode(taskMap)
 	if err != nil {
+		return nil, errors.Wrapf(err, "task %s", dotID)
+	}
+	if requestTimeout, set := task.RequestTimeout(); set {
+		// A timeout of 0 means unlimited
+		if timeout, timeoutSet := task.TaskTimeout(); timeoutSet && timeout > 0 && requestTimeout > timeout {
+			return nil, errors.Errorf("task %s: requestTimeout (%s) must not exceed timeout (%s)", dotID, requestTimeout, timeout)
+		}
+	}
+	if err = task.Base().validateRetryPolicy(); err != nil {
 		return nil, err
 	}
 	return task, nil
@@ -90,6 +90,11 @@ func bestEffortExtractError(responseBytes []byte) string {
 }
 
 func httpRequestCtx(ctx context.Context, t Task, cfg Config) (requestCtx context.Context, cancel context.CancelFunc) {
+	// An explicit request timeout always applies, and is validated to not
+	// exceed the task timeout, unless unlimited, when the task is parsed.
+	if requestTimeout, isSet := t.RequestTimeout(); isSet && requestTimeout > 0 {
+		return context.WithTimeout(ctx, requestTimeout)
+	}
 	// Only set the default timeout if the task timeout is missing; task
 	// timeout if present will have already been set on the context at a higher
 	// level. If task timeout is explicitly set to zero, we must not override
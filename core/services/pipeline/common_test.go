@@ -38,6 +38,41 @@ func TestTimeoutAttribute(t *testing.T) {
 	assert.Equal(t, false, set)
 }
 
+func TestRequestTimeoutAttribute(t *testing.T) {
+	t.Parallel()
+
+	a := `ds1 [type=http method=GET url="https://chain.link/voter_turnout/USA-2020" requestTimeout="2s" timeout="10s"];`
+	p, err := pipeline.Parse(a)
+	require.NoError(t, err)
+	timeout, set := p.Tasks[0].RequestTimeout()
+	assert.Equal(t, cltest.MustParseDuration(t, "2s"), timeout)
+	assert.Equal(t, true, set)
+
+	a = `ds1 [type=http method=GET url="https://chain.link/voter_turnout/USA-2020"];`
+	p, err = pipeline.Parse(a)
+	require.NoError(t, err)
+	timeout, set = p.Tasks[0].RequestTimeout()
+	assert.Equal(t, cltest.MustParseDuration(t, "0s"), timeout)
+	assert.Equal(t, false, set)
+
+	a = `ds1 [type=http method=GET url="https://chain.link/voter_turnout/USA-2020" requestTimeout="20s" timeout="10s"];`
+	_, err = pipeline.Parse(a)
+	require.EqualError(t, err, "UnmarshalTaskFromMap: task ds1: requestTimeout (20s) must not exceed timeout (10s)")
+
+	a = `ds1 [type=http method=GET url="https://chain.link/voter_turnout/USA-2020" requestTimeout="5s" timeout="0s"];`
+	p, err = pipeline.Parse(a)
+	require.NoError(t, err, "a timeout of 0 is unlimited")
+	timeout, set = p.Tasks[0].RequestTimeout()
+	assert.Equal(t, cltest.MustParseDuration(t, "5s"), timeout)
+	assert.Equal(t, true, set)
+
+	a = `ds1 [type=http method=GET url="https://chain.link/voter_turnout/USA-2020" requestTimeout="soon"];`
+	_, err = pipeline.Parse(a)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "task ds1")
+	require.Contains(t, err.Error(), "requestTimeout")
+}
+
 func TestTaskHTTPUnmarshal(t *testing.T) {
 	t.Parallel()
 
@@ -109,6 +144,59 @@ func TestRetryUnmarshal(t *testing.T) {
 	}
 }
 
+func TestBaseTask_RetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name   string
+		spec   string
+		policy pipeline.RetryPolicy
+		set    bool
+	}{
+		{"nothing specified", `ds1 [type=any];`, pipeline.RetryPolicy{MinBackoff: 5 * time.Second, MaxBackoff: time.Minute}, false},
+		{"only retries", `ds1 [type=any retries=3];`, pipeline.RetryPolicy{Retries: 3, MinBackoff: 5 * time.Second, MaxBackoff: time.Minute}, true},
+		{"no retries", `ds1 [type=any retries=0];`, pipeline.RetryPolicy{MinBackoff: 5 * time.Second, MaxBackoff: time.Minute}, true},
+		{"all set", `ds1 [type=any retries=10 minBackoff="1s" maxBackoff="30m"];`, pipeline.RetryPolicy{Retries: 10, MinBackoff: time.Second, MaxBackoff: 30 * time.Minute}, true},
+		{"equal backoffs", `ds1 [type=any retries=2 minBackoff="10s" maxBackoff="10s"];`, pipeline.RetryPolicy{Retries: 2, MinBackoff: 10 * time.Second, MaxBackoff: 10 * time.Second}, true},
+		{"only minBackoff", `ds1 [type=any retries=1 minBackoff="1s"];`, pipeline.RetryPolicy{Retries: 1, MinBackoff: time.Second, MaxBackoff: time.Minute}, true},
+		{"minBackoff above default", `ds1 [type=any retries=1 minBackoff="5m"];`, pipeline.RetryPolicy{Retries: 1, MinBackoff: 5 * time.Minute, MaxBackoff: 5 * time.Minute}, true},
+		{"only maxBackoff", `ds1 [type=any maxBackoff="2m"];`, pipeline.RetryPolicy{MinBackoff: 5 * time.Second, MaxBackoff: 2 * time.Minute}, true},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			p, err := pipeline.Parse(test.spec)
+			require.NoError(t, err)
+			policy, set := p.Tasks[0].RetryPolicy()
+			assert.Equal(t, test.policy, policy)
+			assert.Equal(t, test.set, set)
+			assert.Equal(t, policy.MinBackoff, p.Tasks[0].TaskMinBackoff())
+			assert.Equal(t, policy.MaxBackoff, p.Tasks[0].TaskMaxBackoff())
+		})
+	}
+
+	for _, test := range []struct {
+		name string
+		spec string
+		err  string
+	}{
+		{"negative retries", `ds1 [type=any retries=-1];`, "error decoding 'retries'"},
+		{"invalid retries", `ds1 [type=any retries=many];`, "error decoding 'retries'"},
+		{"invalid minBackoff", `ds1 [type=any minBackoff=soon];`, "error decoding 'minBackoff'"},
+		{"invalid maxBackoff", `ds1 [type=any maxBackoff=later];`, "error decoding 'maxBackoff'"},
+		{"negative minBackoff", `ds1 [type=any minBackoff="-1s"];`, "task ds1: minBackoff must not be negative, got -1s"},
+		{"negative maxBackoff", `ds1 [type=any maxBackoff="-1s"];`, "task ds1: maxBackoff must not be negative, got -1s"},
+		{"minBackoff above maxBackoff", `ds1 [type=any minBackoff="1m" maxBackoff="10s"];`, "task ds1: minBackoff (1m0s) must not exceed maxBackoff (10s)"},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := pipeline.Parse(test.spec)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+			assert.Contains(t, err.Error(), "task ds1")
+		})
+	}
+}
+
 func TestUnmarshalTaskFromMap(t *testing.T) {
 	t.Parallel()
 
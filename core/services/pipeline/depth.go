@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseLimits bounds the shape of the pipelines accepted by ParseWithLimits. Zero values are unlimited.
+type ParseLimits struct {
+	// MaxDepth is the highest Depth of a pipeline, e.g. to keep long dependency chains within the execution budget.
+	MaxDepth int
+}
+
+// ParseWithLimits parses the pipeline like Parse, and rejects it if it exceeds limits. The error of a pipeline too
+// deep names its deepest path, see Pipeline.DeepestPath.
+func ParseWithLimits(text string, limits ParseLimits) (*Pipeline, error) {
+	p, err := Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.validateDepth(limits.MaxDepth); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// validateDepth returns an error naming the deepest path of the pipeline if its Depth exceeds maxDepth, unless maxDepth
+// is 0.
+func (p *Pipeline) validateDepth(maxDepth int) error {
+	if maxDepth <= 0 {
+		return nil
+	}
+	if path := p.DeepestPath(); len(path) > maxDepth {
+		return errors.Errorf("pipeline depth %d exceeds the maximum of %d: %s", len(path), maxDepth,
+			strings.Join(path, " -> "))
+	}
+	return nil
+}
+
+// Depth returns the number of tasks along the longest path through the pipeline, 0 if it has no tasks. Like
+// GraphStats.MaxDepth, implicit dependencies on a task's result count as much as explicit edges.
+func (p *Pipeline) Depth() int {
+	return len(p.DeepestPath())
+}
+
+// DeepestPath returns the dotIDs of the tasks along the longest path through the pipeline, from a task without inputs
+// to a task without outputs. Of several paths as long, the one ending with the first task is returned.
+func (p *Pipeline) DeepestPath() []string {
+	// Tasks are sorted topologically, so the depth of each task's inputs is known by the time it's visited
+	indexes := make(map[Task]int, len(p.Tasks))
+	depths := make([]int, len(p.Tasks))
+	prev := make([]int, len(p.Tasks))
+	deepest := -1
+	for i, task := range p.Tasks {
+		indexes[task] = i
+		depths[i], prev[i] = 1, -1
+		for _, input := range task.Inputs() {
+			if j, ok := indexes[input.InputTask]; ok && depths[j]+1 > depths[i] {
+				depths[i], prev[i] = depths[j]+1, j
+			}
+		}
+		if deepest < 0 || depths[i] > depths[deepest] {
+			deepest = i
+		}
+	}
+	if deepest < 0 {
+		return nil
+	}
+	path := make([]string, depths[deepest])
+	for i, j := len(path)-1, deepest; j >= 0; i, j = i-1, prev[j] {
+		path[i] = p.Tasks[j].DotID()
+	}
+	return path
+}
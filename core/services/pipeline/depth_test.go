@@ -0,0 +1,82 @@
+package pipeline_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink/core/services/pipeline"
+)
+
+func TestPipeline_Depth(t *testing.T) {
+	t.Parallel()
+
+	t.Run("linear chain", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			a [type=memo value=1];
+			b [type=multiply times=2];
+			c [type=multiply times=3];
+			d [type=multiply times=4];
+			a -> b -> c -> d;
+		`)
+		require.NoError(t, err)
+		assert.Equal(t, 4, p.Depth())
+		assert.Equal(t, []string{"a", "b", "c", "d"}, p.DeepestPath())
+	})
+
+	t.Run("wide and shallow", func(t *testing.T) {
+		var spec strings.Builder
+		for i := 0; i < 20; i++ {
+			fmt.Fprintf(&spec, "ds%d [type=memo value=%d];\nds%d -> answer;\n", i, i, i)
+		}
+		spec.WriteString("answer [type=median];\n")
+		p, err := pipeline.Parse(spec.String())
+		require.NoError(t, err)
+		assert.Equal(t, 2, p.Depth())
+		assert.Equal(t, []string{"ds0", "answer"}, p.DeepestPath())
+	})
+
+	t.Run("implicit dependencies", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			a [type=memo value=1];
+			b [type=memo value=2];
+			c [type=multiply input="$(b)" times="$(a)"];
+			a -> b;
+		`)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, p.DeepestPath())
+	})
+
+	t.Run("no tasks", func(t *testing.T) {
+		assert.Zero(t, (&pipeline.Pipeline{}).Depth())
+	})
+}
+
+func TestParseWithLimits(t *testing.T) {
+	t.Parallel()
+
+	const spec = `
+		ds1       [type=http method=GET url="https://a.example"];
+		ds1_parse [type=jsonparse path="data"];
+		ds1_mul   [type=multiply times=100];
+		ds2       [type=http method=GET url="https://b.example"];
+		answer    [type=median];
+		ds1 -> ds1_parse -> ds1_mul -> answer;
+		ds2 -> answer;
+	`
+	p, err := pipeline.ParseWithLimits(spec, pipeline.ParseLimits{MaxDepth: 4})
+	require.NoError(t, err)
+	assert.Equal(t, 4, p.Depth())
+
+	_, err = pipeline.ParseWithLimits(spec, pipeline.ParseLimits{})
+	require.NoError(t, err, "unlimited by default")
+
+	_, err = pipeline.ParseWithLimits(spec, pipeline.ParseLimits{MaxDepth: 3})
+	require.EqualError(t, err, "pipeline depth 4 exceeds the maximum of 3: ds1 -> ds1_parse -> ds1_mul -> answer")
+
+	_, err = pipeline.ParseWithLimits(`a [type=nope];`, pipeline.ParseLimits{MaxDepth: 3})
+	require.ErrorContains(t, err, `unknown task type: "nope"`)
+}
@@ -0,0 +1,223 @@
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PipelineDiff lists the changes between two pipelines, see DiffPipelines. All lists are sorted, so the diff of two
+// pipelines is always the same.
+type PipelineDiff struct {
+	// GraphAttributes are the changes to the attributes of the graph itself, e.g. its timeout, sorted by key.
+	GraphAttributes []AttributeChange
+	// AddedTasks are the dotIDs of the tasks only in the new pipeline.
+	AddedTasks []string
+	// RemovedTasks are the dotIDs of the tasks only in the old pipeline.
+	RemovedTasks []string
+	// ChangedTasks are the tasks in both pipelines whose attributes changed, sorted by dotID.
+	ChangedTasks []TaskDiff
+	// AddedEdges are the explicit edges only in the new pipeline, including those of added tasks.
+	AddedEdges []PipelineEdge
+	// RemovedEdges are the explicit edges only in the old pipeline, including those of removed tasks.
+	RemovedEdges []PipelineEdge
+}
+
+// TaskDiff lists the attribute changes of a task, sorted by key.
+type TaskDiff struct {
+	DotID      string
+	Attributes []AttributeChange
+}
+
+// AttributeChange is an attribute added, removed or modified. Keys are lowercase, as in the parsed pipeline.
+type AttributeChange struct {
+	Key string
+	// Old is the value in the old pipeline, nil if the attribute was added.
+	Old *string
+	// New is the value in the new pipeline, nil if the attribute was removed.
+	New *string
+}
+
+// PipelineEdge is an explicit edge between two tasks, by dotID.
+type PipelineEdge struct {
+	From, To string
+}
+
+func (e PipelineEdge) String() string {
+	return e.From + " -> " + e.To
+}
+
+// DiffPipelines compares two parsed pipelines, matching tasks by dotID, e.g. to review the changes of an edited spec
+// before applying it. Only the parsed pipelines are compared, so cosmetic changes to the source, like reordering
+// statements or quoting values differently, aren't reported. Edges are the explicit ones, as listed by DOT: implicit
+// dependencies follow from the variables in the attributes, so rewiring them is reported as an attribute change.
+// A nil pipeline has no tasks, e.g. to diff a new spec against nothing.
+func DiffPipelines(old, updated *Pipeline) PipelineDiff {
+	var diff PipelineDiff
+	diff.GraphAttributes = diffAttributes(old.graphAttributes(), updated.graphAttributes())
+
+	oldTasks, newTasks := old.attributesByDotID(), updated.attributesByDotID()
+	for dotID := range oldTasks {
+		if _, ok := newTasks[dotID]; !ok {
+			diff.RemovedTasks = append(diff.RemovedTasks, dotID)
+		}
+	}
+	for dotID, attrs := range newTasks {
+		oldAttrs, ok := oldTasks[dotID]
+		if !ok {
+			diff.AddedTasks = append(diff.AddedTasks, dotID)
+			continue
+		}
+		if changes := diffAttributes(oldAttrs, attrs); len(changes) > 0 {
+			diff.ChangedTasks = append(diff.ChangedTasks, TaskDiff{DotID: dotID, Attributes: changes})
+		}
+	}
+	sort.Strings(diff.AddedTasks)
+	sort.Strings(diff.RemovedTasks)
+	sort.Slice(diff.ChangedTasks, func(i, j int) bool { return diff.ChangedTasks[i].DotID < diff.ChangedTasks[j].DotID })
+
+	oldEdges, newEdges := old.explicitEdges(), updated.explicitEdges()
+	for edge := range oldEdges {
+		if !newEdges[edge] {
+			diff.RemovedEdges = append(diff.RemovedEdges, edge)
+		}
+	}
+	for edge := range newEdges {
+		if !oldEdges[edge] {
+			diff.AddedEdges = append(diff.AddedEdges, edge)
+		}
+	}
+	sortEdges(diff.AddedEdges)
+	sortEdges(diff.RemovedEdges)
+	return diff
+}
+
+// Empty returns true if the pipelines are the same.
+func (d PipelineDiff) Empty() bool {
+	return len(d.GraphAttributes) == 0 && len(d.AddedTasks) == 0 && len(d.RemovedTasks) == 0 &&
+		len(d.ChangedTasks) == 0 && len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0
+}
+
+// String returns the diff as a line per change, in the order of the PipelineDiff fields, e.g.
+//
+//	graph: changed timeout from "10s" to "20s"
+//	added task ds3
+//	removed task ds1
+//	task ds2: changed url from "https://a.example" to "https://b.example"
+//	task ds2: added method "POST"
+//	added edge ds3 -> answer
+//	removed edge ds1 -> answer
+func (d PipelineDiff) String() string {
+	if d.Empty() {
+		return "no changes\n"
+	}
+	var sb strings.Builder
+	for _, change := range d.GraphAttributes {
+		sb.WriteString("graph: " + change.String() + "\n")
+	}
+	for _, dotID := range d.AddedTasks {
+		sb.WriteString("added task " + dotID + "\n")
+	}
+	for _, dotID := range d.RemovedTasks {
+		sb.WriteString("removed task " + dotID + "\n")
+	}
+	for _, task := range d.ChangedTasks {
+		for _, change := range task.Attributes {
+			sb.WriteString("task " + task.DotID + ": " + change.String() + "\n")
+		}
+	}
+	for _, edge := range d.AddedEdges {
+		sb.WriteString("added edge " + edge.String() + "\n")
+	}
+	for _, edge := range d.RemovedEdges {
+		sb.WriteString("removed edge " + edge.String() + "\n")
+	}
+	return sb.String()
+}
+
+func (c AttributeChange) String() string {
+	switch {
+	case c.Old == nil:
+		return fmt.Sprintf("added %s %q", c.Key, *c.New)
+	case c.New == nil:
+		return fmt.Sprintf("removed %s %q", c.Key, *c.Old)
+	default:
+		return fmt.Sprintf("changed %s from %q to %q", c.Key, *c.Old, *c.New)
+	}
+}
+
+// diffAttributes returns the changes from the old to the new attributes, sorted by key.
+func diffAttributes(old, updated map[string]string) []AttributeChange {
+	var changes []AttributeChange
+	for k, v := range old {
+		v := v
+		newV, ok := updated[k]
+		switch {
+		case !ok:
+			changes = append(changes, AttributeChange{Key: k, Old: &v})
+		case newV != v:
+			changes = append(changes, AttributeChange{Key: k, Old: &v, New: &newV})
+		}
+	}
+	for k, v := range updated {
+		v := v
+		if _, ok := old[k]; !ok {
+			changes = append(changes, AttributeChange{Key: k, New: &v})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// graphAttributes returns the attributes of the graph, nil if p is nil.
+func (p *Pipeline) graphAttributes() map[string]string {
+	if p == nil || p.tree == nil {
+		return nil
+	}
+	return p.tree.attrs
+}
+
+// attributesByDotID returns the attributes of each task, keyed by dotID. Tasks built without a graph have none.
+func (p *Pipeline) attributesByDotID() map[string]map[string]string {
+	if p == nil {
+		return nil
+	}
+	attrs := make(map[string]map[string]string, len(p.Tasks))
+	for _, task := range p.Tasks {
+		attrs[task.DotID()] = nil
+	}
+	if p.tree != nil {
+		for iter := p.tree.Nodes(); iter.Next(); {
+			node := iter.Node().(*GraphNode)
+			if _, ok := attrs[node.dotID]; ok {
+				attrs[node.dotID] = node.attrs
+			}
+		}
+	}
+	return attrs
+}
+
+// explicitEdges returns the set of explicit edges, nil if p is nil.
+func (p *Pipeline) explicitEdges() map[PipelineEdge]bool {
+	if p == nil {
+		return nil
+	}
+	edges := make(map[PipelineEdge]bool)
+	for _, task := range p.Tasks {
+		for _, input := range task.Inputs() {
+			if input.PropagateResult {
+				edges[PipelineEdge{From: input.InputTask.DotID(), To: task.DotID()}] = true
+			}
+		}
+	}
+	return edges
+}
+
+func sortEdges(edges []PipelineEdge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+}
@@ -0,0 +1,171 @@
+package pipeline_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink/core/services/pipeline"
+)
+
+func TestDiffPipelines(t *testing.T) {
+	t.Parallel()
+
+	const base = `
+	ds1          [type=http method=GET url="https://a.example"];
+	ds1_parse    [type=jsonparse path="data,result"];
+	ds2          [type=http method=GET url="https://b.example"];
+	ds2_parse    [type=jsonparse path="data,result"];
+	answer       [type=median];
+	ds1 -> ds1_parse -> answer;
+	ds2 -> ds2_parse -> answer;
+	`
+	parse := func(t *testing.T, spec string) *pipeline.Pipeline {
+		t.Helper()
+		p, err := pipeline.Parse(spec)
+		require.NoError(t, err)
+		return p
+	}
+	str := func(s string) *string { return &s }
+
+	for _, test := range []struct {
+		name    string
+		updated string
+		want    pipeline.PipelineDiff
+		str     string
+	}{
+		{
+			name: "cosmetic changes",
+			updated: `
+			ds2 [type="http" url=<https://b.example> METHOD=GET];
+			ds2_parse [type=jsonparse path="data,result"];
+			ds1 [type=http method=GET url="https://a.example"];
+			ds1_parse [type=jsonparse path="data,result"];
+			answer [type=median];
+			ds2 -> ds2_parse -> answer;
+			ds1 -> ds1_parse -> answer;
+			`,
+			str: "no changes\n",
+		},
+		{
+			name: "attributes",
+			updated: `
+			ds1          [type=http method=POST url="https://a.example" timeout="10s"];
+			ds1_parse    [type=jsonparse path="data,result"];
+			ds2          [type=http url="https://b.example"];
+			ds2_parse    [type=jsonparse path="data,result"];
+			answer       [type=median];
+			ds1 -> ds1_parse -> answer;
+			ds2 -> ds2_parse -> answer;
+			`,
+			want: pipeline.PipelineDiff{
+				ChangedTasks: []pipeline.TaskDiff{
+					{DotID: "ds1", Attributes: []pipeline.AttributeChange{
+						{Key: "method", Old: str("GET"), New: str("POST")},
+						{Key: "timeout", New: str("10s")},
+					}},
+					{DotID: "ds2", Attributes: []pipeline.AttributeChange{
+						{Key: "method", Old: str("GET")},
+					}},
+				},
+			},
+			str: `task ds1: changed method from "GET" to "POST"
+task ds1: added timeout "10s"
+task ds2: removed method "GET"
+`,
+		},
+		{
+			name: "tasks added and removed",
+			updated: `
+			ds1          [type=http method=GET url="https://a.example"];
+			ds1_parse    [type=jsonparse path="data,result"];
+			ds3          [type=http method=GET url="https://c.example"];
+			ds3_parse    [type=jsonparse path="data,result"];
+			answer       [type=median];
+			ds1 -> ds1_parse -> answer;
+			ds3 -> ds3_parse -> answer;
+			`,
+			want: pipeline.PipelineDiff{
+				AddedTasks:   []string{"ds3", "ds3_parse"},
+				RemovedTasks: []string{"ds2", "ds2_parse"},
+				AddedEdges:   []pipeline.PipelineEdge{{From: "ds3", To: "ds3_parse"}, {From: "ds3_parse", To: "answer"}},
+				RemovedEdges: []pipeline.PipelineEdge{{From: "ds2", To: "ds2_parse"}, {From: "ds2_parse", To: "answer"}},
+			},
+			str: `added task ds3
+added task ds3_parse
+removed task ds2
+removed task ds2_parse
+added edge ds3 -> ds3_parse
+added edge ds3_parse -> answer
+removed edge ds2 -> ds2_parse
+removed edge ds2_parse -> answer
+`,
+		},
+		{
+			name: "edges rewired",
+			updated: `
+			ds1          [type=http method=GET url="https://a.example"];
+			ds1_parse    [type=jsonparse path="data,result"];
+			ds2          [type=http method=GET url="https://b.example"];
+			ds2_parse    [type=jsonparse path="data,result"];
+			answer       [type=median];
+			ds1 -> ds2_parse -> answer;
+			ds2 -> ds1_parse -> answer;
+			`,
+			want: pipeline.PipelineDiff{
+				AddedEdges:   []pipeline.PipelineEdge{{From: "ds1", To: "ds2_parse"}, {From: "ds2", To: "ds1_parse"}},
+				RemovedEdges: []pipeline.PipelineEdge{{From: "ds1", To: "ds1_parse"}, {From: "ds2", To: "ds2_parse"}},
+			},
+			str: `added edge ds1 -> ds2_parse
+added edge ds2 -> ds1_parse
+removed edge ds1 -> ds1_parse
+removed edge ds2 -> ds2_parse
+`,
+		},
+		{
+			name:    "graph attributes",
+			updated: `digraph { timeout="10s";` + base + `}`,
+			want: pipeline.PipelineDiff{
+				GraphAttributes: []pipeline.AttributeChange{{Key: "timeout", New: str("10s")}},
+			},
+			str: "graph: added timeout \"10s\"\n",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			diff := pipeline.DiffPipelines(parse(t, base), parse(t, test.updated))
+			assert.Equal(t, test.want, diff)
+			assert.Equal(t, test.want.Empty(), diff.Empty())
+			assert.Equal(t, test.str, diff.String())
+		})
+	}
+
+	t.Run("new pipeline", func(t *testing.T) {
+		t.Parallel()
+		diff := pipeline.DiffPipelines(nil, parse(t, `a [type=memo value=1]; b [type=memo value=2]; a -> b;`))
+		assert.Equal(t, pipeline.PipelineDiff{
+			AddedTasks: []string{"a", "b"},
+			AddedEdges: []pipeline.PipelineEdge{{From: "a", To: "b"}},
+		}, diff)
+		assert.True(t, pipeline.DiffPipelines(nil, nil).Empty())
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		t.Parallel()
+		old, updated := parse(t, base), parse(t, `
+		ds1 [type=http method=POST url="https://z.example" timeout="1s"];
+		ds3 [type=http method=GET url="https://c.example"];
+		ds4 [type=http method=GET url="https://d.example"];
+		answer [type=mean];
+		ds1 -> answer;
+		ds3 -> answer;
+		ds4 -> answer;
+		`)
+		first := pipeline.DiffPipelines(old, updated).String()
+		for i := 0; i < 20; i++ {
+			require.Equal(t, first, pipeline.DiffPipelines(old, updated).String())
+		}
+	})
+}
@@ -1,16 +1,23 @@
 package pipeline
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/pkg/errors"
+	"go.uber.org/multierr"
 	"gonum.org/v1/gonum/graph"
 	"gonum.org/v1/gonum/graph/encoding"
 	"gonum.org/v1/gonum/graph/encoding/dot"
+	dotformat "gonum.org/v1/gonum/graph/formats/dot"
+	"gonum.org/v1/gonum/graph/formats/dot/ast"
 	"gonum.org/v1/gonum/graph/simple"
 	"gonum.org/v1/gonum/graph/topo"
 )
@@ -19,6 +26,16 @@ import (
 // for us to `dot.Unmarshal(...)` a DOT string directly into it.
 type Graph struct {
 	*simple.DirectedGraph
+
+	// errs are the problems found while unmarshaling that don't prevent analyzing the rest of the graph, e.g. a
+	// self-loop, so they're reported along with any others, see Parse
+	errs error
+
+	// attrs are the attributes of the graph itself, e.g. its timeout, keyed by lowercase key like task attributes
+	attrs map[string]string
+
+	// vars are the spec variables, keyed by lowercase name, see expandSpecVars
+	vars map[string]string
 }
 
 func NewGraph() *Graph {
@@ -33,7 +50,28 @@ func (g *Graph) NewEdge(from, to graph.Node) graph.Edge {
 	return &GraphEdge{Edge: g.DirectedGraph.NewEdge(from, to)}
 }
 
-func (g *Graph) UnmarshalText(bs []byte) (err error) {
+// SetEdge adds e to the graph. Self-loops are left out and reported by UnmarshalText with a more precise error than the
+// generic cycle or self edge errors, as they're usually a typo in the spec.
+func (g *Graph) SetEdge(e graph.Edge) {
+	if e.From().ID() == e.To().ID() {
+		g.errs = multierr.Append(g.errs, errors.Errorf("task %q cannot depend on itself", e.From()))
+		return
+	}
+	g.DirectedGraph.SetEdge(e)
+}
+
+// UnmarshalText unmarshals a DOT graph, which may be a bare graph body, into g. Empty statements, e.g. `a -> b;;`, are
+// ignored. Besides malformed DOT, it returns every self-loop and task declared more than once.
+func (g *Graph) UnmarshalText(bs []byte) error {
+	if err := g.unmarshalText(bs); err != nil {
+		return err
+	}
+	return g.errs
+}
+
+// unmarshalText is like UnmarshalText, but only returns the errors which prevent analyzing the graph any further, e.g.
+// malformed DOT. The others are left in g.errs.
+func (g *Graph) unmarshalText(bs []byte) (err error) {
 	if g.DirectedGraph == nil {
 		g.DirectedGraph = simple.NewDirectedGraph()
 	}
@@ -42,16 +80,399 @@ func (g *Graph) UnmarshalText(bs []byte) (err error) {
 			err = fmt.Errorf("could not unmarshal DOT into a pipeline.Graph: %v", rerr)
 		}
 	}()
-	bs = append([]byte("digraph {\n"), bs...)
-	bs = append(bs, []byte("\n}")...)
+	bs = blankEmptyStatements(bs)
+	if !hasGraphHeader(bs) {
+		bs = append([]byte("digraph {\n"), bs...)
+		bs = append(bs, []byte("\n}")...)
+	}
 	err = dot.Unmarshal(bs, g)
 	if err != nil {
 		return errors.Wrap(err, "could not unmarshal DOT into a pipeline.Graph")
 	}
+	file, err := dotformat.ParseBytes(bs)
+	if err != nil {
+		return errors.Wrap(err, "could not unmarshal DOT into a pipeline.Graph")
+	}
+	if err = g.addClusterGroups(file); err != nil {
+		return errors.Wrap(err, "could not unmarshal DOT into a pipeline.Graph")
+	}
+	for _, dg := range file.Graphs {
+		g.addGraphAttributes(dg.Stmts)
+	}
+	if timeout, ok := g.attrs[pipelineTimeoutAttribute]; ok {
+		if _, err = time.ParseDuration(timeout); err != nil {
+			g.errs = multierr.Append(g.errs, errors.Wrapf(err, "invalid pipeline %s %q", pipelineTimeoutAttribute, timeout))
+		}
+	}
+	declared := make(map[string]int)
+	for _, dg := range file.Graphs {
+		countNodeDeclarations(dg.Stmts, declared)
+	}
+	var duplicates []string
+	for id, n := range declared {
+		if n > 1 {
+			duplicates = append(duplicates, id)
+		}
+	}
+	sort.Strings(duplicates)
+	for _, id := range duplicates {
+		g.errs = multierr.Append(g.errs, errors.Errorf("task %q is declared %d times", id, declared[id]))
+	}
+	g.expandSpecVars()
+	g.expandTemplates()
 	g.AddImplicitDependenciesAsEdges()
 	return nil
 }
 
+// specVarsNode is the reserved node declaring the variables of a spec, see expandSpecVars.
+const specVarsNode = "vars"
+
+// specVarRefRegexp matches a reference to a spec variable, capturing its name.
+var specVarRefRegexp = regexp.MustCompile(`\$var\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// expandSpecVars removes the node declaring the variables of the spec, if any, and replaces the $var.name references
+// to them in task attributes with their value, e.g.
+//
+//	vars  [baseURL="https://example.com" times=100];
+//	fetch [type=http method=GET url="$var.baseURL/price"];
+//	mul   [type=multiply times="$var.times"];
+//
+// Unlike the $(name) variables of a run, spec variables are constants expanded once, when the spec is parsed, so they
+// can be used in any attribute. Their names are case insensitive, like attribute keys, and their values are used as is:
+// references within them aren't expanded. The vars node is told apart from a task by its lack of a type, and must not
+// have any dependencies. A reference to an undefined variable is an error.
+func (g *Graph) expandSpecVars() {
+	var nodes []*GraphNode
+	var varsNode *GraphNode
+	for iter := g.Nodes(); iter.Next(); {
+		n := iter.Node().(*GraphNode)
+		if _, isTask := n.attrs["type"]; n.dotID == specVarsNode && !isTask {
+			varsNode = n
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+	if varsNode != nil {
+		if g.From(varsNode.ID()).Len() > 0 || g.To(varsNode.ID()).Len() > 0 {
+			g.errs = multierr.Append(g.errs, errors.Errorf("the %s node declares spec variables and cannot have dependencies", specVarsNode))
+		}
+		g.vars = varsNode.attrs
+		g.RemoveNode(varsNode.ID())
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].dotID < nodes[j].dotID })
+	for _, n := range nodes {
+		undefined := make(map[string]struct{})
+		for key, value := range n.attrs {
+			n.attrs[key] = g.expandSpecVarRefs(value, undefined)
+		}
+		g.addUndefinedSpecVarErrs(n.dotID, undefined)
+	}
+}
+
+// expandSpecVarRefs returns value with its $var.name references replaced by the value of the spec variable, see
+// expandSpecVars. References to undefined variables are left as is, and their names added to undefined.
+func (g *Graph) expandSpecVarRefs(value string, undefined map[string]struct{}) string {
+	return specVarRefRegexp.ReplaceAllStringFunc(value, func(ref string) string {
+		name := specVarRefRegexp.FindStringSubmatch(ref)[1]
+		if v, ok := g.vars[strings.ToLower(name)]; ok {
+			return v
+		}
+		undefined[name] = struct{}{}
+		return ref
+	})
+}
+
+// addUndefinedSpecVarErrs records an error for each undefined spec variable task dotID references, sorted by name.
+func (g *Graph) addUndefinedSpecVarErrs(dotID string, undefined map[string]struct{}) {
+	names := make([]string, 0, len(undefined))
+	for name := range undefined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.errs = multierr.Append(g.errs, errors.Errorf("task %q references undefined spec variable %q", dotID, name))
+	}
+}
+
+// pipelineTimeoutAttribute is the graph attribute declaring the total time budget of the pipeline, see
+// Pipeline.TotalTimeout.
+const pipelineTimeoutAttribute = "timeout"
+
+// addGraphAttributes sets the graph attributes declared at the top level of the graph, either as `key=value` statements
+// or `graph [key=value]` statements. Attributes within subgraphs, e.g. cluster labels, only apply to the subgraph and are
+// left out.
+func (g *Graph) addGraphAttributes(stmts []ast.Stmt) {
+	var attrs []*ast.Attr
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.Attr:
+			attrs = append(attrs, stmt)
+		case *ast.AttrStmt:
+			if stmt.Kind == ast.GraphKind {
+				attrs = append(attrs, stmt.Attrs...)
+			}
+		}
+	}
+	for _, attr := range attrs {
+		if g.attrs == nil {
+			g.attrs = make(map[string]string)
+		}
+		g.attrs[strings.ToLower(attr.Key)] = unquoteDOTID(attr.Val)
+	}
+}
+
+// countNodeDeclarations counts the node statements with attributes of each node, including those within subgraphs.
+// Node statements without attributes only mention a node, e.g. to add it to a cluster, so they aren't counted.
+func countNodeDeclarations(stmts []ast.Stmt, declared map[string]int) {
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.NodeStmt:
+			if len(stmt.Attrs) > 0 {
+				declared[unquoteDOTID(stmt.Node.ID)]++
+			}
+		case *ast.Subgraph:
+			countNodeDeclarations(stmt.Stmts, declared)
+		case *ast.EdgeStmt:
+			vertices := []ast.Vertex{stmt.From}
+			for edge := stmt.To; edge != nil; edge = edge.To {
+				vertices = append(vertices, edge.Vertex)
+			}
+			for _, vertex := range vertices {
+				if sg, ok := vertex.(*ast.Subgraph); ok {
+					countNodeDeclarations(sg.Stmts, declared)
+				}
+			}
+		}
+	}
+}
+
+// hasGraphHeader reports whether bs is a complete DOT graph, i.e. starts with `[strict] (digraph|graph) [ID] {` after
+// any whitespace and comments, as exported by graphviz and other DOT tools. Otherwise bs is a bare graph body.
+// Requiring the opening brace keeps body statements like `graph [rankdir=LR]` from being mistaken for a header.
+func hasGraphHeader(bs []byte) bool {
+	s := skipDOTSpace(string(bs))
+	word, s := cutDOTWord(s)
+	if strings.EqualFold(word, "strict") {
+		word, s = cutDOTWord(skipDOTSpace(s))
+	}
+	if !strings.EqualFold(word, "digraph") && !strings.EqualFold(word, "graph") {
+		return false
+	}
+	s = skipDOTSpace(s)
+	if strings.HasPrefix(s, `"`) {
+		end := 1
+		for ; end < len(s) && s[end] != '"'; end++ {
+			if s[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(s) {
+			return false
+		}
+		s = s[end+1:]
+	} else {
+		_, s = cutDOTWord(s)
+	}
+	return strings.HasPrefix(skipDOTSpace(s), "{")
+}
+
+// skipDOTSpace trims leading whitespace and comments from s.
+func skipDOTSpace(s string) string {
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		switch {
+		case strings.HasPrefix(s, "//"), strings.HasPrefix(s, "#"):
+			if i := strings.IndexByte(s, '\n'); i >= 0 {
+				s = s[i+1:]
+			} else {
+				return ""
+			}
+		case strings.HasPrefix(s, "/*"):
+			if i := strings.Index(s[2:], "*/"); i >= 0 {
+				s = s[i+4:]
+			} else {
+				return ""
+			}
+		default:
+			return s
+		}
+	}
+}
+
+// cutDOTWord splits a leading identifier or numeral off s.
+func cutDOTWord(s string) (word, rest string) {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '_' || r == '.' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+// blankEmptyStatements replaces the semicolons ending empty statements with spaces, e.g. the second one of `a -> b;;`
+// or one right after an opening brace, as machine generated DOT sometimes has them and the DOT parser rejects them.
+// Semicolons within quoted ("...") and HTML-like (<...>) strings, attribute lists and comments are left alone. Blanking
+// rather than removing them keeps the positions of any parse errors. bs is copied if anything is blanked.
+func blankEmptyStatements(bs []byte) []byte {
+	var (
+		out       []byte
+		inQuotes  bool
+		escaped   bool
+		htmlDepth int
+		attrDepth int
+		emptyStmt = true // only whitespace and comments since the start, a '{' or a ';'
+		lineStart = true
+	)
+	for i := 0; i < len(bs); i++ {
+		c := bs[i]
+		switch {
+		case inQuotes:
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inQuotes = false
+			}
+		case htmlDepth > 0:
+			switch c {
+			case '<':
+				htmlDepth++
+			case '>':
+				htmlDepth--
+			}
+		case c == '/' && i+1 < len(bs) && bs[i+1] == '/', c == '#' && lineStart:
+			if end := bytes.IndexByte(bs[i:], '\n'); end >= 0 {
+				i += end - 1
+			} else {
+				i = len(bs)
+			}
+			continue
+		case c == '/' && i+1 < len(bs) && bs[i+1] == '*':
+			if end := bytes.Index(bs[i+2:], []byte("*/")); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(bs)
+			}
+			lineStart = false
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			lineStart = lineStart || c == '\n'
+			continue
+		case c == '"':
+			inQuotes = true
+		case c == '<':
+			htmlDepth = 1
+		case c == '[':
+			attrDepth++
+		case c == ']' && attrDepth > 0:
+			attrDepth--
+		case attrDepth == 0 && (c == ';' || c == '{'):
+			if c == ';' && emptyStmt {
+				if out == nil {
+					out = append([]byte(nil), bs...)
+				}
+				out[i] = ' '
+			}
+			emptyStmt, lineStart = true, false
+			continue
+		}
+		emptyStmt, lineStart = false, false
+	}
+	if out == nil {
+		return bs
+	}
+	return out
+}
+
+// clusterPrefix marks DOT subgraphs whose tasks are grouped together, e.g. `subgraph cluster_feeds { ... }`.
+const clusterPrefix = "cluster_"
+
+// addClusterGroups sets the group attribute of every node declared within a cluster subgraph to the cluster name
+// without its prefix. Subgraphs are otherwise flattened into the main graph when unmarshaling.
+func (g *Graph) addClusterGroups(file *ast.File) error {
+	groups := make(map[string]string)
+	for _, dg := range file.Graphs {
+		if err := collectClusterGroups(dg.Stmts, "", groups); err != nil {
+			return err
+		}
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+	for nodes := g.Nodes(); nodes.Next(); {
+		node := nodes.Node().(*GraphNode)
+		group, ok := groups[node.dotID]
+		if !ok {
+			continue
+		}
+		if existing, set := node.attrs["group"]; set && existing != group {
+			return errors.Errorf("task %q has group %q but is declared in cluster %q", node.dotID, existing, group)
+		}
+		if node.attrs == nil {
+			node.attrs = make(map[string]string)
+		}
+		node.attrs["group"] = group
+	}
+	return nil
+}
+
+// collectClusterGroups records the cluster name of each node declared within a cluster subgraph in groups.
+func collectClusterGroups(stmts []ast.Stmt, group string, groups map[string]string) error {
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.NodeStmt:
+			if group == "" {
+				continue
+			}
+			id := unquoteDOTID(stmt.Node.ID)
+			if existing, ok := groups[id]; ok && existing != group {
+				return errors.Errorf("task %q is declared in both cluster %q and cluster %q", id, existing, group)
+			}
+			groups[id] = group
+		case *ast.EdgeStmt:
+			if err := collectVertexClusterGroups(stmt.From, group, groups); err != nil {
+				return err
+			}
+			for edge := stmt.To; edge != nil; edge = edge.To {
+				if err := collectVertexClusterGroups(edge.Vertex, group, groups); err != nil {
+					return err
+				}
+			}
+		case *ast.Subgraph:
+			if err := collectVertexClusterGroups(stmt, group, groups); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func collectVertexClusterGroups(vertex ast.Vertex, group string, groups map[string]string) error {
+	subgraph, ok := vertex.(*ast.Subgraph)
+	if !ok {
+		return nil
+	}
+	id := unquoteDOTID(subgraph.ID)
+	if strings.HasPrefix(id, clusterPrefix) {
+		if group != "" {
+			return errors.Errorf("cluster %q is nested within cluster %q, nested clusters are not supported", id, clusterPrefix+group)
+		}
+		group = strings.TrimPrefix(id, clusterPrefix)
+	}
+	return collectClusterGroups(subgraph.Stmts, group, groups)
+}
+
+func unquoteDOTID(id string) string {
+	if unquoted, err := strconv.Unquote(id); err == nil {
+		return unquoted
+	}
+	return id
+}
+
 // Looks at node attributes and searches for implicit dependencies on other nodes
 // expressed as attribute values. Adds those dependencies as implicit edges in the graph.
 func (g *Graph) AddImplicitDependenciesAsEdges() {
@@ -129,8 +550,79 @@ func (n *GraphNode) String() string {
 	return n.dotID
 }
 
-var bracketQuotedAttrRegexp = regexp.MustCompile(`\A\s*<([^<>]+)>\s*\z`)
+// unquoteDoubleQuoted unquotes a double quoted value the DOT decoder left quoted. The decoder unquotes values with
+// strconv.Unquote, so values it can't unquote keep their quotes: those spanning several lines, as found in
+// pretty-printed specs, those with backslashes that aren't Go escapes, e.g. regular expressions like "\d+,\d+", and
+// those quoted in angle brackets as well, e.g. "<a>", which it leaves quoted on purpose. These are unquoted with
+// strconv.Unquote if possible, for consistency with the other values, keeping their line breaks. Otherwise they're
+// unquoted per the DOT rules, where \" is the only escape sequence and other backslashes are kept.
+// Values which aren't quoted, or whose quotes are part of the value, e.g. "\"a\"" decoded as "a" in quotes, are
+// returned as is.
+func unquoteDoubleQuoted(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	angleQuoted := len(s) >= 4 && strings.HasPrefix(s, `"<`) && strings.HasSuffix(s, `>"`)
+	multiline := strings.ContainsAny(s, "\r\n")
+	if !angleQuoted && !multiline {
+		if _, err := strconv.Unquote(s); err == nil {
+			// The decoder would have unquoted it, so it was unquoted already and its quotes are literal
+			return s
+		}
+	}
+	escaped := strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(s)
+	if unquoted, err := strconv.Unquote(escaped); err == nil {
+		return unquoted
+	}
+	var b strings.Builder
+	inner := s[1 : len(s)-1]
+	for i := 0; i < len(inner); i++ {
+		switch {
+		case inner[i] == '\\' && i+1 < len(inner) && inner[i+1] == '"':
+			b.WriteByte('"')
+			i++
+		case inner[i] == '"':
+			// A quoted DOT string can't contain an unescaped quote, so this value was unquoted already
+			return s
+		default:
+			b.WriteByte(inner[i])
+		}
+	}
+	return b.String()
+}
+
+// unquoteAngleBrackets strips the outermost pair of angle brackets from a value quoted in them, as supported natively by
+// DOT, along with any whitespace around them. Brackets within the value must be balanced and are kept, e.g.
+// <{"x": <inner>}> becomes {"x": <inner>}. Values not enclosed in a single pair of brackets, e.g. "<a> or <b>", are
+// returned as is, while an enclosed value with unbalanced brackets, e.g. <{"x": <inner}>, is an error rather than
+// being unquoted partially.
+func unquoteAngleBrackets(s string) (string, error) {
+	trimmed := strings.TrimSpace(s)
+	if len(trimmed) < 2 || trimmed[0] != '<' || trimmed[len(trimmed)-1] != '>' {
+		return s, nil
+	}
+	depth := 0
+	for i, r := range trimmed {
+		switch r {
+		case '<':
+			depth++
+		case '>':
+			depth--
+			if depth == 0 && i < len(trimmed)-1 {
+				// The first pair closes before the end, so the value isn't enclosed in it
+				return s, nil
+			}
+		}
+	}
+	if depth != 0 {
+		return "", errors.Errorf("unbalanced angle brackets in %s: %d more '<' than '>'", trimmed, depth)
+	}
+	return trimmed[1 : len(trimmed)-1], nil
+}
 
+// SetAttribute sets a task attribute. Unlike DOT itself, attribute keys are case-insensitive: they are normalized to
+// lowercase, so e.g. Type=, TYPE= and type= are equivalent. Keys differing only in case therefore refer to the same
+// attribute, with the last one taking precedence. Values are kept verbatim, except for their quotes.
 func (n *GraphNode) SetAttribute(attr encoding.Attribute) error {
 	if n.attrs == nil {
 		n.attrs = make(map[string]string)
@@ -138,9 +630,12 @@ func (n *GraphNode) SetAttribute(attr encoding.Attribute) error {
 
 	// Strings quoted in angle brackets (supported natively by DOT) should
 	// have those brackets removed before decoding to task parameter types
-	sanitized := bracketQuotedAttrRegexp.ReplaceAllString(attr.Value, "$1")
+	sanitized, err := unquoteAngleBrackets(unquoteDoubleQuoted(attr.Value))
+	if err != nil {
+		return errors.Wrapf(err, "task %q", n.dotID)
+	}
 
-	n.attrs[attr.Key] = sanitized
+	n.attrs[strings.ToLower(attr.Key)] = sanitized
 	return nil
 }
 
@@ -160,6 +655,13 @@ type Pipeline struct {
 	Tasks  []Task
 	tree   *Graph
 	Source string
+
+	// byDotID indexes Tasks by dotID. It's built along with Tasks by newPipeline, so anything modifying Tasks must call
+	// indexTasks afterwards.
+	byDotID map[string]Task
+	// resolve builds the tasks of custom types, see ParseWithResolver. It's kept to build the tasks again, e.g. in
+	// WithAttributeOverride.
+	resolve TaskResolver
 }
 
 func (p *Pipeline) UnmarshalText(bs []byte) (err error) {
@@ -183,6 +685,113 @@ func (p *Pipeline) MinTimeout() (time.Duration, bool, error) {
 	return minTimeout, aTimeoutSet, nil
 }
 
+// TasksWithoutTimeout returns the tasks which may run indefinitely, in topological order, e.g. for a linter to warn
+// about. A task has a timeout if it sets a positive timeout. HTTP and bridge tasks also have one if they set a positive
+// requestTimeout, or if they don't set a timeout at all, as their requests then time out after the node's
+// DefaultHTTPTimeout, see httpRequestCtx. An explicit timeout of 0 disables that default. Timeouts outside of the spec,
+// like the job's maxTaskDuration, aren't considered.
+func (p *Pipeline) TasksWithoutTimeout() []Task {
+	var tasks []Task
+	for _, task := range p.Tasks {
+		timeout, set := task.TaskTimeout()
+		if set && timeout > 0 {
+			continue
+		}
+		if task.Type() == TaskTypeHTTP || task.Type() == TaskTypeBridge {
+			if requestTimeout, ok := task.RequestTimeout(); !set || (ok && requestTimeout > 0) {
+				continue
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// TotalTimeout returns the time budget of the whole pipeline, if declared with a graph attribute, e.g.
+//
+//	digraph {
+//		timeout="30s"
+//		...
+//	}
+//
+// Unlike MinTimeout, which bounds each task on its own, it bounds the run as a whole. Parse checks that the budget
+// leaves enough time for the task timeouts along the longest path of the pipeline.
+func (p *Pipeline) TotalTimeout() (time.Duration, bool) {
+	if p.tree == nil {
+		return 0, false
+	}
+	timeoutStr, ok := p.tree.attrs[pipelineTimeoutAttribute]
+	if !ok {
+		return 0, false
+	}
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		// rejected by Parse
+		return 0, false
+	}
+	return timeout, true
+}
+
+// validateTotalTimeout checks that the tasks along each path can run for as long as their timeouts allow without
+// exceeding the total timeout. Tasks without a timeout count as taking no time.
+func (p *Pipeline) validateTotalTimeout() error {
+	total, ok := p.TotalTimeout()
+	if !ok {
+		return nil
+	}
+	// Tasks are in topological order, so the longest path to each task's inputs is known by the time it's visited
+	longest := make(map[Task]time.Duration, len(p.Tasks))
+	prev := make(map[Task]Task, len(p.Tasks))
+	var end Task
+	for _, task := range p.Tasks {
+		for _, input := range task.Inputs() {
+			if d := longest[input.InputTask]; prev[task] == nil || d > longest[task] {
+				longest[task] = d
+				prev[task] = input.InputTask
+			}
+		}
+		timeout, _ := task.TaskTimeout()
+		longest[task] += timeout
+		if end == nil || longest[task] > longest[end] {
+			end = task
+		}
+	}
+	if end == nil || longest[end] <= total {
+		return nil
+	}
+	var path []string
+	for task := end; task != nil; task = prev[task] {
+		path = append([]string{task.DotID()}, path...)
+	}
+	return errors.Errorf("task timeouts along %s add up to %s, exceeding the pipeline %s of %s",
+		strings.Join(path, " -> "), longest[end], pipelineTimeoutAttribute, total)
+}
+
+// NamedOutputs returns the terminal tasks naming their result with an outputName attribute, by name, so results can be
+// looked up by name rather than by their position among the outputs, e.g.
+//
+//	answer [type=median outputName="price"]
+//
+// It returns an error if several tasks have the same output name, or if a task which isn't terminal has one, as its
+// result isn't an output of the pipeline.
+func (p *Pipeline) NamedOutputs() (map[string]Task, error) {
+	outputs := make(map[string]Task)
+	for _, task := range p.Tasks {
+		name, ok := task.OutputName()
+		if !ok {
+			continue
+		}
+		if len(task.Outputs()) > 0 {
+			return nil, errors.Errorf("task %s has output name %q but is not a terminal task", task.DotID(), name)
+		}
+		if other, exists := outputs[name]; exists {
+			return nil, errors.Errorf("output name %q is used by both task %s and task %s", name, other.DotID(), task.DotID())
+		}
+		outputs[name] = task
+	}
+	return outputs, nil
+}
+
 func (p *Pipeline) RequiresPreInsert() bool {
 	for _, task := range p.Tasks {
 		switch task.Type() {
@@ -199,30 +808,351 @@ func (p *Pipeline) RequiresPreInsert() bool {
 	return false
 }
 
+// ByDotID returns the task with the given dotID, or nil if there's none.
 func (p *Pipeline) ByDotID(id string) Task {
+	task, _ := p.TaskByID(id)
+	return task
+}
+
+// TaskByID returns the task with the given dotID, and whether there is one.
+func (p *Pipeline) TaskByID(dotID string) (Task, bool) {
+	if p.byDotID == nil {
+		// Not built by Parse, e.g. a zero Pipeline
+		for _, task := range p.Tasks {
+			if task.DotID() == dotID {
+				return task, true
+			}
+		}
+		return nil, false
+	}
+	task, ok := p.byDotID[dotID]
+	return task, ok
+}
+
+// TaskByIndex returns the task at index i of Tasks, i.e. in dependency order, and whether there is one. A task's index
+// is its ID.
+func (p *Pipeline) TaskByIndex(i int) (Task, bool) {
+	if i < 0 || i >= len(p.Tasks) {
+		return nil, false
+	}
+	return p.Tasks[i], true
+}
+
+// indexTasks rebuilds the index of tasks by dotID.
+func (p *Pipeline) indexTasks() {
+	p.byDotID = make(map[string]Task, len(p.Tasks))
 	for _, task := range p.Tasks {
-		if task.DotID() == id {
-			return task
+		p.byDotID[task.DotID()] = task
+	}
+}
+
+// Walk calls visit for each task in topological order, i.e. every task is visited after all of its inputs.
+// Walking stops at the first error returned by visit, which is returned.
+func (p *Pipeline) Walk(visit func(t Task) error) error {
+	for _, task := range p.Tasks {
+		if err := visit(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkReverse calls visit for each task in reverse topological order, i.e. starting from terminal tasks, every task
+// is visited after all of its outputs. Walking stops at the first error returned by visit, which is returned.
+func (p *Pipeline) WalkReverse(visit func(t Task) error) error {
+	for i := len(p.Tasks) - 1; i >= 0; i-- {
+		if err := visit(p.Tasks[i]); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// DependenciesOf returns the tasks that the task named dotID transitively depends on, in topological order.
+func (p *Pipeline) DependenciesOf(dotID string) ([]Task, error) {
+	task := p.ByDotID(dotID)
+	if task == nil {
+		return nil, errors.Errorf("unknown task %q", dotID)
+	}
+	return p.reachable(task, func(t Task) []Task {
+		inputs := make([]Task, len(t.Inputs()))
+		for i, input := range t.Inputs() {
+			inputs[i] = input.InputTask
+		}
+		return inputs
+	}), nil
+}
+
+// DependentsOf returns the tasks that transitively depend on the task named dotID, in topological order.
+func (p *Pipeline) DependentsOf(dotID string) ([]Task, error) {
+	task := p.ByDotID(dotID)
+	if task == nil {
+		return nil, errors.Errorf("unknown task %q", dotID)
+	}
+	return p.reachable(task, Task.Outputs), nil
+}
+
+// Adjacency returns the dotIDs of the outputs of each task, keyed by the task's dotID and sorted. Tasks without outputs
+// have an empty list. The map is a snapshot, so modifying it doesn't affect the pipeline.
+func (p *Pipeline) Adjacency() map[string][]string {
+	return p.adjacency(Task.Outputs)
+}
+
+// ReverseAdjacency is like Adjacency, but lists the dotIDs of the inputs of each task, including implicit dependencies
+// on a variable.
+func (p *Pipeline) ReverseAdjacency() map[string][]string {
+	return p.adjacency(func(t Task) []Task {
+		inputs := make([]Task, len(t.Inputs()))
+		for i, input := range t.Inputs() {
+			inputs[i] = input.InputTask
+		}
+		return inputs
+	})
+}
+
+func (p *Pipeline) adjacency(next func(Task) []Task) map[string][]string {
+	adjacency := make(map[string][]string, len(p.Tasks))
+	for _, task := range p.Tasks {
+		neighbors := []string{}
+		for _, n := range next(task) {
+			neighbors = append(neighbors, n.DotID())
+		}
+		sort.Strings(neighbors)
+		adjacency[task.DotID()] = neighbors
+	}
+	return adjacency
+}
+
+// reachable returns the tasks reachable from start by following next, excluding start, in topological order.
+func (p *Pipeline) reachable(start Task, next func(Task) []Task) []Task {
+	seen := map[int]bool{start.ID(): true}
+	stack := []Task{start}
+	for len(stack) > 0 {
+		t := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, n := range next(t) {
+			if !seen[n.ID()] {
+				seen[n.ID()] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+	var tasks []Task
+	for _, t := range p.Tasks {
+		if t.ID() != start.ID() && seen[t.ID()] {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks
+}
+
+// Parse parses the DOT source of a pipeline spec. Tasks are ordered so that each task comes after all of its inputs.
+//
+// Declaration order never affects the parsed graph: nodes may be declared before or after their dependencies, or only
+// appear in edge statements, and each task gets the same inputs, outputs and explicit or implicit edges regardless.
+// Declaration order only breaks ties between tasks which don't depend on each other, so it determines their relative
+// order in Tasks and in the inputs of a task with several inputs.
+//
+// Parse reports every problem it finds at once, combined with multierr, e.g. unknown task types, self-loops, tasks
+// declared more than once, references to tasks which aren't upstream and a pipeline timeout too short for the task
+// timeouts along some path, see TotalTimeout. Only problems preventing further analysis,
+// like malformed DOT, are returned on their own.
 func Parse(text string) (*Pipeline, error) {
+	return ParseWithResolver(text, nil)
+}
+
+// TaskResolver builds the task dotID of the lowercase type taskType from its attributes, id being its position in the
+// pipeline, see ParseWithResolver. It returns a nil Task for the types it doesn't handle, which are then built by
+// DefaultTaskResolver.
+type TaskResolver func(taskType TaskType, attrs map[string]string, id int, dotID string) (Task, error)
+
+// DefaultTaskResolver builds the built-in task types, see UnmarshalTaskFromMap.
+func DefaultTaskResolver(taskType TaskType, attrs map[string]string, id int, dotID string) (Task, error) {
+	return UnmarshalTaskFromMap(taskType, attrs, id, dotID)
+}
+
+// ParseWithResolver parses the pipeline like Parse, building its tasks with resolve first, e.g. to support task types
+// defined outside this package. Tasks of the types resolve doesn't handle are built as usual. The ID and dotID of the
+// resolved tasks are set by the pipeline, but any other attribute, including the common ones like timeout and index,
+// is up to resolve. Pipelines derived from the parsed one, e.g. by WithoutDisabled, are built with resolve too.
+// A nil resolve only builds the built-in task types, like Parse.
+func ParseWithResolver(text string, resolve TaskResolver) (*Pipeline, error) {
+	g := NewGraph()
+	if err := g.unmarshalText([]byte(text)); err != nil {
+		return nil, err
+	}
+
+	cycles := g.checkReferenceCycles()
+	errs := multierr.Combine(g.errs, cycles, g.validateReferences())
+	if cycles == nil {
+		// Reference cycles would only be reported again as a generic cycle
+		p, err := newPipeline(g, text, resolve)
+		if err == nil {
+			err = p.validateTotalTimeout()
+		}
+		if errs = multierr.Append(errs, err); errs == nil {
+			return p, nil
+		}
+	}
+	return nil, errs
+}
+
+// GraphStats describes the structure of a pipeline spec, see ParseStructure.
+type GraphStats struct {
+	Nodes int
+	// Edges counts both explicit edges and the implicit ones added for $(name) references.
+	Edges int
+	// MaxDepth is the number of tasks along the longest path, or 0 if the graph has a cycle.
+	MaxDepth int
+	// MaxFanOut is the largest number of outputs of a single task.
+	MaxFanOut int
+	HasCycle  bool
+	// Roots are the tasks without inputs, and Terminals the tasks without outputs, both sorted by name.
+	Roots     []string
+	Terminals []string
+}
+
+// ParseStructure parses the DOT source of a pipeline spec into its graph and returns its structure, without building
+// its tasks, e.g. for linting specs quickly. Unlike Parse, it doesn't check task types or attributes, so specs using
+// task types unknown to this node are described all the same, and a cycle is reported by GraphStats.HasCycle rather
+// than as an error. It only returns errors for malformed DOT, self-loops and tasks declared more than once.
+func ParseStructure(text string) (*GraphStats, error) {
 	g := NewGraph()
-	err := g.UnmarshalText([]byte(text))
+	if err := g.UnmarshalText([]byte(text)); err != nil {
+		return nil, err
+	}
+
+	stats := &GraphStats{
+		Nodes:     g.Nodes().Len(),
+		Edges:     g.Edges().Len(),
+		Roots:     []string{},
+		Terminals: []string{},
+	}
+	for iter := g.Nodes(); iter.Next(); {
+		node := iter.Node().(*GraphNode)
+		if g.To(node.ID()).Len() == 0 {
+			stats.Roots = append(stats.Roots, node.dotID)
+		}
+		outputs := g.From(node.ID()).Len()
+		if outputs == 0 {
+			stats.Terminals = append(stats.Terminals, node.dotID)
+		}
+		if outputs > stats.MaxFanOut {
+			stats.MaxFanOut = outputs
+		}
+	}
+	sort.Strings(stats.Roots)
+	sort.Strings(stats.Terminals)
+
+	nodes, err := topo.Sort(g)
+	if err != nil {
+		stats.HasCycle = true
+		return stats, nil
+	}
+	// dependencies come first, so the depth of each node's inputs is known by the time it's visited
+	depths := make(map[int64]int, len(nodes))
+	for _, node := range nodes {
+		depth := 1
+		for inputs := g.To(node.ID()); inputs.Next(); {
+			if d := depths[inputs.Node().ID()] + 1; d > depth {
+				depth = d
+			}
+		}
+		depths[node.ID()] = depth
+		if depth > stats.MaxDepth {
+			stats.MaxDepth = depth
+		}
+	}
+	return stats, nil
+}
+
+// checkReferenceCycles returns an error naming the reference responsible for each implicit edge added for a $(name)
+// reference which closes a cycle, i.e. a task references the result of one of its own dependents.
+func (g *Graph) checkReferenceCycles() error {
+	var errs error
+	nodes := graph.NodesOf(g.Nodes())
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID() < nodes[j].ID() })
+	for _, to := range nodes {
+		inputs := graph.NodesOf(g.To(to.ID()))
+		sort.Slice(inputs, func(i, j int) bool { return inputs[i].ID() < inputs[j].ID() })
+		for _, from := range inputs {
+			if g.IsImplicitEdge(from.ID(), to.ID()) && topo.PathExistsIn(g, to, from) {
+				errs = multierr.Append(errs, errors.Errorf("task %q references %q which is not an upstream dependency", to.(*GraphNode).dotID, from.(*GraphNode).dotID))
+			}
+		}
+	}
+	return errs
+}
+
+// validateReferences checks that every $(name) reference in a task attribute which names a task refers to one of the
+// task's transitive inputs, so its result is available when the referencing task runs. Referencing a task usually adds
+// an implicit edge from it, but not e.g. when a task references its own result. Names that aren't tasks, like
+// $(jobRun.requestBody), are resolved from the run's variables when the pipeline runs and aren't checked.
+func (g *Graph) validateReferences() error {
+	nodes := graph.NodesOf(g.Nodes())
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID() < nodes[j].ID() })
+	byDotID := make(map[string]*GraphNode, len(nodes))
+	for _, node := range nodes {
+		byDotID[node.(*GraphNode).dotID] = node.(*GraphNode)
+	}
+	var errs error
+	for _, n := range nodes {
+		node := n.(*GraphNode)
+		reported := make(map[string]bool)
+		for _, attr := range node.Attributes() {
+			for _, m := range variableRegexp.FindAllStringSubmatch(attr.Value, -1) {
+				name := strings.Split(m[1], ".")[0]
+				ref, ok := byDotID[name]
+				if !ok || reported[name] {
+					continue
+				}
+				if ref.ID() == node.ID() || !topo.PathExistsIn(g, ref, node) {
+					reported[name] = true
+					errs = multierr.Append(errs, errors.Errorf("task %q references %q which is not an upstream dependency", node.dotID, name))
+				}
+			}
+		}
+	}
+	return errs
+}
 
+// dotErrPosRegexp matches the position reported by DOT syntax errors.
+var dotErrPosRegexp = regexp.MustCompile(`Pos\(offset=\d+, line=(\d+), column=(\d+)\)`)
+
+// ParseFile reads and parses the pipeline spec at path. Errors are prefixed with the path and, for syntax errors,
+// the line and column in the file, e.g. "specs/feed.dot:3:5: ...".
+func ParseFile(path string) (*Pipeline, error) {
+	bs, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	p, err := Parse(string(bs))
+	if err == nil {
+		return p, nil
+	}
+	if m := dotErrPosRegexp.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		if !hasGraphHeader(bs) {
+			// Account for the line of the digraph wrapper added by Graph.UnmarshalText
+			line--
+		}
+		return nil, errors.Wrapf(err, "%s:%d:%s", path, line, m[2])
+	}
+	return nil, errors.Wrap(err, path)
+}
 
+// newPipeline builds the tasks of g, which was unmarshaled from source, with resolve, see ParseWithResolver. It returns
+// the errors of every task which fails to build.
+func newPipeline(g *Graph, source string, resolve TaskResolver) (*Pipeline, error) {
 	p := &Pipeline{
-		tree:   g,
-		Tasks:  make([]Task, 0, g.Nodes().Len()),
-		Source: text,
+		tree:    g,
+		Tasks:   make([]Task, 0, g.Nodes().Len()),
+		Source:  source,
+		resolve: resolve,
 	}
 
 	// toposort all the nodes: dependencies ordered before outputs. This also does cycle checking for us.
+	// Ties are broken by node ID, i.e. the order in which nodes first appear in the source.
 	nodes, err := topo.SortStabilized(g, nil)
 
 	if err != nil {
@@ -231,6 +1161,7 @@ func Parse(text string) (*Pipeline, error) {
 
 	// we need a temporary mapping of graph.IDs to positional ids after toposort
 	ids := make(map[int64]int)
+	var errs error
 
 	// use the new ordering as the id so that we can easily reproduce the original toposort
 	for id, node := range nodes {
@@ -239,13 +1170,19 @@ func Parse(text string) (*Pipeline, error) {
 			panic("unreachable")
 		}
 
-		if node.dotID == InputTaskKey {
-			return nil, errors.Errorf("'%v' is a reserved keyword that cannot be used as a task's name", InputTaskKey)
+		if err = checkTaskName(node.dotID); err != nil {
+			errs = multierr.Append(errs, err)
+			continue
 		}
 
-		task, err := UnmarshalTaskFromMap(TaskType(node.attrs["type"]), node.attrs, id, node.dotID)
+		task, err := p.buildTask(TaskType(node.attrs["type"]), node.attrs, id, node.dotID)
 		if err != nil {
-			return nil, err
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		if errs != nil {
+			// The tasks can't be linked without their inputs, so only the remaining tasks' errors are collected
+			continue
 		}
 
 		// re-link the edges
@@ -267,6 +1204,244 @@ func Parse(text string) (*Pipeline, error) {
 		p.Tasks = append(p.Tasks, task)
 		ids[node.ID()] = id
 	}
+	if errs != nil {
+		return nil, errs
+	}
+	p.indexTasks()
 
 	return p, nil
 }
+
+// buildTask builds a task with the pipeline's resolver, falling back to DefaultTaskResolver for the types it doesn't
+// handle.
+func (p *Pipeline) buildTask(taskType TaskType, attrs map[string]string, id int, dotID string) (Task, error) {
+	taskType = TaskType(strings.ToLower(string(taskType)))
+	if p.resolve != nil {
+		task, err := p.resolve(taskType, attrs, id, dotID)
+		if err != nil {
+			return nil, err
+		}
+		if task != nil {
+			task.Base().id = id
+			task.Base().dotID = dotID
+			return task, nil
+		}
+	}
+	return DefaultTaskResolver(taskType, attrs, id, dotID)
+}
+
+// WithoutDisabled returns a copy of the pipeline with all disabled tasks removed. Each input of a disabled task is
+// connected to each of its outputs instead, so results bypass the disabled task. Bypass edges are explicit, so that the
+// copy's DOT parses back into the same graph, while edges between enabled tasks keep whether they're implicit. Tasks
+// referencing a disabled task's result by variable are left as is. Since the copy no longer matches p's Source, its
+// Source is its DOT.
+// If no task is disabled, p itself is returned. It returns an error if the remaining tasks can't be built again, e.g.
+// as the pipeline's TaskResolver fails on them.
+func (p *Pipeline) WithoutDisabled() (*Pipeline, error) {
+	disabled := make(map[string]bool)
+	for _, task := range p.Tasks {
+		if task.Disabled() {
+			disabled[task.DotID()] = true
+		}
+	}
+	if len(disabled) == 0 {
+		return p, nil
+	}
+
+	// add nodes in their original order, which keeps the topological sort of the remaining tasks stable
+	oldNodes := graph.NodesOf(p.tree.Nodes())
+	sort.Slice(oldNodes, func(i, j int) bool { return oldNodes[i].ID() < oldNodes[j].ID() })
+
+	g := NewGraph()
+	g.attrs = p.tree.attrs
+	nodes := make(map[int64]*GraphNode)
+	for _, oldNode := range oldNodes {
+		node := oldNode.(*GraphNode)
+		if disabled[node.dotID] {
+			continue
+		}
+		attrs := make(map[string]string, len(node.attrs))
+		for k, v := range node.attrs {
+			attrs[k] = v
+		}
+		n := g.NewNode().(*GraphNode)
+		n.dotID = node.dotID
+		n.attrs = attrs
+		g.AddNode(n)
+		nodes[node.ID()] = n
+	}
+
+	for from, n := range nodes {
+		// explicit maps each enabled node reachable from n, directly or through disabled nodes only, to whether its
+		// edge is explicit: a direct edge keeps its own, while a bypass edge is always explicit, as DOT only emits
+		// explicit edges and parsing wouldn't add it back from variable references.
+		explicit := make(map[int64]bool)
+		var visit func(id int64)
+		visit = func(id int64) {
+			for iter := p.tree.From(id); iter.Next(); {
+				to := iter.Node().(*GraphNode)
+				switch {
+				case disabled[to.dotID]:
+					visit(to.ID())
+				case id == from:
+					explicit[to.ID()] = explicit[to.ID()] || !p.tree.IsImplicitEdge(id, to.ID())
+				default:
+					explicit[to.ID()] = true
+				}
+			}
+		}
+		visit(from)
+
+		for to, isExplicit := range explicit {
+			edge := g.NewEdge(n, nodes[to]).(*GraphEdge)
+			edge.SetIsImplicit(!isExplicit)
+			g.SetEdge(edge)
+		}
+	}
+
+	wp, err := newPipeline(g, p.Source, p.resolve)
+	if err != nil {
+		return nil, err
+	}
+	wp.Source = wp.DOT()
+	return wp, nil
+}
+
+// WithAttributeOverride returns a copy of the pipeline with the attribute key of task dotID set to value, e.g. to run a
+// spec with a different url without authoring it again. Spec variables referenced by value are expanded, like when
+// parsing. The task is built again from its overridden attributes, and its implicit dependencies follow the tasks the
+// new value references: it gains one on any task the new value references, and loses those only the old value did.
+// Since the copy no longer matches p's Source, its Source is its DOT. p itself is left untouched.
+// It returns an error if there's no such task, if the task's type doesn't accept the attribute, if value references an
+// undefined spec variable or a task which isn't upstream, or if the task can't be built with the new value, e.g. a
+// timeout which no longer fits the pipeline's TotalTimeout.
+func (p *Pipeline) WithAttributeOverride(dotID, key, value string) (*Pipeline, error) {
+	task, ok := p.TaskByID(dotID)
+	if !ok || p.tree == nil {
+		return nil, errors.Errorf("task %q not found", dotID)
+	}
+	key = strings.ToLower(key)
+	if _, accepted := taskAttributes(task)[key]; !accepted {
+		return nil, errors.Errorf("task %s: unknown attribute %q for type %s", dotID, key, task.Type())
+	}
+
+	// nodes keep their IDs, which keeps the topological sort of the tasks stable
+	g := NewGraph()
+	g.attrs = p.tree.attrs
+	g.vars = p.tree.vars
+	for iter := p.tree.Nodes(); iter.Next(); {
+		node := iter.Node().(*GraphNode)
+		attrs := make(map[string]string, len(node.attrs)+1)
+		for k, v := range node.attrs {
+			attrs[k] = v
+		}
+		if node.dotID == dotID {
+			// the other attributes were expanded when parsing, and spec variable values aren't expanded again
+			undefined := make(map[string]struct{})
+			attrs[key] = g.expandSpecVarRefs(value, undefined)
+			g.addUndefinedSpecVarErrs(dotID, undefined)
+		}
+		g.AddNode(&GraphNode{Node: simple.Node(node.ID()), dotID: node.dotID, attrs: attrs})
+	}
+	// implicit edges are added again from the references of the new attributes, so the old value's are dropped
+	for iter := p.tree.Edges(); iter.Next(); {
+		e := iter.Edge()
+		if p.tree.IsImplicitEdge(e.From().ID(), e.To().ID()) {
+			continue
+		}
+		g.SetEdge(g.NewEdge(g.Node(e.From().ID()), g.Node(e.To().ID())))
+	}
+	g.AddImplicitDependenciesAsEdges()
+	cycles := g.checkReferenceCycles()
+	if err := multierr.Combine(g.errs, cycles); err != nil {
+		return nil, err
+	}
+	if err := g.validateReferences(); err != nil {
+		return nil, err
+	}
+
+	op, err := newPipeline(g, p.Source, p.resolve)
+	if err != nil {
+		return nil, err
+	}
+	if err = op.validateTotalTimeout(); err != nil {
+		return nil, err
+	}
+	op.Source = op.DOT()
+	return op, nil
+}
+
+var bareDOTIDRegexp = regexp.MustCompile(`\A(?:[A-Za-z_][A-Za-z0-9_]*|-?(?:\.[0-9]+|[0-9]+(?:\.[0-9]*)?))\z`)
+
+// DOT returns the pipeline as a normalized DOT graph, e.g. for rendering with graphviz. Unlike Source, it reflects the
+// parsed pipeline, including any transformations like WithoutDisabled: the graph attributes, e.g. the pipeline timeout,
+// are listed first, then the tasks in topological order with their attributes sorted by key, followed by the explicit
+// edges. Implicit edges are left out, as parsing derives them from
+// the variables in the task attributes again. Parsing the output results in an equivalent pipeline.
+func (p *Pipeline) DOT() string {
+	nodes := make(map[string]*GraphNode)
+	if p.tree != nil {
+		for iter := p.tree.Nodes(); iter.Next(); {
+			node := iter.Node().(*GraphNode)
+			nodes[node.dotID] = node
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("digraph {\n")
+	if p.tree != nil && len(p.tree.attrs) > 0 {
+		keys := make([]string, 0, len(p.tree.attrs))
+		for k := range p.tree.attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sb.WriteString("\t" + quoteDOTID(k) + "=" + quoteDOTID(p.tree.attrs[k]) + ";\n")
+		}
+		sb.WriteString("\n")
+	}
+	for _, task := range p.Tasks {
+		sb.WriteString("\t" + quoteDOTID(task.DotID()))
+		if node, ok := nodes[task.DotID()]; ok && len(node.attrs) > 0 {
+			attrs := node.Attributes()
+			sb.WriteString(" [")
+			for i, attr := range attrs {
+				if i > 0 {
+					sb.WriteString(" ")
+				}
+				sb.WriteString(quoteDOTID(attr.Key) + "=" + quoteDOTID(attr.Value))
+			}
+			sb.WriteString("]")
+		}
+		sb.WriteString(";\n")
+	}
+
+	var edges []string
+	for _, task := range p.Tasks {
+		for _, input := range task.Inputs() {
+			if input.PropagateResult {
+				edges = append(edges, fmt.Sprintf("\t%s -> %s;\n", quoteDOTID(input.InputTask.DotID()), quoteDOTID(task.DotID())))
+			}
+		}
+	}
+	if len(edges) > 0 {
+		sb.WriteString("\n")
+		for _, edge := range edges {
+			sb.WriteString(edge)
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// quoteDOTID returns s as a DOT ID, quoting it unless it's a plain identifier that isn't a DOT keyword, or a number.
+func quoteDOTID(s string) string {
+	if bareDOTIDRegexp.MatchString(s) {
+		switch strings.ToLower(s) {
+		case "node", "edge", "graph", "digraph", "subgraph", "strict":
+		default:
+			return s
+		}
+	}
+	return strconv.Quote(s)
+}
@@ -1,11 +1,22 @@
 package pipeline_test
 
 import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/multierr"
 	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
 
+	"github.com/smartcontractkit/chainlink/core/logger"
 	"github.com/smartcontractkit/chainlink/core/services/pipeline"
 )
 
@@ -208,6 +219,91 @@ func TestGraph_TasksInDependencyOrder(t *testing.T) {
 	require.Equal(t, expected, p.Tasks)
 }
 
+func TestGraph_DeclarationOrder(t *testing.T) {
+	t.Parallel()
+
+	specs := map[string]string{
+		"dependency order": `
+ds1       [type=memo value=1]
+ds1_parse [type=multiply times=2]
+ds2       [type=memo value=2]
+ds2_parse [type=multiply times=3]
+answer    [type=median]
+result    [type=multiply input="$(answer)" times=10]
+ds1 -> ds1_parse -> answer
+ds2 -> ds2_parse -> answer
+`,
+		"reverse dependency order": `
+result    [type=multiply input="$(answer)" times=10]
+answer    [type=median]
+ds2_parse [type=multiply times=3]
+ds2       [type=memo value=2]
+ds1_parse [type=multiply times=2]
+ds1       [type=memo value=1]
+ds2 -> ds2_parse -> answer
+ds1 -> ds1_parse -> answer
+`,
+		"edges first": `
+ds1_parse -> answer
+ds2_parse -> answer
+ds1 -> ds1_parse
+ds2 -> ds2_parse
+result    [type=multiply input="$(answer)" times=10]
+answer    [type=median]
+ds2_parse [type=multiply times=3]
+ds1_parse [type=multiply times=2]
+ds2       [type=memo value=2]
+ds1       [type=memo value=1]
+`,
+	}
+
+	// wiring describes each task's inputs, whether they're explicit, and outputs, independent of task order
+	type wiring struct {
+		inputs  []string
+		outputs []string
+	}
+	expected := map[string]wiring{
+		"ds1":       {outputs: []string{"ds1_parse"}},
+		"ds2":       {outputs: []string{"ds2_parse"}},
+		"ds1_parse": {inputs: []string{"ds1 explicit"}, outputs: []string{"answer"}},
+		"ds2_parse": {inputs: []string{"ds2 explicit"}, outputs: []string{"answer"}},
+		"answer":    {inputs: []string{"ds1_parse explicit", "ds2_parse explicit"}, outputs: []string{"result"}},
+		"result":    {inputs: []string{"answer implicit"}},
+	}
+
+	for name, spec := range specs {
+		spec := spec
+		t.Run(name, func(t *testing.T) {
+			p, err := pipeline.Parse(spec)
+			require.NoError(t, err)
+			require.Len(t, p.Tasks, len(expected))
+
+			actual := make(map[string]wiring)
+			for i, task := range p.Tasks {
+				require.Equal(t, i, task.ID())
+				var w wiring
+				for _, input := range task.Inputs() {
+					// Inputs come before the task
+					require.Less(t, input.InputTask.ID(), task.ID())
+					edge := "implicit"
+					if input.PropagateResult {
+						edge = "explicit"
+					}
+					w.inputs = append(w.inputs, input.InputTask.DotID()+" "+edge)
+				}
+				for _, output := range task.Outputs() {
+					require.Greater(t, output.ID(), task.ID())
+					w.outputs = append(w.outputs, output.DotID())
+				}
+				sort.Strings(w.inputs)
+				sort.Strings(w.outputs)
+				actual[task.DotID()] = w
+			}
+			require.Equal(t, expected, actual)
+		})
+	}
+}
+
 func TestGraph_HasCycles(t *testing.T) {
 	t.Parallel()
 
@@ -221,6 +317,15 @@ func TestGraph_HasCycles(t *testing.T) {
     `)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "cycle detected")
+
+	_, err = pipeline.Parse(`
+        a [type=bridge];
+        b [type=multiply times=1.23];
+        a -> b -> b;
+    `)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `task "b" cannot depend on itself`)
+	require.NotContains(t, err.Error(), "cycle detected")
 }
 
 func TestGraph_ImplicitDependencies(t *testing.T) {
@@ -249,3 +354,1363 @@ func TestGraph_ImplicitDependencies(t *testing.T) {
 	require.True(t, g.HasEdgeFromTo(nodes["b"], nodes["c"]))
 	require.True(t, g.HasEdgeFromTo(nodes["c"], nodes["d"]))
 }
+
+func TestGraph_References(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name string
+		spec string
+		err  string
+	}{
+		{"upstream task", `
+			fetch [type=http method=GET url="https://chain.link"];
+			parse [type=jsonparse path="data,result" data="$(fetch)"];
+			multiply [type=multiply input="$(parse)" times=100];
+			fetch -> parse -> multiply;
+		`, ""},
+		{"transitive input", `
+			fetch [type=http method=GET url="https://chain.link"];
+			parse [type=jsonparse path="data,result"];
+			multiply [type=multiply input="$(fetch.data)" times=100];
+			fetch -> parse -> multiply;
+		`, ""},
+		{"implicit dependency", `
+			fetch [type=http method=GET url="https://chain.link"];
+			parse [type=jsonparse path="data,result" data="$(fetch)"];
+		`, ""},
+		{"run variables", `
+			parse [type=jsonparse path="data,result" data="$(jobRun.requestBody)" other="$( jobSpec.id )"];
+		`, ""},
+		{"downstream task", `
+			fetch [type=http method=GET url="https://chain.link"];
+			parse [type=jsonparse path="data,result" data="$(fetch)" times="$(multiply)"];
+			multiply [type=multiply times=100];
+			fetch -> parse -> multiply;
+		`, `task "parse" references "multiply" which is not an upstream dependency`},
+		{"transitive downstream task", `
+			a [type=memo value=1];
+			b [type=multiply input="$(c.value)" times=2];
+			c [type=multiply times=3];
+			d [type=multiply times=4];
+			a -> b -> d -> c;
+		`, `task "b" references "c" which is not an upstream dependency`},
+		{"own result", `
+			a [type=memo value=1];
+			b [type=multiply input="$(b)" times=2];
+			a -> b;
+		`, `task "b" references "b" which is not an upstream dependency`},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := pipeline.Parse(tt.spec)
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reports every problem", func(t *testing.T) {
+		_, err := pipeline.Parse(`
+ds     [type=nope]
+parse  [type=jsonparse path="a" data="$(parse)"]
+answer [type=median]
+ds -> parse -> answer
+answer -> answer
+`)
+		require.Error(t, err)
+		errs := multierr.Errors(err)
+		require.Len(t, errs, 3, err.Error())
+		assert.EqualError(t, errs[0], `task "answer" cannot depend on itself`)
+		assert.EqualError(t, errs[1], `task "parse" references "parse" which is not an upstream dependency`)
+		assert.EqualError(t, errs[2], `UnmarshalTaskFromMap: unknown task type: "nope"`)
+	})
+
+	t.Run("duplicate declarations", func(t *testing.T) {
+		_, err := pipeline.Parse(`
+a [type=memo value=1]
+b [type=memo value=2]
+a [type=memo value=3]
+subgraph cluster_x { b [type=memo value=4]; a }
+`)
+		require.Error(t, err)
+		errs := multierr.Errors(err)
+		require.Len(t, errs, 2, err.Error())
+		assert.EqualError(t, errs[0], `task "a" is declared 2 times`)
+		assert.EqualError(t, errs[1], `task "b" is declared 2 times`)
+	})
+
+	t.Run("fails fast on malformed DOT", func(t *testing.T) {
+		_, err := pipeline.Parse(`
+a [type=nope
+a -> a
+`)
+		require.Error(t, err)
+		require.Len(t, multierr.Errors(err), 1)
+		require.Contains(t, err.Error(), "could not unmarshal DOT into a pipeline.Graph")
+	})
+}
+
+func TestGraph_ReservedTaskNames(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"input", "INPUT", "Input", "jobSpec", "JOBSPEC", "jobspec", "jobRun", "JobRun", "JOBrun", "jb", "JB", "jB"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			_, err := pipeline.Parse(name + ` [type=memo value=1]`)
+			require.EqualError(t, err, `task name "`+name+`" is reserved, task names can't be any of (case-insensitive): input, jobSpec, jobRun, jb`)
+		})
+	}
+
+	t.Run("similar names", func(t *testing.T) {
+		_, err := pipeline.Parse(`
+inputs   [type=memo value=1]
+jobSpec2 [type=memo value=2]
+jbRun    [type=memo value=3]
+`)
+		require.NoError(t, err)
+	})
+}
+
+func TestGraph_CaseInsensitiveAttributes(t *testing.T) {
+	t.Parallel()
+
+	p, err := pipeline.Parse(`
+		a [type=multiply times=2 input=1];
+		b [Type=multiply Times=3 Input="$(a)"];
+		c [TYPE=multiply TIMES=4 INPUT="$(b)"];
+		d [type=http URL="https://chain.link/Path?Q=A"];
+	`)
+	require.NoError(t, err)
+	for _, id := range []string{"a", "b", "c"} {
+		task := p.ByDotID(id)
+		require.NotNil(t, task, id)
+		require.Equal(t, pipeline.TaskTypeMultiply, task.Type(), id)
+	}
+	require.Equal(t, "3", p.ByDotID("b").(*pipeline.MultiplyTask).Times)
+	require.Equal(t, "4", p.ByDotID("c").(*pipeline.MultiplyTask).Times)
+	// Values are kept verbatim
+	require.Equal(t, "https://chain.link/Path?Q=A", p.ByDotID("d").(*pipeline.HTTPTask).URL)
+
+	g := pipeline.NewGraph()
+	require.NoError(t, g.UnmarshalText([]byte(`a [Type=multiply Times=3];`)))
+	for nodes := g.Nodes(); nodes.Next(); {
+		n := nodes.Node().(encoding.Attributer)
+		require.Equal(t, []encoding.Attribute{{Key: "times", Value: "3"}, {Key: "type", Value: "multiply"}}, n.Attributes())
+	}
+}
+
+func TestGraph_DigraphWrapper(t *testing.T) {
+	t.Parallel()
+
+	const body = `
+		a [type=memo value=1];
+		b [type=multiply times=2];
+		a -> b;
+	`
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"bare body", body},
+		{"digraph", "digraph {" + body + "}"},
+		{"named digraph", "digraph feed {" + body + "}"},
+		{"quoted name", `digraph "my \"feed\"" {` + body + "}"},
+		{"strict", "strict digraph feed {" + body + "}"},
+		{"uppercase", "DIGRAPH {" + body + "}"},
+		{"leading comments", "// exported by graphviz\n# more\n/* multi\nline */\n  digraph {" + body + "}"},
+		{"graph attribute statement", "graph [rankdir=LR];" + body},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := pipeline.Parse(tt.src)
+			require.NoError(t, err)
+			require.Len(t, p.Tasks, 2)
+			require.Equal(t, []pipeline.Task{p.ByDotID("b")}, p.ByDotID("a").Outputs())
+		})
+	}
+
+	t.Run("attribute value starting with digraph", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			a [type=memo value="digraph { b }"];
+			b [type=memo value="digraph"];
+			a -> b;
+		`)
+		require.NoError(t, err)
+		require.Equal(t, "digraph { b }", p.ByDotID("a").(*pipeline.MemoTask).Value)
+		require.Equal(t, "digraph", p.ByDotID("b").(*pipeline.MemoTask).Value)
+	})
+}
+
+func TestGraph_MultilineAttributes(t *testing.T) {
+	t.Parallel()
+
+	const compact = `
+ds1       [type=http method=GET url="https://chain.link/voter_turnout/USA-2020" requestData=<{"hi": "hello"}>];
+ds1_parse [type=jsonparse path="three,four"];
+ds1 -> ds1_parse;
+`
+	pretty := map[string]string{
+		"one attribute per line": `
+ds1 [
+	type=http
+	method=GET
+	url="https://chain.link/voter_turnout/USA-2020"
+	requestData=<{"hi": "hello"}>
+];
+ds1_parse [
+	type=jsonparse
+	path="three,four"
+];
+ds1 -> ds1_parse;
+`,
+		"separators at line ends": `
+ds1 [type=http,
+     method=GET;
+     url="https://chain.link/voter_turnout/USA-2020",
+     requestData=<{"hi": "hello"}>,
+];
+ds1_parse
+	[type=jsonparse
+	 path="three,four"]
+;
+ds1 -> ds1_parse;
+`,
+		"CRLF line endings": "ds1 [\r\n\ttype=http\r\n\tmethod=GET\r\n\turl=\"https://chain.link/voter_turnout/USA-2020\"\r\n" +
+			"\trequestData=<{\"hi\": \"hello\"}>\r\n];\r\nds1_parse [\r\n\ttype=jsonparse\r\n\tpath=\"three,four\"\r\n];\r\nds1 -> ds1_parse;\r\n",
+		"comments between attributes": `
+ds1 [
+	type=http // the data source
+	method=GET
+	# preprocessor style comment
+	url="https://chain.link/voter_turnout/USA-2020" /* inline */ requestData=<{"hi": "hello"}>
+];
+ds1_parse [type=jsonparse
+	path="three,four"];
+ds1 -> ds1_parse;
+`,
+	}
+
+	expected, err := pipeline.Parse(compact)
+	require.NoError(t, err)
+	for name, spec := range pretty {
+		spec := spec
+		t.Run(name, func(t *testing.T) {
+			for _, src := range []string{spec, "digraph {" + spec + "}", "digraph feed {\n" + spec + "\n}"} {
+				p, err := pipeline.Parse(src)
+				require.NoError(t, err)
+				require.Equal(t, expected.Tasks, p.Tasks)
+			}
+		})
+	}
+
+	t.Run("angle bracket quoted values keep their line breaks", func(t *testing.T) {
+		const value = "{\n\t\"hi\": \"hello\",\r\n\t\"list\": [1,\n\t\t2]\n}"
+		p, err := pipeline.Parse("ds1 [\n\ttype=http\n\tmethod=POST\n\turl=\"https://chain.link\"\n\trequestData=<" + value + ">\n];")
+		require.NoError(t, err)
+		require.Equal(t, value, p.ByDotID("ds1").(*pipeline.HTTPTask).RequestData)
+	})
+
+	t.Run("double quoted values spanning lines are unquoted", func(t *testing.T) {
+		p, err := pipeline.Parse("a [\n\ttype=memo\n\tvalue=\"line one\nline \\\"two\\\"\r\n\"\n];\nb [type=memo value=\"joined \\\nline\"];")
+		require.NoError(t, err)
+		require.Equal(t, "line one\nline \"two\"\r\n", p.ByDotID("a").(*pipeline.MemoTask).Value)
+		// A backslash before a line break continues the line, as in DOT
+		require.Equal(t, "joined line", p.ByDotID("b").(*pipeline.MemoTask).Value)
+	})
+}
+
+func TestGraph_AngleBracketQuotedValues(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"plain", `<{"x": 1}>`, `{"x": 1}`},
+		{"nested", `<{"x": <inner>}>`, `{"x": <inner>}`},
+		// the DOT lexer only nests angle brackets one level deep, but multiline values are unquoted before them
+		{"deeply nested multiline", "\"<a <b <c>>\n>\"", "a <b <c>>\n"},
+		{"not enclosed multiline", "\"<a>\nor <b>\"", "<a>\nor <b>"},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			p, err := pipeline.Parse(`a [type=memo value=` + test.value + `];`)
+			require.NoError(t, err)
+			require.Equal(t, test.want, p.ByDotID("a").(*pipeline.MemoTask).Value)
+		})
+	}
+
+	t.Run("unbalanced", func(t *testing.T) {
+		_, err := pipeline.Parse("a [type=memo value=\"<{\\\"x\\\": <inner}\n>\"];")
+		require.ErrorContains(t, err, `task "a": unbalanced angle brackets`)
+		require.ErrorContains(t, err, `1 more '<' than '>'`)
+	})
+}
+
+func TestGraph_DoubleQuotedValues(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"commas", `"0x01,0x02, 0x03"`, `0x01,0x02, 0x03`},
+		{"brackets", `"[1, 2], {3}, (4)"`, `[1, 2], {3}, (4)`},
+		{"escaped quotes", `"{\"a\": \"x,y\"}"`, `{"a": "x,y"}`},
+		{"quotes only", `"\"a, b\""`, `"a, b"`},
+		{"empty", `""`, ``},
+		// not valid Go escapes, so unquoted per the DOT rules, keeping the backslashes
+		{"other backslashes", `"\d+,\d+"`, `\d+,\d+`},
+		{"other backslashes and escaped quotes", `"\d \"x,y\""`, `\d "x,y"`},
+		{"angle brackets", `"<a, b>"`, `a, b`},
+		{"angle brackets and escaped quotes", `"<{\"a\": [1, 2]}>"`, `{"a": [1, 2]}`},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			p, err := pipeline.Parse(`a [type=memo value=` + test.value + `];`)
+			require.NoError(t, err)
+			require.Equal(t, test.want, p.ByDotID("a").(*pipeline.MemoTask).Value)
+		})
+	}
+}
+
+func TestGraph_EmptyStatements(t *testing.T) {
+	t.Parallel()
+
+	expected, err := pipeline.Parse(`a [type=memo value=1]; b [type=multiply times=2]; a -> b;`)
+	require.NoError(t, err)
+	for _, test := range []struct {
+		name string
+		src  string
+	}{
+		{"double semicolons", `a [type=memo value=1];; b [type=multiply times=2];; a -> b;;`},
+		{"leading semicolons", `; ;a [type=memo value=1]; b [type=multiply times=2]; a -> b`},
+		{"trailing semicolons before closing brace", `digraph { a [type=memo value=1]; b [type=multiply times=2]; a -> b;; }`},
+		{"semicolon after opening brace", "digraph feed {;\n a [type=memo value=1]; b [type=multiply times=2]; a -> b; }"},
+		{"semicolons between comments", "a [type=memo value=1]; // one; two;;\n; /* ;; \" */ ;\n# ;;\nb [type=multiply times=2]; a -> b;"},
+		{"subgraph", "subgraph x { ;a [type=memo value=1];; };; b [type=multiply times=2]; a -> b;"},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			p, err := pipeline.Parse(test.src)
+			require.NoError(t, err)
+			require.Equal(t, expected.Tasks, p.Tasks)
+			assert.Equal(t, test.src, p.Source)
+		})
+	}
+
+	t.Run("semicolons in attribute values", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			a [type=memo value="x;;y;"];;
+			b [type=memo value=<{"x": ";;"};>];;
+			c [type=memo value="{\";\": \";;\"}"];;
+		`)
+		require.NoError(t, err)
+		assert.Equal(t, "x;;y;", p.ByDotID("a").(*pipeline.MemoTask).Value)
+		assert.Equal(t, `{"x": ";;"};`, p.ByDotID("b").(*pipeline.MemoTask).Value)
+		assert.Equal(t, `{";": ";;"}`, p.ByDotID("c").(*pipeline.MemoTask).Value)
+	})
+
+	t.Run("parse errors keep their position", func(t *testing.T) {
+		_, err := pipeline.Parse("a [type=memo value=1];;\nb [type=memo value=2] -> ->;")
+		require.Error(t, err)
+		_, expectedErr := pipeline.Parse("a [type=memo value=1]; \nb [type=memo value=2] -> ->;")
+		require.EqualError(t, err, expectedErr.Error())
+	})
+}
+
+func TestPipeline_Walk(t *testing.T) {
+	t.Parallel()
+
+	p, err := pipeline.Parse(pipeline.DotStr)
+	require.NoError(t, err)
+
+	t.Run("visits inputs before outputs", func(t *testing.T) {
+		visited := make(map[string]bool)
+		var order []string
+		err := p.Walk(func(task pipeline.Task) error {
+			for _, input := range task.Inputs() {
+				require.True(t, visited[input.InputTask.DotID()], "%s visited before its input %s", task.DotID(), input.InputTask.DotID())
+			}
+			visited[task.DotID()] = true
+			order = append(order, task.DotID())
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"ds1", "ds1_parse", "ds1_multiply", "ds2", "ds2_parse", "ds2_multiply", "answer1", "answer2"}, order)
+	})
+
+	t.Run("reverse visits outputs before inputs", func(t *testing.T) {
+		visited := make(map[string]bool)
+		var order []string
+		err := p.WalkReverse(func(task pipeline.Task) error {
+			for _, output := range task.Outputs() {
+				require.True(t, visited[output.DotID()], "%s visited before its output %s", task.DotID(), output.DotID())
+			}
+			visited[task.DotID()] = true
+			order = append(order, task.DotID())
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"answer2", "answer1", "ds2_multiply", "ds2_parse", "ds2", "ds1_multiply", "ds1_parse", "ds1"}, order)
+	})
+
+	t.Run("stops at first error", func(t *testing.T) {
+		var visits int
+		err := p.Walk(func(task pipeline.Task) error {
+			visits++
+			if task.DotID() == "ds1_multiply" {
+				return errors.New("stop")
+			}
+			return nil
+		})
+		require.EqualError(t, err, "stop")
+		require.Equal(t, 3, visits)
+
+		visits = 0
+		err = p.WalkReverse(func(task pipeline.Task) error {
+			visits++
+			if task.DotID() == "answer1" {
+				return errors.New("stop")
+			}
+			return nil
+		})
+		require.EqualError(t, err, "stop")
+		require.Equal(t, 2, visits)
+	})
+}
+
+func TestPipeline_DependenciesOf(t *testing.T) {
+	t.Parallel()
+
+	p, err := pipeline.Parse(pipeline.DotStr)
+	require.NoError(t, err)
+
+	dotIDs := func(tasks []pipeline.Task) []string {
+		var ids []string
+		for _, task := range tasks {
+			ids = append(ids, task.DotID())
+		}
+		return ids
+	}
+
+	deps, err := p.DependenciesOf("answer1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ds1", "ds1_parse", "ds1_multiply", "ds2", "ds2_parse", "ds2_multiply"}, dotIDs(deps))
+
+	deps, err = p.DependenciesOf("ds2_parse")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ds2"}, dotIDs(deps))
+
+	deps, err = p.DependenciesOf("ds1")
+	require.NoError(t, err)
+	require.Empty(t, deps)
+
+	dependents, err := p.DependentsOf("ds1_parse")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ds1_multiply", "answer1"}, dotIDs(dependents))
+
+	dependents, err = p.DependentsOf("ds2")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ds2_parse", "ds2_multiply", "answer1"}, dotIDs(dependents))
+
+	dependents, err = p.DependentsOf("answer2")
+	require.NoError(t, err)
+	require.Empty(t, dependents)
+
+	_, err = p.DependenciesOf("nope")
+	require.EqualError(t, err, `unknown task "nope"`)
+	_, err = p.DependentsOf("nope")
+	require.EqualError(t, err, `unknown task "nope"`)
+}
+
+func TestPipeline_Adjacency(t *testing.T) {
+	t.Parallel()
+
+	p, err := pipeline.Parse(`
+		top    [type=memo value=1];
+		left   [type=multiply times=2];
+		right  [type=multiply times=3];
+		bottom [type=sum values=<[ $(left), $(right) ]>];
+		top -> left -> bottom;
+		top -> right;
+	`)
+	require.NoError(t, err)
+
+	require.Equal(t, map[string][]string{
+		"top":    {"left", "right"},
+		"left":   {"bottom"},
+		"right":  {"bottom"},
+		"bottom": {},
+	}, p.Adjacency())
+	require.Equal(t, map[string][]string{
+		"top":    {},
+		"left":   {"top"},
+		"right":  {"top"},
+		"bottom": {"left", "right"},
+	}, p.ReverseAdjacency())
+
+	// a snapshot
+	p.Adjacency()["top"][0] = "changed"
+	require.Equal(t, []string{"left", "right"}, p.Adjacency()["top"])
+}
+
+func TestPipeline_TasksWithoutTimeout(t *testing.T) {
+	t.Parallel()
+
+	p, err := pipeline.Parse(`
+		fetch         [type=http method=GET url="https://example.com" timeout="0s"];
+		bridged       [type=bridge name=foo];
+		requested     [type=http method=GET url="https://example.com" timeout="0s" requestTimeout="0s"];
+		limited       [type=http method=GET url="https://example.com" timeout="10s" requestTimeout="5s"];
+		parse         [type=jsonparse path="data"];
+		parseTimeout  [type=jsonparse path="data" timeout="1s"];
+		answer        [type=median];
+		fetch -> parse -> answer;
+		bridged -> parseTimeout -> answer;
+		requested -> answer;
+		limited -> answer;
+	`)
+	require.NoError(t, err)
+
+	var names []string
+	for _, task := range p.TasksWithoutTimeout() {
+		names = append(names, task.DotID())
+	}
+	// fetch and requested disable the default http timeout, bridged gets it
+	require.Equal(t, []string{"fetch", "requested", "parse", "answer"}, names)
+}
+
+func TestParse_SpecVars(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defined and referenced", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			vars   [baseURL="https://example.com/api" apiKey="s3cr3t" times=100];
+			fetch  [type=http method=GET url="$var.baseURL/price?key=$var.apiKey"];
+			volume [type=http method=GET url="$var.baseURL/volume?key=$var.APIKEY"];
+			parse  [type=jsonparse path="data" data="$(fetch)"];
+			mul    [type=multiply times="$var.times"];
+			fetch -> parse -> mul;
+			volume -> mul;
+		`)
+		require.NoError(t, err)
+
+		// the vars node isn't a task
+		require.Len(t, p.Tasks, 4)
+		_, ok := p.TaskByID("vars")
+		assert.False(t, ok)
+
+		assert.Equal(t, "https://example.com/api/price?key=s3cr3t", p.ByDotID("fetch").(*pipeline.HTTPTask).URL)
+		assert.Equal(t, "https://example.com/api/volume?key=s3cr3t", p.ByDotID("volume").(*pipeline.HTTPTask).URL)
+		assert.Equal(t, "100", p.ByDotID("mul").(*pipeline.MultiplyTask).Times)
+		// run variables are left alone
+		assert.Equal(t, "$(fetch)", p.ByDotID("parse").(*pipeline.JSONParseTask).Data)
+	})
+
+	t.Run("task named vars", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			vars [type=memo value="$var.x"];
+		`)
+		require.Nil(t, p)
+		require.EqualError(t, err, `task "vars" references undefined spec variable "x"`)
+	})
+
+	t.Run("undefined reference", func(t *testing.T) {
+		_, err := pipeline.Parse(`
+			vars  [baseURL="https://example.com"];
+			fetch [type=http method=GET url="$var.baseURL/$var.path" requestData="$var.body"];
+			mul   [type=multiply times="$var.times"];
+			fetch -> mul;
+		`)
+		require.EqualError(t, err, `task "fetch" references undefined spec variable "body"; `+
+			`task "fetch" references undefined spec variable "path"; `+
+			`task "mul" references undefined spec variable "times"`)
+	})
+
+	t.Run("vars node with dependencies", func(t *testing.T) {
+		_, err := pipeline.Parse(`
+			vars [x=1];
+			a    [type=memo value="$var.x"];
+			vars -> a;
+		`)
+		require.EqualError(t, err, "the vars node declares spec variables and cannot have dependencies")
+	})
+}
+
+func TestPipeline_NamedOutputs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unique names", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			a      [type=memo value=1];
+			price  [type=multiply times=2 outputName="price"];
+			volume [type=multiply times=3 outputName="volume"];
+			other  [type=multiply times=4];
+			a -> price;
+			a -> volume;
+			a -> other;
+		`)
+		require.NoError(t, err)
+
+		outputs, err := p.NamedOutputs()
+		require.NoError(t, err)
+		require.Len(t, outputs, 2)
+		assert.Equal(t, "price", outputs["price"].DotID())
+		assert.Equal(t, "volume", outputs["volume"].DotID())
+
+		name, ok := p.ByDotID("other").OutputName()
+		assert.False(t, ok)
+		assert.Empty(t, name)
+	})
+
+	t.Run("duplicate names", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			a [type=memo value=1];
+			b [type=multiply times=2 outputName="result"];
+			c [type=multiply times=3 outputName="result"];
+			a -> b;
+			a -> c;
+		`)
+		require.NoError(t, err)
+
+		_, err = p.NamedOutputs()
+		require.EqualError(t, err, `output name "result" is used by both task b and task c`)
+	})
+
+	t.Run("not terminal", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			a [type=memo value=1 outputName="result"];
+			b [type=multiply times=2];
+			a -> b;
+		`)
+		require.NoError(t, err)
+
+		_, err = p.NamedOutputs()
+		require.EqualError(t, err, `task a has output name "result" but is not a terminal task`)
+	})
+}
+
+func TestPipeline_TotalTimeout(t *testing.T) {
+	t.Parallel()
+
+	// the longest path is top -> right -> bottom, taking up to 25s
+	const body = `
+		top    [type=memo value=1 timeout="5s"];
+		left   [type=multiply times=2 timeout="5s"];
+		right  [type=multiply times=3 timeout="15s"];
+		bottom [type=sum values=<[ $(left), $(right) ]> timeout="5s"];
+		top -> left -> bottom;
+		top -> right;
+	`
+
+	t.Run("not declared", func(t *testing.T) {
+		p, err := pipeline.Parse(body)
+		require.NoError(t, err)
+		_, ok := p.TotalTimeout()
+		require.False(t, ok)
+	})
+
+	for _, test := range []struct {
+		name string
+		spec string
+	}{
+		{"graph attribute", `timeout="30s"` + body},
+		{"graph attribute statement", `graph [timeout="30s"];` + body},
+		{"digraph header", "digraph {\nTimeout=\"30s\"\n" + body + "}"},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			p, err := pipeline.Parse(test.spec)
+			require.NoError(t, err)
+			timeout, ok := p.TotalTimeout()
+			require.True(t, ok)
+			require.Equal(t, 30*time.Second, timeout)
+
+			reparsed, err := pipeline.Parse(p.DOT())
+			require.NoError(t, err, p.DOT())
+			timeout, ok = reparsed.TotalTimeout()
+			require.True(t, ok)
+			require.Equal(t, 30*time.Second, timeout)
+		})
+	}
+
+	t.Run("subgraph attributes are left out", func(t *testing.T) {
+		p, err := pipeline.Parse(`subgraph cluster_a { timeout="1s"; a [type=memo value=1]; }`)
+		require.NoError(t, err)
+		_, ok := p.TotalTimeout()
+		require.False(t, ok)
+	})
+
+	t.Run("exceeded by the longest path", func(t *testing.T) {
+		_, err := pipeline.Parse(`timeout="20s"` + body)
+		require.EqualError(t, err, "task timeouts along top -> right -> bottom add up to 25s, exceeding the pipeline timeout of 20s")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := pipeline.Parse(`timeout="soon"` + body)
+		require.ErrorContains(t, err, `invalid pipeline timeout "soon"`)
+	})
+
+	t.Run("checked on attribute overrides", func(t *testing.T) {
+		p, err := pipeline.Parse(`timeout="30s"` + body)
+		require.NoError(t, err)
+		_, err = p.WithAttributeOverride("left", "timeout", "25s")
+		require.EqualError(t, err, "task timeouts along top -> left -> bottom add up to 35s, exceeding the pipeline timeout of 30s")
+
+		op, err := p.WithAttributeOverride("left", "timeout", "10s")
+		require.NoError(t, err)
+		timeout, ok := op.TotalTimeout()
+		require.True(t, ok)
+		require.Equal(t, 30*time.Second, timeout)
+	})
+}
+
+func TestPipeline_Schedule(t *testing.T) {
+	t.Parallel()
+
+	// a diamond, with a task depending on both ends of it and one depending on an implicit edge
+	p, err := pipeline.Parse(`
+ds      [type=memo value=1]
+left    [type=multiply times=2]
+right   [type=multiply times=3]
+join    [type=median]
+other   [type=memo value=2]
+both    [type=median]
+implied [type=multiply input="$(join)" times=4]
+ds -> left -> join
+ds -> right -> join
+join -> both
+other -> both
+`)
+	require.NoError(t, err)
+
+	t.Run("workers", func(t *testing.T) {
+		s := p.Schedule()
+		var mu sync.Mutex
+		done := map[string]bool{}
+		runs := map[string]int{}
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for task := range s.Tasks() {
+					mu.Lock()
+					runs[task.DotID()]++
+					for _, input := range task.Inputs() {
+						assert.True(t, done[input.InputTask.DotID()], "%s yielded before its input %s is done", task.DotID(), input.InputTask.DotID())
+					}
+					mu.Unlock()
+					time.Sleep(time.Millisecond)
+					mu.Lock()
+					done[task.DotID()] = true
+					mu.Unlock()
+					s.Done(task)
+				}
+			}()
+		}
+		wg.Wait()
+
+		require.Len(t, runs, len(p.Tasks))
+		for dotID, n := range runs {
+			assert.Equal(t, 1, n, dotID)
+		}
+	})
+
+	t.Run("done again", func(t *testing.T) {
+		s := p.Schedule()
+		var yielded []string
+		for task := range s.Tasks() {
+			yielded = append(yielded, task.DotID())
+			s.Done(task)
+			s.Done(task)
+			// not yielded yet, so ignored
+			s.Done(p.ByDotID("both"))
+		}
+		require.Len(t, yielded, len(p.Tasks))
+		assert.Equal(t, "ds", yielded[0])
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, ok := <-(&pipeline.Pipeline{}).Schedule().Tasks()
+		assert.False(t, ok)
+	})
+}
+
+func TestPipeline_TaskByID(t *testing.T) {
+	t.Parallel()
+
+	p, err := pipeline.Parse(pipeline.DotStr)
+	require.NoError(t, err)
+
+	for i, task := range p.Tasks {
+		byID, ok := p.TaskByID(task.DotID())
+		require.True(t, ok, task.DotID())
+		require.Same(t, task, byID)
+		require.Same(t, task, p.ByDotID(task.DotID()))
+
+		byIndex, ok := p.TaskByIndex(i)
+		require.True(t, ok)
+		require.Same(t, task, byIndex)
+		require.Equal(t, i, byIndex.ID())
+	}
+
+	for _, id := range []string{"nope", "", "DS1"} {
+		task, ok := p.TaskByID(id)
+		require.False(t, ok, id)
+		require.Nil(t, task, id)
+		require.Nil(t, p.ByDotID(id), id)
+	}
+	for _, i := range []int{-1, len(p.Tasks)} {
+		task, ok := p.TaskByIndex(i)
+		require.False(t, ok, i)
+		require.Nil(t, task, i)
+	}
+
+	t.Run("copies are indexed", func(t *testing.T) {
+		wp, err := pipeline.Parse(`
+			a [type=memo value=1];
+			b [type=multiply times=2 disabled=true];
+			c [type=multiply times=3];
+			a -> b -> c;
+		`)
+		require.NoError(t, err)
+		enabled, err := wp.WithoutDisabled()
+		require.NoError(t, err)
+		_, ok := enabled.TaskByID("b")
+		require.False(t, ok)
+		c, ok := enabled.TaskByID("c")
+		require.True(t, ok)
+		require.Same(t, enabled.Tasks[1], c)
+		require.NotSame(t, wp.ByDotID("c"), c)
+
+		var unmarshaled pipeline.Pipeline
+		require.NoError(t, unmarshaled.UnmarshalText([]byte(wp.Source)))
+		a, ok := unmarshaled.TaskByID("a")
+		require.True(t, ok)
+		require.Same(t, unmarshaled.Tasks[0], a)
+	})
+
+	t.Run("zero pipeline", func(t *testing.T) {
+		var zero pipeline.Pipeline
+		task, ok := zero.TaskByID("a")
+		require.False(t, ok)
+		require.Nil(t, task)
+		_, ok = zero.TaskByIndex(0)
+		require.False(t, ok)
+	})
+}
+
+func TestPipeline_DOT(t *testing.T) {
+	t.Parallel()
+
+	reparse := func(t *testing.T, p *pipeline.Pipeline) *pipeline.Pipeline {
+		dot := p.DOT()
+		reparsed, err := pipeline.Parse(dot)
+		require.NoError(t, err, dot)
+		require.Equal(t, p.Tasks, reparsed.Tasks, dot)
+		require.Equal(t, dot, reparsed.DOT(), "output should be stable")
+		return reparsed
+	}
+
+	t.Run("normalizes the source", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			// comments and whitespace are dropped
+			b [type=multiply times=2 input="$(a)"];
+			a [
+				type=memo
+				value=<{"hi": "hello"}>
+			];
+			c [type=median];
+			a -> c;
+			b -> c;
+		`)
+		require.NoError(t, err)
+		require.Equal(t, `digraph {
+	a [type=memo value="{\"hi\": \"hello\"}"];
+	b [input="$(a)" times=2 type=multiply];
+	c [type=median];
+
+	a -> c;
+	b -> c;
+}
+`, p.DOT())
+		reparse(t, p)
+	})
+
+	t.Run("reparses", func(t *testing.T) {
+		p, err := pipeline.Parse(pipeline.DotStr)
+		require.NoError(t, err)
+		reparse(t, p)
+	})
+
+	t.Run("reflects transformations", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			a [type=memo value=1];
+			b [type=multiply times=2 disabled=true];
+			c [type=multiply times=3];
+			a -> b -> c;
+		`)
+		require.NoError(t, err)
+		wp, err := p.WithoutDisabled()
+		require.NoError(t, err)
+		enabled := reparse(t, wp)
+		require.Len(t, enabled.Tasks, 2)
+		require.NotContains(t, wp.DOT(), "disabled")
+		require.Contains(t, wp.DOT(), "a -> c;")
+	})
+
+	t.Run("quotes IDs and values as needed", func(t *testing.T) {
+		p, err := pipeline.Parse("\"my task\" [type=memo value=\"line one\nline \\\"two\\\"\" group=\"edge\"];\n" +
+			"\"node\" [type=memo value=\"\"];\n\"my task\" -> \"node\";")
+		require.NoError(t, err)
+		dot := p.DOT()
+		require.Contains(t, dot, `"my task" -> "node";`)
+		require.Contains(t, dot, `group="edge"`)
+		reparsed := reparse(t, p)
+		require.Equal(t, "line one\nline \"two\"", reparsed.ByDotID("my task").(*pipeline.MemoTask).Value)
+	})
+
+	t.Run("clusters", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			subgraph cluster_feeds {
+				ds1 [type=bridge name=voter_turnout];
+			}
+			answer [type=median];
+			ds1 -> answer;
+		`)
+		require.NoError(t, err)
+		require.Equal(t, "feeds", reparse(t, p).ByDotID("ds1").Base().Group)
+	})
+}
+
+func TestGraph_Clusters(t *testing.T) {
+	t.Parallel()
+
+	t.Run("flattens clusters and records groups", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			subgraph cluster_feeds {
+				ds1 [type=bridge name=voter_turnout];
+				ds2 [type=http method=GET url="https://chain.link/voter_turnout/USA-2020"];
+			}
+			subgraph cluster_aggregation {
+				answer [type=median];
+			}
+			other [type=multiply times=2];
+
+			ds1 -> answer;
+			ds2 -> answer -> other;
+		`)
+		require.NoError(t, err)
+		require.Len(t, p.Tasks, 4)
+
+		require.Equal(t, "feeds", p.ByDotID("ds1").Base().Group)
+		require.Equal(t, "feeds", p.ByDotID("ds2").Base().Group)
+		require.Equal(t, "aggregation", p.ByDotID("answer").Base().Group)
+		require.Equal(t, "", p.ByDotID("other").Base().Group)
+
+		answer := p.ByDotID("answer")
+		require.Len(t, answer.Inputs(), 2)
+		require.Equal(t, "ds1", answer.Inputs()[0].InputTask.DotID())
+		require.Equal(t, "ds2", answer.Inputs()[1].InputTask.DotID())
+		require.Len(t, answer.Outputs(), 1)
+		require.Equal(t, "other", answer.Outputs()[0].DotID())
+	})
+
+	t.Run("edges within a cluster", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			subgraph cluster_feeds {
+				ds1 [type=bridge name=voter_turnout];
+				ds1_parse [type=jsonparse path="one,two"];
+				ds1 -> ds1_parse;
+			}
+			answer [type=median];
+			ds1_parse -> answer;
+		`)
+		require.NoError(t, err)
+		require.Equal(t, "feeds", p.ByDotID("ds1").Base().Group)
+		require.Equal(t, "feeds", p.ByDotID("ds1_parse").Base().Group)
+		require.Equal(t, "", p.ByDotID("answer").Base().Group)
+		require.Equal(t, "ds1", p.ByDotID("ds1_parse").Inputs()[0].InputTask.DotID())
+	})
+
+	t.Run("rejects nested clusters", func(t *testing.T) {
+		_, err := pipeline.Parse(`
+			subgraph cluster_outer {
+				subgraph cluster_inner {
+					ds1 [type=bridge name=voter_turnout];
+				}
+			}
+		`)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `cluster "cluster_inner" is nested within cluster "cluster_outer"`)
+	})
+
+	t.Run("rejects a conflicting group attribute", func(t *testing.T) {
+		_, err := pipeline.Parse(`
+			subgraph cluster_feeds {
+				ds1 [type=bridge name=voter_turnout group=other];
+			}
+		`)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `task "ds1" has group "other" but is declared in cluster "feeds"`)
+	})
+}
+
+func TestPipeline_WithoutDisabled(t *testing.T) {
+	t.Parallel()
+
+	t.Run("disabled middle task", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			ds1          [type=memo value=1];
+			ds2          [type=memo value=2];
+			ds_parse     [type=jsonparse path="data" disabled=true];
+			ds_multiply  [type=multiply times=10];
+			answer       [type=sum values=<[ $(ds_multiply) ]> disabled=false];
+			ds1 -> ds_parse;
+			ds2 -> ds_parse;
+			ds_parse -> ds_multiply -> answer;
+		`)
+		require.NoError(t, err)
+		require.True(t, p.ByDotID("ds_parse").Disabled())
+		require.False(t, p.ByDotID("answer").Disabled())
+		require.False(t, p.ByDotID("ds1").Disabled())
+
+		wp, err := p.WithoutDisabled()
+		require.NoError(t, err)
+		require.Len(t, wp.Tasks, 4)
+		require.Len(t, p.Tasks, 5)
+		require.Nil(t, wp.ByDotID("ds_parse"))
+
+		multiply := wp.ByDotID("ds_multiply")
+		require.Equal(t, []pipeline.TaskDependency{
+			{PropagateResult: true, InputTask: wp.ByDotID("ds1")},
+			{PropagateResult: true, InputTask: wp.ByDotID("ds2")},
+		}, multiply.Inputs())
+		require.Equal(t, []pipeline.Task{multiply}, wp.ByDotID("ds1").Outputs())
+		require.Equal(t, []pipeline.Task{multiply}, wp.ByDotID("ds2").Outputs())
+
+		deps, err := wp.DependenciesOf("answer")
+		require.NoError(t, err)
+		require.Len(t, deps, 3)
+
+		require.NotEqual(t, p.SpecHash(), wp.SpecHash())
+		reparsed, err := pipeline.Parse(wp.Source)
+		require.NoError(t, err)
+		require.Len(t, reparsed.Tasks, 4)
+		require.Nil(t, reparsed.ByDotID("ds_parse"))
+		require.Equal(t, []pipeline.TaskDependency{
+			{PropagateResult: true, InputTask: reparsed.ByDotID("ds1")},
+			{PropagateResult: true, InputTask: reparsed.ByDotID("ds2")},
+		}, reparsed.ByDotID("ds_multiply").Inputs())
+	})
+
+	t.Run("chain of disabled tasks", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			a [type=memo value=1];
+			b [type=multiply times=2 disabled=true];
+			c [type=multiply times=3 disabled=true];
+			d [type=multiply times=4];
+			a -> b -> c -> d;
+		`)
+		require.NoError(t, err)
+
+		wp, err := p.WithoutDisabled()
+		require.NoError(t, err)
+		require.Len(t, wp.Tasks, 2)
+		require.Equal(t, []pipeline.TaskDependency{{PropagateResult: true, InputTask: wp.ByDotID("a")}}, wp.ByDotID("d").Inputs())
+	})
+
+	t.Run("bypass edges are explicit", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			a [type=memo value=1];
+			b [type=memo value="$(a)" disabled=true];
+			c [type=multiply times=3];
+			b -> c;
+		`)
+		require.NoError(t, err)
+
+		wp, err := p.WithoutDisabled()
+		require.NoError(t, err)
+		require.Equal(t, []pipeline.TaskDependency{{PropagateResult: true, InputTask: wp.ByDotID("a")}}, wp.ByDotID("c").Inputs())
+
+		reparsed, err := pipeline.Parse(wp.DOT())
+		require.NoError(t, err)
+		require.Equal(t, []pipeline.TaskDependency{{PropagateResult: true, InputTask: reparsed.ByDotID("a")}}, reparsed.ByDotID("c").Inputs())
+	})
+
+	t.Run("implicit edges between enabled tasks stay implicit", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			a [type=memo value=1];
+			b [type=memo value=2 disabled=true];
+			c [type=multiply input="$(a)" times=3];
+			b -> c;
+		`)
+		require.NoError(t, err)
+
+		wp, err := p.WithoutDisabled()
+		require.NoError(t, err)
+		require.Equal(t, []pipeline.TaskDependency{{PropagateResult: false, InputTask: wp.ByDotID("a")}}, wp.ByDotID("c").Inputs())
+
+		reparsed, err := pipeline.Parse(wp.DOT())
+		require.NoError(t, err)
+		require.Equal(t, []pipeline.TaskDependency{{PropagateResult: false, InputTask: reparsed.ByDotID("a")}}, reparsed.ByDotID("c").Inputs())
+	})
+
+	t.Run("nothing disabled", func(t *testing.T) {
+		p, err := pipeline.Parse(pipeline.DotStr)
+		require.NoError(t, err)
+		wp, err := p.WithoutDisabled()
+		require.NoError(t, err)
+		require.Same(t, p, wp)
+	})
+}
+
+func TestPipeline_WithAttributeOverride(t *testing.T) {
+	t.Parallel()
+
+	p, err := pipeline.Parse(`
+		a        [type=memo value=2];
+		ds       [type=http method=GET url="https://a.example.com"];
+		ds_parse [type=jsonparse path="data"];
+		answer   [type=multiply times=10];
+		ds -> ds_parse -> answer;
+	`)
+	require.NoError(t, err)
+
+	t.Run("valid", func(t *testing.T) {
+		op, err := p.WithAttributeOverride("ds", "URL", "https://b.example.com")
+		require.NoError(t, err)
+
+		require.Equal(t, "https://b.example.com", op.ByDotID("ds").(*pipeline.HTTPTask).URL)
+		require.Equal(t, "https://a.example.com", p.ByDotID("ds").(*pipeline.HTTPTask).URL)
+		require.Equal(t, []pipeline.TaskDependency{{PropagateResult: true, InputTask: op.ByDotID("ds")}}, op.ByDotID("ds_parse").Inputs())
+		require.Equal(t, []pipeline.TaskDependency{{PropagateResult: true, InputTask: p.ByDotID("ds")}}, p.ByDotID("ds_parse").Inputs())
+		require.NotEqual(t, p.SpecHash(), op.SpecHash())
+
+		reparsed, err := pipeline.Parse(op.Source)
+		require.NoError(t, err)
+		require.Equal(t, "https://b.example.com", reparsed.ByDotID("ds").(*pipeline.HTTPTask).URL)
+	})
+
+	t.Run("reference", func(t *testing.T) {
+		op, err := p.WithAttributeOverride("answer", "times", "$(a)")
+		require.NoError(t, err)
+		require.Equal(t, []pipeline.TaskDependency{
+			{PropagateResult: false, InputTask: op.ByDotID("a")},
+			{PropagateResult: true, InputTask: op.ByDotID("ds_parse")},
+		}, op.ByDotID("answer").Inputs())
+		require.Len(t, p.ByDotID("answer").Inputs(), 1)
+
+		// overriding the reference away drops the dependency
+		op, err = op.WithAttributeOverride("answer", "times", "3")
+		require.NoError(t, err)
+		require.Equal(t, []pipeline.TaskDependency{{PropagateResult: true, InputTask: op.ByDotID("ds_parse")}}, op.ByDotID("answer").Inputs())
+		require.Empty(t, op.ByDotID("a").Outputs())
+	})
+
+	t.Run("spec variables", func(t *testing.T) {
+		vp, err := pipeline.Parse(`
+			vars [base="https://b.example.com"];
+			ds   [type=http method=GET url="$var.base/a"];
+		`)
+		require.NoError(t, err)
+		op, err := vp.WithAttributeOverride("ds", "url", "$var.BASE/b")
+		require.NoError(t, err)
+		require.Equal(t, "https://b.example.com/b", op.ByDotID("ds").(*pipeline.HTTPTask).URL)
+
+		_, err = vp.WithAttributeOverride("ds", "url", "$var.nope/b")
+		require.EqualError(t, err, `task "ds" references undefined spec variable "nope"`)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := p.WithAttributeOverride("nope", "url", "https://b.example.com")
+		require.EqualError(t, err, `task "nope" not found`)
+
+		_, err = p.WithAttributeOverride("ds", "uri", "https://b.example.com")
+		require.EqualError(t, err, `task ds: unknown attribute "uri" for type http`)
+
+		_, err = p.WithAttributeOverride("ds", "type", "memo")
+		require.Error(t, err)
+
+		_, err = p.WithAttributeOverride("ds", "timeout", "soon")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "task ds")
+
+		_, err = p.WithAttributeOverride("ds", "url", "$(answer)")
+		require.EqualError(t, err, `task "ds" references "answer" which is not an upstream dependency`)
+
+		_, err = p.WithAttributeOverride("answer", "times", "$(answer)")
+		require.EqualError(t, err, `task "answer" references "answer" which is not an upstream dependency`)
+
+		require.Equal(t, "https://a.example.com", p.ByDotID("ds").(*pipeline.HTTPTask).URL)
+	})
+}
+
+// greetTask is a task type defined outside the pipeline package, see TestParseWithResolver.
+type greetTask struct {
+	pipeline.BaseTask
+	Name string
+}
+
+func (t *greetTask) Type() pipeline.TaskType { return "greet" }
+
+func (t *greetTask) Run(context.Context, logger.Logger, pipeline.Vars, []pipeline.Result) (pipeline.Result, pipeline.RunInfo) {
+	return pipeline.Result{Value: "hello " + t.Name}, pipeline.RunInfo{}
+}
+
+func TestParseWithResolver(t *testing.T) {
+	t.Parallel()
+
+	resolve := func(taskType pipeline.TaskType, attrs map[string]string, id int, dotID string) (pipeline.Task, error) {
+		if taskType != "greet" {
+			return nil, nil
+		}
+		if attrs["name"] == "" {
+			return nil, errors.New("greet: missing name")
+		}
+		return &greetTask{Name: attrs["name"]}, nil
+	}
+	p, err := pipeline.ParseWithResolver(`
+		greeting [type=GREET name=bob];
+		ds       [type=http method=GET url="https://a.example.com" disabled=true];
+		answer   [type=memo value="$(greeting)"];
+		greeting -> ds -> answer;
+	`, resolve)
+	require.NoError(t, err)
+
+	greeting, ok := p.ByDotID("greeting").(*greetTask)
+	require.True(t, ok)
+	assert.Equal(t, "greeting", greeting.DotID())
+	assert.Equal(t, 0, greeting.ID())
+	assert.Equal(t, []pipeline.TaskDependency{{PropagateResult: true, InputTask: greeting}}, p.ByDotID("ds").Inputs())
+	assert.IsType(t, &pipeline.HTTPTask{}, p.ByDotID("ds"))
+	result, _ := greeting.Run(context.Background(), logger.TestLogger(t), pipeline.NewVarsFrom(nil), nil)
+	assert.Equal(t, "hello bob", result.Value)
+
+	t.Run("derived pipelines", func(t *testing.T) {
+		enabled, err := p.WithoutDisabled()
+		require.NoError(t, err)
+		require.IsType(t, &greetTask{}, enabled.ByDotID("greeting"))
+		assert.Equal(t, []pipeline.TaskDependency{{PropagateResult: true, InputTask: enabled.ByDotID("greeting")}},
+			enabled.ByDotID("answer").Inputs())
+
+		op, err := p.WithAttributeOverride("greeting", "name", "alice")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", op.ByDotID("greeting").(*greetTask).Name)
+		assert.Equal(t, "bob", greeting.Name)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := pipeline.ParseWithResolver(`greeting [type=greet];`, resolve)
+		require.EqualError(t, err, "greet: missing name")
+
+		_, err = pipeline.ParseWithResolver(`a [type=nope];`, resolve)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown task type: "nope"`)
+
+		_, err = pipeline.Parse(`greeting [type=greet name=bob];`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown task type: "greet"`)
+
+		// a resolver failing when the tasks are built again fails the derived pipeline
+		var builds int
+		flaky := func(taskType pipeline.TaskType, attrs map[string]string, id int, dotID string) (pipeline.Task, error) {
+			if taskType != "greet" {
+				return nil, nil
+			}
+			if builds++; builds > 1 {
+				return nil, errors.New("greet: unavailable")
+			}
+			return &greetTask{Name: attrs["name"]}, nil
+		}
+		fp, err := pipeline.ParseWithResolver(`
+			greeting [type=greet name=bob];
+			ds       [type=http method=GET url="https://a.example.com" disabled=true];
+			greeting -> ds;
+		`, flaky)
+		require.NoError(t, err)
+		_, err = fp.WithoutDisabled()
+		require.EqualError(t, err, "greet: unavailable")
+	})
+}
+
+func TestParseStructure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("describes the graph", func(t *testing.T) {
+		stats, err := pipeline.ParseStructure(`
+			ds1        [type=http];
+			ds2        [type=custom_source];
+			parse1     [type=jsonparse];
+			parse2     [type=jsonparse];
+			answer     [type=median];
+			multiplied [type=multiply input="$(answer)" times=100];
+			audit      [type=unregistered];
+			ds1 -> parse1 -> answer;
+			ds2 -> parse2 -> answer;
+			ds1 -> audit;
+		`)
+		require.NoError(t, err)
+		require.Equal(t, &pipeline.GraphStats{
+			Nodes:     7,
+			Edges:     6,
+			MaxDepth:  4,
+			MaxFanOut: 2,
+			Roots:     []string{"ds1", "ds2"},
+			Terminals: []string{"audit", "multiplied"},
+		}, stats)
+	})
+
+	t.Run("reports cycles", func(t *testing.T) {
+		stats, err := pipeline.ParseStructure(`
+			a [type=unregistered];
+			b [type=unregistered];
+			a -> b -> a;
+		`)
+		require.NoError(t, err)
+		require.True(t, stats.HasCycle)
+		require.Zero(t, stats.MaxDepth)
+		require.Empty(t, stats.Roots)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := pipeline.ParseStructure(`a [type=memo`)
+		require.Error(t, err)
+	})
+}
+
+func TestParseFile(t *testing.T) {
+	t.Parallel()
+
+	p, err := pipeline.ParseFile("testdata/valid.dot")
+	require.NoError(t, err)
+	require.Len(t, p.Tasks, 2)
+
+	_, err = pipeline.ParseFile("testdata/malformed.dot")
+	require.Error(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), "testdata/malformed.dot:3:14: "), err.Error())
+
+	_, err = pipeline.ParseFile("testdata/malformed_digraph.dot")
+	require.Error(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), "testdata/malformed_digraph.dot:5:9: "), err.Error())
+
+	_, err = pipeline.ParseFile("testdata/cyclic.dot")
+	require.Error(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), "testdata/cyclic.dot: "), err.Error())
+	require.Contains(t, err.Error(), "cycle detected")
+
+	_, err = pipeline.ParseFile("testdata/missing.dot")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -34,6 +34,20 @@ func (_m *Task) Base() *pipeline.BaseTask {
 	return r0
 }
 
+// Disabled provides a mock function with given fields:
+func (_m *Task) Disabled() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // DotID provides a mock function with given fields:
 func (_m *Task) DotID() string {
 	ret := _m.Called()
@@ -92,6 +106,27 @@ func (_m *Task) OutputIndex() int32 {
 	return r0
 }
 
+// OutputName provides a mock function with given fields:
+func (_m *Task) OutputName() (string, bool) {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func() bool); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
 // Outputs provides a mock function with given fields:
 func (_m *Task) Outputs() []pipeline.Task {
 	ret := _m.Called()
@@ -108,6 +143,48 @@ func (_m *Task) Outputs() []pipeline.Task {
 	return r0
 }
 
+// RequestTimeout provides a mock function with given fields:
+func (_m *Task) RequestTimeout() (time.Duration, bool) {
+	ret := _m.Called()
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func() bool); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// RetryPolicy provides a mock function with given fields:
+func (_m *Task) RetryPolicy() (pipeline.RetryPolicy, bool) {
+	ret := _m.Called()
+
+	var r0 pipeline.RetryPolicy
+	if rf, ok := ret.Get(0).(func() pipeline.RetryPolicy); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(pipeline.RetryPolicy)
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func() bool); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
 // Run provides a mock function with given fields: ctx, lggr, vars, inputs
 func (_m *Task) Run(ctx context.Context, lggr logger.Logger, vars pipeline.Vars, inputs []pipeline.Result) (pipeline.Result, pipeline.RunInfo) {
 	ret := _m.Called(ctx, lggr, vars, inputs)
@@ -224,15 +224,15 @@ func (s *scheduler) Run() {
 		}
 
 		// if task hasn't reached it's max retry count yet, we schedule it again
-		if result.Attempts < uint(result.Task.TaskRetries()) && result.Result.Error != nil {
+		if policy, _ := result.Task.RetryPolicy(); result.Attempts < uint(policy.Retries) && result.Result.Error != nil {
 			// we immediately increase the in-flight counter so the pipeline doesn't terminate
 			// while we wait for the next retry
 			s.waiting++
 
 			backoff := backoff.Backoff{
 				Factor: 2,
-				Min:    result.Task.TaskMinBackoff(),
-				Max:    result.Task.TaskMaxBackoff(),
+				Min:    policy.MinBackoff,
+				Max:    policy.MaxBackoff,
 			}
 
 			go func(vars Vars) {
@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+// HashSource returns the hex encoded SHA-256 digest of the normalized pipeline source text, for use as a cache key
+// or to detect spec changes. The normalization only removes cosmetic differences:
+//   - leading and trailing whitespace is trimmed
+//   - every run of whitespace outside of quoted ("...") and HTML-like (<...>) strings is collapsed into a single space
+//
+// Whitespace is never removed entirely, so e.g. `a->b` and `a -> b` hash differently, and values within quotes or
+// angle brackets are hashed verbatim. The graph is not canonicalized: reordering statements or attributes, comments,
+// and quoting style all change the hash, even if the parsed pipeline is the same.
+func HashSource(text string) string {
+	sum := sha256.Sum256([]byte(normalizeSource(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// SpecHash returns HashSource of the pipeline's source.
+func (p *Pipeline) SpecHash() string {
+	return HashSource(p.Source)
+}
+
+func normalizeSource(text string) string {
+	var b strings.Builder
+	var (
+		inQuotes  bool
+		escaped   bool
+		htmlDepth int
+		space     bool
+	)
+	for _, r := range strings.TrimSpace(text) {
+		switch {
+		case inQuotes:
+			switch {
+			case escaped:
+				escaped = false
+			case r == '\\':
+				escaped = true
+			case r == '"':
+				inQuotes = false
+			}
+		case htmlDepth > 0:
+			switch r {
+			case '<':
+				htmlDepth++
+			case '>':
+				htmlDepth--
+			}
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		case r == '"':
+			inQuotes = true
+		case r == '<':
+			htmlDepth = 1
+		}
+		if space {
+			b.WriteRune(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package pipeline_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink/core/services/pipeline"
+)
+
+func TestHashSource(t *testing.T) {
+	t.Parallel()
+
+	const spec = `
+ds1          [type=http method=GET url="https://chain.link/voter_turnout/USA-2020" requestData=<{"hi": "hello"}>];
+ds1_parse    [type=jsonparse path="three,four"];
+ds1 -> ds1_parse;
+`
+	hash := pipeline.HashSource(spec)
+	require.Len(t, hash, 64)
+
+	t.Run("is stable", func(t *testing.T) {
+		assert.Equal(t, hash, pipeline.HashSource(spec))
+		p, err := pipeline.Parse(spec)
+		require.NoError(t, err)
+		assert.Equal(t, hash, p.SpecHash())
+	})
+
+	t.Run("ignores cosmetic whitespace", func(t *testing.T) {
+		assert.Equal(t, hash, pipeline.HashSource(`ds1 [type=http method=GET url="https://chain.link/voter_turnout/USA-2020" requestData=<{"hi": "hello"}>];
+			ds1_parse [type=jsonparse   path="three,four"];
+
+			ds1 -> ds1_parse;`))
+		assert.Equal(t, hash, pipeline.HashSource("\t"+spec+"\n\n"))
+	})
+
+	t.Run("keeps whitespace within strings", func(t *testing.T) {
+		assert.NotEqual(t, hash, pipeline.HashSource(`
+ds1          [type=http method=GET url="https://chain.link/voter_turnout/USA-2020" requestData=<{"hi":  "hello"}>];
+ds1_parse    [type=jsonparse path="three,four"];
+ds1 -> ds1_parse;
+`))
+		assert.NotEqual(t, pipeline.HashSource(`a [type=memo value="x  y"];`), pipeline.HashSource(`a [type=memo value="x y"];`))
+		assert.NotEqual(t, pipeline.HashSource(`a [type=memo value="\"  "];`), pipeline.HashSource(`a [type=memo value="\" "];`))
+		assert.NotEqual(t, pipeline.HashSource(`a [type=memo value=<<b>  x</b>>];`), pipeline.HashSource(`a [type=memo value=<<b> x</b>>];`))
+	})
+
+	t.Run("detects changes", func(t *testing.T) {
+		assert.NotEqual(t, hash, pipeline.HashSource(`
+ds1          [type=http method=GET url="https://chain.link/voter_turnout/USA-2021" requestData=<{"hi": "hello"}>];
+ds1_parse    [type=jsonparse path="three,four"];
+ds1 -> ds1_parse;
+`))
+	})
+
+	t.Run("does not canonicalize statement order", func(t *testing.T) {
+		// Reordering independent nodes yields the same pipeline, but a different hash
+		reordered := `
+ds1_parse    [type=jsonparse path="three,four"];
+ds1          [type=http method=GET url="https://chain.link/voter_turnout/USA-2020" requestData=<{"hi": "hello"}>];
+ds1 -> ds1_parse;
+`
+		p1, err := pipeline.Parse(spec)
+		require.NoError(t, err)
+		p2, err := pipeline.Parse(reordered)
+		require.NoError(t, err)
+		require.Equal(t, p1.Tasks, p2.Tasks)
+		assert.NotEqual(t, hash, pipeline.HashSource(reordered))
+	})
+}
@@ -3,6 +3,7 @@ package pipeline
 import (
 	"time"
 
+	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 
 	"github.com/smartcontractkit/chainlink/core/null"
@@ -12,11 +13,15 @@ type BaseTask struct {
 	outputs []Task
 	inputs  []TaskDependency
 
-	id        int
-	dotID     string
-	Index     int32          `mapstructure:"index" json:"-" `
-	Timeout   *time.Duration `mapstructure:"timeout"`
-	FailEarly bool           `mapstructure:"failEarly"`
+	id         int
+	dotID      string
+	Index      int32          `mapstructure:"index" json:"-" `
+	Timeout    *time.Duration `mapstructure:"timeout"`
+	ReqTimeout *time.Duration `mapstructure:"requestTimeout"`
+	FailEarly  bool           `mapstructure:"failEarly"`
+	Group      string         `mapstructure:"group"`
+	IsDisabled bool           `mapstructure:"disabled"`
+	OutName    string         `mapstructure:"outputName"`
 
 	Retries    null.Uint32   `mapstructure:"retries"`
 	MinBackoff time.Duration `mapstructure:"minBackoff"`
@@ -60,20 +65,77 @@ func (t BaseTask) TaskTimeout() (time.Duration, bool) {
 	return *t.Timeout, true
 }
 
+// RequestTimeout returns the timeout for individual network requests made by the task, if set.
+func (t BaseTask) RequestTimeout() (time.Duration, bool) {
+	if t.ReqTimeout == nil {
+		return time.Duration(0), false
+	}
+	return *t.ReqTimeout, true
+}
+
+// Disabled returns whether the task was disabled in the spec, see Pipeline.WithoutDisabled.
+func (t BaseTask) Disabled() bool {
+	return t.IsDisabled
+}
+
+// OutputName returns the name of the task's result among the pipeline outputs, if set, see Pipeline.NamedOutputs.
+func (t BaseTask) OutputName() (string, bool) {
+	return t.OutName, t.OutName != ""
+}
+
+const (
+	defaultMinBackoff = 5 * time.Second
+	defaultMaxBackoff = time.Minute
+)
+
+// RetryPolicy is how the scheduler retries a failed task: up to Retries more times, waiting an exponential backoff
+// between MinBackoff and MaxBackoff before each retry.
+type RetryPolicy struct {
+	Retries    uint32
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// RetryPolicy returns the task's retry policy, declared with the retries, minBackoff and maxBackoff attributes, and
+// whether any of them is set. Backoffs not set default to 5s and 1m, or minBackoff if it's longer.
+func (t BaseTask) RetryPolicy() (RetryPolicy, bool) {
+	policy := RetryPolicy{Retries: t.Retries.Uint32, MinBackoff: t.MinBackoff, MaxBackoff: t.MaxBackoff}
+	if policy.MinBackoff <= 0 {
+		policy.MinBackoff = defaultMinBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultMaxBackoff
+		if policy.MinBackoff > policy.MaxBackoff {
+			policy.MaxBackoff = policy.MinBackoff
+		}
+	}
+	return policy, t.Retries.Valid || t.MinBackoff != 0 || t.MaxBackoff != 0
+}
+
+// validateRetryPolicy checks the task's backoffs aren't negative, and that minBackoff doesn't exceed maxBackoff.
+func (t BaseTask) validateRetryPolicy() error {
+	if t.MinBackoff < 0 {
+		return errors.Errorf("task %s: minBackoff must not be negative, got %s", t.dotID, t.MinBackoff)
+	}
+	if t.MaxBackoff < 0 {
+		return errors.Errorf("task %s: maxBackoff must not be negative, got %s", t.dotID, t.MaxBackoff)
+	}
+	if t.MinBackoff > 0 && t.MaxBackoff > 0 && t.MinBackoff > t.MaxBackoff {
+		return errors.Errorf("task %s: minBackoff (%s) must not exceed maxBackoff (%s)", t.dotID, t.MinBackoff, t.MaxBackoff)
+	}
+	return nil
+}
+
 func (t BaseTask) TaskRetries() uint32 {
 	return t.Retries.Uint32
 }
 
 func (t BaseTask) TaskMinBackoff() time.Duration {
-	if t.MinBackoff > 0 {
-		return t.MinBackoff
-	}
-	return time.Second * 5
+	policy, _ := t.RetryPolicy()
+	return policy.MinBackoff
 }
 
 func (t BaseTask) TaskMaxBackoff() time.Duration {
-	if t.MinBackoff > 0 {
-		return t.MaxBackoff
-	}
-	return time.Minute
+	policy, _ := t.RetryPolicy()
+	return policy.MaxBackoff
 }
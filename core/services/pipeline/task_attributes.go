@@ -0,0 +1,190 @@
+package pipeline
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// requiredTaskAttributes declares, per task type, the attributes that have no default and can't be taken from the
+// task's inputs. The accepted attributes are derived from the task structs, see taskAttributes.
+var requiredTaskAttributes = map[TaskType][]string{
+	TaskTypeHTTP:             {"url"},
+	TaskTypeBridge:           {"name"},
+	TaskTypeMultiply:         {"times"},
+	TaskTypeDivide:           {"divisor"},
+	TaskTypeLessThan:         {"right"},
+	TaskTypeMerge:            {"right"},
+	TaskTypeCBORParse:        {"data"},
+	TaskTypeEstimateGasLimit: {"to", "data"},
+	TaskTypeETHCall:          {"contract", "data"},
+	TaskTypeETHTx:            {"to", "data"},
+	TaskTypeETHABIEncode:     {"abi"},
+	TaskTypeETHABIEncode2:    {"abi"},
+	TaskTypeETHABIDecode:     {"abi"},
+	TaskTypeETHABIDecodeLog:  {"abi", "topics"},
+	TaskTypeVRF:              {"publicKey", "requestBlockHash", "requestBlockNumber", "topics"},
+	TaskTypeVRFV2:            {"publicKey", "requestBlockHash", "requestBlockNumber", "topics"},
+}
+
+// taskFanLimit caps the number of inputs whose results a task receives, and the number of tasks its result is passed
+// on to. Zero means unlimited.
+type taskFanLimit struct {
+	maxInputs  int
+	maxOutputs int
+}
+
+// taskFanLimits declares, per task type, the fan-in and fan-out the task can handle. The input limits match the
+// CheckInputs calls of the tasks, which otherwise only fail when the pipeline runs.
+var taskFanLimits = map[TaskType]taskFanLimit{
+	TaskTypeBase64Decode: {maxInputs: 1},
+	TaskTypeBase64Encode: {maxInputs: 1},
+	TaskTypeConditional:  {maxInputs: 1},
+	TaskTypeETHABIDecode: {maxInputs: 1},
+	TaskTypeHexDecode:    {maxInputs: 1},
+	TaskTypeHexEncode:    {maxInputs: 1},
+	TaskTypeJSONParse:    {maxInputs: 1},
+	TaskTypeLength:       {maxInputs: 1},
+	TaskTypeLessThan:     {maxInputs: 1},
+	TaskTypeLookup:       {maxInputs: 1},
+	TaskTypeLowercase:    {maxInputs: 1},
+	TaskTypeMemo:         {maxInputs: 1},
+	TaskTypeMerge:        {maxInputs: 1},
+	TaskTypeMultiply:     {maxInputs: 1},
+	TaskTypeUppercase:    {maxInputs: 1},
+	TaskTypeVRF:          {maxInputs: 1},
+	TaskTypeVRFV2:        {maxInputs: 1},
+}
+
+// TaskAttribute describes an attribute accepted by a task type.
+type TaskAttribute struct {
+	Name     string
+	Required bool
+}
+
+// TaskTypeAttributes returns the attributes accepted by taskType, sorted by name, not including the type attribute
+// itself. Names are given as spelled in the task's json tags where possible, but like all attribute keys they are
+// matched case-insensitively.
+func TaskTypeAttributes(taskType TaskType) ([]TaskAttribute, error) {
+	newTask, ok := taskConstructors[taskType]
+	if !ok {
+		return nil, errors.Errorf(`unknown task type: "%v"`, taskType)
+	}
+	required := make(map[string]bool)
+	for _, name := range requiredTaskAttributes[taskType] {
+		required[strings.ToLower(name)] = true
+	}
+	var attrs []TaskAttribute
+	for key, name := range taskAttributes(newTask(BaseTask{})) {
+		attrs = append(attrs, TaskAttribute{Name: name, Required: required[key]})
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Name < attrs[j].Name })
+	return attrs, nil
+}
+
+// taskAttributes returns the attributes that UnmarshalTaskFromMap decodes into task, following the same rules as
+// mapstructure: the mapstructure tag name if set, otherwise the field name, matched case-insensitively.
+// They are keyed by lowercased name. Values are spelled as in the mapstructure or matching json tag, if any.
+func taskAttributes(task Task) map[string]string {
+	attrs := make(map[string]string)
+	collectStructAttributes(reflect.TypeOf(task).Elem(), attrs)
+	return attrs
+}
+
+func collectStructAttributes(typ reflect.Type, attrs map[string]string) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if field.Anonymous && opts == "squash" {
+			collectStructAttributes(field.Type, attrs)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+			if jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ","); strings.EqualFold(jsonName, field.Name) {
+				name = jsonName
+			}
+		}
+		attrs[strings.ToLower(name)] = name
+	}
+}
+
+// ValidateAttributes checks the attributes of every task against its type's schema, reporting unknown attributes
+// (e.g. typos, which UnmarshalTaskFromMap silently ignores) and missing required ones.
+// It is stricter than Parse, so callers opt in to it explicitly.
+func (p *Pipeline) ValidateAttributes() error {
+	if p.tree == nil {
+		return nil
+	}
+	nodeAttrs := make(map[string]map[string]string)
+	for nodes := p.tree.Nodes(); nodes.Next(); {
+		node := nodes.Node().(*GraphNode)
+		nodeAttrs[node.dotID] = node.attrs
+	}
+
+	var errs error
+	for _, task := range p.Tasks {
+		attrs := nodeAttrs[task.DotID()]
+		accepted := taskAttributes(task)
+		set := make(map[string]struct{}, len(attrs))
+		keys := make([]string, 0, len(attrs))
+		for key := range attrs {
+			set[strings.ToLower(key)] = struct{}{}
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if _, ok := accepted[strings.ToLower(key)]; !ok && strings.ToLower(key) != "type" {
+				errs = multierr.Append(errs, errors.Errorf("task %s: unknown attribute %q for type %s", task.DotID(), key, task.Type()))
+			}
+		}
+		for _, key := range requiredTaskAttributes[task.Type()] {
+			if _, ok := set[strings.ToLower(key)]; !ok {
+				errs = multierr.Append(errs, errors.Errorf("task %s: missing required attribute %q for type %s", task.DotID(), key, task.Type()))
+			}
+		}
+	}
+	return errs
+}
+
+// ValidateFanLimits checks the number of inputs and outputs of every task against the limits of its type, reporting
+// e.g. a multiply task with several inputs, which would only fail when the pipeline runs. Only inputs passing their
+// result to the task count, not implicit dependencies on a variable. Like ValidateAttributes, callers opt in to it.
+func (p *Pipeline) ValidateFanLimits() error {
+	return p.validateFanLimits(taskFanLimits)
+}
+
+func (p *Pipeline) validateFanLimits(limits map[TaskType]taskFanLimit) error {
+	var errs error
+	for _, task := range p.Tasks {
+		limit := limits[task.Type()]
+		var inputs int
+		for _, input := range task.Inputs() {
+			if input.PropagateResult {
+				inputs++
+			}
+		}
+		if limit.maxInputs > 0 && inputs > limit.maxInputs {
+			errs = multierr.Append(errs, errors.Errorf("task %q accepts at most %s, got %d", task.DotID(), pluralize(limit.maxInputs, "input"), inputs))
+		}
+		if outputs := len(task.Outputs()); limit.maxOutputs > 0 && outputs > limit.maxOutputs {
+			errs = multierr.Append(errs, errors.Errorf("task %q allows at most %s, got %d", task.DotID(), pluralize(limit.maxOutputs, "output"), outputs))
+		}
+	}
+	return errs
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,120 @@
+package pipeline_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink/core/services/pipeline"
+)
+
+func TestPipeline_ValidateAttributes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+ds1          [type=http method=GET url="https://chain.link/voter_turnout/USA-2020" requestData=<{"hi": "hello"}> timeout="10s"]
+ds1_parse    [type=jsonparse path="three,four" failEarly=true]
+ds1_multiply [type=multiply Times=100 retries=3]
+ds1 -> ds1_parse -> ds1_multiply
+`)
+		require.NoError(t, err)
+		require.NoError(t, p.ValidateAttributes())
+	})
+
+	t.Run("unknown attribute", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+ds1          [type=memo value=1]
+ds1_multiply [type=multiply times=100 mutiply=10]
+ds1 -> ds1_multiply
+`)
+		require.NoError(t, err)
+		err = p.ValidateAttributes()
+		require.Error(t, err)
+		assert.EqualError(t, err, `task ds1_multiply: unknown attribute "mutiply" for type multiply`)
+	})
+
+	t.Run("missing required attribute", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+ds1          [type=memo value=1]
+ds1_multiply [type=multiply input="$(ds1)"]
+ds1 -> ds1_multiply
+`)
+		require.NoError(t, err)
+		err = p.ValidateAttributes()
+		require.Error(t, err)
+		assert.EqualError(t, err, `task ds1_multiply: missing required attribute "times" for type multiply`)
+	})
+}
+
+func TestPipeline_ValidateFanLimits(t *testing.T) {
+	t.Parallel()
+
+	t.Run("within limits", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+ds1      [type=memo value=1]
+ds2      [type=memo value=2]
+median   [type=median]
+multiply [type=multiply input="$(ds2)" times=10]
+ds1 -> median -> multiply
+ds2 -> median
+`)
+		require.NoError(t, err)
+		require.NoError(t, p.ValidateFanLimits())
+	})
+
+	t.Run("too many inputs", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+ds1      [type=memo value=1]
+ds2      [type=memo value=2]
+ds3      [type=memo value=3]
+multiply [type=multiply times=10]
+ds1 -> multiply
+ds2 -> multiply
+ds3 -> multiply
+`)
+		require.NoError(t, err)
+		require.EqualError(t, p.ValidateFanLimits(), `task "multiply" accepts at most 1 input, got 3`)
+	})
+}
+
+func TestRegisteredTaskTypes(t *testing.T) {
+	t.Parallel()
+
+	types := pipeline.RegisteredTaskTypes()
+	require.Contains(t, types, pipeline.TaskTypeHTTP)
+	require.Contains(t, types, pipeline.TaskTypeMultiply)
+	require.Contains(t, types, pipeline.TaskTypeFail)
+	require.True(t, sort.SliceIsSorted(types, func(i, j int) bool { return types[i] < types[j] }))
+
+	for _, taskType := range types {
+		_, err := pipeline.UnmarshalTaskFromMap(taskType, map[string]string{}, 0, "task")
+		require.NoError(t, err, taskType)
+
+		attrs, err := pipeline.TaskTypeAttributes(taskType)
+		require.NoError(t, err, taskType)
+		// Every task type accepts the base attributes
+		require.Contains(t, attrs, pipeline.TaskAttribute{Name: "timeout"}, taskType)
+	}
+}
+
+func TestTaskTypeAttributes(t *testing.T) {
+	t.Parallel()
+
+	attrs, err := pipeline.TaskTypeAttributes(pipeline.TaskTypeMultiply)
+	require.NoError(t, err)
+	require.Contains(t, attrs, pipeline.TaskAttribute{Name: "times", Required: true})
+	require.Contains(t, attrs, pipeline.TaskAttribute{Name: "input"})
+	require.Contains(t, attrs, pipeline.TaskAttribute{Name: "requestTimeout"})
+	require.NotContains(t, attrs, pipeline.TaskAttribute{Name: "type"})
+
+	attrs, err = pipeline.TaskTypeAttributes(pipeline.TaskTypeETHCall)
+	require.NoError(t, err)
+	require.Contains(t, attrs, pipeline.TaskAttribute{Name: "evmChainID"})
+	require.Contains(t, attrs, pipeline.TaskAttribute{Name: "contract", Required: true})
+
+	_, err = pipeline.TaskTypeAttributes("nope")
+	require.EqualError(t, err, `unknown task type: "nope"`)
+}
@@ -0,0 +1,61 @@
+package pipeline
+
+import "sync"
+
+// TaskSchedule yields the tasks of a pipeline as soon as they can run, to drive e.g. a pool of workers: a task is only
+// yielded once all of its inputs are Done. Each task is yielded exactly once, including tasks with several paths from
+// the same input, and Tasks is closed once every task is Done. A TaskSchedule is safe for concurrent use.
+type TaskSchedule struct {
+	tasks chan Task
+
+	mu      sync.Mutex
+	waiting map[int]int // number of inputs not done yet, per task ID
+	done    map[int]bool
+}
+
+// Schedule returns a TaskSchedule of the pipeline's tasks. Tasks without inputs are ready to be received right away.
+func (p *Pipeline) Schedule() *TaskSchedule {
+	s := &TaskSchedule{
+		// never blocks, as each task is sent once
+		tasks:   make(chan Task, len(p.Tasks)),
+		waiting: make(map[int]int, len(p.Tasks)),
+		done:    make(map[int]bool, len(p.Tasks)),
+	}
+	for _, task := range p.Tasks {
+		s.waiting[task.ID()] = len(task.Inputs())
+	}
+	for _, task := range p.Tasks {
+		if s.waiting[task.ID()] == 0 {
+			s.tasks <- task
+		}
+	}
+	if len(p.Tasks) == 0 {
+		close(s.tasks)
+	}
+	return s
+}
+
+// Tasks yields each task once all of its inputs are Done. It is closed once every task is Done.
+func (s *TaskSchedule) Tasks() <-chan Task {
+	return s.tasks
+}
+
+// Done marks a task received from Tasks as done, yielding the tasks for which it was the last input not done. Marking a
+// task as done again, or a task which wasn't yielded yet, has no effect.
+func (s *TaskSchedule) Done(task Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if waiting, ok := s.waiting[task.ID()]; !ok || waiting > 0 || s.done[task.ID()] {
+		return
+	}
+	s.done[task.ID()] = true
+	for _, output := range task.Outputs() {
+		s.waiting[output.ID()]--
+		if s.waiting[output.ID()] == 0 {
+			s.tasks <- output
+		}
+	}
+	if len(s.done) == len(s.waiting) {
+		close(s.tasks)
+	}
+}
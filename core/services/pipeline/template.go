@@ -0,0 +1,125 @@
+package pipeline
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// templateRefAttribute is the attribute of a task naming the template it inherits attributes from, see expandTemplates.
+const templateRefAttribute = "templateref"
+
+// uninheritedAttributes are the attributes of a template which aren't inherited, as they're specific to a single task.
+var uninheritedAttributes = map[string]bool{
+	templateRefAttribute: true,
+	"index":              true,
+	"outputname":         true,
+}
+
+// expandTemplates removes the template nodes, and merges their attributes into the tasks referencing them with
+// templateRef, e.g.
+//
+//	base [type=http method=GET timeout="5s" retries=3];
+//	ds1  [templateRef="base" url="https://a.example"];
+//	ds2  [templateRef="base" url="https://b.example" timeout="10s"];
+//
+// The attributes of a task take precedence over the inherited ones. Templates may reference a template themselves, in
+// which case they inherit its attributes the same way. The index and outputName attributes of a template aren't
+// inherited, as they're specific to a single task. A node is a template if it's referenced by templateRef, and as it
+// isn't a task, it must not have any dependencies. References to undefined templates and template cycles are errors.
+func (g *Graph) expandTemplates() {
+	nodes := make(map[string]*GraphNode)
+	var refs []*GraphNode
+	for iter := g.Nodes(); iter.Next(); {
+		n := iter.Node().(*GraphNode)
+		nodes[n.dotID] = n
+		if _, ok := n.attrs[templateRefAttribute]; ok {
+			refs = append(refs, n)
+		}
+	}
+	if len(refs) == 0 {
+		return
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].dotID < refs[j].dotID })
+
+	templates := make(map[string]*GraphNode)
+	for _, n := range refs {
+		name := n.attrs[templateRefAttribute]
+		template, ok := nodes[name]
+		if !ok {
+			g.errs = multierr.Append(g.errs, errors.Errorf("task %q references undefined template %q", n.dotID, name))
+			continue
+		}
+		templates[name] = template
+	}
+
+	resolved := make(map[string]map[string]string)
+	failed := make(map[string]bool)
+	var resolve func(n *GraphNode, path []string) (map[string]string, bool)
+	resolve = func(n *GraphNode, path []string) (map[string]string, bool) {
+		if attrs, ok := resolved[n.dotID]; ok {
+			return attrs, true
+		}
+		name, ok := n.attrs[templateRefAttribute]
+		if !ok {
+			return n.attrs, true
+		}
+		if failed[n.dotID] {
+			return nil, false
+		}
+		for i, dotID := range path {
+			if dotID == n.dotID {
+				cycle := append(append([]string{}, path[i:]...), n.dotID)
+				g.errs = multierr.Append(g.errs, errors.Errorf("template cycle: %s", strings.Join(cycle, " -> ")))
+				for _, dotID := range cycle {
+					failed[dotID] = true
+				}
+				return nil, false
+			}
+		}
+		template, ok := templates[name]
+		if !ok {
+			// Reported above
+			failed[n.dotID] = true
+			return nil, false
+		}
+		inherited, ok := resolve(template, append(path, n.dotID))
+		if !ok {
+			failed[n.dotID] = true
+			return nil, false
+		}
+		attrs := make(map[string]string, len(inherited)+len(n.attrs))
+		for key, value := range inherited {
+			if !uninheritedAttributes[key] {
+				attrs[key] = value
+			}
+		}
+		for key, value := range n.attrs {
+			if key != templateRefAttribute {
+				attrs[key] = value
+			}
+		}
+		resolved[n.dotID] = attrs
+		return attrs, true
+	}
+	for _, n := range refs {
+		if attrs, ok := resolve(n, nil); ok {
+			n.attrs = attrs
+		}
+	}
+
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		template := templates[name]
+		if g.From(template.ID()).Len() > 0 || g.To(template.ID()).Len() > 0 {
+			g.errs = multierr.Append(g.errs, errors.Errorf("template %q cannot have dependencies", name))
+		}
+		g.RemoveNode(template.ID())
+	}
+}
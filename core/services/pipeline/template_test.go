@@ -0,0 +1,110 @@
+package pipeline_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink/core/services/pipeline"
+)
+
+func TestParse_Templates(t *testing.T) {
+	t.Parallel()
+
+	t.Run("inheritance", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			base   [type=http method=GET timeout="5s" retries=3 index=0 outputName="base"];
+			ds1    [templateRef="base" url="https://a.example"];
+			ds2    [templateRef="base" url="https://b.example" timeout="10s" method=POST];
+			answer [type=median index=1];
+			ds1 -> answer;
+			ds2 -> answer;
+		`)
+		require.NoError(t, err)
+
+		// the template isn't a task
+		require.Len(t, p.Tasks, 3)
+		_, ok := p.TaskByID("base")
+		assert.False(t, ok)
+
+		ds1 := p.ByDotID("ds1").(*pipeline.HTTPTask)
+		assert.Equal(t, "GET", ds1.Method)
+		assert.Equal(t, "https://a.example", ds1.URL)
+		timeout, _ := ds1.TaskTimeout()
+		assert.Equal(t, 5*time.Second, timeout)
+		assert.Equal(t, uint32(3), ds1.TaskRetries())
+
+		// the task's own attributes take precedence
+		ds2 := p.ByDotID("ds2").(*pipeline.HTTPTask)
+		assert.Equal(t, "POST", ds2.Method)
+		assert.Equal(t, "https://b.example", ds2.URL)
+		timeout, _ = ds2.TaskTimeout()
+		assert.Equal(t, 10*time.Second, timeout)
+
+		// index and outputName are specific to a task
+		for _, task := range []pipeline.Task{ds1, ds2} {
+			assert.Equal(t, int32(0), task.OutputIndex())
+			_, named := task.OutputName()
+			assert.False(t, named)
+		}
+		require.NoError(t, p.ValidateAttributes())
+
+		// the inherited attributes are part of the task's DOT
+		reparsed, err := pipeline.Parse(p.DOT())
+		require.NoError(t, err)
+		assert.Equal(t, "GET", reparsed.ByDotID("ds1").(*pipeline.HTTPTask).Method)
+	})
+
+	t.Run("chained templates", func(t *testing.T) {
+		p, err := pipeline.Parse(`
+			vars  [baseURL="https://example.com"];
+			base  [type=http method=GET url="$var.baseURL/price"];
+			slow  [templateRef="base" timeout="30s"];
+			ds    [templateRef="slow"];
+			other [type=memo value="$(ds)"];
+		`)
+		require.NoError(t, err)
+		require.Len(t, p.Tasks, 2)
+		ds := p.ByDotID("ds").(*pipeline.HTTPTask)
+		assert.Equal(t, "https://example.com/price", ds.URL)
+		timeout, _ := ds.TaskTimeout()
+		assert.Equal(t, 30*time.Second, timeout)
+		// references to other tasks still add dependencies
+		assert.Equal(t, []pipeline.TaskDependency{{PropagateResult: false, InputTask: ds}}, p.ByDotID("other").Inputs())
+	})
+
+	t.Run("missing template", func(t *testing.T) {
+		_, err := pipeline.Parse(`
+			ds1 [templateRef="base" url="https://a.example"];
+			ds2 [type=http method=GET url="https://b.example"];
+		`)
+		require.ErrorContains(t, err, `task "ds1" references undefined template "base"`)
+	})
+
+	t.Run("template cycle", func(t *testing.T) {
+		_, err := pipeline.Parse(`
+			a  [templateRef="b" type=memo];
+			b  [templateRef="a" value=1];
+			ds [templateRef="a"];
+		`)
+		require.ErrorContains(t, err, "template cycle: a -> b -> a")
+		assert.NotContains(t, err.Error(), "b -> a -> b", "a cycle is reported once")
+
+		_, err = pipeline.Parse(`
+			a  [templateRef="a" type=memo value=1];
+		`)
+		require.ErrorContains(t, err, "template cycle: a -> a")
+	})
+
+	t.Run("template with dependencies", func(t *testing.T) {
+		_, err := pipeline.Parse(`
+			base [type=memo value=1];
+			a    [templateRef="base"];
+			b    [type=memo value=2];
+			base -> b;
+		`)
+		require.EqualError(t, err, `template "base" cannot have dependencies`)
+	})
+}
@@ -0,0 +1,112 @@
+package pipeline
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// PipelineValidator names an optional check of a parsed pipeline, see Pipeline.Validate.
+type PipelineValidator string
+
+const (
+	// AttributesValidator checks the attributes of every task against its type's schema, see ValidateAttributes.
+	AttributesValidator PipelineValidator = "attributes"
+	// FanLimitsValidator checks the inputs and outputs of every task against the limits of its type, see
+	// ValidateFanLimits.
+	FanLimitsValidator PipelineValidator = "fanlimits"
+	// DepthValidator checks the Depth of the pipeline against the limit set with WithMaxDepth, like ParseWithLimits. It
+	// accepts any depth without a limit.
+	DepthValidator PipelineValidator = "depth"
+	// TimeoutsValidator reports the tasks which may run indefinitely, see TasksWithoutTimeout.
+	TimeoutsValidator PipelineValidator = "timeouts"
+)
+
+// pipelineValidators are the validators run by Validate, in the order their errors are reported.
+var pipelineValidators = []struct {
+	name     PipelineValidator
+	validate func(*Pipeline, validateOptions) error
+}{
+	{AttributesValidator, func(p *Pipeline, _ validateOptions) error { return p.ValidateAttributes() }},
+	{FanLimitsValidator, func(p *Pipeline, _ validateOptions) error { return p.ValidateFanLimits() }},
+	{DepthValidator, func(p *Pipeline, opts validateOptions) error { return p.validateDepth(opts.maxDepth) }},
+	{TimeoutsValidator, func(p *Pipeline, _ validateOptions) error { return p.validateTimeouts() }},
+}
+
+// validateOptions holds the settings of Validate.
+type validateOptions struct {
+	only     map[PipelineValidator]bool
+	maxDepth int
+}
+
+// ValidateOption configures Pipeline.Validate.
+type ValidateOption func(*validateOptions)
+
+// WithValidators only runs the given validators, rather than all of them. Unknown names are reported by Validate.
+func WithValidators(validators ...PipelineValidator) ValidateOption {
+	return func(opts *validateOptions) {
+		if opts.only == nil {
+			opts.only = make(map[PipelineValidator]bool)
+		}
+		for _, v := range validators {
+			opts.only[v] = true
+		}
+	}
+}
+
+// WithMaxDepth sets the highest Depth accepted by DepthValidator. 0, the default, is unlimited.
+func WithMaxDepth(limit int) ValidateOption {
+	return func(opts *validateOptions) {
+		opts.maxDepth = limit
+	}
+}
+
+// Validate runs the optional checks of the pipeline at once, e.g. for a linter or before accepting a spec, and returns
+// all the problems they find combined with multierr. By default, every validator runs: AttributesValidator,
+// FanLimitsValidator, DepthValidator and TimeoutsValidator. WithValidators limits them to a subset. The problems reported by Parse, like unknown task types,
+// tasks declared more than once, or references to tasks which aren't upstream, can't occur in a parsed pipeline, so
+// they aren't checked again.
+func (p *Pipeline) Validate(opts ...ValidateOption) error {
+	var options validateOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	for name := range options.only {
+		if !isPipelineValidator(name) {
+			return errors.Errorf("unknown pipeline validator %q", name)
+		}
+	}
+	var errs error
+	for _, v := range pipelineValidators {
+		if options.only == nil || options.only[v.name] {
+			errs = multierr.Append(errs, v.validate(p, options))
+		}
+	}
+	return errs
+}
+
+// validateTimeouts returns an error listing the tasks without a timeout, if any.
+func (p *Pipeline) validateTimeouts() error {
+	tasks := p.TasksWithoutTimeout()
+	if len(tasks) == 0 {
+		return nil
+	}
+	dotIDs := make([]string, len(tasks))
+	for i, task := range tasks {
+		dotIDs[i] = task.DotID()
+	}
+	if len(dotIDs) == 1 {
+		return errors.Errorf("task %s has no timeout", dotIDs[0])
+	}
+	return errors.Errorf("tasks %s have no timeout", strings.Join(dotIDs, ", "))
+}
+
+func isPipelineValidator(name PipelineValidator) bool {
+	for _, v := range pipelineValidators {
+		if v.name == name {
+			return true
+		}
+	}
+	return false
+}
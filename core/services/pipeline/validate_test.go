@@ -0,0 +1,76 @@
+package pipeline_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/multierr"
+
+	"github.com/smartcontractkit/chainlink/core/services/pipeline"
+)
+
+func TestPipeline_Validate(t *testing.T) {
+	t.Parallel()
+
+	p, err := pipeline.Parse(`
+ds1      [type=memo value=1 timeout="1s"]
+ds2      [type=memo value=2 timeout="1s"]
+multiply [type=multiply tmies=10]
+ds1 -> multiply
+ds2 -> multiply
+`)
+	require.NoError(t, err)
+	const (
+		unknownAttr = `task multiply: unknown attribute "tmies" for type multiply`
+		missingAttr = `task multiply: missing required attribute "times" for type multiply`
+		tooManyIns  = `task "multiply" accepts at most 1 input, got 2`
+		tooDeep     = `pipeline depth 2 exceeds the maximum of 1: ds1 -> multiply`
+		noTimeout   = `task multiply has no timeout`
+	)
+
+	t.Run("all by default", func(t *testing.T) {
+		err := p.Validate()
+		require.Error(t, err)
+		var msgs []string
+		for _, err := range multierr.Errors(err) {
+			msgs = append(msgs, err.Error())
+		}
+		assert.Equal(t, []string{unknownAttr, missingAttr, tooManyIns, noTimeout}, msgs)
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		err := p.Validate(pipeline.WithMaxDepth(1))
+		assert.Len(t, multierr.Errors(err), 5)
+		assert.ErrorContains(t, err, tooDeep)
+		require.EqualError(t, p.Validate(pipeline.WithValidators(pipeline.DepthValidator), pipeline.WithMaxDepth(1)), tooDeep)
+		require.NoError(t, p.Validate(pipeline.WithValidators(pipeline.DepthValidator), pipeline.WithMaxDepth(2)))
+		require.NoError(t, p.Validate(pipeline.WithValidators(pipeline.DepthValidator)))
+	})
+
+	t.Run("timeouts", func(t *testing.T) {
+		require.EqualError(t, p.Validate(pipeline.WithValidators(pipeline.TimeoutsValidator)), noTimeout)
+		noTimeouts, err := pipeline.Parse(`ds1 [type=memo value=1]; ds2 [type=memo value=2]; ds1 -> ds2`)
+		require.NoError(t, err)
+		require.EqualError(t, noTimeouts.Validate(pipeline.WithValidators(pipeline.TimeoutsValidator)), `tasks ds1, ds2 have no timeout`)
+	})
+
+	t.Run("subset", func(t *testing.T) {
+		require.EqualError(t, p.Validate(pipeline.WithValidators(pipeline.FanLimitsValidator)), tooManyIns)
+		err := p.Validate(pipeline.WithValidators(pipeline.AttributesValidator))
+		assert.Len(t, multierr.Errors(err), 2)
+		assert.ErrorContains(t, err, unknownAttr)
+		assert.NotContains(t, err.Error(), tooManyIns)
+	})
+
+	t.Run("unknown validator", func(t *testing.T) {
+		err := p.Validate(pipeline.WithValidators(pipeline.AttributesValidator, "connectivity"))
+		require.EqualError(t, err, `unknown pipeline validator "connectivity"`)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		valid, err := pipeline.Parse(`ds1 [type=memo value=1 timeout="1s"]; multiply [type=multiply times=10 timeout="1s"]; ds1 -> multiply`)
+		require.NoError(t, err)
+		require.NoError(t, valid.Validate(pipeline.WithMaxDepth(2)))
+	})
+}
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE terra_msgs ADD COLUMN idempotency_key text;
+CREATE UNIQUE INDEX idx_terra_msgs_terra_chain_id_idempotency_key ON terra_msgs (terra_chain_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_terra_msgs_terra_chain_id_idempotency_key;
+ALTER TABLE terra_msgs DROP COLUMN idempotency_key;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE terra_msgs ADD COLUMN priority integer NOT NULL DEFAULT 0;
+-- Batches of msgs in a state are selected by priority, then age, so they're read in index order rather than sorted.
+-- The (terra_chain_id, state, contract_id) index is kept for the per-contract queries.
+CREATE INDEX idx_terra_msgs_terra_chain_id_state_priority ON terra_msgs (terra_chain_id, state, priority DESC, created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_terra_msgs_terra_chain_id_state_priority;
+ALTER TABLE terra_msgs DROP COLUMN priority;
+-- +goose StatementEnd
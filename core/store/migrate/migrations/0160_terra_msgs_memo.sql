@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE terra_msgs ADD COLUMN memo text;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE terra_msgs DROP COLUMN memo;
+-- +goose StatementEnd
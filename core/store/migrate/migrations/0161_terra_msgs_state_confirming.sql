@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE OR REPLACE FUNCTION check_terra_msg_state_transition() RETURNS TRIGGER AS $$
+DECLARE
+state_transition_map jsonb := json_build_object(
+        'unstarted', json_build_object('errored', true, 'started', true),
+        'started', json_build_object('errored', true, 'broadcasted', true),
+        'broadcasted', json_build_object('errored', true, 'confirmed', true, 'timed_out', true, 'confirming', true),
+        'timed_out', json_build_object('errored', true, 'confirmed', true, 'unstarted', true, 'confirming', true),
+        'confirming', json_build_object('errored', true, 'confirmed', true, 'timed_out', true));
+BEGIN
+    -- Updates of other columns, e.g. the tx hash or retry count, leave the state as is
+    IF OLD.state = NEW.state THEN
+        RETURN NEW;
+END IF;
+    IF NOT state_transition_map ? OLD.state THEN
+        RAISE EXCEPTION 'Invalid from state %. Valid from states %', OLD.state, state_transition_map;
+END IF;
+    IF NOT state_transition_map->OLD.state ? NEW.state THEN
+        RAISE EXCEPTION 'Invalid state transition from % to %. Valid to states %', OLD.state, NEW.state, state_transition_map->OLD.state;
+END IF;
+RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+UPDATE terra_msgs SET state = 'confirmed' WHERE state = 'confirming';
+
+CREATE OR REPLACE FUNCTION check_terra_msg_state_transition() RETURNS TRIGGER AS $$
+DECLARE
+state_transition_map jsonb := json_build_object(
+        'unstarted', json_build_object('errored', true, 'started', true),
+        'started', json_build_object('errored', true, 'broadcasted', true),
+        'broadcasted', json_build_object('errored', true, 'confirmed', true, 'timed_out', true),
+        'timed_out', json_build_object('errored', true, 'confirmed', true, 'unstarted', true));
+BEGIN
+    -- Updates of other columns, e.g. the tx hash or retry count, leave the state as is
+    IF OLD.state = NEW.state THEN
+        RETURN NEW;
+END IF;
+    IF NOT state_transition_map ? OLD.state THEN
+        RAISE EXCEPTION 'Invalid from state %. Valid from states %', OLD.state, state_transition_map;
+END IF;
+    IF NOT state_transition_map->OLD.state ? NEW.state THEN
+        RAISE EXCEPTION 'Invalid state transition from % to %. Valid to states %', OLD.state, NEW.state, state_transition_map->OLD.state;
+END IF;
+RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE terra_msgs ADD COLUMN retry_count integer NOT NULL DEFAULT 0;
+ALTER TABLE terra_msgs ADD COLUMN last_error text;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE terra_msgs DROP COLUMN last_error;
+ALTER TABLE terra_msgs DROP COLUMN retry_count;
+-- +goose StatementEnd
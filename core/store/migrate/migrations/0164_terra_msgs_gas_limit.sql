@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE terra_msgs ADD COLUMN gas_limit bigint CHECK (gas_limit > 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE terra_msgs DROP COLUMN gas_limit;
+-- +goose StatementEnd
@@ -0,0 +1,8 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE terra_msgs ADD COLUMN not_before timestamptz;
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE terra_msgs DROP COLUMN not_before;
+-- +goose StatementEnd
@@ -275,7 +275,7 @@ func TeardownSuite(
 }
 
 // TeardownRemoteSuite is used when running a test within a remote-test-runner, like for long-running performance and
-// soak tests
+// soak tests. It collects the logs of the Chainlink nodes and mockserver first, see CollectRemoteLogs.
 func TeardownRemoteSuite(
 	t *testing.T,
 	env *environment.Environment,
@@ -283,6 +283,9 @@ func TeardownRemoteSuite(
 	optionalTestReporter testreporters.TestReporter, // Optionally pass in a test reporter to log further metrics
 	client blockchain.EVMClient,
 ) error {
+	if _, err := CollectRemoteLogs(env, t.Name()); err != nil {
+		log.Warn().Err(err).Str("Namespace", env.Cfg.Namespace).Msg("Error collecting logs")
+	}
 	var err error
 	if err = testreporters.SendReport(t, env, "./", optionalTestReporter); err != nil {
 		log.Warn().Err(err).Msg("Error writing test report")
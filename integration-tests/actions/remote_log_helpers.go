@@ -0,0 +1,103 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/smartcontractkit/chainlink-env/environment"
+	"github.com/smartcontractkit/chainlink-env/pkg/helm/chainlink"
+	"github.com/smartcontractkit/chainlink-testing-framework/testreporters"
+)
+
+// RemoteLogsDirEnvVar overrides the directory that logs are collected into when a remote test, like a soak test,
+// finishes. It's read where the logs are collected: on the remote test runner, or by the launcher if the launch fails.
+const RemoteLogsDirEnvVar = "SOAK_LOGS_DIR"
+
+// CollectRemoteLogs pulls the logs of every Chainlink node and the mockserver into a timestamped directory named after
+// the test, under SOAK_LOGS_DIR or the default artifacts dir, and returns that directory. Collection is best-effort:
+// pods whose logs can't be read are logged and skipped, so a partial failure doesn't lose the logs of the other pods.
+func CollectRemoteLogs(env *environment.Environment, testName string) (string, error) {
+	baseDir := os.Getenv(RemoteLogsDirEnvVar)
+	if baseDir == "" {
+		baseDir = testreporters.DefaultArtifactsDir
+	}
+	dir := filepath.Join(baseDir, fmt.Sprintf(
+		"%s-%s-%s",
+		strings.ReplaceAll(testName, "/", "_"),
+		env.Cfg.Namespace,
+		time.Now().UTC().Format("20060102T150405Z"),
+	))
+	pods, err := env.Client.ListPods(env.Cfg.Namespace, "app")
+	if err != nil {
+		return "", errors.Wrap(err, "error listing pods")
+	}
+
+	collected, failed := 0, 0
+	for _, pod := range pods.Items {
+		app := pod.Labels["app"]
+		if !strings.HasPrefix(app, chainlink.AppName+"-") && app != "mockserver" {
+			continue
+		}
+		podDir := filepath.Join(dir, pod.Name)
+		if err = os.MkdirAll(podDir, 0755); err != nil {
+			return "", errors.Wrap(err, "error creating logs directory")
+		}
+		for _, container := range pod.Spec.Containers {
+			if err = writeContainerLogs(env, pod, container.Name, podDir); err != nil {
+				log.Warn().Err(err).Str("Pod", pod.Name).Str("Container", container.Name).Msg("Error collecting logs")
+				failed++
+				continue
+			}
+			collected++
+		}
+	}
+	log.Info().
+		Str("Directory", dir).
+		Int("Collected", collected).
+		Int("Failed", failed).
+		Msg("Collected Chainlink node and mockserver logs")
+	return dir, nil
+}
+
+// writeContainerLogs writes the logs of a container to <container>.log in podDir. If the container restarted, the
+// logs of its previous run are written to <container>.previous.log, as they usually show why it restarted.
+func writeContainerLogs(env *environment.Environment, pod v1.Pod, container, podDir string) error {
+	if err := writeLogs(env, pod, &v1.PodLogOptions{Container: container}, filepath.Join(podDir, container+".log")); err != nil {
+		return err
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container && status.RestartCount > 0 {
+			previous := &v1.PodLogOptions{Container: container, Previous: true}
+			return writeLogs(env, pod, previous, filepath.Join(podDir, container+".previous.log"))
+		}
+	}
+	return nil
+}
+
+func writeLogs(env *environment.Environment, pod v1.Pod, opts *v1.PodLogOptions, path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	logs, err := env.Client.ClientSet.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+	logFile, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(logFile, logs); err != nil {
+		_ = logFile.Close()
+		return err
+	}
+	return logFile.Close()
+}
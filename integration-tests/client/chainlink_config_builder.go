@@ -2,10 +2,134 @@ package client
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+
+	solcfg "github.com/smartcontractkit/chainlink-solana/pkg/solana/config"
 	"github.com/smartcontractkit/chainlink-testing-framework/blockchain"
+
+	evmcfg "github.com/smartcontractkit/chainlink/core/chains/evm/config/v2"
+	v2 "github.com/smartcontractkit/chainlink/core/config/v2"
 )
 
+// nodeConfig mirrors the root of the Chainlink config, see core/services/chainlink.Config, with the core settings and the
+// EVM and Solana chains the tests configure nodes with. Only the config packages are imported, rather than the whole node.
+type nodeConfig struct {
+	v2.Core
+
+	EVM evmcfg.EVMConfigs `toml:",omitempty"`
+
+	Solana solanaConfigs `toml:",omitempty"`
+}
+
+// SetFrom updates c with any non-nil values from f.
+func (c *nodeConfig) SetFrom(f *nodeConfig) {
+	c.Core.SetFrom(&f.Core)
+	c.EVM.SetFrom(&f.EVM)
+	c.Solana.SetFrom(&f.Solana)
+}
+
+// solanaConfigs mirrors core/chains/solana.SolanaConfigs, which lives in the chain package rather than a config package.
+type solanaConfigs []*solanaConfig
+
+// SetFrom merges chains by ChainID, like the node does.
+func (cs *solanaConfigs) SetFrom(fs *solanaConfigs) {
+	for _, f := range *fs {
+		if f.ChainID == nil {
+			*cs = append(*cs, f)
+		} else if i := slices.IndexFunc(*cs, func(c *solanaConfig) bool {
+			return c.ChainID != nil && *c.ChainID == *f.ChainID
+		}); i == -1 {
+			*cs = append(*cs, f)
+		} else {
+			(*cs)[i].SetFrom(f)
+		}
+	}
+}
+
+type solanaConfig struct {
+	ChainID *string
+	Enabled *bool
+	solcfg.Chain
+	Nodes solanaNodes
+}
+
+// SetFrom updates c with any non-nil values from f.
+func (c *solanaConfig) SetFrom(f *solanaConfig) {
+	if f.ChainID != nil {
+		c.ChainID = f.ChainID
+	}
+	if f.Enabled != nil {
+		c.Enabled = f.Enabled
+	}
+	setFromSolanaChain(&c.Chain, &f.Chain)
+	c.Nodes.SetFrom(&f.Nodes)
+}
+
+func setFromSolanaChain(c, f *solcfg.Chain) {
+	if f.BalancePollPeriod != nil {
+		c.BalancePollPeriod = f.BalancePollPeriod
+	}
+	if f.ConfirmPollPeriod != nil {
+		c.ConfirmPollPeriod = f.ConfirmPollPeriod
+	}
+	if f.OCR2CachePollPeriod != nil {
+		c.OCR2CachePollPeriod = f.OCR2CachePollPeriod
+	}
+	if f.OCR2CacheTTL != nil {
+		c.OCR2CacheTTL = f.OCR2CacheTTL
+	}
+	if f.TxTimeout != nil {
+		c.TxTimeout = f.TxTimeout
+	}
+	if f.TxRetryTimeout != nil {
+		c.TxRetryTimeout = f.TxRetryTimeout
+	}
+	if f.TxConfirmTimeout != nil {
+		c.TxConfirmTimeout = f.TxConfirmTimeout
+	}
+	if f.SkipPreflight != nil {
+		c.SkipPreflight = f.SkipPreflight
+	}
+	if f.Commitment != nil {
+		c.Commitment = f.Commitment
+	}
+	if f.MaxRetries != nil {
+		c.MaxRetries = f.MaxRetries
+	}
+}
+
+type solanaNodes []*solcfg.Node
+
+// SetFrom merges nodes by Name, like the node does.
+func (ns *solanaNodes) SetFrom(fs *solanaNodes) {
+	for _, f := range *fs {
+		if f.Name == nil {
+			*ns = append(*ns, f)
+		} else if i := slices.IndexFunc(*ns, func(n *solcfg.Node) bool {
+			return n.Name != nil && *n.Name == *f.Name
+		}); i == -1 {
+			*ns = append(*ns, f)
+		} else {
+			if f.URL != nil {
+				(*ns)[i].URL = f.URL
+			}
+		}
+	}
+}
+
+// TOMLString returns a TOML encoded string.
+func (c *nodeConfig) TOMLString() (string, error) {
+	b, err := toml.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
 // AddNetworksConfig adds EVM network configurations to a base config TOML. Useful for adding networks with default
 // settings. See AddNetworkDetailedConfig for adding more detailed network configuration.
 func AddNetworksConfig(baseTOML string, networks ...blockchain.EVMNetwork) string {
@@ -17,8 +141,51 @@ func AddNetworksConfig(baseTOML string, networks ...blockchain.EVMNetwork) strin
 }
 
 // AddNetworkDetailedConfig adds EVM config to a base TOML. Also takes a detailed network config TOML where values like
-// using transaction forwarders can be included.
+// using transaction forwarders can be included, which is applied to every network.
 // See https://github.com/smartcontractkit/chainlink/blob/develop/docs/CONFIG.md#EVM
-func AddNetworkDetailedConfig(baseTOML, detailedNetworkConfig string, network blockchain.EVMNetwork) string {
-	return fmt.Sprintf("%s\n\n%s", baseTOML, network.MustChainlinkTOML(detailedNetworkConfig))
+func AddNetworkDetailedConfig(baseTOML, detailedNetworkConfig string, networks ...blockchain.EVMNetwork) string {
+	networksToml := ""
+	for _, network := range networks {
+		networksToml = fmt.Sprintf("%s\n\n%s", networksToml, network.MustChainlinkTOML(detailedNetworkConfig))
+	}
+	return fmt.Sprintf("%s\n\n%s", baseTOML, networksToml)
+}
+
+// AddSolanaNetworkConfig adds a Solana chain with a single RPC node to a base config TOML.
+func AddSolanaNetworkConfig(baseTOML, chainID, nodeName, url string) string {
+	return fmt.Sprintf(`%s
+
+[[Solana]]
+ChainID = '%s'
+Enabled = true
+
+[[Solana.Nodes]]
+Name = '%s'
+URL = '%s'`, baseTOML, chainID, nodeName, url)
+}
+
+// ValidateConfigTOML strictly decodes a node config TOML against the Chainlink config, so that unknown or misspelled keys
+// are caught before launching nodes instead of hours into a test. Keys are checked against the same config types the
+// node uses, so newly added config fields are recognized without changes here. Only the core settings, EVM and Solana
+// chains are supported, as those are what the tests configure.
+func ValidateConfigTOML(configTOML string) error {
+	var cfg nodeConfig
+	if err := v2.DecodeTOML(strings.NewReader(configTOML), &cfg); err != nil {
+		return errors.Wrap(err, "invalid Chainlink config TOML")
+	}
+	return nil
+}
+
+// MergeConfigTOML applies a node specific override TOML on top of a base config TOML, returning the merged config.
+// Overrides follow the same merge rules as the node's own config files, e.g. EVM chains are merged by ChainID.
+func MergeConfigTOML(baseTOML, overrideTOML string) (string, error) {
+	var base, override nodeConfig
+	if err := v2.DecodeTOML(strings.NewReader(baseTOML), &base); err != nil {
+		return "", errors.Wrap(err, "invalid base Chainlink config TOML")
+	}
+	if err := v2.DecodeTOML(strings.NewReader(overrideTOML), &override); err != nil {
+		return "", errors.Wrap(err, "invalid override Chainlink config TOML")
+	}
+	base.SetFrom(&override)
+	return base.TOMLString()
 }
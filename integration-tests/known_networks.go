@@ -3,6 +3,7 @@ package networks
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -175,13 +176,20 @@ var (
 	}
 )
 
-// determineSelectedNetworks uses `SELECTED_NETWORKS` to determine which network(s) to run the tests on
+// determineSelectedNetworks uses `SELECTED_NETWORKS` to determine which network(s) to run the tests on,
+// defaulting to `SIMULATED` when unset
 func determineSelectedNetworks() []blockchain.EVMNetwork {
 	logging.Init()
 	selectedNetworks := make([]blockchain.EVMNetwork, 0)
-	setNetworkNames := strings.Split(strings.ToUpper(os.Getenv("SELECTED_NETWORKS")), ",")
+	selectedNetworksEnv := strings.TrimSpace(os.Getenv("SELECTED_NETWORKS"))
+	if selectedNetworksEnv == "" {
+		log.Warn().Msg("No 'SELECTED_NETWORKS' env var defined, defaulting to 'SIMULATED'")
+		selectedNetworksEnv = "SIMULATED"
+	}
+	setNetworkNames := strings.Split(strings.ToUpper(selectedNetworksEnv), ",")
 
 	for _, setNetworkName := range setNetworkNames {
+		setNetworkName = strings.TrimSpace(setNetworkName)
 		if chosenNetwork, valid := mappedNetworks[setNetworkName]; valid {
 			log.Info().
 				Interface("SELECTED_NETWORKS", setNetworkNames).
@@ -195,9 +203,11 @@ func determineSelectedNetworks() []blockchain.EVMNetwork {
 			for validNetwork := range mappedNetworks {
 				validNetworks = append(validNetworks, validNetwork)
 			}
+			sort.Strings(validNetworks)
 			log.Fatal().
 				Interface("SELECTED_NETWORKS", setNetworkNames).
 				Str("Valid Networks", strings.Join(validNetworks, ", ")).
+				Str("Invalid Network", setNetworkName).
 				Msg("SELECTED_NETWORKS value is invalid. Use a valid network(s).")
 		}
 	}
@@ -1,34 +1,327 @@
 package soak_test
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 
+	"github.com/smartcontractkit/chainlink-env/config"
 	"github.com/smartcontractkit/chainlink-env/environment"
 	"github.com/smartcontractkit/chainlink-env/pkg/helm/chainlink"
-	"github.com/smartcontractkit/chainlink-env/pkg/helm/ethereum"
 	"github.com/smartcontractkit/chainlink-env/pkg/helm/mockserver"
 	mockservercfg "github.com/smartcontractkit/chainlink-env/pkg/helm/mockserver-cfg"
-	"github.com/smartcontractkit/chainlink-env/pkg/helm/remotetestrunner"
-	"github.com/smartcontractkit/chainlink-testing-framework/actions"
 	"github.com/smartcontractkit/chainlink-testing-framework/blockchain"
 	"github.com/smartcontractkit/chainlink-testing-framework/logging"
 
 	networks "github.com/smartcontractkit/chainlink/integration-tests"
 	"github.com/smartcontractkit/chainlink/integration-tests/client"
+	soaktests "github.com/smartcontractkit/chainlink/integration-tests/soak/tests"
+	"github.com/smartcontractkit/chainlink/integration-tests/testsetups"
 )
 
 func init() {
 	logging.Init()
+	baseEnvironmentConfig.TTL = soakTTL()
 }
 
 var baseEnvironmentConfig = &environment.Config{
-	TTL: time.Hour * 720, // 30 days,
+	TTL: defaultSoakTTL,
+}
+
+const (
+	nodeCountEnvVar  = "CL_NODE_COUNT"
+	defaultNodeCount = 6
+	fleetFileEnvVar  = "CL_FLEET_FILE"
+
+	soakTTLEnvVar  = "SOAK_TTL"
+	defaultSoakTTL = time.Hour * 720 // 30 days
+)
+
+// soakTTL reads how long the soak environment should live from SOAK_TTL, defaulting to 30 days
+func soakTTL() time.Duration {
+	ttl := defaultSoakTTL
+	if ttlStr, ok := os.LookupEnv(soakTTLEnvVar); ok && ttlStr != "" {
+		var err error
+		ttl, err = time.ParseDuration(ttlStr)
+		if err != nil {
+			log.Fatal().Err(err).Str(soakTTLEnvVar, ttlStr).Msg("Error parsing soak environment TTL")
+		}
+		if ttl <= 0 {
+			log.Fatal().Str(soakTTLEnvVar, ttlStr).Msg("Soak environment TTL must be positive")
+		}
+	}
+	log.Info().Str("TTL", ttl.String()).Msg("Soak environment TTL")
+	return ttl
+}
+
+// fleet describes the Chainlink nodes to launch, allowing each node to carry its own config overrides.
+// Nodes are either listed one by one, or generated from a weighted distribution.
+type fleet struct {
+	Nodes        []fleetNode        `yaml:"nodes"`
+	Distribution *fleetDistribution `yaml:"distribution"`
+}
+
+// fleetNode holds config overrides for a single node, applied on top of the test's base config
+type fleetNode struct {
+	TOML      string                   `yaml:"toml"`
+	LogLevel  string                   `yaml:"logLevel"`  // Shorthand for setting Log.Level, taking precedence over TOML
+	Resources testsetups.NodeResources `yaml:"resources"` // Taking precedence over the CL_NODE_* resource env vars
+}
+
+// fleetDistribution generates a fleet by spreading config overrides across nodes by weight,
+// e.g. 70% of nodes with fast polling and 30% with slow polling
+type fleetDistribution struct {
+	NodeCount int           `yaml:"nodeCount"` // Defaults to CL_NODE_COUNT
+	Seed      *int64        `yaml:"seed"`      // Random if unset, and logged so the fleet can be reproduced
+	Buckets   []fleetBucket `yaml:"buckets"`
+}
+
+// fleetBucket holds config overrides shared by a share of the fleet proportional to its weight
+type fleetBucket struct {
+	Weight    int                      `yaml:"weight"`
+	TOML      string                   `yaml:"toml"`
+	LogLevel  string                   `yaml:"logLevel"`
+	Resources testsetups.NodeResources `yaml:"resources"`
+}
+
+// loadFleet reads a fleet from the YAML or JSON file at CL_FLEET_FILE, so fleet topologies can be version controlled.
+// If unset, it returns a fleet of identical nodes sized by CL_NODE_COUNT.
+func loadFleet(t *testing.T) fleet {
+	fleetFile, ok := os.LookupEnv(fleetFileEnvVar)
+	if !ok || fleetFile == "" {
+		return fleet{Nodes: make([]fleetNode, nodeCount(t))}
+	}
+	fleetBytes, err := os.ReadFile(fleetFile)
+	require.NoError(t, err, "Error reading fleet file")
+	var f fleet
+	// JSON is a subset of YAML, so this handles both
+	require.NoError(t, yaml.Unmarshal(fleetBytes, &f), "Error parsing fleet file %s", fleetFile)
+	if f.Distribution != nil {
+		require.Empty(t, f.Nodes, "Fleet file %s must define either nodes or a distribution, not both", fleetFile)
+		dist := f.Distribution
+		count := dist.NodeCount
+		if count == 0 {
+			count = nodeCount(t)
+		}
+		seed := time.Now().UnixNano()
+		if dist.Seed != nil {
+			seed = *dist.Seed
+		}
+		log.Info().Int64("Seed", seed).Int("Buckets", len(dist.Buckets)).Msg("Distributing Chainlink fleet")
+		f.Nodes, err = distributeFleet(dist.Buckets, count, seed)
+		require.NoError(t, err, "Error distributing fleet from %s", fleetFile)
+	}
+	require.NotEmpty(t, f.Nodes, "Fleet file %s defines no nodes", fleetFile)
+	log.Info().Str(fleetFileEnvVar, fleetFile).Int("Nodes", len(f.Nodes)).Msg("Read Chainlink fleet")
+	return f
+}
+
+// distributeFleet builds nodeCount nodes, giving each bucket a share of the nodes proportional to its weight, rounded
+// by largest remainder. Equal weights spread the buckets uniformly. Which node gets which bucket is shuffled using
+// seed, so the same seed always produces the same fleet.
+func distributeFleet(buckets []fleetBucket, nodeCount int, seed int64) ([]fleetNode, error) {
+	if nodeCount <= 0 {
+		return nil, errors.Errorf("node count must be positive, got %d", nodeCount)
+	}
+	if len(buckets) == 0 {
+		return nil, errors.New("no buckets to distribute")
+	}
+	totalWeight := 0
+	for i, bucket := range buckets {
+		if bucket.Weight <= 0 {
+			return nil, errors.Errorf("weight of bucket %d must be positive, got %d", i, bucket.Weight)
+		}
+		totalWeight += bucket.Weight
+	}
+
+	counts := make([]int, len(buckets))
+	remainders := make([]int, len(buckets))
+	assigned := 0
+	for i, bucket := range buckets {
+		counts[i] = bucket.Weight * nodeCount / totalWeight
+		remainders[i] = bucket.Weight * nodeCount % totalWeight
+		assigned += counts[i]
+	}
+	// Hand out the nodes lost to rounding down to the buckets closest to their next node
+	byRemainder := make([]int, len(buckets))
+	for i := range byRemainder {
+		byRemainder[i] = i
+	}
+	sort.SliceStable(byRemainder, func(i, j int) bool {
+		return remainders[byRemainder[i]] > remainders[byRemainder[j]]
+	})
+	for _, i := range byRemainder[:nodeCount-assigned] {
+		counts[i]++
+	}
+
+	nodes := make([]fleetNode, 0, nodeCount)
+	for i, bucket := range buckets {
+		for j := 0; j < counts[i]; j++ {
+			nodes = append(nodes, fleetNode{TOML: bucket.TOML, LogLevel: bucket.LogLevel, Resources: bucket.Resources})
+		}
+	}
+	//nolint:gosec // Reproducibility matters here, not unpredictability
+	rand.New(rand.NewSource(seed)).Shuffle(len(nodes), func(i, j int) {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	})
+	return nodes, nil
+}
+
+// rampedNodes are the Chainlink nodes left out of the launch by a testsetups.NodeRamp, added on its schedule
+type rampedNodes struct {
+	charts   []environment.ConnectedChart
+	schedule []testsetups.RampStep
+}
+
+// addChainlinkNodes adds a Chainlink node to the environment for each node in the fleet, applying that node's
+// config overrides on top of the shared base TOML. For any setting, e.g. the log level, the node's logLevel takes
+// precedence over its TOML overrides, which take precedence over the base TOML. The remote test runner's log level
+// is set separately, see testsetups.RunnerLogLevelEnvVar.
+//
+// If a ramp is set, see testsetups.RampInitialNodesEnvVar, only its initial nodes are added, and the others are
+// returned to be added on its schedule once the environment is launched. Every node's chart is built here either way,
+// so ramped nodes get the same base TOML, fleet overrides and resources as the ones launched upfront.
+func addChainlinkNodes(t *testing.T, testEnvironment *environment.Environment, baseTOML string) *rampedNodes {
+	baseResources, err := testsetups.LoadNodeResources()
+	require.NoError(t, err, "Error loading node resources")
+	ramp, err := testsetups.LoadNodeRamp()
+	require.NoError(t, err, "Error loading node ramp")
+	nodes := loadFleet(t).Nodes
+	charts := make([]environment.ConnectedChart, 0, len(nodes))
+	for i, node := range nodes {
+		nodeTOML := baseTOML
+		for _, overrides := range []string{node.TOML, logLevelTOML(node.LogLevel)} {
+			if overrides == "" {
+				continue
+			}
+			nodeTOML, err = client.MergeConfigTOML(nodeTOML, overrides)
+			require.NoError(t, err, "Error applying config overrides for node %d", i)
+		}
+		require.NoError(t, client.ValidateConfigTOML(nodeTOML), "Error validating config for node %d", i)
+		nodeResources := baseResources.Merge(node.Resources)
+		require.NoError(t, nodeResources.Validate(), "Invalid resources for node %d", i)
+		chainlinkValues := chainlinkImageValues()
+		if resourceValues := nodeResources.HelmValues(); resourceValues != nil {
+			if chainlinkValues == nil {
+				chainlinkValues = map[string]interface{}{}
+			}
+			chainlinkValues["resources"] = resourceValues
+		}
+		values := map[string]interface{}{
+			"toml": nodeTOML,
+		}
+		if chainlinkValues != nil {
+			values["chainlink"] = chainlinkValues
+		}
+		chart := chainlink.New(i, values)
+		image, version := chartImage(chart)
+		// Resources left unset come from the chart's defaults, which may conflict with the ones set, e.g. a request
+		// above the default limit
+		chartResources := chartResources(chart)
+		require.NoError(t, chartResources.Validate(), "Invalid resources for node %d, including the chart's defaults", i)
+		log.Info().
+			Int("Node", i).
+			Str("Image", image).
+			Str("Version", version).
+			Interface("Resources", chartResources).
+			Msg("Chainlink node image and resources")
+		charts = append(charts, chart)
+	}
+	launchedCount := len(charts)
+	if ramp != nil {
+		launchedCount = ramp.InitialNodes(len(charts))
+	}
+	for _, chart := range charts[:launchedCount] {
+		testEnvironment.AddHelm(chart)
+	}
+	if launchedCount == len(charts) {
+		return nil
+	}
+	schedule := ramp.Schedule(len(charts))
+	log.Info().Int("Initial Nodes", launchedCount).Interface("Schedule", schedule).Msg("Ramping up Chainlink nodes")
+	return &rampedNodes{charts: charts[launchedCount:], schedule: schedule}
+}
+
+// chainlinkImageValues returns the chart values pinning the Chainlink image to the repository in CHAINLINK_IMAGE and the
+// tag in CHAINLINK_VERSION, so release candidates are soaked on an exact build. Either one left unset keeps the
+// chart's default.
+func chainlinkImageValues() map[string]interface{} {
+	image := map[string]interface{}{}
+	if repository := os.Getenv(config.EnvVarCLImage); repository != "" {
+		image["image"] = repository
+	}
+	if tag := os.Getenv(config.EnvVarCLTag); tag != "" {
+		image["version"] = tag
+	}
+	if len(image) == 0 {
+		return nil
+	}
+	return map[string]interface{}{"image": image}
+}
+
+// chartImage returns the Chainlink image repository and tag a chart resolved to
+func chartImage(chart environment.ConnectedChart) (image, version string) {
+	values := chart.GetValues()
+	if values == nil {
+		return "", ""
+	}
+	chainlinkValues, _ := (*values)["chainlink"].(map[string]interface{})
+	imageValues, _ := chainlinkValues["image"].(map[string]interface{})
+	image, _ = imageValues["image"].(string)
+	version, _ = imageValues["version"].(string)
+	return image, version
+}
+
+// chartResources returns the resources of the Chainlink container a chart resolved to
+func chartResources(chart environment.ConnectedChart) testsetups.NodeResources {
+	values := chart.GetValues()
+	if values == nil {
+		return testsetups.NodeResources{}
+	}
+	chainlinkValues, _ := (*values)["chainlink"].(map[string]interface{})
+	resourceValues, _ := chainlinkValues["resources"].(map[string]interface{})
+	get := func(kind, name string) string {
+		kindValues, _ := resourceValues[kind].(map[string]interface{})
+		value, _ := kindValues[name].(string)
+		return value
+	}
+	return testsetups.NodeResources{
+		Requests: testsetups.ResourceValues{CPU: get("requests", "cpu"), Memory: get("requests", "memory")},
+		Limits:   testsetups.ResourceValues{CPU: get("limits", "cpu"), Memory: get("limits", "memory")},
+	}
+}
+
+// logLevelTOML returns the config overrides setting the log level, or nothing if level is empty
+func logLevelTOML(level string) string {
+	if level == "" {
+		return ""
+	}
+	return fmt.Sprintf("[Log]\nLevel = '%s'", strings.ToLower(level))
+}
+
+// nodeCount reads the amount of Chainlink nodes to launch from CL_NODE_COUNT, defaulting to 6
+func nodeCount(t *testing.T) int {
+	countStr, ok := os.LookupEnv(nodeCountEnvVar)
+	if !ok || countStr == "" {
+		return defaultNodeCount
+	}
+	count, err := strconv.Atoi(countStr)
+	require.NoError(t, err, "Error parsing %s", nodeCountEnvVar)
+	require.Greater(t, count, 0, "%s must be positive", nodeCountEnvVar)
+	return count
 }
 
 // Run the OCR soak test defined in ./tests/ocr_test.go
@@ -40,7 +333,6 @@ func TestOCRSoak(t *testing.T) {
 		strings.ReplaceAll(strings.ToLower(activeEVMNetwork.Name), " ", "-"),
 	)
 
-	replicas := 6
 	// Values you want each node to have the exact same of (e.g. eth_chain_id)
 	baseTOML := `[OCR]
 Enabled = true
@@ -53,13 +345,60 @@ ListenPort = 6690`
 	testEnvironment := environment.New(baseEnvironmentConfig).
 		AddHelm(mockservercfg.New(nil)).
 		AddHelm(mockserver.New(nil))
-	for i := 0; i < replicas; i++ {
-		testEnvironment.AddHelm(chainlink.New(i, map[string]interface{}{
-			"toml": client.AddNetworksConfig(baseTOML, activeEVMNetwork),
-		}))
+	ramp := addChainlinkNodes(t, testEnvironment, client.AddNetworksConfig(baseTOML, activeEVMNetwork))
+
+	soakTestHelper(t, testEnvironment, activeEVMNetwork, ramp, soaktests.OCRSoak)
+}
+
+// Run the OCR soak test defined in ./tests/ocr_test.go with every node connected to all SELECTED_NETWORKS at once,
+// the OCR contracts being deployed on the first of them
+func TestOCRSoakMultiNetwork(t *testing.T) {
+	activeEVMNetworks := networks.SelectedNetworks // Environments currently being used to soak test on
+
+	networkNames := make([]string, len(activeEVMNetworks))
+	for i, network := range activeEVMNetworks {
+		networkNames[i] = strings.ReplaceAll(strings.ToLower(network.Name), " ", "-")
 	}
+	baseEnvironmentConfig.NamespacePrefix = fmt.Sprintf("soak-ocr-multi-%s", strings.Join(networkNames, "-"))
+
+	// Values you want each node to have the exact same of (e.g. eth_chain_id)
+	baseTOML := `[OCR]
+Enabled = true
+
+[P2P]
+[P2P.V1]
+Enabled = true
+ListenIP = '0.0.0.0'
+ListenPort = 6690`
+	testEnvironment := environment.New(baseEnvironmentConfig).
+		AddHelm(mockservercfg.New(nil)).
+		AddHelm(mockserver.New(nil))
+	ramp := addChainlinkNodes(t, testEnvironment, client.AddNetworksConfig(baseTOML, activeEVMNetworks...))
+
+	multiNetworkSoakTestHelper(t, testEnvironment, activeEVMNetworks, ramp, soaktests.OCRSoak)
+}
+
+// Run the Solana node soak test defined in ./tests/solana_test.go, deploying a Solana validator for the nodes
+func TestSolanaNodeSoak(t *testing.T) {
+	solanaNetwork := testsetups.DefaultSolanaNetwork()
+
+	baseEnvironmentConfig.NamespacePrefix = "soak-solana-node"
+
+	// Values you want each node to have the exact same of
+	baseTOML := `[OCR2]
+Enabled = true
 
-	soakTestHelper(t, testEnvironment, activeEVMNetwork)
+[P2P]
+[P2P.V2]
+Enabled = true
+ListenAddresses = ['0.0.0.0:6690']`
+	testEnvironment := environment.New(baseEnvironmentConfig).
+		AddHelm(mockservercfg.New(nil)).
+		AddHelm(mockserver.New(nil))
+	ramp := addChainlinkNodes(t, testEnvironment,
+		client.AddSolanaNetworkConfig(baseTOML, solanaNetwork.ChainID, solanaNetwork.Name, solanaNetwork.URL))
+
+	solanaSoakTestHelper(t, testEnvironment, solanaNetwork, ramp, soaktests.SolanaNodeSoak)
 }
 
 // Run the OCR soak test defined in ./tests/ocr_test.go
@@ -71,7 +410,6 @@ func TestForwarderOCRSoak(t *testing.T) {
 		strings.ReplaceAll(strings.ToLower(activeEVMNetwork.Name), " ", "-"),
 	)
 
-	replicas := 6
 	// Values you want each node to have the exact same of (e.g. eth_chain_id)
 	baseTOML := `[OCR]
 Enabled = true
@@ -89,14 +427,9 @@ ForwardersEnabled = true`
 	testEnvironment := environment.New(baseEnvironmentConfig).
 		AddHelm(mockservercfg.New(nil)).
 		AddHelm(mockserver.New(nil))
-	for i := 0; i < replicas; i++ {
-		testEnvironment.AddHelm(chainlink.New(i, map[string]interface{}{
-			"toml": client.AddNetworkDetailedConfig(baseTOML, networkDetailTOML, activeEVMNetwork),
-		}))
-	}
-	// List of distinct Chainlink nodes to launch, and their distinct values (blank interface for none)
-
-	soakTestHelper(t, testEnvironment, activeEVMNetwork)
+	ramp := addChainlinkNodes(t, testEnvironment,
+		client.AddNetworkDetailedConfig(baseTOML, networkDetailTOML, activeEVMNetwork))
+	soakTestHelper(t, testEnvironment, activeEVMNetwork, ramp, soaktests.ForwarderOCRSoak)
 }
 
 // Run the keeper soak test defined in ./tests/keeper_test.go
@@ -108,7 +441,6 @@ func TestKeeperSoak(t *testing.T) {
 		strings.ReplaceAll(strings.ToLower(activeEVMNetwork.Name), " ", "-"),
 	)
 
-	replicas := 6
 	// Values you want each node to have the exact same of (e.g. eth_chain_id)
 	baseTOML := `[Keeper]
 TurnLookBack = 0
@@ -116,47 +448,150 @@ TurnLookBack = 0
 SyncInterval = '5s'
 PerformGasOverhead = 150_000`
 	testEnvironment := environment.New(baseEnvironmentConfig)
-	for i := 0; i < replicas; i++ {
-		testEnvironment.AddHelm(chainlink.New(i, map[string]interface{}{
-			"toml": client.AddNetworksConfig(baseTOML, activeEVMNetwork),
-		}))
-	}
+	ramp := addChainlinkNodes(t, testEnvironment, client.AddNetworksConfig(baseTOML, activeEVMNetwork))
 
-	soakTestHelper(t, testEnvironment, activeEVMNetwork)
+	soakTestHelper(t, testEnvironment, activeEVMNetwork, ramp, soaktests.KeeperSoak)
 }
 
-// builds tests, launches environment, and triggers the soak test to run
+// evmSoak runs a soak test on an EVM network of a launched environment, e.g. soaktests.OCRSoak
+type evmSoak func(t *testing.T, testEnvironment *environment.Environment, network blockchain.EVMNetwork)
+
+// solanaSoak runs a soak test on the Solana network of a launched environment, e.g. soaktests.SolanaNodeSoak
+type solanaSoak func(t *testing.T, testEnvironment *environment.Environment, network testsetups.SolanaNetwork)
+
+// localSoak runs a soak test in-process against a launched environment, see testsetups.RunLocalEnvVar
+type localSoak func(t *testing.T, testEnvironment *environment.Environment)
+
+// launches the environment and triggers the soak test to run on an EVM network
 func soakTestHelper(
 	t *testing.T,
 	testEnvironment *environment.Environment,
 	activeEVMNetwork blockchain.EVMNetwork,
+	ramp *rampedNodes,
+	soak evmSoak,
+	mockRoutes ...testsetups.MockRoute,
 ) {
-	testDirectory := "./soak/tests"
-	log.Info().
-		Str("Name", t.Name()).
-		Str("Directory", testDirectory).
-		Str("Namespace", testEnvironment.Cfg.Namespace).
-		Msg("Soak Test")
-	remoteRunnerValues := actions.BasicRunnerValuesSetup(
-		t.Name(),
-		testEnvironment.Cfg.Namespace,
-		testDirectory,
-	)
-	// Set evm network connection for remote runner
-	for key, value := range activeEVMNetwork.ToMap() {
-		remoteRunnerValues[key] = value
-	}
-	remoteRunnerWrapper := map[string]interface{}{"remote_test_runner": remoteRunnerValues}
-
-	err := testEnvironment.
-		AddHelm(remotetestrunner.New(remoteRunnerWrapper)).
-		AddHelm(ethereum.New(&ethereum.Props{
-			NetworkName: activeEVMNetwork.Name,
-			Simulated:   activeEVMNetwork.Simulated,
-			WsURLs:      activeEVMNetwork.URLs,
-		})).
-		Run()
-	require.NoError(t, err, "Error launching test environment")
-	err = actions.TriggerRemoteTest("../../", testEnvironment)
-	require.NoError(t, err, "Error activating remote test")
+	multiNetworkSoakTestHelper(t, testEnvironment, []blockchain.EVMNetwork{activeEVMNetwork}, ramp, soak, mockRoutes...)
+}
+
+// launches the environment and triggers the soak test to run on several EVM networks at once, the first being the
+// primary network. The Chainlink nodes should already be configured for all of them, see client.AddNetworksConfig.
+// When running locally, the soak test runs on the primary network.
+func multiNetworkSoakTestHelper(
+	t *testing.T,
+	testEnvironment *environment.Environment,
+	activeEVMNetworks []blockchain.EVMNetwork,
+	ramp *rampedNodes,
+	soak evmSoak,
+	mockRoutes ...testsetups.MockRoute,
+) {
+	require.NotEmpty(t, activeEVMNetworks, "No EVM network to soak test on")
+	launchSoakHelper(t, testEnvironment, testsetups.EVMSoakNetworks(activeEVMNetworks...), ramp, mockRoutes,
+		func(t *testing.T, testEnvironment *environment.Environment) {
+			soak(t, testEnvironment, activeEVMNetworks[0])
+		})
+}
+
+// launches the environment and triggers the soak test to run on a Solana network. The Chainlink nodes should already be
+// configured for it, see client.AddSolanaNetworkConfig.
+func solanaSoakTestHelper(
+	t *testing.T,
+	testEnvironment *environment.Environment,
+	solanaNetwork testsetups.SolanaNetwork,
+	ramp *rampedNodes,
+	soak solanaSoak,
+	mockRoutes ...testsetups.MockRoute,
+) {
+	solanaNetworks := []testsetups.SoakNetwork{testsetups.SolanaSoakNetwork(solanaNetwork)}
+	launchSoakHelper(t, testEnvironment, solanaNetworks, ramp, mockRoutes,
+		func(t *testing.T, testEnvironment *environment.Environment) {
+			soak(t, testEnvironment, solanaNetwork)
+		})
+}
+
+// launches the environment, seeds the mockserver with the mock routes, if any, and triggers the soak test to run on
+// the given networks. If SOAK_RUN_LOCAL is set, the soak test is run in this process by soak instead, which may be nil
+// if the test can't run locally. If SOAK_MOCK_VALUE_INTERVAL is set, the values of the mock routes are changed over
+// time, until the local soak test is done or, when running remotely, until the launch context is, keeping this process
+// running meanwhile. Likewise, the ramped nodes, if any, are added on their schedule until it's done or the launch
+// context is, see testsetups.RampChainlinkNodes.
+func launchSoakHelper(
+	t *testing.T,
+	testEnvironment *environment.Environment,
+	networks []testsetups.SoakNetwork,
+	ramp *rampedNodes,
+	mockRoutes []testsetups.MockRoute,
+	soak localSoak,
+) {
+	runLocal := testsetups.RunLocal()
+	require.False(t, runLocal && soak == nil, "%s can't run locally, unset %s", t.Name(), testsetups.RunLocalEnvVar)
+	// Re-running the environment to add nodes would reconnect it under the local soak test
+	require.False(t, runLocal && ramp != nil, "Nodes can't be ramped up with %s, unset %s",
+		testsetups.RunLocalEnvVar, testsetups.RampInitialNodesEnvVar)
+	mockValues, err := testsetups.LoadMockValueSchedule()
+	require.NoError(t, err, "Error loading mock value schedule")
+	if mockValues != nil && len(mockValues.Paths) == 0 {
+		for _, route := range mockRoutes {
+			mockValues.Paths = append(mockValues.Paths, route.Path)
+		}
+		require.NotEmpty(t, mockValues.Paths, "%s is set, but there are no mock routes to drive, set %s",
+			testsetups.MockValueIntervalEnvVar, testsetups.MockValuePathsEnvVar)
+	}
+	ctx, cancel := launchContext(t)
+	defer cancel()
+	launchedEnvironment, err := testsetups.LaunchSoakEnvironment(ctx, testsetups.SoakLaunchInputs{
+		TestName:      t.Name(),
+		TestDirectory: "./soak/tests",
+		RootDirectory: "../../",
+		Environment:   testEnvironment,
+		Networks:      networks,
+		MockRoutes:    mockRoutes,
+	})
+	require.NoError(t, err, "Error launching soak test")
+	var mockValuesDone chan error
+	driveCtx, stopDriving := context.WithCancel(ctx)
+	defer stopDriving()
+	if mockValues != nil {
+		mockValuesDone = make(chan error, 1)
+		go func() {
+			mockValuesDone <- testsetups.DriveEnvironmentMockValues(driveCtx, launchedEnvironment, *mockValues)
+		}()
+	}
+	var rampDone chan error
+	if ramp != nil {
+		rampDone = make(chan error, 1)
+		go func() {
+			rampDone <- testsetups.RampChainlinkNodes(driveCtx, launchedEnvironment, ramp.charts, ramp.schedule)
+		}()
+	}
+	if runLocal {
+		log.Info().Str("Namespace", launchedEnvironment.Cfg.Namespace).Msg("Running soak test locally")
+		soak(t, launchedEnvironment)
+		stopDriving()
+	}
+	if rampDone != nil {
+		require.NoError(t, <-rampDone, "Error ramping up Chainlink nodes")
+	}
+	if mockValuesDone != nil {
+		// The remote soak test runs until its own end, so values are driven until the launch context is done
+		require.NoError(t, <-mockValuesDone, "Error driving mock values")
+	}
+}
+
+// launchTeardownMargin is how long before the test's deadline a launch is cancelled, to leave time for the teardown
+const launchTeardownMargin = 2 * time.Minute
+
+// launchContext is cancelled on an interrupt, e.g. a cancelled CI job, or shortly before the test times out, so the
+// launch is aborted and its environment torn down instead of the test being killed mid-launch
+func launchContext(t *testing.T) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	deadline, ok := t.Deadline()
+	if !ok {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithDeadline(ctx, deadline.Add(-launchTeardownMargin))
+	return ctx, func() {
+		cancel()
+		stop()
+	}
 }
@@ -0,0 +1,187 @@
+// Package soak holds the soak tests run by the remote test runner, see ../soak_runner_test.go. The soak logic is
+// exported so the runner can also run it in-process against a local connection to the environment, see
+// testsetups.RunLocalEnvVar.
+package soak
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-env/environment"
+	"github.com/smartcontractkit/chainlink-env/pkg/helm/chainlink"
+	"github.com/smartcontractkit/chainlink-testing-framework/blockchain"
+
+	"github.com/smartcontractkit/chainlink/integration-tests/actions"
+	"github.com/smartcontractkit/chainlink/integration-tests/client"
+	"github.com/smartcontractkit/chainlink/integration-tests/contracts"
+	"github.com/smartcontractkit/chainlink/integration-tests/testsetups"
+)
+
+// addChainlinkNodes adds the Chainlink nodes launched with the soak test to the environment, as many as the launcher
+// deployed, see testsetups.LoadSoakNodeCount
+func addChainlinkNodes(t *testing.T, testEnvironment *environment.Environment) *environment.Environment {
+	nodeCount, err := testsetups.LoadSoakNodeCount()
+	require.NoError(t, err, "Error loading Chainlink node count")
+	for i := 0; i < nodeCount; i++ {
+		testEnvironment.AddHelm(chainlink.New(i, nil))
+	}
+	return testEnvironment
+}
+
+// OCRSoak runs the OCR soak test on a launched environment
+func OCRSoak(t *testing.T, testEnvironment *environment.Environment, soakNetwork blockchain.EVMNetwork) {
+	newOCRSoakTest(t, testEnvironment, soakNetwork, false)
+}
+
+// ForwarderOCRSoak runs the OCR soak test with operator forwarders on a launched environment
+func ForwarderOCRSoak(t *testing.T, testEnvironment *environment.Environment, soakNetwork blockchain.EVMNetwork) {
+	newOCRSoakTest(t, testEnvironment, soakNetwork, true)
+}
+
+func newOCRSoakTest(t *testing.T, testEnvironment *environment.Environment, soakNetwork blockchain.EVMNetwork, forwarders bool) {
+	chainClient, err := blockchain.NewEVMClient(soakNetwork, testEnvironment)
+	require.NoError(t, err, "Error connecting to network")
+	ocrSoakTest := testsetups.NewOCRSoakTest(&testsetups.OCRSoakTestInputs{
+		BlockchainClient:     chainClient,
+		TestDuration:         time.Minute * 15,
+		NumberOfContracts:    2,
+		ChainlinkNodeFunding: big.NewFloat(.1),
+		ExpectedRoundTime:    time.Minute * 2,
+		RoundTimeout:         time.Minute * 15,
+		TimeBetweenRounds:    time.Minute * 1,
+		StartingAdapterValue: 5,
+	})
+	t.Cleanup(func() {
+		if err := actions.TeardownRemoteSuite(ocrSoakTest.TearDownVals(t)); err != nil {
+			log.Error().Err(err).Msg("Error tearing down environment")
+		}
+	})
+	ocrSoakTest.OperatorForwarderFlow = forwarders
+	ocrSoakTest.Setup(t, testEnvironment)
+	log.Info().Msg("Set up soak test")
+	ocrSoakTest.Run(t)
+}
+
+const (
+	solanaSoakDuration      = time.Minute * 15
+	solanaSoakCheckInterval = time.Minute
+)
+
+// SolanaNodeSoak soaks Chainlink nodes connected to a launched environment's Solana validator. No OCR runs: the OCR2
+// programs and feeds on Solana are deployed by the chainlink-solana test suite, which this module doesn't depend on.
+// Instead, every node creates its Solana OCR2 and transmitter keys, then the validator's health and each node's keys
+// are checked to stay available for the duration of the test.
+func SolanaNodeSoak(t *testing.T, testEnvironment *environment.Environment, soakNetwork testsetups.SolanaNetwork) {
+	chainlinkNodes, err := client.ConnectChainlinkNodes(testEnvironment)
+	require.NoError(t, err, "Error connecting to Chainlink nodes")
+	require.NotEmpty(t, chainlinkNodes, "No Chainlink nodes to soak test")
+	networkURLs := testEnvironment.URLs[soakNetwork.Name]
+	require.NotEmpty(t, networkURLs, "No URLs for Solana network %s", soakNetwork.Name)
+	validatorURL := networkURLs[0]
+
+	for i, node := range chainlinkNodes {
+		_, _, err = node.CreateOCR2Key("solana")
+		require.NoError(t, err, "Error creating Solana OCR2 key on node %d", i)
+		_, _, err = node.CreateTxKey("solana", soakNetwork.ChainID)
+		require.NoError(t, err, "Error creating Solana tx key on node %d", i)
+	}
+	log.Info().Str("Network", soakNetwork.Name).Int("Nodes", len(chainlinkNodes)).Msg("Set up Solana node soak test")
+
+	ticker := time.NewTicker(solanaSoakCheckInterval)
+	defer ticker.Stop()
+	for end := time.Now().Add(solanaSoakDuration); time.Now().Before(end); <-ticker.C {
+		require.NoError(t, solanaValidatorHealth(validatorURL), "Solana validator is unhealthy")
+		for i, node := range chainlinkNodes {
+			ocr2Keys, err := node.MustReadOCR2Keys()
+			require.NoError(t, err, "Error reading OCR2 keys of node %d", i)
+			require.True(t, hasOCR2Key(ocr2Keys, "solana"), "Node %d lost its Solana OCR2 key", i)
+			txKeys, _, err := node.ReadTxKeys("solana")
+			require.NoError(t, err, "Error reading Solana tx keys of node %d", i)
+			require.NotEmpty(t, txKeys.Data, "Node %d lost its Solana tx key", i)
+		}
+		log.Info().Str("Time Left", time.Until(end).Round(time.Second).String()).Msg("Solana soak check passed")
+	}
+}
+
+func hasOCR2Key(keys *client.OCR2Keys, chainType string) bool {
+	for _, key := range keys.Data {
+		if key.Attributes.ChainType == chainType {
+			return true
+		}
+	}
+	return false
+}
+
+// solanaValidatorHealth calls the validator's getHealth RPC method, which errors unless it's caught up with the cluster
+func solanaValidatorHealth(url string) error {
+	body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "getHealth"})
+	if err != nil {
+		return err
+	}
+	//nolint:gosec // The URL is the validator's, from the environment
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var health struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return err
+	}
+	if health.Error != nil {
+		return fmt.Errorf("getHealth: %s", health.Error.Message)
+	}
+	if health.Result != "ok" {
+		return fmt.Errorf("getHealth returned %q", health.Result)
+	}
+	return nil
+}
+
+// KeeperSoak runs the keeper soak test on a launched environment
+func KeeperSoak(t *testing.T, testEnvironment *environment.Environment, soakNetwork blockchain.EVMNetwork) {
+	chainClient, err := blockchain.NewEVMClient(soakNetwork, testEnvironment)
+	require.NoError(t, err, "Connecting to blockchain nodes shouldn't fail")
+	keeperBlockTimeTest := testsetups.NewKeeperBlockTimeTest(
+		testsetups.KeeperBlockTimeTestInputs{
+			BlockchainClient:  chainClient,
+			NumberOfContracts: 5,
+			KeeperRegistrySettings: &contracts.KeeperRegistrySettings{
+				PaymentPremiumPPB:    uint32(200000000),
+				FlatFeeMicroLINK:     uint32(0),
+				BlockCountPerTurn:    big.NewInt(3),
+				CheckGasLimit:        uint32(2500000),
+				StalenessSeconds:     big.NewInt(90000),
+				GasCeilingMultiplier: uint16(1),
+				MinUpkeepSpend:       big.NewInt(0),
+				MaxPerformGas:        uint32(5000000),
+				FallbackGasPrice:     big.NewInt(2e11),
+				FallbackLinkPrice:    big.NewInt(2e18),
+			},
+			CheckGasToBurn:       1,
+			PerformGasToBurn:     1,
+			BlockRange:           1000,
+			BlockInterval:        50,
+			ChainlinkNodeFunding: big.NewFloat(1),
+		},
+	)
+	t.Cleanup(func() {
+		if err := actions.TeardownRemoteSuite(keeperBlockTimeTest.TearDownVals(t)); err != nil {
+			log.Error().Err(err).Msg("Error tearing down environment")
+		}
+	})
+	keeperBlockTimeTest.Setup(t, testEnvironment)
+	keeperBlockTimeTest.Run(t)
+}
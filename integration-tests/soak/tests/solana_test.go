@@ -0,0 +1,31 @@
+package soak
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-env/environment"
+	"github.com/smartcontractkit/chainlink-env/pkg/helm/mockserver"
+	mockservercfg "github.com/smartcontractkit/chainlink-env/pkg/helm/mockserver-cfg"
+	"github.com/smartcontractkit/chainlink-env/pkg/helm/sol"
+
+	"github.com/smartcontractkit/chainlink/integration-tests/testsetups"
+)
+
+// TestSolanaNodeSoak runs the Solana node soak test on an environment's Solana validator, see SolanaNodeSoak
+func TestSolanaNodeSoak(t *testing.T) {
+	soakNetwork, err := testsetups.LoadSolanaSoakNetwork()
+	require.NoError(t, err, "Error loading soak network")
+	testEnvironment := environment.New(&environment.Config{InsideK8s: true})
+	testEnvironment.
+		AddHelm(mockservercfg.New(nil)).
+		AddHelm(mockserver.New(nil)).
+		AddHelm(sol.New(&sol.Props{NetworkName: soakNetwork.Name}))
+	err = addChainlinkNodes(t, testEnvironment).Run()
+	require.NoError(t, err, "Error running soak environment")
+	log.Info().Str("Namespace", testEnvironment.Cfg.Namespace).Msg("Connected to Soak Environment")
+
+	SolanaNodeSoak(t, testEnvironment, soakNetwork)
+}
@@ -0,0 +1,617 @@
+package testsetups
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/smartcontractkit/chainlink-env/environment"
+	"github.com/smartcontractkit/chainlink-env/pkg/helm/chainlink"
+	"github.com/smartcontractkit/chainlink-env/pkg/helm/ethereum"
+	"github.com/smartcontractkit/chainlink-env/pkg/helm/remotetestrunner"
+	"github.com/smartcontractkit/chainlink-env/pkg/helm/sol"
+	ctfActions "github.com/smartcontractkit/chainlink-testing-framework/actions"
+	"github.com/smartcontractkit/chainlink-testing-framework/blockchain"
+
+	"github.com/smartcontractkit/chainlink/integration-tests/actions"
+)
+
+const (
+	// RunnerLogLevelEnvVar overrides the log level of the remote test runner
+	RunnerLogLevelEnvVar  = "SOAK_RUNNER_LOG_LEVEL"
+	defaultRunnerLogLevel = "debug"
+
+	// NodeReadyTimeoutEnvVar overrides how long to wait for the Chainlink nodes to become ready after launch
+	NodeReadyTimeoutEnvVar  = "SOAK_NODE_READY_TIMEOUT"
+	defaultNodeReadyTimeout = 10 * time.Minute
+	nodeReadyPollInterval   = 5 * time.Second
+	nodeLogTailLines        = 20
+
+	// RunLocalEnvVar set to true runs the soak test in the launching process, against port forwards to the environment,
+	// instead of on the remote test runner. This is meant for quick iterations: the soak test ends along with the
+	// process, so a long environment TTL doesn't keep it running, and it ties up the local machine and its connection
+	// to the cluster for the whole test.
+	RunLocalEnvVar = "SOAK_RUN_LOCAL"
+
+	// evmChainIDsKey lists the chain IDs of the EVM networks on the remote test runner
+	evmChainIDsKey = "evm_chain_ids"
+	// nodeCountKey is the number of Chainlink nodes launched, on the remote test runner
+	nodeCountKey         = "cl_node_count"
+	defaultSoakNodeCount = 6
+)
+
+// SoakLaunchInputs define required inputs to launch a remote soak test
+type SoakLaunchInputs struct {
+	TestName      string                   // Name of the test to run on the remote test runner
+	TestDirectory string                   // Directory of the soak tests, relative to the integration-tests folder
+	RootDirectory string                   // Path to the integration-tests folder, relative to the caller
+	Environment   *environment.Environment // Environment with the Chainlink nodes already added
+	Networks      []SoakNetwork            // Networks the soak test runs on, the first being the primary network
+	Retry         *LaunchRetry             // Retries of transient launch failures, DefaultLaunchRetry if nil
+	MockRoutes    []MockRoute              // Responses seeded into the mockserver before the soak test starts, if any
+}
+
+// SoakNetwork describes the chain a soak test runs on
+type SoakNetwork struct {
+	Chart        environment.ConnectedChart // Chart that deploys, or connects to, the network
+	RunnerValues map[string]interface{}     // Network connection values for the remote test runner, if any
+}
+
+// EVMSoakNetwork builds a SoakNetwork for an EVM chain, deploying a geth network if it's simulated
+func EVMSoakNetwork(network blockchain.EVMNetwork) SoakNetwork {
+	return EVMSoakNetworks(network)[0]
+}
+
+// EVMSoakNetworks builds a SoakNetwork for each EVM chain, deploying a geth network for a simulated one. The remote test
+// runner gets the values of every network prefixed by its chain ID, e.g. chain_1337_evm_urls, along with the list of
+// chain IDs, see LoadEVMSoakNetworks. The first network's values are also set without a prefix, so tests on a single
+// network can keep using blockchain.LoadNetworkFromEnvironment.
+//
+// At most one of the networks may be simulated: every simulated network is deployed by the same geth chart, so the
+// launch fails if there are several, see LaunchSoakEnvironment. The others have to be live networks.
+func EVMSoakNetworks(networks ...blockchain.EVMNetwork) []SoakNetwork {
+	soakNetworks := make([]SoakNetwork, len(networks))
+	chainIDs := make([]string, len(networks))
+	for i, network := range networks {
+		chainIDs[i] = fmt.Sprint(network.ChainID)
+		networkValues := network.ToMap()
+		runnerValues := make(map[string]interface{}, len(networkValues)*2)
+		for key, value := range networkValues {
+			runnerValues[evmNetworkPrefix(network.ChainID)+"_"+key] = value
+			if i == 0 {
+				runnerValues[key] = value
+			}
+		}
+		soakNetworks[i] = SoakNetwork{
+			Chart: ethereum.New(&ethereum.Props{
+				NetworkName: network.Name,
+				Simulated:   network.Simulated,
+				WsURLs:      network.URLs,
+			}),
+			RunnerValues: runnerValues,
+		}
+	}
+	if len(soakNetworks) > 0 {
+		soakNetworks[0].RunnerValues[evmChainIDsKey] = strings.Join(chainIDs, ",")
+	}
+	return soakNetworks
+}
+
+// LoadEVMSoakNetworks loads the EVM networks set on the remote test runner by EVMSoakNetworks, in order, the first
+// being the primary network. If the runner wasn't given a list of chain IDs, it loads the single unprefixed network.
+func LoadEVMSoakNetworks() ([]blockchain.EVMNetwork, error) {
+	chainIDs := strings.TrimSpace(os.Getenv(strings.ToUpper(evmChainIDsKey)))
+	if chainIDs == "" {
+		return []blockchain.EVMNetwork{blockchain.LoadNetworkFromEnvironment()}, nil
+	}
+	var networks []blockchain.EVMNetwork
+	for _, chainIDStr := range strings.Split(chainIDs, ",") {
+		chainID, err := strconv.ParseInt(strings.TrimSpace(chainIDStr), 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid chain ID in %s", strings.ToUpper(evmChainIDsKey))
+		}
+		var network blockchain.EVMNetwork
+		if err = envconfig.Process(evmNetworkPrefix(chainID), &network); err != nil {
+			return nil, errors.Wrapf(err, "error loading network with chain ID %d", chainID)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// evmNetworkPrefix is the prefix of the remote test runner values of the EVM network with the given chain ID
+func evmNetworkPrefix(chainID int64) string {
+	return fmt.Sprintf("chain_%d", chainID)
+}
+
+// SolanaNetwork describes the Solana validator a soak test runs on, along with how the Chainlink nodes connect to it
+type SolanaNetwork struct {
+	Name    string `envconfig:"solana_network_name" default:"sol"`     // Name of the network, also the nodes' RPC node name
+	ChainID string `envconfig:"solana_chain_id" default:"localnet"`    // Chain ID the nodes configure the network with
+	URL     string `envconfig:"solana_url" default:"http://sol:8899"`  // HTTP RPC URL of the validator, from inside the cluster
+	WSURL   string `envconfig:"solana_ws_url" default:"ws://sol:8900"` // Websocket RPC URL of the validator, from inside the cluster
+}
+
+// DefaultSolanaNetwork is the validator deployed by the sol chart
+func DefaultSolanaNetwork() SolanaNetwork {
+	return SolanaNetwork{Name: "sol", ChainID: "localnet", URL: "http://sol:8899", WSURL: "ws://sol:8900"}
+}
+
+// ToMap returns the network's values for the remote test runner, read back by LoadSolanaSoakNetwork
+func (n SolanaNetwork) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"solana_network_name": n.Name,
+		"solana_chain_id":     n.ChainID,
+		"solana_url":          n.URL,
+		"solana_ws_url":       n.WSURL,
+	}
+}
+
+// SolanaSoakNetwork builds a SoakNetwork deploying a Solana validator. Only one can be deployed per environment, as the
+// chart is always named sol, and it's only reachable from inside the cluster at the network's URLs.
+func SolanaSoakNetwork(network SolanaNetwork) SoakNetwork {
+	chart := sol.New(nil).(sol.Chart)
+	chart.Props.NetworkName = network.Name
+	return SoakNetwork{Chart: chart, RunnerValues: network.ToMap()}
+}
+
+// LoadSolanaSoakNetwork loads the Solana network set on the remote test runner by SolanaSoakNetwork, defaulting to
+// DefaultSolanaNetwork's values for any unset.
+func LoadSolanaSoakNetwork() (SolanaNetwork, error) {
+	var network SolanaNetwork
+	if err := envconfig.Process("", &network); err != nil {
+		return SolanaNetwork{}, errors.Wrap(err, "error loading Solana network")
+	}
+	return network, nil
+}
+
+// LoadSoakNodeCount loads the number of Chainlink nodes launched with the soak test, set on the remote test runner by
+// LaunchSoak, so the soak test connects to all of them, named chainlink-0 onwards. It defaults to 6 if unset.
+func LoadSoakNodeCount() (int, error) {
+	countStr := strings.TrimSpace(os.Getenv(strings.ToUpper(nodeCountKey)))
+	if countStr == "" {
+		return defaultSoakNodeCount, nil
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error parsing %s", strings.ToUpper(nodeCountKey))
+	}
+	if count <= 0 {
+		return 0, errors.Errorf("%s must be positive, got %d", strings.ToUpper(nodeCountKey), count)
+	}
+	return count, nil
+}
+
+// LaunchSoak adds the remote test runner and networks to the environment, launches it, and triggers the soak test to run
+// remotely. It returns errors instead of asserting on them so the same path can be used outside of go tests.
+// If the launch fails, the environment is shut down unless KEEP_ENVIRONMENTS is set to ALWAYS or ONFAIL. A successful
+// launch leaves the environment running for the soak test to proceed.
+//
+// Launches failing on transient errors, e.g. image pull timeouts, are retried in a fresh environment with the same
+// charts and config but a new namespace, see SoakLaunchInputs.Retry. Other errors fail the launch right away. A retry
+// launches a new environment, so SoakLaunchInputs.Environment is only the environment of the first attempt.
+//
+// Cancelling ctx aborts the launch: it waits for the step in progress, which can't be interrupted, for up to 5 minutes,
+// and the partially launched environment is torn down like a failed one, unless KEEP_ENVIRONMENTS is set to ALWAYS or
+// ONFAIL. If the step is still running by then, the environment is left to its TTL rather than torn down under it.
+//
+// If SOAK_RUN_LOCAL is set, the environment is launched without the remote test runner and nothing is triggered, see
+// LaunchSoakEnvironment.
+func LaunchSoak(ctx context.Context, inputs SoakLaunchInputs) error {
+	_, err := LaunchSoakEnvironment(ctx, inputs)
+	return err
+}
+
+// LaunchSoakEnvironment launches a soak test like LaunchSoak, returning the environment that was launched, which is a
+// fresh one if the launch was retried. If SOAK_RUN_LOCAL is set, the remote test runner is left out and the soak test
+// isn't triggered: it's up to the caller to run the soak test against the returned environment.
+func LaunchSoakEnvironment(ctx context.Context, inputs SoakLaunchInputs) (*environment.Environment, error) {
+	// Checked before anything is launched, so there's nothing to tear down
+	networkValues, err := networkRunnerValues(inputs.Networks)
+	if err != nil {
+		return nil, err
+	}
+	retry := inputs.Retry
+	if retry == nil {
+		if retry, err = DefaultLaunchRetry(); err != nil {
+			return nil, err
+		}
+	}
+	// The charts added by the caller, without the runner and networks added on launch, to recreate the environment from
+	baseCharts := append([]environment.ConnectedChart(nil), inputs.Environment.Charts...)
+
+	testEnvironment := inputs.Environment
+	for attempt := 1; ; attempt++ {
+		if err = ctx.Err(); err != nil {
+			return nil, errors.Wrap(err, "soak launch cancelled")
+		}
+		if attempt > 1 {
+			testEnvironment = freshEnvironment(testEnvironment, baseCharts)
+		}
+		log.Info().
+			Str("Name", inputs.TestName).
+			Str("Directory", inputs.TestDirectory).
+			Str("Runner Log Level", runnerLogLevel()).
+			Bool("Run Local", RunLocal()).
+			Str("Namespace", testEnvironment.Cfg.Namespace).
+			Int("Attempt", attempt).
+			Int("Max Attempts", retry.Attempts).
+			Msg("Soak Test")
+		finished, err := runUntilDone(ctx, cancelledLaunchWait, func() error {
+			return launchAttempt(ctx, inputs, testEnvironment, networkValues)
+		})
+		if err == nil {
+			return testEnvironment, nil
+		}
+		if ctx.Err() != nil {
+			// Logs aren't collected so the launch returns promptly
+			log.Warn().Err(err).Str("Namespace", testEnvironment.Cfg.Namespace).Msg("Soak launch cancelled")
+			if finished {
+				teardownFailedSoak(testEnvironment)
+			} else {
+				// Shutting the environment down while the launch still uses it would race with it
+				log.Warn().
+					Str("Namespace", testEnvironment.Cfg.Namespace).
+					Str("Waited", cancelledLaunchWait.String()).
+					Msg("Cancelled soak launch is still running, leaving its environment to its TTL")
+			}
+			return nil, errors.Wrap(ctx.Err(), "soak launch cancelled")
+		}
+		if _, lerr := actions.CollectRemoteLogs(testEnvironment, inputs.TestName); lerr != nil {
+			log.Warn().Err(lerr).Msg("Error collecting logs of failed soak launch")
+		}
+		teardownFailedSoak(testEnvironment)
+		if !retry.IsTransient(err) {
+			return nil, err
+		}
+		if attempt >= retry.Attempts {
+			return nil, errors.Wrapf(err, "soak launch failed on transient errors %d time(s)", attempt)
+		}
+		log.Warn().Err(err).
+			Str("Namespace", testEnvironment.Cfg.Namespace).
+			Int("Attempt", attempt).
+			Int("Max Attempts", retry.Attempts).
+			Msg("Soak launch failed on a transient error, retrying in a fresh environment")
+	}
+}
+
+// RunLocal reports whether SOAK_RUN_LOCAL asks for the soak test to run locally instead of on the remote test runner
+func RunLocal() bool {
+	runLocal, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(RunLocalEnvVar)))
+	return err == nil && runLocal
+}
+
+// Overridden in tests, to launch and tear down without a cluster
+var (
+	launchAttempt       = launchSoakAttempt
+	shutdownEnvironment = (*environment.Environment).Shutdown
+	// cancelledLaunchWait is how long a cancelled launch is waited for before its environment is torn down
+	cancelledLaunchWait = 5 * time.Minute
+)
+
+// runUntilDone runs f, returning its error, or the context's error once ctx is done. As the environment's calls can't
+// be interrupted, f is then waited for up to wait longer, so the environment isn't torn down while f still uses it.
+// finished reports whether f returned, and is false if it's left running in the background.
+func runUntilDone(ctx context.Context, wait time.Duration, f func() error) (finished bool, err error) {
+	done := make(chan error, 1)
+	go func() { done <- f() }()
+	select {
+	case err = <-done:
+		return true, err
+	case <-ctx.Done():
+	}
+	select {
+	case <-done:
+		return true, ctx.Err()
+	case <-time.After(wait):
+		return false, ctx.Err()
+	}
+}
+
+// launchSoakAttempt adds the remote test runner and networks to the environment, launches it, seeds the mockserver, and
+// triggers the soak test. When running locally, the remote test runner is left out and the soak test is left to the
+// caller, see RunLocalEnvVar. It stops between steps once ctx is done, so a cancelled launch doesn't go on to trigger
+// the soak test in the background.
+func launchSoakAttempt(ctx context.Context, inputs SoakLaunchInputs, testEnvironment *environment.Environment, networkValues map[string]interface{}) error {
+	if RunLocal() {
+		for _, network := range inputs.Networks {
+			testEnvironment.AddHelm(network.Chart)
+		}
+		return launchEnvironment(ctx, inputs, testEnvironment)
+	}
+	remoteRunnerValues := ctfActions.BasicRunnerValuesSetup(
+		inputs.TestName,
+		testEnvironment.Cfg.Namespace,
+		inputs.TestDirectory,
+	)
+	remoteRunnerValues["test_log_level"] = runnerLogLevel()
+	remoteRunnerValues[nodeCountKey] = len(chainlinkNodes(testEnvironment))
+	// Set network connections for remote runner
+	for key, value := range networkValues {
+		remoteRunnerValues[key] = value
+	}
+	remoteRunnerWrapper := map[string]interface{}{"remote_test_runner": remoteRunnerValues}
+
+	testEnvironment.AddHelm(remotetestrunner.New(remoteRunnerWrapper))
+	for _, network := range inputs.Networks {
+		testEnvironment.AddHelm(network.Chart)
+	}
+	if err := launchEnvironment(ctx, inputs, testEnvironment); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := ctfActions.TriggerRemoteTest(inputs.RootDirectory, testEnvironment); err != nil {
+		return errors.Wrap(err, "error activating remote test")
+	}
+	return nil
+}
+
+// launchEnvironment launches the environment, waits for the Chainlink nodes to become ready, and seeds the mockserver
+func launchEnvironment(ctx context.Context, inputs SoakLaunchInputs, testEnvironment *environment.Environment) error {
+	if err := testEnvironment.Run(); err != nil {
+		return errors.Wrap(err, "error launching test environment")
+	}
+	if err := waitForChainlinkNodes(ctx, testEnvironment); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return seedEnvironmentMockRoutes(testEnvironment, inputs.MockRoutes)
+}
+
+// freshEnvironment creates a new environment with the config and charts of a failed one, in a new namespace
+func freshEnvironment(failed *environment.Environment, charts []environment.ConnectedChart) *environment.Environment {
+	cfg := *failed.Cfg
+	cfg.Namespace = ""
+	fresh := environment.New(&cfg)
+	for _, chart := range charts {
+		fresh.AddHelm(chart)
+	}
+	return fresh
+}
+
+// networkRunnerValues merges the remote test runner values of every network, erroring if two networks set the same
+// value differently instead of letting one silently win. It also errors if two networks deploy a chart of the same name,
+// e.g. every simulated EVM network is deployed as geth, as only one of them would be deployed.
+func networkRunnerValues(networks []SoakNetwork) (map[string]interface{}, error) {
+	if len(networks) == 0 {
+		return nil, errors.New("no network to run the soak test on")
+	}
+	networkValues := map[string]interface{}{}
+	deployedCharts := map[string]bool{}
+	for _, network := range networks {
+		if network.Chart.IsDeploymentNeeded() {
+			if deployedCharts[network.Chart.GetName()] {
+				return nil, errors.Errorf("more than one network deploys the %s chart", network.Chart.GetName())
+			}
+			deployedCharts[network.Chart.GetName()] = true
+		}
+		for key, value := range network.RunnerValues {
+			if existing, ok := networkValues[key]; ok && !reflect.DeepEqual(existing, value) {
+				return nil, errors.Errorf("networks set conflicting values for %s: %v and %v", key, existing, value)
+			}
+			networkValues[key] = value
+		}
+	}
+	return networkValues, nil
+}
+
+// runnerLogLevel reads the remote test runner's log level from SOAK_RUNNER_LOG_LEVEL, defaulting to debug.
+// It doesn't affect the Chainlink nodes, whose log levels are part of their config.
+func runnerLogLevel() string {
+	if level := strings.TrimSpace(os.Getenv(RunnerLogLevelEnvVar)); level != "" {
+		return strings.ToLower(level)
+	}
+	return defaultRunnerLogLevel
+}
+
+// nodeReadyTimeout reads how long to wait for the Chainlink nodes to become ready from SOAK_NODE_READY_TIMEOUT,
+// defaulting to 10 minutes. A timeout of 0 skips waiting.
+func nodeReadyTimeout() (time.Duration, error) {
+	timeoutStr := strings.TrimSpace(os.Getenv(NodeReadyTimeoutEnvVar))
+	if timeoutStr == "" {
+		return defaultNodeReadyTimeout, nil
+	}
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error parsing %s", NodeReadyTimeoutEnvVar)
+	}
+	if timeout < 0 {
+		return 0, errors.Errorf("%s must not be negative, got %s", NodeReadyTimeoutEnvVar, timeoutStr)
+	}
+	return timeout, nil
+}
+
+// waitForChainlinkNodes polls the pods of the environment's Chainlink nodes until they're all ready, so a node with a
+// bad config that crash-loops fails the launch instead of silently wasting the soak. If any node isn't ready within
+// the timeout, the error lists the pods that aren't ready along with the tail of their logs. It stops waiting once ctx is
+// done.
+func waitForChainlinkNodes(ctx context.Context, testEnvironment *environment.Environment) error {
+	timeout, err := nodeReadyTimeout()
+	if err != nil {
+		return err
+	}
+	if timeout == 0 || testEnvironment.Cfg.DryRun {
+		return nil
+	}
+	nodes := chainlinkNodes(testEnvironment)
+	if len(nodes) == 0 {
+		return nil
+	}
+	log.Info().Strs("Nodes", nodes).Str("Timeout", timeout.String()).Msg("Waiting for Chainlink nodes to become ready")
+
+	namespace := testEnvironment.Cfg.Namespace
+	selector := fmt.Sprintf("app in (%s)", strings.Join(nodes, ","))
+	deadline := time.Now().Add(timeout)
+	for {
+		pods, err := testEnvironment.Client.ListPods(namespace, selector)
+		if err != nil {
+			return errors.Wrap(err, "error listing Chainlink node pods")
+		}
+		notReady := notReadyNodes(nodes, pods.Items)
+		if len(notReady) == 0 {
+			log.Info().Int("Nodes", len(nodes)).Msg("All Chainlink nodes are ready")
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return notReadyError(testEnvironment, notReady, timeout)
+		}
+		log.Debug().Strs("Not Ready", notReadyNames(notReady)).Msg("Waiting for Chainlink nodes")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(nodeReadyPollInterval):
+		}
+	}
+}
+
+// chainlinkNodes returns the names of the environment's Chainlink nodes
+func chainlinkNodes(testEnvironment *environment.Environment) []string {
+	var nodes []string
+	for _, chart := range testEnvironment.Charts {
+		if node, ok := chart.(chainlink.Chart); ok {
+			nodes = append(nodes, node.Name)
+		}
+	}
+	return nodes
+}
+
+// notReadyNode is a Chainlink node which isn't ready, with its pod if it has one
+type notReadyNode struct {
+	name string
+	pod  *v1.Pod
+}
+
+// notReadyNodes returns the nodes which don't have a ready pod, sorted by name
+func notReadyNodes(nodes []string, pods []v1.Pod) []notReadyNode {
+	podsByNode := make(map[string]*v1.Pod)
+	for i := range pods {
+		pod := &pods[i]
+		node := pod.Labels["app"]
+		if existing, ok := podsByNode[node]; !ok || !podReady(*existing) {
+			podsByNode[node] = pod
+		}
+	}
+	var notReady []notReadyNode
+	for _, node := range nodes {
+		pod := podsByNode[node]
+		if pod == nil || !podReady(*pod) {
+			notReady = append(notReady, notReadyNode{name: node, pod: pod})
+		}
+	}
+	sort.Slice(notReady, func(i, j int) bool { return notReady[i].name < notReady[j].name })
+	return notReady
+}
+
+// podReady reports whether the pod is running and passes its readiness probes
+func podReady(pod v1.Pod) bool {
+	if pod.Status.Phase != v1.PodRunning {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func notReadyNames(notReady []notReadyNode) []string {
+	names := make([]string, len(notReady))
+	for i, node := range notReady {
+		names[i] = node.name
+		if node.pod != nil {
+			names[i] = node.pod.Name
+		}
+	}
+	return names
+}
+
+// notReadyError describes the nodes which aren't ready, including the tail of each pod's Chainlink container logs
+func notReadyError(testEnvironment *environment.Environment, notReady []notReadyNode, timeout time.Duration) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d Chainlink node(s) not ready after %s: %s", len(notReady), timeout, strings.Join(notReadyNames(notReady), ", "))
+	for _, node := range notReady {
+		if node.pod == nil {
+			fmt.Fprintf(&sb, "\n\n%s: no pod found", node.name)
+			continue
+		}
+		fmt.Fprintf(&sb, "\n\n%s (%s)", node.pod.Name, podStatus(*node.pod))
+		logs, err := podLogTail(testEnvironment, *node.pod)
+		if err != nil {
+			fmt.Fprintf(&sb, "\nerror reading logs: %s", err)
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s", logs)
+	}
+	return errors.New(sb.String())
+}
+
+// podStatus summarizes why a pod isn't ready, e.g. "Running, node: CrashLoopBackOff, 4 restarts"
+func podStatus(pod v1.Pod) string {
+	status := []string{string(pod.Status.Phase)}
+	for _, c := range pod.Status.ContainerStatuses {
+		if c.State.Waiting != nil {
+			status = append(status, fmt.Sprintf("%s: %s", c.Name, c.State.Waiting.Reason))
+		}
+		if c.RestartCount > 0 {
+			status = append(status, fmt.Sprintf("%s: %d restarts", c.Name, c.RestartCount))
+		}
+	}
+	return strings.Join(status, ", ")
+}
+
+// podLogTail returns the last lines logged by the pod's Chainlink container. The logs of the previous run are used if
+// the container restarted, as a crash-looping container usually hasn't logged anything yet.
+func podLogTail(testEnvironment *environment.Environment, pod v1.Pod) (string, error) {
+	const container = "node"
+	opts := &v1.PodLogOptions{Container: container, TailLines: new(int64)}
+	*opts.TailLines = nodeLogTailLines
+	for _, c := range pod.Status.ContainerStatuses {
+		if c.Name == container && c.RestartCount > 0 {
+			opts.Previous = true
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	logs, err := testEnvironment.Client.ClientSet.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts).Stream(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer logs.Close()
+	bs, err := io.ReadAll(logs)
+	return strings.TrimSpace(string(bs)), err
+}
+
+// teardownFailedSoak shuts down the environment of a soak test that failed to launch, so it doesn't linger until its TTL
+// expires. Teardown errors are only logged so they don't mask the launch failure.
+func teardownFailedSoak(testEnvironment *environment.Environment) {
+	keepEnvs := strings.ToUpper(os.Getenv("KEEP_ENVIRONMENTS"))
+	if keepEnvs == "ALWAYS" || keepEnvs == "ONFAIL" {
+		log.Warn().
+			Str("Namespace", testEnvironment.Cfg.Namespace).
+			Str("KEEP_ENVIRONMENTS", keepEnvs).
+			Msg("Soak test failed to launch, leaving environment running for debugging")
+		return
+	}
+	log.Warn().Str("Namespace", testEnvironment.Cfg.Namespace).Msg("Soak test failed to launch, tearing down environment")
+	if err := shutdownEnvironment(testEnvironment); err != nil {
+		log.Error().Err(err).
+			Str("Namespace", testEnvironment.Cfg.Namespace).
+			Msg("Error tearing down environment, it will need to be removed manually")
+	}
+}
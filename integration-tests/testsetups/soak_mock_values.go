@@ -0,0 +1,232 @@
+package testsetups
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/smartcontractkit/chainlink-env/environment"
+	"github.com/smartcontractkit/chainlink-env/pkg/helm/mockserver"
+	ctfClient "github.com/smartcontractkit/chainlink-testing-framework/client"
+)
+
+const (
+	// MockValueIntervalEnvVar sets how often the values of the mock routes change during a soak. Set, it enables
+	// driving them.
+	MockValueIntervalEnvVar = "SOAK_MOCK_VALUE_INTERVAL"
+	// MockValuePathsEnvVar sets the comma separated mockserver paths whose values change, defaulting to the seeded routes
+	MockValuePathsEnvVar = "SOAK_MOCK_VALUE_PATHS"
+	// MockValueMinEnvVar sets the lowest value of the random walk
+	MockValueMinEnvVar  = "SOAK_MOCK_VALUE_MIN"
+	defaultMockValueMin = 1
+	// MockValueMaxEnvVar sets the highest value of the random walk
+	MockValueMaxEnvVar  = "SOAK_MOCK_VALUE_MAX"
+	defaultMockValueMax = 1000
+	// MockValueMaxStepEnvVar sets the largest change of the random walk at each update
+	MockValueMaxStepEnvVar  = "SOAK_MOCK_VALUE_MAX_STEP"
+	defaultMockValueMaxStep = 10
+	// MockValueSeedEnvVar seeds the random walk, so its values can be reproduced
+	MockValueSeedEnvVar = "SOAK_MOCK_VALUE_SEED"
+	// MockValueSeriesEnvVar sets comma separated values to cycle through instead of a random walk, e.g. a recorded
+	// price feed
+	MockValueSeriesEnvVar = "SOAK_MOCK_VALUE_SERIES"
+)
+
+// MockValueSchedule describes changing the values of mockserver routes over time, so the nodes of a soak observe moving
+// prices rather than static ones. Values follow a random walk within [Min, Max], starting halfway, unless a Series is
+// given to cycle through.
+type MockValueSchedule struct {
+	Interval time.Duration // Time between updates, the first update being right away
+	Paths    []string      // Paths of the routes to update, all set to the same value, e.g. /ocr_price
+	Min      int           // Lowest value of the random walk
+	Max      int           // Highest value of the random walk
+	MaxStep  int           // Largest change of the random walk at each update
+	Seed     int64         // Seed of the random walk
+	Series   []int         // Values to cycle through instead of a random walk, if set
+}
+
+// LoadMockValueSchedule reads the schedule from the SOAK_MOCK_VALUE_* env vars. It returns nil if
+// SOAK_MOCK_VALUE_INTERVAL isn't set, leaving the mock routes static. The random walk defaults to steps of up to 10
+// between 1 and 1000, with a random seed which is logged so the values can be reproduced.
+func LoadMockValueSchedule() (*MockValueSchedule, error) {
+	intervalStr := strings.TrimSpace(os.Getenv(MockValueIntervalEnvVar))
+	if intervalStr == "" {
+		return nil, nil
+	}
+	schedule := &MockValueSchedule{
+		Min:     defaultMockValueMin,
+		Max:     defaultMockValueMax,
+		MaxStep: defaultMockValueMaxStep,
+		Seed:    time.Now().UnixNano(),
+	}
+	var err error
+	if schedule.Interval, err = time.ParseDuration(intervalStr); err != nil {
+		return nil, errors.Wrapf(err, "error parsing %s", MockValueIntervalEnvVar)
+	}
+	for _, setting := range []struct {
+		envVar string
+		value  *int
+	}{
+		{MockValueMinEnvVar, &schedule.Min},
+		{MockValueMaxEnvVar, &schedule.Max},
+		{MockValueMaxStepEnvVar, &schedule.MaxStep},
+	} {
+		if str := strings.TrimSpace(os.Getenv(setting.envVar)); str != "" {
+			if *setting.value, err = strconv.Atoi(str); err != nil {
+				return nil, errors.Wrapf(err, "error parsing %s", setting.envVar)
+			}
+		}
+	}
+	if seedStr := strings.TrimSpace(os.Getenv(MockValueSeedEnvVar)); seedStr != "" {
+		if schedule.Seed, err = strconv.ParseInt(seedStr, 10, 64); err != nil {
+			return nil, errors.Wrapf(err, "error parsing %s", MockValueSeedEnvVar)
+		}
+	}
+	schedule.Paths = splitList(os.Getenv(MockValuePathsEnvVar))
+	for _, valueStr := range splitList(os.Getenv(MockValueSeriesEnvVar)) {
+		value, err := strconv.Atoi(valueStr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing %s", MockValueSeriesEnvVar)
+		}
+		schedule.Series = append(schedule.Series, value)
+	}
+	if err = schedule.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid mock value schedule")
+	}
+	return schedule, nil
+}
+
+// splitList splits a comma separated list, dropping empty items
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Validate checks that the schedule has a positive interval, and a valid random walk unless it has a series. Paths are
+// checked when the routes are updated.
+func (s MockValueSchedule) Validate() error {
+	if s.Interval <= 0 {
+		return errors.Errorf("mock value interval must be positive, got %s", s.Interval)
+	}
+	if len(s.Series) > 0 {
+		return nil
+	}
+	if s.Min > s.Max {
+		return errors.Errorf("mock value min %d is above max %d", s.Min, s.Max)
+	}
+	if s.MaxStep < 1 {
+		return errors.Errorf("mock value max step must be at least 1, got %d", s.MaxStep)
+	}
+	return nil
+}
+
+// MockValues generates the successive values of a MockValueSchedule
+type MockValues struct {
+	schedule MockValueSchedule
+	rng      *rand.Rand
+	current  int
+	next     int // index of the next value of the series
+	started  bool
+}
+
+// Values returns a generator of the schedule's values. Generators of the same schedule, including its seed, return the
+// same values.
+func (s MockValueSchedule) Values() *MockValues {
+	return &MockValues{
+		schedule: s,
+		rng:      rand.New(rand.NewSource(s.Seed)), // #nosec G404 | Reproducible test values, not security sensitive
+		current:  s.Min + (s.Max-s.Min)/2,
+	}
+}
+
+// Next returns the next value: the next value of the series, wrapping around at its end, or the random walk moved by up
+// to MaxStep either way, kept within [Min, Max]. The first value of the random walk is its starting point.
+func (v *MockValues) Next() int {
+	if series := v.schedule.Series; len(series) > 0 {
+		value := series[v.next]
+		v.next = (v.next + 1) % len(series)
+		return value
+	}
+	if !v.started {
+		v.started = true
+		return v.current
+	}
+	v.current += v.rng.Intn(2*v.schedule.MaxStep+1) - v.schedule.MaxStep
+	if v.current < v.schedule.Min {
+		v.current = v.schedule.Min
+	}
+	if v.current > v.schedule.Max {
+		v.current = v.schedule.Max
+	}
+	return v.current
+}
+
+// DriveMockValues sets the routes at the schedule's paths to the schedule's values, as external adapter responses, see
+// AdapterMockRoute, every interval until ctx is done. Failed updates are logged and retried at the next interval rather
+// than ending the soak. It returns an error if there's nothing to update, or if the mockserver isn't reachable to begin
+// with.
+func DriveMockValues(ctx context.Context, mockServer *ctfClient.MockserverClient, schedule MockValueSchedule) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	if len(schedule.Paths) == 0 {
+		return errors.Errorf("no mock routes to drive the values of, set %s", MockValuePathsEnvVar)
+	}
+	if err := waitForMockserver(mockServer, mockserverReadyTimeout); err != nil {
+		return err
+	}
+	log.Info().
+		Strs("Paths", schedule.Paths).
+		Str("Interval", schedule.Interval.String()).
+		Int64("Seed", schedule.Seed).
+		Ints("Series", schedule.Series).
+		Msg("Driving mock values")
+	values := schedule.Values()
+	ticker := time.NewTicker(schedule.Interval)
+	defer ticker.Stop()
+	for {
+		value := values.Next()
+		routes := make([]MockRoute, len(schedule.Paths))
+		for i, path := range schedule.Paths {
+			routes[i] = AdapterMockRoute(path, value)
+		}
+		if err := putMockRoutes(mockServer, routes); err != nil {
+			log.Warn().Err(err).Int("Value", value).Msg("Error updating mock values, retrying at the next interval")
+		} else {
+			log.Debug().Int("Value", value).Msg("Updated mock values")
+		}
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Stopped driving mock values")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// DriveEnvironmentMockValues drives the mock values of a launched environment's mockserver, see DriveMockValues
+func DriveEnvironmentMockValues(ctx context.Context, testEnvironment *environment.Environment, schedule MockValueSchedule) error {
+	if testEnvironment.Cfg.DryRun {
+		log.Info().Strs("Paths", schedule.Paths).Msg("Dry-run mode, not driving mock values")
+		return nil
+	}
+	if len(testEnvironment.URLs[mockserver.URLsKey]) < 2 {
+		return errors.New("mock values were scheduled, but the environment has no mockserver")
+	}
+	mockServer, err := ctfClient.ConnectMockServer(testEnvironment)
+	if err != nil {
+		return errors.Wrap(err, "error connecting to mockserver")
+	}
+	return DriveMockValues(ctx, mockServer, schedule)
+}
@@ -0,0 +1,178 @@
+package testsetups
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ctfClient "github.com/smartcontractkit/chainlink-testing-framework/client"
+)
+
+func TestMockValues_RandomWalk(t *testing.T) {
+	schedule := MockValueSchedule{Interval: time.Minute, Min: 90, Max: 110, MaxStep: 5, Seed: 42}
+	values := func(n int) []int {
+		generator := schedule.Values()
+		generated := make([]int, n)
+		for i := range generated {
+			generated[i] = generator.Next()
+		}
+		return generated
+	}
+
+	walk := values(1000)
+	assert.Equal(t, walk, values(1000), "same seed should generate the same values")
+	assert.Equal(t, 100, walk[0], "should start halfway")
+	moved := false
+	for i, value := range walk {
+		require.GreaterOrEqual(t, value, schedule.Min)
+		require.LessOrEqual(t, value, schedule.Max)
+		if i > 0 {
+			require.LessOrEqual(t, abs(value-walk[i-1]), schedule.MaxStep)
+			moved = moved || value != walk[i-1]
+		}
+	}
+	assert.True(t, moved, "values should change over time")
+
+	schedule.Seed = 43
+	assert.NotEqual(t, walk, values(1000), "another seed should generate other values")
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func TestMockValues_Series(t *testing.T) {
+	generator := MockValueSchedule{Interval: time.Minute, Series: []int{5, 7, 6}}.Values()
+	var generated []int
+	for i := 0; i < 7; i++ {
+		generated = append(generated, generator.Next())
+	}
+	assert.Equal(t, []int{5, 7, 6, 5, 7, 6, 5}, generated)
+}
+
+func TestMockValueSchedule_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule MockValueSchedule
+		wantErr  string
+	}{
+		{name: "random walk", schedule: MockValueSchedule{Interval: time.Second, Min: 1, Max: 1, MaxStep: 1}},
+		{name: "series", schedule: MockValueSchedule{Interval: time.Second, Series: []int{1}}},
+		{name: "no interval", schedule: MockValueSchedule{Series: []int{1}}, wantErr: "interval must be positive"},
+		{name: "min above max", schedule: MockValueSchedule{Interval: time.Second, Min: 2, Max: 1, MaxStep: 1}, wantErr: "min 2 is above max 1"},
+		{name: "no step", schedule: MockValueSchedule{Interval: time.Second, Max: 1}, wantErr: "max step must be at least 1"},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			err := test.schedule.Validate()
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLoadMockValueSchedule(t *testing.T) {
+	unset := func(t *testing.T) {
+		for _, envVar := range []string{MockValueIntervalEnvVar, MockValuePathsEnvVar, MockValueMinEnvVar, MockValueMaxEnvVar,
+			MockValueMaxStepEnvVar, MockValueSeedEnvVar, MockValueSeriesEnvVar} {
+			t.Setenv(envVar, "")
+		}
+	}
+
+	t.Run("unset", func(t *testing.T) {
+		unset(t)
+		schedule, err := LoadMockValueSchedule()
+		require.NoError(t, err)
+		assert.Nil(t, schedule)
+	})
+	t.Run("defaults", func(t *testing.T) {
+		unset(t)
+		t.Setenv(MockValueIntervalEnvVar, "30s")
+		schedule, err := LoadMockValueSchedule()
+		require.NoError(t, err)
+		require.NotNil(t, schedule)
+		assert.Equal(t, 30*time.Second, schedule.Interval)
+		assert.Equal(t, defaultMockValueMin, schedule.Min)
+		assert.Equal(t, defaultMockValueMax, schedule.Max)
+		assert.Equal(t, defaultMockValueMaxStep, schedule.MaxStep)
+		assert.Empty(t, schedule.Paths)
+	})
+	t.Run("set", func(t *testing.T) {
+		unset(t)
+		t.Setenv(MockValueIntervalEnvVar, "1m")
+		t.Setenv(MockValuePathsEnvVar, "/ocr_price, /other_price,")
+		t.Setenv(MockValueMinEnvVar, "10")
+		t.Setenv(MockValueMaxEnvVar, "20")
+		t.Setenv(MockValueMaxStepEnvVar, "2")
+		t.Setenv(MockValueSeedEnvVar, "7")
+		t.Setenv(MockValueSeriesEnvVar, "10, 12,11")
+		schedule, err := LoadMockValueSchedule()
+		require.NoError(t, err)
+		assert.Equal(t, &MockValueSchedule{
+			Interval: time.Minute,
+			Paths:    []string{"/ocr_price", "/other_price"},
+			Min:      10,
+			Max:      20,
+			MaxStep:  2,
+			Seed:     7,
+			Series:   []int{10, 12, 11},
+		}, schedule)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		unset(t)
+		t.Setenv(MockValueIntervalEnvVar, "1m")
+		t.Setenv(MockValueSeriesEnvVar, "1,two")
+		_, err := LoadMockValueSchedule()
+		require.ErrorContains(t, err, "error parsing "+MockValueSeriesEnvVar)
+
+		t.Setenv(MockValueSeriesEnvVar, "")
+		t.Setenv(MockValueMinEnvVar, "2000")
+		_, err = LoadMockValueSchedule()
+		require.ErrorContains(t, err, "invalid mock value schedule")
+	})
+}
+
+func TestDriveMockValues(t *testing.T) {
+	srv := fakeMockserver(t)
+	mockServer := ctfClient.NewMockserverClient(&ctfClient.MockserverConfig{LocalURL: srv.URL, ClusterURL: srv.URL})
+	schedule := MockValueSchedule{
+		Interval: 10 * time.Millisecond,
+		Paths:    []string{"/a", "/b"},
+		Series:   []int{1, 2, 3},
+	}
+	result := func(path string) interface{} {
+		return getRoute(t, srv.URL+path)["data"].(map[string]interface{})["result"]
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- DriveMockValues(ctx, mockServer, schedule) }()
+	seen := map[float64]bool{}
+	require.Eventually(t, func() bool {
+		resp, err := srv.Client().Get(srv.URL + "/a")
+		if err != nil || resp.StatusCode != 200 {
+			return false
+		}
+		resp.Body.Close()
+		seen[result("/a").(float64)] = true
+		return len(seen) == 3
+	}, 5*time.Second, 5*time.Millisecond, "every value of the series should be set")
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, result("/a"), result("/b"), "all paths should have the same value")
+
+	t.Run("no paths", func(t *testing.T) {
+		err := DriveMockValues(context.Background(), mockServer, MockValueSchedule{Interval: time.Second, Series: []int{1}})
+		require.ErrorContains(t, err, "no mock routes")
+	})
+}
@@ -0,0 +1,107 @@
+package testsetups
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/smartcontractkit/chainlink-env/environment"
+	"github.com/smartcontractkit/chainlink-env/pkg/helm/mockserver"
+	ctfClient "github.com/smartcontractkit/chainlink-testing-framework/client"
+)
+
+const (
+	mockserverReadyTimeout      = 2 * time.Minute
+	mockserverReadyPollInterval = 2 * time.Second
+)
+
+// MockRoute is a response the soak mockserver returns for every request to a path, e.g. a price the nodes observe
+type MockRoute struct {
+	Path     string      // Path of the route, e.g. /ocr_price
+	Response interface{} // Body of the response, encoded as JSON
+}
+
+// AdapterMockRoute returns a route responding like an external adapter reporting result, the way the OCR soak's
+// bridges read their values
+func AdapterMockRoute(path string, result interface{}) MockRoute {
+	return MockRoute{
+		Path: path,
+		Response: map[string]interface{}{
+			"id":    "",
+			"data":  map[string]interface{}{"result": result},
+			"error": nil,
+		},
+	}
+}
+
+// seedEnvironmentMockRoutes pushes the routes to the mockserver of a launched environment
+func seedEnvironmentMockRoutes(testEnvironment *environment.Environment, routes []MockRoute) error {
+	if len(routes) == 0 {
+		return nil
+	}
+	if testEnvironment.Cfg.DryRun {
+		log.Info().Int("Routes", len(routes)).Msg("Dry-run mode, not seeding mockserver")
+		return nil
+	}
+	if len(testEnvironment.URLs[mockserver.URLsKey]) < 2 {
+		return errors.New("mock routes were given, but the environment has no mockserver")
+	}
+	mockServer, err := ctfClient.ConnectMockServer(testEnvironment)
+	if err != nil {
+		return errors.Wrap(err, "error connecting to mockserver")
+	}
+	return SeedMockRoutes(mockServer, routes)
+}
+
+// SeedMockRoutes waits for the mockserver to be reachable, then sets the response of each route, replacing any earlier
+// response for the same path. Seeding again with new responses changes the values the nodes observe over time.
+func SeedMockRoutes(mockServer *ctfClient.MockserverClient, routes []MockRoute) error {
+	if err := waitForMockserver(mockServer, mockserverReadyTimeout); err != nil {
+		return err
+	}
+	if err := putMockRoutes(mockServer, routes); err != nil {
+		return errors.Wrap(err, "error seeding mockserver routes")
+	}
+	log.Info().Int("Routes", len(routes)).Msg("Seeded mockserver routes")
+	return nil
+}
+
+// putMockRoutes sets the response of each route, replacing any earlier response for the same path
+func putMockRoutes(mockServer *ctfClient.MockserverClient, routes []MockRoute) error {
+	initializers := make([]ctfClient.HttpInitializer, len(routes))
+	for i, route := range routes {
+		if !strings.HasPrefix(route.Path, "/") {
+			return errors.Errorf("mock route path %q must start with /", route.Path)
+		}
+		initializers[i] = ctfClient.HttpInitializer{
+			// Mockserver replaces expectations by ID, so each path keeps a single response
+			Id:       fmt.Sprintf("%s_mock_id", strings.ReplaceAll(route.Path, "/", "_")),
+			Request:  ctfClient.HttpRequest{Path: route.Path},
+			Response: ctfClient.HttpResponse{Body: route.Response},
+		}
+	}
+	return mockServer.PutExpectations(initializers)
+}
+
+// waitForMockserver polls the mockserver's status until it responds, so seeding doesn't race its startup
+func waitForMockserver(mockServer *ctfClient.MockserverClient, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		resp, err := mockServer.APIClient.R().Put("/status")
+		if err == nil && resp.StatusCode() == http.StatusOK {
+			return nil
+		}
+		if err == nil {
+			err = errors.Errorf("unexpected status code %d", resp.StatusCode())
+		}
+		if time.Now().After(deadline) {
+			return errors.Wrapf(err, "mockserver at %s not reachable after %s", mockServer.Config.LocalURL, timeout)
+		}
+		log.Debug().Err(err).Str("URL", mockServer.Config.LocalURL).Msg("Waiting for mockserver")
+		time.Sleep(mockserverReadyPollInterval)
+	}
+}
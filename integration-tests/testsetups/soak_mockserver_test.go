@@ -0,0 +1,102 @@
+package testsetups
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ctfClient "github.com/smartcontractkit/chainlink-testing-framework/client"
+)
+
+// fakeMockserver implements the parts of the mockserver API used for seeding, answering requests to seeded paths with
+// their responses
+func fakeMockserver(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	routes := map[string][]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/status":
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodPut && r.URL.Path == "/expectation":
+			var initializers []ctfClient.HttpInitializer
+			if err := json.NewDecoder(r.Body).Decode(&initializers); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			for _, initializer := range initializers {
+				body, err := json.Marshal(initializer.Response.Body)
+				if err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				routes[initializer.Request.Path] = body
+			}
+			w.WriteHeader(http.StatusCreated)
+		default:
+			body, ok := routes[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(body)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func getRoute(t *testing.T, url string) map[string]interface{} {
+	resp, err := http.Get(url) //nolint:gosec // Test server URL
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &payload))
+	return payload
+}
+
+func TestSeedMockRoutes(t *testing.T) {
+	srv := fakeMockserver(t)
+	mockServer := ctfClient.NewMockserverClient(&ctfClient.MockserverConfig{LocalURL: srv.URL, ClusterURL: srv.URL})
+
+	require.NoError(t, SeedMockRoutes(mockServer, []MockRoute{
+		AdapterMockRoute("/ocr_price", 5),
+		{Path: "/custom", Response: map[string]interface{}{"price": "1.5"}},
+	}))
+	assert.Equal(t, map[string]interface{}{
+		"id":    "",
+		"data":  map[string]interface{}{"result": float64(5)},
+		"error": nil,
+	}, getRoute(t, srv.URL+"/ocr_price"))
+	assert.Equal(t, map[string]interface{}{"price": "1.5"}, getRoute(t, srv.URL+"/custom"))
+
+	t.Run("reseeding changes the response", func(t *testing.T) {
+		require.NoError(t, SeedMockRoutes(mockServer, []MockRoute{AdapterMockRoute("/ocr_price", 7)}))
+		assert.Equal(t, float64(7), getRoute(t, srv.URL+"/ocr_price")["data"].(map[string]interface{})["result"])
+	})
+
+	t.Run("rejects relative paths", func(t *testing.T) {
+		require.Error(t, SeedMockRoutes(mockServer, []MockRoute{AdapterMockRoute("ocr_price", 5)}))
+	})
+}
+
+func TestWaitForMockserver_Unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+	mockServer := ctfClient.NewMockserverClient(&ctfClient.MockserverConfig{LocalURL: srv.URL, ClusterURL: srv.URL})
+
+	err := waitForMockserver(mockServer, time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not reachable")
+}
@@ -0,0 +1,153 @@
+package testsetups
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/smartcontractkit/chainlink-env/environment"
+)
+
+const (
+	// RampInitialNodesEnvVar sets how many Chainlink nodes the soak environment is launched with, the others being added
+	// over time. Set, it enables the ramp.
+	RampInitialNodesEnvVar = "SOAK_RAMP_INITIAL_NODES"
+	// RampIncrementEnvVar sets how many nodes are added at each step of the ramp
+	RampIncrementEnvVar  = "SOAK_RAMP_INCREMENT"
+	defaultRampIncrement = 1
+	// RampIntervalEnvVar sets the time between steps of the ramp
+	RampIntervalEnvVar = "SOAK_RAMP_INTERVAL"
+)
+
+// NodeRamp describes launching a soak environment with a few Chainlink nodes and adding more on a schedule, rather
+// than launching all of them upfront, e.g. to test autoscaling behavior
+type NodeRamp struct {
+	InitialCount int           // Nodes launched with the environment
+	Increment    int           // Nodes added at each step
+	Interval     time.Duration // Time between steps, the first step being one interval after the launch
+}
+
+// RampStep is a step of a NodeRamp's schedule
+type RampStep struct {
+	After     time.Duration // Time since the environment was launched
+	NodeCount int           // Total nodes once the step is done
+}
+
+// LoadNodeRamp reads the ramp from the SOAK_RAMP_* env vars. It returns nil if SOAK_RAMP_INITIAL_NODES isn't set,
+// launching all nodes upfront. The increment defaults to 1 node, and the interval has to be set.
+func LoadNodeRamp() (*NodeRamp, error) {
+	initialStr := strings.TrimSpace(os.Getenv(RampInitialNodesEnvVar))
+	if initialStr == "" {
+		return nil, nil
+	}
+	ramp := &NodeRamp{Increment: defaultRampIncrement}
+	var err error
+	if ramp.InitialCount, err = strconv.Atoi(initialStr); err != nil {
+		return nil, errors.Wrapf(err, "error parsing %s", RampInitialNodesEnvVar)
+	}
+	if incrementStr := strings.TrimSpace(os.Getenv(RampIncrementEnvVar)); incrementStr != "" {
+		if ramp.Increment, err = strconv.Atoi(incrementStr); err != nil {
+			return nil, errors.Wrapf(err, "error parsing %s", RampIncrementEnvVar)
+		}
+	}
+	intervalStr := strings.TrimSpace(os.Getenv(RampIntervalEnvVar))
+	if intervalStr == "" {
+		return nil, errors.Errorf("%s is set, but %s isn't", RampInitialNodesEnvVar, RampIntervalEnvVar)
+	}
+	if ramp.Interval, err = time.ParseDuration(intervalStr); err != nil {
+		return nil, errors.Wrapf(err, "error parsing %s", RampIntervalEnvVar)
+	}
+	if err = ramp.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid node ramp")
+	}
+	return ramp, nil
+}
+
+// Validate checks that the ramp launches and adds at least one node at a time, with a positive interval
+func (r NodeRamp) Validate() error {
+	if r.InitialCount < 1 {
+		return errors.Errorf("initial node count must be at least 1, got %d", r.InitialCount)
+	}
+	if r.Increment < 1 {
+		return errors.Errorf("node increment must be at least 1, got %d", r.Increment)
+	}
+	if r.Interval <= 0 {
+		return errors.Errorf("ramp interval must be positive, got %s", r.Interval)
+	}
+	return nil
+}
+
+// InitialNodes returns how many of the totalNodes are launched with the environment
+func (r NodeRamp) InitialNodes(totalNodes int) int {
+	if r.InitialCount < totalNodes {
+		return r.InitialCount
+	}
+	return totalNodes
+}
+
+// Schedule returns the steps adding nodes after the launch until totalNodes are running, the last step adding fewer
+// than the increment if needed. It's empty if all nodes are launched upfront.
+func (r NodeRamp) Schedule(totalNodes int) []RampStep {
+	var steps []RampStep
+	for count := r.InitialNodes(totalNodes); count < totalNodes; {
+		count += r.Increment
+		if count > totalNodes {
+			count = totalNodes
+		}
+		steps = append(steps, RampStep{After: time.Duration(len(steps)+1) * r.Interval, NodeCount: count})
+	}
+	return steps
+}
+
+// RampChainlinkNodes adds Chainlink nodes to a launched environment following the schedule, re-running the environment
+// after each step so the new nodes are deployed alongside the running ones, and waiting for them to become ready like
+// the launch does. nodes are the charts of the nodes left out of the launch, in order, e.g. chainlink.New(i, values)
+// for i from the initial count onwards. They're built before the launch, so they carry the same values, e.g. TOML
+// config and resources, as if they had been launched upfront.
+//
+// The soak test connects to the nodes it's launched with, so added nodes don't get its jobs, see LoadSoakNodeCount.
+// A step failing to deploy tears the environment down, as the environment does for any failed run.
+// RampChainlinkNodes returns once the schedule is done, or as soon as ctx is.
+func RampChainlinkNodes(
+	ctx context.Context,
+	testEnvironment *environment.Environment,
+	nodes []environment.ConnectedChart,
+	schedule []RampStep,
+) error {
+	if len(schedule) == 0 {
+		return nil
+	}
+	finalCount := schedule[len(schedule)-1].NodeCount
+	initialCount := finalCount - len(nodes)
+	if initialCount < 0 {
+		return errors.Errorf("ramp schedule ends with %d nodes, fewer than the %d to add", finalCount, len(nodes))
+	}
+	launched := time.Now()
+	added := 0
+	for _, step := range schedule {
+		timer := time.NewTimer(time.Until(launched.Add(step.After)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Info().Int("Nodes", initialCount+added).Msg("Stopped ramping Chainlink nodes")
+			return nil
+		case <-timer.C:
+		}
+		for ; initialCount+added < step.NodeCount; added++ {
+			testEnvironment.AddHelm(nodes[added])
+		}
+		log.Info().Int("Nodes", step.NodeCount).Str("After", step.After.String()).Msg("Ramping up Chainlink nodes")
+		if err := testEnvironment.Run(); err != nil {
+			return errors.Wrapf(err, "error adding Chainlink nodes, ramping up to %d", step.NodeCount)
+		}
+		if err := waitForChainlinkNodes(ctx, testEnvironment); err != nil {
+			return errors.Wrapf(err, "error waiting for Chainlink nodes, ramping up to %d", step.NodeCount)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package testsetups
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-env/environment"
+)
+
+func TestNodeRamp_Schedule(t *testing.T) {
+	tests := []struct {
+		name         string
+		ramp         NodeRamp
+		totalNodes   int
+		wantInitial  int
+		wantSchedule []RampStep
+	}{
+		{"one at a time", NodeRamp{InitialCount: 2, Increment: 1, Interval: time.Hour}, 4, 2, []RampStep{
+			{After: time.Hour, NodeCount: 3},
+			{After: 2 * time.Hour, NodeCount: 4},
+		}},
+		{"last step adds the remainder", NodeRamp{InitialCount: 1, Increment: 2, Interval: time.Minute}, 6, 1, []RampStep{
+			{After: time.Minute, NodeCount: 3},
+			{After: 2 * time.Minute, NodeCount: 5},
+			{After: 3 * time.Minute, NodeCount: 6},
+		}},
+		{"all upfront", NodeRamp{InitialCount: 6, Increment: 1, Interval: time.Minute}, 6, 6, nil},
+		{"initial count above the total", NodeRamp{InitialCount: 8, Increment: 1, Interval: time.Minute}, 6, 6, nil},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			require.NoError(t, test.ramp.Validate())
+			assert.Equal(t, test.wantInitial, test.ramp.InitialNodes(test.totalNodes))
+			assert.Equal(t, test.wantSchedule, test.ramp.Schedule(test.totalNodes))
+		})
+	}
+}
+
+func TestNodeRamp_Validate(t *testing.T) {
+	assert.Error(t, NodeRamp{InitialCount: 0, Increment: 1, Interval: time.Minute}.Validate())
+	assert.Error(t, NodeRamp{InitialCount: 1, Increment: 0, Interval: time.Minute}.Validate())
+	assert.Error(t, NodeRamp{InitialCount: 1, Increment: 1}.Validate())
+}
+
+func TestLoadNodeRamp(t *testing.T) {
+	t.Run("unset", func(t *testing.T) {
+		t.Setenv(RampInitialNodesEnvVar, "")
+		ramp, err := LoadNodeRamp()
+		require.NoError(t, err)
+		assert.Nil(t, ramp)
+	})
+	t.Run("default increment", func(t *testing.T) {
+		t.Setenv(RampInitialNodesEnvVar, "2")
+		t.Setenv(RampIncrementEnvVar, "")
+		t.Setenv(RampIntervalEnvVar, "30m")
+		ramp, err := LoadNodeRamp()
+		require.NoError(t, err)
+		assert.Equal(t, &NodeRamp{InitialCount: 2, Increment: 1, Interval: 30 * time.Minute}, ramp)
+	})
+	t.Run("missing interval", func(t *testing.T) {
+		t.Setenv(RampInitialNodesEnvVar, "2")
+		t.Setenv(RampIntervalEnvVar, "")
+		_, err := LoadNodeRamp()
+		require.ErrorContains(t, err, RampIntervalEnvVar)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Setenv(RampInitialNodesEnvVar, "2")
+		t.Setenv(RampIncrementEnvVar, "0")
+		t.Setenv(RampIntervalEnvVar, "30m")
+		_, err := LoadNodeRamp()
+		require.Error(t, err)
+	})
+}
+
+func TestRampChainlinkNodes_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	schedule := NodeRamp{InitialCount: 1, Increment: 1, Interval: time.Hour}.Schedule(2)
+	// The environment isn't touched before the first step is due
+	require.NoError(t, RampChainlinkNodes(ctx, nil, make([]environment.ConnectedChart, 1), schedule))
+
+	err := RampChainlinkNodes(context.Background(), nil, make([]environment.ConnectedChart, 3), schedule)
+	require.ErrorContains(t, err, "fewer than the 3 to add")
+}
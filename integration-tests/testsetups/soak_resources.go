@@ -0,0 +1,134 @@
+package testsetups
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// NodeCPURequestEnvVar sets the CPU requested by each Chainlink node, e.g. 500m
+	NodeCPURequestEnvVar = "CL_NODE_CPU_REQUEST"
+	// NodeMemoryRequestEnvVar sets the memory requested by each Chainlink node, e.g. 1Gi
+	NodeMemoryRequestEnvVar = "CL_NODE_MEMORY_REQUEST"
+	// NodeCPULimitEnvVar sets the CPU limit of each Chainlink node
+	NodeCPULimitEnvVar = "CL_NODE_CPU_LIMIT"
+	// NodeMemoryLimitEnvVar sets the memory limit of each Chainlink node
+	NodeMemoryLimitEnvVar = "CL_NODE_MEMORY_LIMIT"
+)
+
+// NodeResources are the CPU and memory requests and limits of a Chainlink node container. Unset values keep the
+// chart's defaults.
+type NodeResources struct {
+	Requests ResourceValues `yaml:"requests"`
+	Limits   ResourceValues `yaml:"limits"`
+}
+
+// ResourceValues are kubernetes resource quantities, e.g. 500m of CPU or 1Gi of memory
+type ResourceValues struct {
+	CPU    string `yaml:"cpu"`
+	Memory string `yaml:"memory"`
+}
+
+// LoadNodeResources reads the resources of every Chainlink node from CL_NODE_CPU_REQUEST, CL_NODE_MEMORY_REQUEST,
+// CL_NODE_CPU_LIMIT and CL_NODE_MEMORY_LIMIT, returning an error if they aren't valid.
+func LoadNodeResources() (NodeResources, error) {
+	resources := NodeResources{
+		Requests: ResourceValues{
+			CPU:    strings.TrimSpace(os.Getenv(NodeCPURequestEnvVar)),
+			Memory: strings.TrimSpace(os.Getenv(NodeMemoryRequestEnvVar)),
+		},
+		Limits: ResourceValues{
+			CPU:    strings.TrimSpace(os.Getenv(NodeCPULimitEnvVar)),
+			Memory: strings.TrimSpace(os.Getenv(NodeMemoryLimitEnvVar)),
+		},
+	}
+	if err := resources.Validate(); err != nil {
+		return NodeResources{}, errors.Wrap(err, "invalid Chainlink node resources")
+	}
+	return resources, nil
+}
+
+// Merge returns the resources with the values set in overrides taking precedence
+func (r NodeResources) Merge(overrides NodeResources) NodeResources {
+	merge := func(value, override string) string {
+		if override != "" {
+			return override
+		}
+		return value
+	}
+	return NodeResources{
+		Requests: ResourceValues{
+			CPU:    merge(r.Requests.CPU, overrides.Requests.CPU),
+			Memory: merge(r.Requests.Memory, overrides.Requests.Memory),
+		},
+		Limits: ResourceValues{
+			CPU:    merge(r.Limits.CPU, overrides.Limits.CPU),
+			Memory: merge(r.Limits.Memory, overrides.Limits.Memory),
+		},
+	}
+}
+
+// Validate checks that the set values are positive resource quantities, and that no request exceeds its limit.
+// Requests and limits left unset come from the chart, so are only compared to the values set along with them.
+func (r NodeResources) Validate() error {
+	for _, res := range []struct {
+		name           string
+		request, limit string
+	}{
+		{"cpu", r.Requests.CPU, r.Limits.CPU},
+		{"memory", r.Requests.Memory, r.Limits.Memory},
+	} {
+		request, err := parseResourceQuantity(res.request)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s request", res.name)
+		}
+		limit, err := parseResourceQuantity(res.limit)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s limit", res.name)
+		}
+		if request != nil && limit != nil && request.Cmp(*limit) > 0 {
+			return errors.Errorf("%s request %s exceeds its limit %s", res.name, res.request, res.limit)
+		}
+	}
+	return nil
+}
+
+// parseResourceQuantity parses a positive resource quantity, returning nil if it's unset
+func parseResourceQuantity(value string) (*resource.Quantity, error) {
+	if value == "" {
+		return nil, nil
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing %q", value)
+	}
+	if quantity.Sign() <= 0 {
+		return nil, errors.Errorf("%q must be positive", value)
+	}
+	return &quantity, nil
+}
+
+// HelmValues returns the set resources as Chainlink chart values, to be set under the chart's chainlink.resources, or
+// nil if none are set
+func (r NodeResources) HelmValues() map[string]interface{} {
+	values := map[string]interface{}{}
+	for key, res := range map[string]ResourceValues{"requests": r.Requests, "limits": r.Limits} {
+		resValues := map[string]interface{}{}
+		if res.CPU != "" {
+			resValues["cpu"] = res.CPU
+		}
+		if res.Memory != "" {
+			resValues["memory"] = res.Memory
+		}
+		if len(resValues) > 0 {
+			values[key] = resValues
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
@@ -0,0 +1,112 @@
+package testsetups
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeResources_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources NodeResources
+		wantErr   string
+	}{
+		{
+			name: "unset",
+		},
+		{
+			name: "valid",
+			resources: NodeResources{
+				Requests: ResourceValues{CPU: "500m", Memory: "512Mi"},
+				Limits:   ResourceValues{CPU: "1", Memory: "1Gi"},
+			},
+		},
+		{
+			name:      "request without limit",
+			resources: NodeResources{Requests: ResourceValues{CPU: "2"}},
+		},
+		{
+			name:      "malformed",
+			resources: NodeResources{Limits: ResourceValues{Memory: "1GB of RAM"}},
+			wantErr:   `invalid memory limit: error parsing "1GB of RAM"`,
+		},
+		{
+			name:      "not positive",
+			resources: NodeResources{Requests: ResourceValues{CPU: "0"}},
+			wantErr:   `invalid cpu request: "0" must be positive`,
+		},
+		{
+			name: "request above limit",
+			resources: NodeResources{
+				Requests: ResourceValues{Memory: "2Gi"},
+				Limits:   ResourceValues{Memory: "1024Mi"},
+			},
+			wantErr: "memory request 2Gi exceeds its limit 1024Mi",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			err := test.resources.Validate()
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNodeResources_MergeAndHelmValues(t *testing.T) {
+	base := NodeResources{
+		Requests: ResourceValues{CPU: "500m", Memory: "512Mi"},
+		Limits:   ResourceValues{CPU: "1"},
+	}
+	merged := base.Merge(NodeResources{
+		Requests: ResourceValues{Memory: "256Mi"},
+		Limits:   ResourceValues{Memory: "256Mi"},
+	})
+	assert.Equal(t, NodeResources{
+		Requests: ResourceValues{CPU: "500m", Memory: "256Mi"},
+		Limits:   ResourceValues{CPU: "1", Memory: "256Mi"},
+	}, merged)
+	assert.Equal(t, map[string]interface{}{
+		"requests": map[string]interface{}{"cpu": "500m", "memory": "256Mi"},
+		"limits":   map[string]interface{}{"cpu": "1", "memory": "256Mi"},
+	}, merged.HelmValues())
+
+	assert.Equal(t, map[string]interface{}{
+		"limits": map[string]interface{}{"cpu": "1"},
+	}, NodeResources{Limits: ResourceValues{CPU: "1"}}.HelmValues())
+	assert.Nil(t, NodeResources{}.HelmValues())
+}
+
+func TestLoadNodeResources(t *testing.T) {
+	t.Run("unset", func(t *testing.T) {
+		for _, envVar := range []string{NodeCPURequestEnvVar, NodeMemoryRequestEnvVar, NodeCPULimitEnvVar, NodeMemoryLimitEnvVar} {
+			t.Setenv(envVar, "")
+		}
+		resources, err := LoadNodeResources()
+		require.NoError(t, err)
+		assert.Equal(t, NodeResources{}, resources)
+	})
+	t.Run("set", func(t *testing.T) {
+		t.Setenv(NodeCPURequestEnvVar, "250m")
+		t.Setenv(NodeMemoryRequestEnvVar, "512Mi")
+		t.Setenv(NodeCPULimitEnvVar, "1")
+		t.Setenv(NodeMemoryLimitEnvVar, " 1Gi ")
+		resources, err := LoadNodeResources()
+		require.NoError(t, err)
+		assert.Equal(t, NodeResources{
+			Requests: ResourceValues{CPU: "250m", Memory: "512Mi"},
+			Limits:   ResourceValues{CPU: "1", Memory: "1Gi"},
+		}, resources)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Setenv(NodeCPURequestEnvVar, "lots")
+		_, err := LoadNodeResources()
+		require.ErrorContains(t, err, "invalid Chainlink node resources: invalid cpu request")
+	})
+}
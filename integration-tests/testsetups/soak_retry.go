@@ -0,0 +1,79 @@
+package testsetups
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// LaunchAttemptsEnvVar overrides how many times a soak launch is attempted when it fails on transient errors
+	LaunchAttemptsEnvVar  = "SOAK_LAUNCH_ATTEMPTS"
+	defaultLaunchAttempts = 3
+	// TransientErrorsEnvVar adds comma separated regular expressions matching launch errors that are worth retrying
+	TransientErrorsEnvVar = "SOAK_LAUNCH_TRANSIENT_ERRORS"
+)
+
+// DefaultTransientErrors match launch errors caused by the cluster rather than the test, which usually go away when
+// launching again
+var DefaultTransientErrors = []*regexp.Regexp{
+	regexp.MustCompile(`ErrImagePull|ImagePullBackOff`),
+	regexp.MustCompile(`failed calling webhook`),
+	regexp.MustCompile(`i/o timeout|TLS handshake timeout|connection reset by peer|connection refused`),
+	regexp.MustCompile(`the server is currently unable to handle the request|etcdserver: request timed out`),
+	regexp.MustCompile(`Too Many Requests`),
+}
+
+// LaunchRetry configures retrying soak launches that fail on transient errors. Errors not matching any of the
+// transient errors, e.g. an invalid node config, are treated as config errors and are never retried.
+type LaunchRetry struct {
+	Attempts        int              // Total launch attempts, including the first
+	TransientErrors []*regexp.Regexp // Errors matching any of these are retried
+}
+
+// DefaultLaunchRetry attempts a launch SOAK_LAUNCH_ATTEMPTS times, 3 by default, retrying the DefaultTransientErrors
+// along with any listed in SOAK_LAUNCH_TRANSIENT_ERRORS.
+func DefaultLaunchRetry() (*LaunchRetry, error) {
+	retry := &LaunchRetry{
+		Attempts:        defaultLaunchAttempts,
+		TransientErrors: append([]*regexp.Regexp(nil), DefaultTransientErrors...),
+	}
+	if attemptsStr := strings.TrimSpace(os.Getenv(LaunchAttemptsEnvVar)); attemptsStr != "" {
+		attempts, err := strconv.Atoi(attemptsStr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing %s", LaunchAttemptsEnvVar)
+		}
+		if attempts < 1 {
+			return nil, errors.Errorf("%s must be at least 1, got %d", LaunchAttemptsEnvVar, attempts)
+		}
+		retry.Attempts = attempts
+	}
+	for _, pattern := range strings.Split(os.Getenv(TransientErrorsEnvVar), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern in %s", TransientErrorsEnvVar)
+		}
+		retry.TransientErrors = append(retry.TransientErrors, re)
+	}
+	return retry, nil
+}
+
+// IsTransient reports whether a launch error is worth retrying in a fresh environment
+func (r *LaunchRetry) IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	for _, re := range r.TransientErrors {
+		if re.MatchString(msg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package testsetups
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/chainlink-env/environment"
+	"github.com/smartcontractkit/chainlink-testing-framework/blockchain"
+)
+
+func TestLaunchSoakEnvironment_Cancel(t *testing.T) {
+	tests := []struct {
+		name         string
+		keepEnvs     string
+		finishes     bool // whether the launch returns within cancelledLaunchWait
+		wantShutdown bool
+	}{
+		{"tears down", "", true, true},
+		{"keeps on failure", "ONFAIL", true, false},
+		{"leaves a launch still running", "", false, false},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("KEEP_ENVIRONMENTS", test.keepEnvs)
+
+			// The launch blocks until released, ignoring ctx like the environment's calls do
+			started, release, returned := make(chan struct{}), make(chan struct{}), make(chan struct{})
+			var releaseOnce sync.Once
+			releaseLaunch := func() { releaseOnce.Do(func() { close(release) }) }
+			t.Cleanup(releaseLaunch)
+			launchAttempt = func(context.Context, SoakLaunchInputs, *environment.Environment, map[string]interface{}) error {
+				close(started)
+				<-release
+				close(returned)
+				return nil
+			}
+			shutdown := make(chan *environment.Environment, 1)
+			shutdownEnvironment = func(e *environment.Environment) error {
+				select {
+				case <-returned:
+				default:
+					t.Error("environment shut down while the launch was still running")
+				}
+				shutdown <- e
+				return nil
+			}
+			cancelledLaunchWait = 10 * time.Second
+			if !test.finishes {
+				cancelledLaunchWait = 10 * time.Millisecond
+			}
+			t.Cleanup(func() {
+				launchAttempt = launchSoakAttempt
+				shutdownEnvironment = (*environment.Environment).Shutdown
+				cancelledLaunchWait = 5 * time.Minute
+			})
+
+			testEnvironment := &environment.Environment{Cfg: &environment.Config{Namespace: "soak-cancel"}}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			launched := make(chan error, 1)
+			go func() {
+				_, err := LaunchSoakEnvironment(ctx, SoakLaunchInputs{
+					TestName:    t.Name(),
+					Environment: testEnvironment,
+					Networks:    []SoakNetwork{EVMSoakNetwork(blockchain.EVMNetwork{Name: "Simulated Geth", ChainID: 1337})},
+					Retry:       &LaunchRetry{Attempts: 3},
+				})
+				launched <- err
+			}()
+
+			<-started
+			cancel()
+			if test.finishes {
+				// the step in progress completes after the cancellation
+				time.Sleep(50 * time.Millisecond)
+				assert.Empty(t, shutdown, "environment shut down before the launch returned")
+				releaseLaunch()
+			}
+			select {
+			case err := <-launched:
+				require.ErrorIs(t, err, context.Canceled)
+			case <-time.After(10 * time.Second):
+				t.Fatal("launch didn't return after being cancelled")
+			}
+			if test.wantShutdown {
+				require.Len(t, shutdown, 1)
+				assert.Same(t, testEnvironment, <-shutdown)
+			} else {
+				assert.Empty(t, shutdown)
+			}
+		})
+	}
+}
+
+func TestLoadSoakNodeCount(t *testing.T) {
+	t.Setenv("CL_NODE_COUNT", "")
+	count, err := LoadSoakNodeCount()
+	require.NoError(t, err)
+	assert.Equal(t, defaultSoakNodeCount, count)
+
+	t.Setenv("CL_NODE_COUNT", "16")
+	count, err = LoadSoakNodeCount()
+	require.NoError(t, err)
+	assert.Equal(t, 16, count)
+
+	t.Setenv("CL_NODE_COUNT", "0")
+	_, err = LoadSoakNodeCount()
+	require.ErrorContains(t, err, "must be positive")
+}
+
+func TestEVMSoakNetworks(t *testing.T) {
+	primary := blockchain.EVMNetwork{
+		Name:                      "Simulated Geth",
+		ChainID:                   1337,
+		URLs:                      []string{"ws://geth:8546"},
+		HTTPURLs:                  []string{"http://geth:8544"},
+		Simulated:                 true,
+		PrivateKeys:               []string{"primaryKey"},
+		ChainlinkTransactionLimit: 500000,
+		Timeout:                   2 * time.Minute,
+		MinimumConfirmations:      1,
+		GasEstimationBuffer:       1000,
+		ClientImplementation:      blockchain.EthereumClientImplementation,
+	}
+	secondary := primary
+	secondary.Name, secondary.ChainID, secondary.Simulated = "Goerli", 5, false
+	secondary.URLs, secondary.HTTPURLs = []string{"wss://goerli-0", "wss://goerli-1"}, []string{"https://goerli"}
+	secondary.PrivateKeys = []string{"secondaryKey"}
+	secondary.MinimumConfirmations = 3
+
+	soakNetworks := EVMSoakNetworks(primary, secondary)
+	require.Len(t, soakNetworks, 2)
+	networkValues, err := networkRunnerValues(soakNetworks)
+	require.NoError(t, err)
+	assert.Equal(t, "1337,5", networkValues[evmChainIDsKey])
+	assert.Equal(t, "Simulated Geth", networkValues["evm_name"], "the primary network is set without a prefix")
+	assert.Equal(t, "Goerli", networkValues["chain_5_evm_name"])
+
+	// The remote test runner sets its values as env vars
+	for key, value := range networkValues {
+		t.Setenv(strings.ToUpper(key), fmt.Sprint(value))
+	}
+	loaded, err := LoadEVMSoakNetworks()
+	require.NoError(t, err)
+	assert.Equal(t, []blockchain.EVMNetwork{primary, secondary}, loaded)
+}
+
+func TestEVMSoakNetworks_SingleSimulated(t *testing.T) {
+	simulated := blockchain.EVMNetwork{Name: "Simulated Geth", ChainID: 1337, Simulated: true}
+	other := blockchain.EVMNetwork{Name: "Other Simulated Geth", ChainID: 2337, Simulated: true}
+	_, err := networkRunnerValues(EVMSoakNetworks(simulated, other))
+	require.ErrorContains(t, err, "more than one network deploys the geth chart")
+}
+
+func TestSolanaSoakNetwork(t *testing.T) {
+	network := SolanaNetwork{Name: "sol", ChainID: "devnet", URL: "http://sol:8899", WSURL: "ws://sol:8900"}
+	soakNetwork := SolanaSoakNetwork(network)
+	assert.Equal(t, "sol", soakNetwork.Chart.GetName())
+	assert.True(t, soakNetwork.Chart.IsDeploymentNeeded())
+
+	networkValues, err := networkRunnerValues(append(EVMSoakNetworks(blockchain.EVMNetwork{Name: "Goerli", ChainID: 5}), soakNetwork))
+	require.NoError(t, err, "a Solana network can run alongside EVM ones")
+	for key, value := range networkValues {
+		t.Setenv(strings.ToUpper(key), fmt.Sprint(value))
+	}
+	loaded, err := LoadSolanaSoakNetwork()
+	require.NoError(t, err)
+	assert.Equal(t, network, loaded)
+}
+
+func TestLoadSolanaSoakNetwork_Default(t *testing.T) {
+	loaded, err := LoadSolanaSoakNetwork()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSolanaNetwork(), loaded)
+}